@@ -0,0 +1,189 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DirectoryActivity summarizes recent changes in one directory
+type DirectoryActivity struct {
+	Directory string
+	// Files is the number of distinct files touched
+	Files int
+	// Changes counts file changes: a file changed in three commits counts 3
+	Changes int
+	// Authors are ordered by their number of changes in the directory
+	Authors []AuthorShare
+	// Theme is an AI-written summary of the work, empty when unavailable
+	Theme string
+
+	subjects []string
+}
+
+// AuthorShare is an author's number of changes in a directory
+type AuthorShare struct {
+	Name    string
+	Changes int
+}
+
+// maxActivityDirectories bounds the directories in an activity report
+const maxActivityDirectories = 10
+
+// DirectoryActivity reports the most changed directories (truncated to
+// depth components) since a git date such as "90.days", most active first
+func (gc *GitCommenter) DirectoryActivity(since string, depth int) ([]DirectoryActivity, error) {
+	output, err := gc.gitOutput("log", "--since="+since, "--name-only", "--format=%x1e%an%x00%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	activity := parseActivity(output, depth)
+	if len(activity) > maxActivityDirectories {
+		activity = activity[:maxActivityDirectories]
+	}
+	return activity, nil
+}
+
+// parseActivity aggregates log records of an author, a subject and the
+// touched files per directory
+func parseActivity(output string, depth int) []DirectoryActivity {
+	byDir := make(map[string]*DirectoryActivity)
+	files := make(map[string]map[string]bool)
+	authors := make(map[string]map[string]int)
+
+	for _, record := range strings.Split(output, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		author, subject, ok := strings.Cut(lines[0], "\x00")
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, file := range splitLines(strings.Join(lines[1:], "\n")) {
+			dir := countByDirectory([]string{file}, depth)[0].Directory
+			entry := byDir[dir]
+			if entry == nil {
+				entry = &DirectoryActivity{Directory: dir}
+				byDir[dir] = entry
+				files[dir] = make(map[string]bool)
+				authors[dir] = make(map[string]int)
+			}
+			entry.Changes++
+			files[dir][file] = true
+			authors[dir][author]++
+			if !seen[dir] {
+				seen[dir] = true
+				entry.subjects = append(entry.subjects, subject)
+			}
+		}
+	}
+
+	activity := make([]DirectoryActivity, 0, len(byDir))
+	for dir, entry := range byDir {
+		entry.Files = len(files[dir])
+		for _, count := range sortedCounts(authors[dir]) {
+			entry.Authors = append(entry.Authors, AuthorShare{Name: count.Directory, Changes: count.Count})
+		}
+		activity = append(activity, *entry)
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		if activity[i].Changes != activity[j].Changes {
+			return activity[i].Changes > activity[j].Changes
+		}
+		return activity[i].Directory < activity[j].Directory
+	})
+	return activity
+}
+
+// maxThemeSubjects bounds the commit subjects sent per directory
+const maxThemeSubjects = 15
+
+// SummarizeThemes asks the model for a short theme per directory based on
+// its commit subjects. On failure the themes are left empty.
+func (gc *GitCommenter) SummarizeThemes(activity []DirectoryActivity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Below are directories of a repository with the subjects of recent commits touching them.\n")
+	prompt.WriteString("For each directory, summarize the theme of the work in at most 8 words.\n")
+	prompt.WriteString("Answer with exactly one line per directory in the form 'directory: theme' and nothing else.\n\n")
+	for _, entry := range activity {
+		prompt.WriteString(entry.Directory + "\n")
+		for i, subject := range entry.subjects {
+			if i >= maxThemeSubjects {
+				break
+			}
+			prompt.WriteString("- " + subject + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return fmt.Errorf("failed to summarize themes: %w", err)
+	}
+
+	themes := parseThemes(response)
+	for i := range activity {
+		activity[i].Theme = themes[activity[i].Directory]
+	}
+	return nil
+}
+
+// parseThemes reads "directory: theme" lines, tolerating list markers and
+// backticks around the directory
+func parseThemes(response string) map[string]string {
+	themes := make(map[string]string)
+	for _, line := range splitLines(response) {
+		line = strings.TrimLeft(line, "-*• ")
+		dir, theme, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		dir = strings.Trim(dir, "`*")
+		themes[dir] = strings.TrimSpace(theme)
+	}
+	return themes
+}
+
+// FormatActivityTable renders the activity as a markdown table with a bar
+// showing where churn concentrates
+func FormatActivityTable(activity []DirectoryActivity) string {
+	if len(activity) == 0 {
+		return ""
+	}
+
+	most := activity[0].Changes
+	for _, entry := range activity {
+		if entry.Changes > most {
+			most = entry.Changes
+		}
+	}
+
+	var table strings.Builder
+	table.WriteString("| Directory | Activity | Changes | Files | Top authors | Themes |\n")
+	table.WriteString("|---|---|---:|---:|---|---|\n")
+	for _, entry := range activity {
+		bar := strings.Repeat("█", max(1, entry.Changes*10/max(most, 1)))
+
+		var authors []string
+		for i, author := range entry.Authors {
+			if i >= 3 {
+				break
+			}
+			authors = append(authors, fmt.Sprintf("%s (%d)", author.Name, author.Changes))
+		}
+
+		table.WriteString(fmt.Sprintf("| `%s` | %s | %d | %d | %s | %s |\n", entry.Directory, bar, entry.Changes,
+			entry.Files, markdownCell(strings.Join(authors, ", ")), markdownCell(entry.Theme)))
+	}
+	return table.String()
+}
+
+// markdownCell escapes text for a markdown table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
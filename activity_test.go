@@ -0,0 +1,45 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseActivity(t *testing.T) {
+	output := "\x1eAlice\x00feat: add wizard\n\ncmd/init/main.go\ncmd/init/prompt.go\nREADME.md\n" +
+		"\x1eBob\x00fix: wizard crash\n\ncmd/init/main.go\n" +
+		"\x1eAlice\x00docs: explain wizard\n\nREADME.md\n"
+
+	activity := parseActivity(output, 2)
+	if len(activity) != 2 {
+		t.Fatalf("Expected 2 directories, got %+v", activity)
+	}
+
+	cmd := activity[0]
+	if cmd.Directory != "cmd/init/" || cmd.Changes != 3 || cmd.Files != 2 || len(cmd.subjects) != 2 {
+		t.Errorf("Unexpected cmd/init/ activity %+v", cmd)
+	}
+	if cmd.Authors[0] != (AuthorShare{"Alice", 2}) || cmd.Authors[1] != (AuthorShare{"Bob", 1}) {
+		t.Errorf("Unexpected authors %+v", cmd.Authors)
+	}
+	if activity[1].Directory != "." || activity[1].Changes != 2 || activity[1].Files != 1 {
+		t.Errorf("Unexpected root activity %+v", activity[1])
+	}
+}
+
+func TestParseThemes(t *testing.T) {
+	themes := parseThemes("- `cmd/init/`: setup wizard\n.: documentation | README\nnoise")
+	if themes["cmd/init/"] != "setup wizard" || themes["."] != "documentation | README" || len(themes) != 2 {
+		t.Errorf("Unexpected themes %v", themes)
+	}
+
+	table := FormatActivityTable([]DirectoryActivity{
+		{Directory: "cmd/init/", Changes: 10, Files: 2, Authors: []AuthorShare{{"Alice", 10}}, Theme: themes["cmd/init/"]},
+		{Directory: ".", Changes: 1, Files: 1, Theme: themes["."]},
+	})
+	for _, want := range []string{"| `cmd/init/` | ██████████ | 10 | 2 | Alice (10) | setup wizard |", "documentation \\| README"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("Expected %q in table:\n%s", want, table)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package gitcommenter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AIIgnoreFile lists, in gitignore syntax, files at the repository root
+// whose changes are committed but left out of the prompt
+const AIIgnoreFile = ".aiignore"
+
+// IgnorePattern is one pattern of an .aiignore file
+type IgnorePattern struct {
+	segments []string
+	// negate re-includes paths matched by an earlier pattern ("!keep.js")
+	negate bool
+	// dirOnly matches directories only ("dist/")
+	dirOnly bool
+}
+
+// ParseIgnorePatterns parses gitignore-style patterns, one per line. Blank
+// lines and lines starting with "#" are skipped. A pattern containing a
+// slash other than a trailing one is anchored at the repository root,
+// "**" matches any number of directories, and a leading "!" negates the
+// pattern.
+func ParseIgnorePatterns(text string) []IgnorePattern {
+	var patterns []IgnorePattern
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pattern IgnorePattern
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			pattern.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		pattern.segments = strings.Split(line, "/")
+		if !anchored {
+			pattern.segments = append([]string{"**"}, pattern.segments...)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// MatchesIgnore reports whether a repository-relative path is ignored by
+// the patterns. Like in .gitignore, the last matching pattern decides, and
+// a pattern matching a directory matches every path below it.
+func MatchesIgnore(filePath string, patterns []IgnorePattern) bool {
+	parts := strings.Split(NormalizePath(filePath), "/")
+	ignored := false
+	for _, pattern := range patterns {
+		if pattern.matches(parts) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the pattern matches the path or one of its
+// parent directories
+func (p IgnorePattern) matches(parts []string) bool {
+	for n := 1; n <= len(parts); n++ {
+		if p.dirOnly && n == len(parts) {
+			break
+		}
+		if matchSegments(p.segments, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where
+// "**" stands for zero or more segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// LoadAIIgnore reads the .aiignore file at the repository root, or in
+// RepositoryPath when git cannot tell the root. A missing file yields no
+// patterns.
+func (gc *GitCommenter) LoadAIIgnore() ([]IgnorePattern, error) {
+	root, err := gc.RepoRoot()
+	if err != nil {
+		root = gc.config.RepositoryPath
+	}
+	data, err := os.ReadFile(filepath.Join(root, AIIgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", AIIgnoreFile, err)
+	}
+	return ParseIgnorePatterns(string(data)), nil
+}
+
+// ErrOnlyIgnored is returned by the scans when every changed file is
+// listed in .aiignore, so nothing is left to show the model
+var ErrOnlyIgnored = errors.New("every changed file is listed in " + AIIgnoreFile)
+
+// aiIgnored returns a function reporting whether the .aiignore file lists
+// a repository-relative path
+func (gc *GitCommenter) aiIgnored() (func(path string) bool, error) {
+	patterns, err := gc.LoadAIIgnore()
+	if err != nil {
+		return nil, err
+	}
+	return func(p string) bool {
+		return len(patterns) > 0 && MatchesIgnore(NormalizePath(p), patterns)
+	}, nil
+}
+
+// withoutIgnored leaves the files listed in .aiignore out of changes where
+// they are collected, so that no feature shows them to the model; they are
+// still committed with the rest of the index. A renamed file is left out
+// when either path is listed. Changes that are all left out yield
+// ErrOnlyIgnored.
+func (gc *GitCommenter) withoutIgnored(changes []FileChange) ([]FileChange, error) {
+	ignored, err := gc.aiIgnored()
+	if err != nil {
+		return nil, err
+	}
+	kept := []FileChange{}
+	for _, change := range changes {
+		if !ignored(change.FilePath) && (change.OldPath == "" || !ignored(change.OldPath)) {
+			kept = append(kept, change)
+		}
+	}
+	if len(kept) == 0 && len(changes) > 0 {
+		return nil, ErrOnlyIgnored
+	}
+	return kept, nil
+}
+
+// ScanIgnoredChanges scans the staged changes of the files listed in
+// .aiignore, which ScanStagedChanges leaves out. They are committed with
+// the rest of the index, so pass them to VerifyCommit along with the
+// scanned changes, and to CheckSecrets before committing.
+func (gc *GitCommenter) ScanIgnoredChanges() ([]FileChange, error) {
+	changes, err := gc.stagedChanges()
+	if err != nil {
+		return nil, err
+	}
+	ignored, err := gc.aiIgnored()
+	if err != nil {
+		return nil, err
+	}
+	var listed []FileChange
+	for _, change := range changes {
+		if ignored(change.FilePath) || change.OldPath != "" && ignored(change.OldPath) {
+			listed = append(listed, change)
+		}
+	}
+	return listed, nil
+}
+
+// splitIgnoredHunks splits hunks into those of files listed in .aiignore
+// and the others
+func (gc *GitCommenter) splitIgnoredHunks(hunks []StagedHunk) (kept, ignored []StagedHunk, err error) {
+	isIgnored, err := gc.aiIgnored()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, hunk := range hunks {
+		if isIgnored(hunk.Path) {
+			ignored = append(ignored, hunk)
+		} else {
+			kept = append(kept, hunk)
+		}
+	}
+	return kept, ignored, nil
+}
+
+// withoutIgnoredHunks leaves the hunks of files listed in .aiignore out
+// like withoutIgnored
+func (gc *GitCommenter) withoutIgnoredHunks(hunks []StagedHunk) ([]StagedHunk, error) {
+	kept, ignored, err := gc.splitIgnoredHunks(hunks)
+	if err != nil {
+		return nil, err
+	}
+	if len(kept) == 0 && len(ignored) > 0 {
+		return nil, ErrOnlyIgnored
+	}
+	return kept, nil
+}
+
+// withoutIgnoredDiffs leaves the file diffs of files listed in .aiignore
+// out of a patch
+func (gc *GitCommenter) withoutIgnoredDiffs(patch string) (string, error) {
+	ignored, err := gc.aiIgnored()
+	if err != nil {
+		return "", err
+	}
+	var kept strings.Builder
+	for _, file := range splitFileDiffs(patch) {
+		if !strings.HasPrefix(file, "diff --git ") || !ignored(fileDiffPath(file)) {
+			kept.WriteString(file)
+		}
+	}
+	return kept.String(), nil
+}
+
+// StageIgnored stages the working tree changes of the files listed in
+// .aiignore, which ScanUnstagedChanges and ScanAllChanges leave out, so
+// that committing the scanned changes commits them too
+func (gc *GitCommenter) StageIgnored() error {
+	ignored, err := gc.aiIgnored()
+	if err != nil {
+		return err
+	}
+	changed, err := gc.gitOutput("diff", "--name-only", "-z", "--", ":/")
+	if err != nil {
+		return fmt.Errorf("failed to list changed files: %w", err)
+	}
+	untracked, err := gc.gitOutput("ls-files", "--others", "--exclude-standard", "--full-name", "-z", "--", ":/")
+	if err != nil {
+		return fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var paths []string
+	for _, p := range strings.Split(changed+"\x00"+untracked, "\x00") {
+		if p != "" && ignored(p) {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return gc.Restage(paths)
+}
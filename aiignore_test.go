@@ -0,0 +1,130 @@
+package gitcommenter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMatchesIgnore(t *testing.T) {
+	patterns := ParseIgnorePatterns(`# build output
+dist/
+*.min.js
+/testdata/
+!testdata/README.md
+docs/**/*.svg
+\#notes
+`)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"dist/app.js", true},
+		{"web/dist/app.js", true},
+		{"dist", false},
+		{"assets/vendor.min.js", true},
+		{"testdata/golden/out.txt", true},
+		{"testdata/README.md", false},
+		{"pkg/testdata/case.txt", false},
+		{"docs/img/arch.svg", true},
+		{"docs/arch.svg", true},
+		{"img/arch.svg", false},
+		{"#notes", true},
+		{"main.go", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchesIgnore(test.path, patterns); got != test.expected {
+			t.Errorf("MatchesIgnore(%s) = %v, want %v", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestAIIgnoreLeavesFilesOutOfPrompt(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, AIIgnoreFile, "*.min.js\n")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.HistoryExamples = 0
+	commenter := New(config)
+
+	changes := []FileChange{
+		{FilePath: "app.js", ChangeType: "modified", LinesAdded: 1},
+		{FilePath: "app.min.js", ChangeType: "modified", LinesAdded: 300},
+	}
+	plan, err := commenter.plan(changes)
+	if err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	if strings.Contains(plan.prompt, "app.min.js") || strings.Contains(plan.prompt, "EXCLUDED FILES") {
+		t.Errorf("Expected app.min.js to be left out of the prompt:\n%s", plan.prompt)
+	}
+	if len(plan.changes) != 2 {
+		t.Errorf("Expected ignored files to stay in the commit, got %d change(s)", len(plan.changes))
+	}
+
+	config.CountExcluded = true
+	if plan, _ = commenter.plan(changes); !strings.Contains(plan.prompt, "1 file(s), +300 -0 lines") {
+		t.Errorf("Expected the excluded files to be counted:\n%s", plan.prompt)
+	}
+}
+
+func TestAIIgnoreLeavesFilesOutWhereChangesAreCollected(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, AIIgnoreFile, "*.min.js\n", "first")
+	writeFile(t, dir, "app.js", "app\n")
+	writeFile(t, dir, "app.min.js", "min\n")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	changes, err := gc.ScanUnstagedChanges()
+	if err != nil || len(changes) != 1 || changes[0].FilePath != "app.js" {
+		t.Fatalf("Expected only app.js in the working tree scan, got %+v: %v", changes, err)
+	}
+	if err := gc.StageChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.StageIgnored(); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitRun(t, dir, "diff", "--cached", "--name-only"); got != "app.js\napp.min.js\n" {
+		t.Fatalf("Expected both files staged, got %q", got)
+	}
+
+	changes, err = gc.ScanStagedChanges()
+	if err != nil || len(changes) != 1 || changes[0].FilePath != "app.js" {
+		t.Fatalf("Expected only app.js in the staged scan, got %+v: %v", changes, err)
+	}
+	ignored, err := gc.ScanIgnoredChanges()
+	if err != nil || len(ignored) != 1 || ignored[0].FilePath != "app.min.js" {
+		t.Fatalf("Expected app.min.js as the ignored change, got %+v: %v", ignored, err)
+	}
+	hunks, err := gc.StagedHunks()
+	if err != nil || len(hunks) != 1 || hunks[0].Path != "app.js" {
+		t.Fatalf("Expected only the hunk of app.js, got %+v: %v", hunks, err)
+	}
+
+	// Splitting still commits the ignored file
+	made, err := gc.CommitSplit([]SplitCommit{{Hunks: hunks, Suggestion: &CommitSuggestion{Subject: "feat: add the app"}}})
+	if err != nil || made != 1 {
+		t.Fatalf("CommitSplit made %d commit(s): %v", made, err)
+	}
+	if got := gitRun(t, dir, "show", "--format=", "--name-only", "HEAD"); got != "app.js\napp.min.js\n" {
+		t.Errorf("Expected the ignored file in the commit, got %q", got)
+	}
+	if verification, err := gc.VerifyCommit(append(changes, ignored...)); err != nil || !verification.OK() {
+		t.Errorf("Expected the commit to match the scanned and ignored changes, got %+v: %v", verification, err)
+	}
+	if diff, err := gc.CommitDiff("HEAD"); err != nil || strings.Contains(diff, "app.min.js") || !strings.Contains(diff, "app.js") {
+		t.Errorf("Expected the commit diff without app.min.js, got %q: %v", diff, err)
+	}
+
+	writeFile(t, dir, "app.min.js", "changed\n")
+	if _, err := gc.ScanAllChanges(); !errors.Is(err, ErrOnlyIgnored) {
+		t.Errorf("Expected ErrOnlyIgnored when only ignored files changed, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package gitcommenter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Anonymizer hides code from the host running the model, e.g. a team's
+// shared Ollama server. Anonymize rewrites a prompt before it is sent and
+// Restore maps the placeholders in the answer back. Both are called for
+// every model request, possibly concurrently.
+type Anonymizer interface {
+	Anonymize(prompt string) string
+	Restore(response string) string
+}
+
+// SetAnonymizer sets the anonymizer applied to every model request; nil
+// sends prompts unchanged
+func (gc *GitCommenter) SetAnonymizer(anonymizer Anonymizer) {
+	gc.anonymizer = anonymizer
+}
+
+// CodeAnonymizer replaces the string literals and code identifiers of the
+// diffs in a prompt with placeholders such as str_1 and ident_2. Code
+// identifiers are names with an underscore, a digit or an inner capital
+// (parse_config, sha256sum, retryCount, StripeClient); plain words are
+// kept so the model still understands the change. Once a name is
+// replaced, it is replaced everywhere in the prompt, e.g. in file paths.
+// The same name always gets the same placeholder, so retries and
+// candidates agree.
+type CodeAnonymizer struct {
+	mu           sync.Mutex
+	placeholders map[string]string
+	originals    map[string]string
+	identifiers  int
+	literals     int
+}
+
+// NewCodeAnonymizer creates an anonymizer with no placeholders yet
+func NewCodeAnonymizer() *CodeAnonymizer {
+	return &CodeAnonymizer{placeholders: make(map[string]string), originals: make(map[string]string)}
+}
+
+var (
+	// codeTokenPattern matches single-line string literals and words that
+	// may be identifiers
+	codeTokenPattern = regexp.MustCompile(`"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'|` + "`[^`\n]*`" + `|[A-Za-z_][A-Za-z0-9_]*`)
+	// wordPattern matches words outside the diff hunks
+	wordPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	// placeholderPattern matches the placeholders of CodeAnonymizer
+	placeholderPattern = regexp.MustCompile(`\b(?:ident|str)_\d+\b`)
+)
+
+// Anonymize replaces the literals and identifiers of the diff hunks in
+// prompt, then the replaced identifiers in the rest of the prompt
+func (a *CodeAnonymizer) Anonymize(prompt string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lines := strings.Split(prompt, "\n")
+	hunk := make([]bool, len(lines))
+	inHunk := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			continue
+		case line == "" || !strings.ContainsRune("+- \\", rune(line[0])):
+			inHunk = false
+		}
+		if !inHunk {
+			continue
+		}
+		hunk[i] = true
+		lines[i] = line[:1] + codeTokenPattern.ReplaceAllStringFunc(line[1:], func(token string) string {
+			if quote := token[:1]; strings.Contains("\"'`", quote) {
+				if len(token) == 2 {
+					return token
+				}
+				return quote + a.placeholder(token[1:len(token)-1], false) + quote
+			}
+			if !isCodeIdentifier(token) {
+				return token
+			}
+			return a.placeholder(token, true)
+		})
+	}
+
+	// Names from the diffs also appear in paths, API summaries and retries
+	for i, line := range lines {
+		if hunk[i] {
+			continue
+		}
+		lines[i] = wordPattern.ReplaceAllStringFunc(line, func(word string) string {
+			if placeholder, ok := a.placeholders["i:"+word]; ok {
+				return placeholder
+			}
+			return word
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Restore replaces the placeholders in response with what they stand for
+func (a *CodeAnonymizer) Restore(response string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return placeholderPattern.ReplaceAllStringFunc(response, func(placeholder string) string {
+		if original, ok := a.originals[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
+
+// placeholder returns the placeholder of an identifier or literal,
+// assigning the next one when it has none yet
+func (a *CodeAnonymizer) placeholder(original string, identifier bool) string {
+	key := "s:" + original
+	if identifier {
+		key = "i:" + original
+	}
+	if placeholder, ok := a.placeholders[key]; ok {
+		return placeholder
+	}
+
+	var placeholder string
+	if identifier {
+		a.identifiers++
+		placeholder = "ident_" + strconv.Itoa(a.identifiers)
+	} else {
+		a.literals++
+		placeholder = "str_" + strconv.Itoa(a.literals)
+	}
+	a.placeholders[key] = placeholder
+	a.originals[placeholder] = original
+	return placeholder
+}
+
+// isCodeIdentifier reports whether word looks like a name from code rather
+// than an English word: it has an underscore, a digit, or a capital after a
+// lowercase letter
+func isCodeIdentifier(word string) bool {
+	if len(word) < 3 {
+		return false
+	}
+	var previous rune
+	for i, r := range word {
+		switch {
+		case r == '_' && i > 0 && i < len(word)-1, unicode.IsDigit(r):
+			return true
+		case unicode.IsUpper(r) && unicode.IsLower(previous):
+			return true
+		}
+		previous = r
+	}
+	return false
+}
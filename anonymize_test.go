@@ -0,0 +1,95 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodeAnonymizer(t *testing.T) {
+	prompt := "Files: billing/StripeClient.go\n" +
+		"DIFF CONTENT:\n" +
+		"diff --git a/billing/StripeClient.go b/billing/StripeClient.go\n" +
+		"@@ -1,3 +1,4 @@\n" +
+		" func (c *StripeClient) Charge(amount int) error {\n" +
+		"+\tc.retryCount = max_retries\n" +
+		"+\treturn c.post(\"sk_live_secret\", amount)\n" +
+		" }\n" +
+		"==========\n" +
+		"- Be SPECIFIC about what changed\n"
+
+	a := NewCodeAnonymizer()
+	anonymized := a.Anonymize(prompt)
+	for _, hidden := range []string{"StripeClient", "retryCount", "max_retries", "sk_live_secret"} {
+		if strings.Contains(anonymized, hidden) {
+			t.Errorf("Expected %q to be hidden:\n%s", hidden, anonymized)
+		}
+	}
+	for _, kept := range []string{"func (c *", "Charge(amount int) error", "billing/", "- Be SPECIFIC about what changed", "@@ -1,3 +1,4 @@"} {
+		if !strings.Contains(anonymized, kept) {
+			t.Errorf("Expected %q to be kept:\n%s", kept, anonymized)
+		}
+	}
+
+	// Placeholders are stable across prompts
+	if again := a.Anonymize(prompt); again != anonymized {
+		t.Errorf("Expected the same placeholders again:\n%s", again)
+	}
+
+	client := a.Anonymize("StripeClient")
+	if got := a.Restore("fix(billing): retry " + client + " charges"); got != "fix(billing): retry StripeClient charges" {
+		t.Errorf("Unexpected restored message %q", got)
+	}
+	if got := a.Restore("keep ident_99 unknown"); got != "keep ident_99 unknown" {
+		t.Errorf("Expected unknown placeholders to be kept, got %q", got)
+	}
+}
+
+func TestIsCodeIdentifier(t *testing.T) {
+	for word, want := range map[string]bool{
+		"retryCount": true, "StripeClient": true, "max_retries": true, "sha256": true,
+		"charge": false, "Charge": false, "HTTP": false, "id": false, "_x": false,
+	} {
+		if got := isCodeIdentifier(word); got != want {
+			t.Errorf("isCodeIdentifier(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
+
+func TestGenerateWithAnonymizer(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		// Answer with the placeholder the model was given
+		placeholder := placeholderPattern.FindString(req.Prompt)
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add " + placeholder + " limiter", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.Anonymize = true
+	config.TrivialMaxLines = 0
+	gc := New(config)
+
+	changes := []FileChange{{
+		FilePath:   "limiter.go",
+		ChangeType: "modified",
+		Diff:       "diff --git a/limiter.go b/limiter.go\n@@ -1 +1,2 @@\n package app\n+func newRateLimiter() {}\n",
+		LinesAdded: 1,
+	}}
+	suggestion, err := gc.GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if strings.Contains(prompt, "newRateLimiter") {
+		t.Errorf("Expected the identifier to be hidden from the model:\n%s", prompt)
+	}
+	if suggestion.Subject != "feat: add newRateLimiter limiter" {
+		t.Errorf("Expected the identifier to be restored, got %q", suggestion.Subject)
+	}
+}
@@ -0,0 +1,136 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommitAudit is the quality score of a commit message from the history
+type CommitAudit struct {
+	Commit Commit
+	// Grade is the model's rating of the message against the commit's diff
+	Grade *MessageGrade
+	// FormatProblem says how the message breaks the configured style,
+	// allowed types and scopes; "" when it follows them
+	FormatProblem string
+	// SubjectLength is the subject's length in characters
+	SubjectLength int
+	// Score combines specificity (60), format (25) and subject length (15)
+	// into 0-100
+	Score int
+}
+
+// auditSubjectLength is the subject length getting full marks; longer
+// subjects get some up to the configured limit, or 72 characters
+const auditSubjectLength = 50
+
+// Problems lists why the message lost points, worst first
+func (a *CommitAudit) Problems() []string {
+	var problems []string
+	if a.Grade != nil && a.Grade.Score < 5 {
+		problems = append(problems, "specificity "+strconv.Itoa(a.Grade.Score)+"/5: "+a.Grade.Reason)
+	}
+	if a.FormatProblem != "" {
+		problems = append(problems, a.FormatProblem)
+	}
+	if a.SubjectLength > auditSubjectLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters long", a.SubjectLength))
+	}
+	return problems
+}
+
+// AuditCommit scores a commit's message: the model grades how specifically
+// it describes the diff, and the format and subject length are checked
+// without the model
+func (gc *GitCommenter) AuditCommit(commit Commit) (*CommitAudit, error) {
+	diff, err := gc.CommitDiff(commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	grade, err := gc.GradeCommitMessage(commit.Message(), diff)
+	if err != nil {
+		return nil, err
+	}
+	audit := &CommitAudit{Commit: commit, Grade: grade, SubjectLength: utf8.RuneCountInString(commit.Subject)}
+	audit.Score = (grade.Score - 1) * 15
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.validateSuggestion(style, &CommitSuggestion{Subject: commit.Subject, Body: commit.Body}); err != nil {
+		audit.FormatProblem = err.Error()
+	} else {
+		audit.Score += 25
+	}
+
+	limit := gc.subjectLimit()
+	if limit <= 0 {
+		limit = 72
+	}
+	switch {
+	case audit.SubjectLength <= auditSubjectLength:
+		audit.Score += 15
+	case audit.SubjectLength <= limit:
+		audit.Score += 8
+	}
+	return audit, nil
+}
+
+// AuditHistory scores the messages of the last commits on HEAD, worst
+// first. Merges, reverts and fixups that git wrote the message of are left
+// out. progress, if not nil, is called after each commit.
+func (gc *GitCommenter) AuditHistory(last int, progress func(done, total int)) ([]*CommitAudit, error) {
+	output, err := gc.gitOutput("log", "--no-merges", "-n", strconv.Itoa(last), logFormat, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent commits: %w", err)
+	}
+	var commits []Commit
+	for _, commit := range parseLog(output) {
+		if !isExemptMessage(commit.Message()) {
+			commits = append(commits, commit)
+		}
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits to audit")
+	}
+
+	audits := make([]*CommitAudit, 0, len(commits))
+	for i, commit := range commits {
+		audit, err := gc.AuditCommit(commit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to audit %s: %w", commit.ShortHash(), err)
+		}
+		audits = append(audits, audit)
+		if progress != nil {
+			progress(i+1, len(commits))
+		}
+	}
+	sort.SliceStable(audits, func(i, j int) bool { return audits[i].Score < audits[j].Score })
+	return audits, nil
+}
+
+// AuditSummary renders an overview of audits: the average score and how
+// many messages break the format or have long subjects
+func AuditSummary(audits []*CommitAudit) string {
+	total, unformatted, long := 0, 0, 0
+	for _, audit := range audits {
+		total += audit.Score
+		if audit.FormatProblem != "" {
+			unformatted++
+		}
+		if audit.SubjectLength > auditSubjectLength {
+			long++
+		}
+	}
+	var out strings.Builder
+	if len(audits) > 0 {
+		out.WriteString(fmt.Sprintf("Average score: %d/100 over %d commits\n", total/len(audits), len(audits)))
+	}
+	out.WriteString(fmt.Sprintf("Breaking the format: %d\n", unformatted))
+	out.WriteString(fmt.Sprintf("Subjects over %d characters: %d", auditSubjectLength, long))
+	return out.String()
+}
@@ -0,0 +1,65 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuditHistory(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, message := range []string{
+		"feat(api): add request paging",
+		"fixed stuff",
+		"fix: handle the empty configuration file that is written by older releases of the installer",
+		"fixup! fixed stuff",
+	} {
+		writeFile(t, dir, "file.go", strings.Repeat("// line\n", i+1))
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", message)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		answer := "SCORE: 5\nREASON: Names the feature."
+		if strings.Contains(req.Prompt, "fixed stuff") {
+			answer = "SCORE: 1\nREASON: Does not say what was fixed."
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: answer, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.Style = "conventional"
+	gc := New(config)
+
+	var calls int
+	audits, err := gc.AuditHistory(10, func(done, total int) { calls++ })
+	if err != nil {
+		t.Fatalf("AuditHistory returned error: %v", err)
+	}
+	if len(audits) != 3 || calls != 3 {
+		t.Fatalf("Expected three audits without the fixup, got %d with %d progress calls", len(audits), calls)
+	}
+	if audits[0].Commit.Subject != "fixed stuff" || audits[0].Score != 15 || audits[0].FormatProblem == "" {
+		t.Errorf("Expected the vague, unconventional message first with 15 points, got %+v", audits[0])
+	}
+	if long := audits[1]; long.Score != 85 || long.SubjectLength <= auditSubjectLength || len(long.Problems()) != 1 {
+		t.Errorf("Expected the long subject second with 85 points, got %+v %v", long, long.Problems())
+	}
+	if best := audits[2]; best.Score != 100 || len(best.Problems()) != 0 {
+		t.Errorf("Expected a perfect score for the last, got %+v %v", best, best.Problems())
+	}
+
+	summary := AuditSummary(audits)
+	for _, part := range []string{"Average score: 66/100 over 3 commits", "Breaking the format: 1", "Subjects over 50 characters: 1"} {
+		if !strings.Contains(summary, part) {
+			t.Errorf("Expected %q in the summary:\n%s", part, summary)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Defaults of the prompt budget (see Config.MaxPromptBytes)
+const (
+	// DefaultMaxPromptBytes is the default Config.MaxPromptBytes
+	DefaultMaxPromptBytes = 10000
+	// DefaultMaxPromptFiles is the default Config.MaxPromptFiles
+	DefaultMaxPromptFiles = 5
+	// DefaultMaxFileBytes is the default Config.MaxFileBytes
+	DefaultMaxFileBytes = 2000
+)
+
+// minDiffBytes is the smallest share of the budget worth showing of a
+// diff; files that would get less are listed as not shown instead
+const minDiffBytes = 200
+
+// PromptBudget bounds the diffs shown in a prompt
+type PromptBudget struct {
+	// MaxBytes is the most diff content shown in total
+	MaxBytes int
+	// MaxFiles is the most files whose diffs are shown
+	MaxFiles int
+	// MaxFileBytes is the most diff content shown of a file of average
+	// weight; heavier files may show more, lighter ones less (see
+	// layoutDiffs)
+	MaxFileBytes int
+}
+
+// DefaultPromptBudget returns the budget used when Config sets none
+func DefaultPromptBudget() PromptBudget {
+	return PromptBudget{MaxBytes: DefaultMaxPromptBytes, MaxFiles: DefaultMaxPromptFiles, MaxFileBytes: DefaultMaxFileBytes}
+}
+
+// PromptBudget returns the budget of Config.MaxPromptBytes,
+// Config.MaxPromptFiles and Config.MaxFileBytes, with the defaults for
+// those left at zero
+func (gc *GitCommenter) PromptBudget() PromptBudget {
+	budget := DefaultPromptBudget()
+	if gc.config.MaxPromptBytes > 0 {
+		budget.MaxBytes = gc.config.MaxPromptBytes
+	}
+	if gc.config.MaxPromptFiles > 0 {
+		budget.MaxFiles = gc.config.MaxPromptFiles
+	}
+	if gc.config.MaxFileBytes > 0 {
+		budget.MaxFileBytes = gc.config.MaxFileBytes
+	}
+	return budget
+}
+
+// changeWeight estimates how much a change says about the changeset: its
+// changed lines, halved for documentation, tests and configuration and
+// quartered for dependency manifests and lock files. Whitespace-only
+// changes and files without a diff weigh nothing.
+func changeWeight(change FileChange) int {
+	if change.Diff == "" || isFormattingChange(change) {
+		return 0
+	}
+	weight := change.LinesAdded + change.LinesRemoved
+	switch p := NormalizePath(change.FilePath); {
+	case isDependencyFile(p):
+		weight /= 4
+	case isDocFile(p) || isTestFile(p) || isConfigFile(p):
+		weight /= 2
+	}
+	return weight
+}
+
+// PrioritizeChanges returns the changes ordered by weight, largest first,
+// so the budget goes to the changes that matter most. Changes of equal
+// weight keep their order.
+func PrioritizeChanges(changes []FileChange) []FileChange {
+	weights := make(map[string]int, len(changes))
+	for _, change := range changes {
+		weights[change.FilePath] = changeWeight(change)
+	}
+	ordered := append([]FileChange(nil), changes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return weights[ordered[i].FilePath] > weights[ordered[j].FilePath]
+	})
+	return ordered
+}
+
+// diffLayout is how formatDiffs spends a budget: limits holds the bytes
+// of diff shown of the first changes in order, the others are only counted
+type diffLayout struct {
+	ordered []FileChange
+	limits  []int
+}
+
+// layoutDiffs decides which diffs the budget shows and how much of each.
+// The shown files share the budget in proportion to their weight: a file
+// of average weight may show MaxFileBytes, heavier files more and lighter
+// ones less, and what a short diff leaves unused goes to the others. The
+// lightest files are left out while they would get less than minDiffBytes.
+func layoutDiffs(changes []FileChange, budget PromptBudget) diffLayout {
+	layout := diffLayout{ordered: PrioritizeChanges(changes)}
+	n := min(budget.MaxFiles, len(layout.ordered))
+	weights, caps := make([]int, n), make([]int, n)
+	total, files := 0, 0
+	for i, change := range layout.ordered[:n] {
+		if change.Diff != "" {
+			weights[i] = max(changeWeight(change), 1)
+			total += weights[i]
+			files++
+		}
+	}
+	for i, change := range layout.ordered[:n] {
+		if change.Diff != "" {
+			caps[i] = min(len(change.Diff), max(budget.MaxFileBytes*weights[i]*files/total, minDiffBytes))
+		}
+	}
+
+	for n > 0 {
+		shown := layout.ordered[:n]
+		limits := allocate(budget.MaxBytes, weights[:n], caps[:n])
+		fits := true
+		for i, change := range shown {
+			if change.Diff != "" && limits[i] < min(minDiffBytes, len(change.Diff)) {
+				fits = false
+			}
+		}
+		if fits {
+			layout.limits = limits
+			break
+		}
+		n--
+	}
+	return layout
+}
+
+// allocate splits total in proportion to weights without giving anyone
+// more than their cap; what a capped share leaves over goes to the others
+func allocate(total int, weights, caps []int) []int {
+	shares := make([]int, len(weights))
+	open := make([]bool, len(weights))
+	for i := range weights {
+		open[i] = weights[i] > 0 && caps[i] > 0
+	}
+	for {
+		sum := 0
+		for i, ok := range open {
+			if ok {
+				sum += weights[i]
+			}
+		}
+		if sum == 0 {
+			return shares
+		}
+		// Shares only grow as capped ones leave, so every cap below its
+		// share can be granted at once
+		capped := false
+		remaining := total
+		for i, ok := range open {
+			if ok && caps[i]*sum <= total*weights[i] {
+				shares[i] = caps[i]
+				remaining -= caps[i]
+				open[i] = false
+				capped = true
+			}
+		}
+		if !capped {
+			for i, ok := range open {
+				if ok {
+					shares[i] = total * weights[i] / sum
+				}
+			}
+			return shares
+		}
+		total = remaining
+	}
+}
+
+// cut returns the changes whose diff the layout cuts short or leaves out
+func (layout diffLayout) cut() []FileChange {
+	var cut []FileChange
+	for i, change := range layout.ordered {
+		if change.Diff != "" && (i >= len(layout.limits) || layout.limits[i] < len(change.Diff)) {
+			cut = append(cut, change)
+		}
+	}
+	return cut
+}
+
+// formatDiffs renders the diffs of the most important changes for the
+// prompt within the budget. New files whose diff is cut short or not shown
+// are outlined, so the model can tell what they do.
+func formatDiffs(changes []FileChange, budget PromptBudget) string {
+	var out strings.Builder
+	layout := layoutDiffs(changes, budget)
+	ordered := layout.ordered
+	for i, change := range ordered {
+		if i >= len(layout.limits) {
+			out.WriteString(fmt.Sprintf("... and %d more files\n\n", len(ordered)-i))
+			for _, change := range ordered[i:] {
+				if change.ChangeType == "added" {
+					if outline := formatOutline(change); outline != "" {
+						out.WriteString(outline + "\n")
+					}
+				}
+			}
+			break
+		}
+		if change.Diff != "" {
+			out.WriteString(fmt.Sprintf("=== DETAILED CHANGES IN %s ===\n", change.FilePath))
+			out.WriteString(fmt.Sprintf("Change Type: %s\n", change.ChangeType))
+			out.WriteString(fmt.Sprintf("Lines Added: %d, Lines Removed: %d\n\n", change.LinesAdded, change.LinesRemoved))
+
+			// Include more context but still truncate if very long
+			diff := change.Diff
+			truncated := len(diff) > layout.limits[i]
+			if truncated {
+				diff = truncateUTF8(diff, layout.limits[i])
+				diff += fmt.Sprintf("\n... (truncated - showing first %d characters)", layout.limits[i])
+			}
+			out.WriteString("DIFF CONTENT:\n")
+			out.WriteString(diff)
+			out.WriteString("\n")
+			if truncated && change.ChangeType == "added" {
+				out.WriteString(formatOutline(change))
+			}
+			out.WriteString(strings.Repeat("=", 50) + "\n\n")
+		} else {
+			// For binary files or files without diffs
+			out.WriteString(fmt.Sprintf("=== %s ===\n", change.FilePath))
+			out.WriteString(fmt.Sprintf("Change Type: %s (binary file or no diff available)\n\n", change.ChangeType))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,105 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPrioritizeChanges(t *testing.T) {
+	changes := []FileChange{
+		{FilePath: "README.md", ChangeType: "modified", Diff: "+a\n+b\n+c\n+d\n", LinesAdded: 4},
+		{FilePath: "assets/logo.png", ChangeType: "modified"},
+		{FilePath: "server.go", ChangeType: "modified", Diff: "-x\n+y\n+z\n", LinesAdded: 2, LinesRemoved: 1},
+		{FilePath: "format.go", ChangeType: "modified", Diff: "-a  :=  1\n+a := 1\n", LinesAdded: 1, LinesRemoved: 1},
+		{FilePath: "server_test.go", ChangeType: "modified", Diff: "+t\n+u\n", LinesAdded: 2},
+	}
+
+	var got []string
+	for _, change := range PrioritizeChanges(changes) {
+		got = append(got, change.FilePath)
+	}
+	want := "server.go README.md server_test.go assets/logo.png format.go"
+	if strings.Join(got, " ") != want {
+		t.Errorf("PrioritizeChanges = %v, want %s", got, want)
+	}
+	if changes[0].FilePath != "README.md" {
+		t.Error("Expected the changes passed in to keep their order")
+	}
+}
+
+func TestFormatDiffsBudget(t *testing.T) {
+	var changes []FileChange
+	for i := 0; i < 4; i++ {
+		diff := strings.Repeat(fmt.Sprintf("+line of file %d\n", i), 50)
+		changes = append(changes, FileChange{FilePath: fmt.Sprintf("f%d.go", i), ChangeType: "modified", Diff: diff, LinesAdded: 50})
+	}
+
+	// Files of equal weight each get the per-file limit
+	out := formatDiffs(changes, PromptBudget{MaxBytes: 10000, MaxFiles: 2, MaxFileBytes: 300})
+	if !strings.HasPrefix(out, "=== DETAILED CHANGES IN f0.go ===") || !strings.Contains(out, "=== DETAILED CHANGES IN f1.go ===") {
+		t.Errorf("Expected the first two changes of equal weight:\n%s", out)
+	}
+	if strings.Count(out, "... (truncated - showing first 300 characters)") != 2 || !strings.Contains(out, "... and 2 more files") {
+		t.Errorf("Expected diffs cut to 300 bytes and the rest counted:\n%s", out)
+	}
+
+	// The total budget is shared; files that would get less than
+	// minDiffBytes are left out
+	out = formatDiffs(changes, PromptBudget{MaxBytes: 500, MaxFiles: 10, MaxFileBytes: 600})
+	if strings.Count(out, "showing first 250 characters") != 2 || strings.Contains(out, "f2.go") || !strings.Contains(out, "... and 2 more files") {
+		t.Errorf("Expected the budget split between the files it fits:\n%s", out)
+	}
+}
+
+func TestFormatDiffsProportional(t *testing.T) {
+	changes := []FileChange{
+		{FilePath: "README.md", ChangeType: "modified", Diff: "+" + strings.Repeat("word ", 300) + "\n", LinesAdded: 1},
+		{FilePath: "core.go", ChangeType: "modified", Diff: strings.Repeat("+refactored\n", 200), LinesAdded: 120, LinesRemoved: 80},
+		{FilePath: "config.yaml", ChangeType: "modified", Diff: strings.Repeat("+key: value\n", 40), LinesAdded: 40},
+	}
+
+	// The refactor is shown in full, the configuration gets less than the
+	// per-file limit and the one-line README tweak the least
+	out := formatDiffs(changes, PromptBudget{MaxBytes: 3000, MaxFiles: 5, MaxFileBytes: 1000})
+	if !strings.HasPrefix(out, "=== DETAILED CHANGES IN core.go ===") || strings.Count(out, "truncated") != 2 {
+		t.Errorf("Expected the refactor first and in full:\n%s", out)
+	}
+	for _, want := range []string{"showing first 271 characters", "word word\n... (truncated - showing first 200 characters)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	for _, test := range []struct {
+		total         int
+		weights, caps []int
+		want          string
+	}{
+		{1000, []int{1, 1}, []int{800, 800}, "[500 500]"},
+		{1000, []int{3, 1}, []int{800, 800}, "[750 250]"},
+		{1000, []int{1, 1}, []int{100, 800}, "[100 800]"},
+		{1000, []int{1, 0}, []int{800, 800}, "[800 0]"},
+	} {
+		if got := fmt.Sprint(allocate(test.total, test.weights, test.caps)); got != test.want {
+			t.Errorf("allocate(%d, %v, %v) = %s, want %s", test.total, test.weights, test.caps, got, test.want)
+		}
+	}
+}
+
+func TestPromptBudgetConfig(t *testing.T) {
+	gc := New(&Config{MaxPromptFiles: 8})
+	if budget := gc.PromptBudget(); budget != (PromptBudget{MaxBytes: DefaultMaxPromptBytes, MaxFiles: 8, MaxFileBytes: DefaultMaxFileBytes}) {
+		t.Errorf("Expected defaults for the limits left at zero, got %+v", budget)
+	}
+
+	fc := &FileConfig{}
+	if err := fc.Set("max-file-bytes", "4000"); err != nil || fc.MaxFileBytes != 4000 {
+		t.Errorf("Set max-file-bytes = %d, %v", fc.MaxFileBytes, err)
+	}
+	if err := fc.Set("max-prompt-bytes", "0"); err == nil {
+		t.Error("Expected an error for a zero budget")
+	}
+}
@@ -0,0 +1,139 @@
+package gitcommenter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache stores model responses and embeddings keyed by a hash of the
+// request, so identical requests do not hit the model twice. Implementations
+// must be safe for concurrent use. The filesystem implementation lives here;
+// SQLite and Redis implementations are in the cache/sqlite and cache/redis
+// packages.
+type Cache interface {
+	// Get returns the cached value for key and whether it was found
+	Get(key string) ([]byte, bool, error)
+	// Set stores value under key
+	Set(key string, value []byte) error
+}
+
+// CacheKey derives a cache key from the given request parts. The kind of
+// entry (e.g. "generate" or "embed") is kept readable as a prefix.
+func CacheKey(kind string, parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		// Length-prefix every part so ("ab", "c") and ("a", "bc") differ
+		fmt.Fprintf(hash, "%d:%s\n", len(part), part)
+	}
+	return kind + "-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// FileCache is a Cache storing one file per entry in a directory
+type FileCache struct {
+	// Dir is the directory holding the cache entries
+	Dir string
+}
+
+// NewFileCache creates a FileCache in dir, or in DefaultCacheDir if dir is empty
+func NewFileCache(dir string) (*FileCache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileCache{Dir: dir}, nil
+}
+
+// DefaultCacheDir returns the per-user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "ai-git-commit"), nil
+}
+
+// Get reads the entry for key
+func (c *FileCache) Get(key string) ([]byte, bool, error) {
+	path, err := c.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return data, true, nil
+}
+
+// Set writes the entry for key, replacing it atomically
+func (c *FileCache) Set(key string, value []byte) error {
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path maps a key to a file, rejecting keys that would escape Dir
+func (c *FileCache) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	return filepath.Join(c.Dir, key), nil
+}
+
+// SetCache enables caching of model responses (nil disables it)
+func (gc *GitCommenter) SetCache(cache Cache) {
+	gc.cache = cache
+}
+
+// cachedResponse looks up a response, treating cache failures as misses so
+// a broken cache never blocks a commit
+func (gc *GitCommenter) cachedResponse(key string) (string, bool) {
+	if gc.cache == nil {
+		return "", false
+	}
+	data, ok, err := gc.cache.Get(key)
+	if err != nil || !ok {
+		return "", false
+	}
+	return string(data), true
+}
+
+// storeResponse saves a response, ignoring cache failures
+func (gc *GitCommenter) storeResponse(key, response string) {
+	if gc.cache != nil {
+		_ = gc.cache.Set(key, []byte(response))
+	}
+}
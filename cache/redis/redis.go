@@ -0,0 +1,84 @@
+// Package redis provides a gitcommenter.Cache stored in Redis, so a team can
+// share one cache between a daemon and several machines.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL is how long entries are kept when no TTL is given
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Cache is a gitcommenter.Cache backed by Redis
+type Cache struct {
+	client *goredis.Client
+	// Prefix namespaces the keys, e.g. when the Redis database is shared
+	Prefix string
+	// TTL is the expiry of new entries (0 keeps them forever)
+	TTL time.Duration
+	// Timeout bounds every Redis call
+	Timeout time.Duration
+}
+
+// Open connects to the Redis server at url, e.g. redis://localhost:6379/0
+func Open(url string) (*Cache, error) {
+	options, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	cache := &Cache{
+		client:  goredis.NewClient(options),
+		Prefix:  "ai-git-commit:",
+		TTL:     DefaultTTL,
+		Timeout: 2 * time.Second,
+	}
+
+	ctx, cancel := cache.context()
+	defer cancel()
+	if err := cache.client.Ping(ctx).Err(); err != nil {
+		cache.client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return cache, nil
+}
+
+// Get returns the value stored for key
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	value, err := c.client.Get(ctx, c.Prefix+key).Bytes()
+	if err == goredis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set stores value under key with the configured TTL
+func (c *Cache) Set(key string, value []byte) error {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.Prefix+key, value, c.TTL).Err(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
+
+// context returns a context bounded by Timeout
+func (c *Cache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.Timeout)
+}
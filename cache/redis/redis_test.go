@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL not set")
+	}
+
+	cache, err := Open(url)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer cache.Close()
+	cache.Prefix = "ai-git-commit-test:"
+
+	if err := cache.Set("key", []byte("value")); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	value, ok, err := cache.Get("key")
+	if !ok || err != nil || string(value) != "value" {
+		t.Errorf("Expected cached value, got %q ok=%v err=%v", value, ok, err)
+	}
+
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Errorf("Expected a miss, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestOpenInvalidURL(t *testing.T) {
+	if _, err := Open("not-a-url"); err == nil {
+		t.Error("Expected an error for an invalid URL")
+	}
+}
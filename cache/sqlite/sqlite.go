@@ -0,0 +1,71 @@
+// Package sqlite provides a gitcommenter.Cache stored in a single SQLite
+// database file, convenient for machines that want one portable cache file.
+// The driver is pure Go, so the package builds without cgo.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Cache is a gitcommenter.Cache backed by SQLite
+type Cache struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the cache database at path
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS cache (
+		key TEXT PRIMARY KEY,
+		value BLOB NOT NULL,
+		created_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create cache table: %w", err)
+	}
+
+	return &Cache{db: db}, nil
+}
+
+// Get returns the value stored for key
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	err := c.db.QueryRow(`SELECT value FROM cache WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set stores value under key, replacing any previous value
+func (c *Cache) Set(key string, value []byte) error {
+	_, err := c.db.Exec(`INSERT OR REPLACE INTO cache (key, value, created_at) VALUES (?, ?, ?)`,
+		key, value, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cache.db")
+	cache, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer cache.Close()
+
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Errorf("Expected a miss, got ok=%v err=%v", ok, err)
+	}
+
+	for _, value := range []string{"first", "second"} {
+		if err := cache.Set("key", []byte(value)); err != nil {
+			t.Fatalf("Set returned error: %v", err)
+		}
+	}
+
+	value, ok, err := cache.Get("key")
+	if !ok || err != nil || string(value) != "second" {
+		t.Errorf("Expected the latest value, got %q ok=%v err=%v", value, ok, err)
+	}
+}
@@ -0,0 +1,83 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	a := CacheKey("generate", "ab", "c")
+	b := CacheKey("generate", "a", "bc")
+	if a == b {
+		t.Error("Expected different part boundaries to produce different keys")
+	}
+	if a != CacheKey("generate", "ab", "c") {
+		t.Error("Expected keys to be deterministic")
+	}
+	if !contains(a, "generate-") {
+		t.Errorf("Expected kind prefix, got %q", a)
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache returned error: %v", err)
+	}
+
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Errorf("Expected a miss, got ok=%v err=%v", ok, err)
+	}
+
+	if err := cache.Set("key", []byte("value")); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	value, ok, err := cache.Get("key")
+	if !ok || err != nil || string(value) != "value" {
+		t.Errorf("Expected cached value, got %q ok=%v err=%v", value, ok, err)
+	}
+
+	if err := cache.Set("../escape", []byte("x")); err == nil {
+		t.Error("Expected an error for a key containing a path separator")
+	}
+}
+
+func TestCallOllamaUsesCache(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add cache", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc.SetCache(cache)
+
+	for i := 0; i < 2; i++ {
+		response, err := gc.callOllama("prompt")
+		if err != nil || response != "feat: add cache" {
+			t.Fatalf("Unexpected response %q (err %v)", response, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one model call, got %d", calls)
+	}
+
+	// Changing the model changes the key
+	config.Model = "mistral"
+	if _, err := gc.callOllama("prompt"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected a second model call for another model, got %d", calls)
+	}
+}
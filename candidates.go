@@ -0,0 +1,92 @@
+package gitcommenter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// GenerateCommitMessages generates up to n distinct suggestions for the
+// changes, ranked best first. The candidates are requested in parallel with
+// increasing temperatures. Duplicates returned by the model are dropped, so
+// fewer than n suggestions may be returned.
+func (gc *GitCommenter) GenerateCommitMessages(changes []FileChange, n int) ([]*CommitSuggestion, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of candidates: %d", n)
+	}
+
+	plan, err := gc.plan(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*CommitSuggestion, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for candidate := 0; candidate < n; candidate++ {
+		wg.Add(1)
+		go func(candidate int) {
+			defer wg.Done()
+			results[candidate], errs[candidate] = gc.complete(context.Background(), plan, candidate, nil)
+		}(candidate)
+	}
+	wg.Wait()
+
+	var suggestions []*CommitSuggestion
+	seen := make(map[string]bool)
+	for candidate, suggestion := range results {
+		if errs[candidate] != nil {
+			return nil, errs[candidate]
+		}
+		if seen[suggestion.Message()] {
+			continue
+		}
+		seen[suggestion.Message()] = true
+		suggestions = append(suggestions, suggestion)
+	}
+
+	RankSuggestions(suggestions)
+	return suggestions, nil
+}
+
+// RankSuggestions sorts suggestions best first: messages that follow every
+// rule, with higher confidence, a subject within 50 characters and a body
+// rank higher. Ties keep their order.
+func RankSuggestions(suggestions []*CommitSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return rankScore(suggestions[i]) > rankScore(suggestions[j])
+	})
+}
+
+// rankScore scores a suggestion for RankSuggestions
+func rankScore(s *CommitSuggestion) float64 {
+	score := s.Confidence - 0.5*float64(len(s.Warnings))
+	if utf8.RuneCountInString(s.Subject) <= 50 {
+		score += 0.1
+	}
+	if s.Body != "" {
+		score += 0.05
+	}
+	return score
+}
+
+// ComposeSuggestion builds a suggestion from the subject of one candidate
+// and the body of another. A nil body candidate gives a subject-only message.
+func ComposeSuggestion(subjectFrom, bodyFrom *CommitSuggestion) *CommitSuggestion {
+	composed := &CommitSuggestion{
+		Subject:       subjectFrom.Subject,
+		Confidence:    subjectFrom.Confidence,
+		FilesAffected: subjectFrom.FilesAffected,
+		Warnings:      subjectFrom.Warnings,
+		PromptHash:    subjectFrom.PromptHash,
+	}
+	if bodyFrom != nil {
+		composed.Body = bodyFrom.Body
+		if bodyFrom.Confidence < composed.Confidence {
+			composed.Confidence = bodyFrom.Confidence
+		}
+	}
+	return composed
+}
@@ -0,0 +1,63 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateCommitMessages(t *testing.T) {
+	// Candidates are requested in parallel, so answer by temperature
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		response := "feat(cli): add setup wizard\n\nWrites .ai-commit.yaml."
+		if req.Options.Temperature < 0.55 {
+			response = "Add setup wizard"
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.Temperature = 0.5
+	config.AdaptiveTemperature = false
+	gc := New(config)
+	changes := []FileChange{{FilePath: "init.go", ChangeType: "added", Diff: "+package main"}}
+
+	suggestions, err := gc.GenerateCommitMessages(changes, 3)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessages returned error: %v", err)
+	}
+	if len(suggestions) != 2 {
+		t.Fatalf("Expected 2 distinct suggestions, got %d", len(suggestions))
+	}
+	// The first candidate breaks the style, so it is ranked last
+	if suggestions[0].Subject != "feat(cli): add setup wizard" || len(suggestions[1].Warnings) == 0 {
+		t.Errorf("Unexpected ranking: %q, then %q", suggestions[0].Subject, suggestions[1].Subject)
+	}
+
+	if _, err := gc.GenerateCommitMessages(changes, 0); err == nil {
+		t.Error("Expected an error for zero candidates")
+	}
+}
+
+func TestComposeSuggestion(t *testing.T) {
+	first := &CommitSuggestion{Subject: "feat: add init wizard", Body: "first body", Confidence: 0.8, Warnings: []string{"w"}}
+	second := &CommitSuggestion{Subject: "feat(cli): add setup wizard", Body: "second body", Confidence: 0.5}
+
+	composed := ComposeSuggestion(first, second)
+	if composed.Message() != "feat: add init wizard\n\nsecond body" {
+		t.Errorf("Unexpected composed message %q", composed.Message())
+	}
+	if composed.Confidence != 0.5 || len(composed.Warnings) != 1 {
+		t.Errorf("Expected the lower confidence and the subject's warnings, got %+v", composed)
+	}
+
+	if composed := ComposeSuggestion(second, nil); composed.Message() != "feat(cli): add setup wizard" {
+		t.Errorf("Expected a subject-only message, got %q", composed.Message())
+	}
+}
@@ -0,0 +1,219 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ChangelogCategories are the change categories of Keep a Changelog
+// (https://keepachangelog.com), in the order they appear in a release
+var ChangelogCategories = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// changelogTypes maps conventional types to categories when the model's
+// answer cannot be used; other types are maintenance and left out
+var changelogTypes = map[string]string{
+	"feat":     "Added",
+	"fix":      "Fixed",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"revert":   "Removed",
+}
+
+// ChangelogRelease is a version section of a Keep a Changelog file
+type ChangelogRelease struct {
+	Version string
+	Date    time.Time
+	// Entries maps categories (see ChangelogCategories) to their bullets
+	Entries map[string][]string
+}
+
+// String renders the section, e.g. "## [1.3.0] - 2026-01-31" followed by
+// one "### Added" list per category with entries
+func (r *ChangelogRelease) String() string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("## [%s] - %s\n", r.Version, r.Date.Format("2006-01-02")))
+	for _, category := range ChangelogCategories {
+		if len(r.Entries[category]) == 0 {
+			continue
+		}
+		out.WriteString("\n### " + category + "\n\n")
+		for _, entry := range r.Entries[category] {
+			out.WriteString("- " + entry + "\n")
+		}
+	}
+	return out.String()
+}
+
+// GenerateChangelogRelease writes the changelog section of version from the
+// commits since the last tag (see GenerateReleaseNotes). The model sorts the
+// user-facing changes into the Keep a Changelog categories; when its answer
+// has none, feat, fix, perf, refactor and revert commits are sorted by type.
+func (gc *GitCommenter) GenerateChangelogRelease(version string) (*ChangelogRelease, error) {
+	commits, _, _, err := gc.releaseCommits("", "")
+	if err != nil {
+		return nil, err
+	}
+	release := &ChangelogRelease{Version: version, Date: time.Now(), Entries: make(map[string][]string)}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing the " + version + " section of a CHANGELOG.md in the Keep a Changelog format, for the users of the project.\n\n")
+	prompt.WriteString("COMMITS SINCE THE LAST RELEASE:\n")
+	for _, commit := range commits {
+		prompt.WriteString("- " + commit.Subject + "\n")
+		if commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(commit.Body, 300), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\nSort the changes users notice under these headings, leaving out empty ones: ### " + strings.Join(ChangelogCategories, ", ### ") + "\n")
+	prompt.WriteString("- One bullet per change, starting with '- ', in plain language without commit types or hashes\n")
+	prompt.WriteString("- Merge commits describing the same change; leave out refactoring, tests, CI and other maintenance\n")
+	prompt.WriteString("- Mark breaking changes with **Breaking:** at the start of the bullet\n")
+	if language := gc.language(); language != "en" {
+		prompt.WriteString("- Write the bullets in " + languageName(language) + "; keep the headings in English\n")
+	}
+	prompt.WriteString("\nRespond with only the headings and bullets, no additional text.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate changelog: %w", err)
+	}
+	release.Entries = parseChangelogEntries(response)
+	if len(release.Entries) > 0 {
+		return release, nil
+	}
+
+	for _, commit := range commits {
+		entry := ParseReleaseEntry(commit)
+		category, ok := changelogTypes[entry.Type]
+		if !ok {
+			continue
+		}
+		bullet := entry.Description
+		if entry.Breaking {
+			bullet = "**Breaking:** " + bullet
+		}
+		release.Entries[category] = append(release.Entries[category], bullet)
+	}
+	return release, nil
+}
+
+// parseChangelogEntries reads "### Category" headings and their "- " bullets,
+// ignoring unknown headings and anything else
+func parseChangelogEntries(text string) map[string][]string {
+	entries := make(map[string][]string)
+	category := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(line, "###"); ok {
+			category = ""
+			for _, known := range ChangelogCategories {
+				if strings.EqualFold(strings.TrimSpace(heading), known) {
+					category = known
+				}
+			}
+			continue
+		}
+		bullet, ok := strings.CutPrefix(line, "- ")
+		if !ok {
+			bullet, ok = strings.CutPrefix(line, "* ")
+		}
+		if ok && category != "" && strings.TrimSpace(bullet) != "" {
+			entries[category] = append(entries[category], strings.TrimSpace(bullet))
+		}
+	}
+	return entries
+}
+
+// changelogHeader starts a new CHANGELOG.md
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [Unreleased]
+`
+
+var (
+	// changelogVersionHeading matches "## [1.2.0] - 2024-01-31" and
+	// "## [Unreleased]" headings
+	changelogVersionHeading = regexp.MustCompile(`^## \[([^\]]+)\]`)
+	// unreleasedLink matches the compare link of the Unreleased section
+	unreleasedLink = regexp.MustCompile(`(?i)^\[unreleased\]:\s*(\S+/compare/)(\S+?)\.\.\.(\S+)\s*$`)
+)
+
+// InsertChangelogRelease adds release to a Keep a Changelog file, below the
+// Unreleased section and above the previous releases, leaving everything
+// else as it is. An empty changelog gets the standard header. When the file
+// has an Unreleased compare link, e.g. ".../compare/v1.2.0...HEAD", it is
+// moved to the new version and a link for the release is added.
+func InsertChangelogRelease(changelog string, release *ChangelogRelease) (string, error) {
+	if strings.TrimSpace(changelog) == "" {
+		changelog = changelogHeader
+	}
+	lines := strings.Split(strings.TrimRight(changelog, "\n"), "\n")
+
+	insertAt, linksAt := -1, -1
+	for i, line := range lines {
+		if match := changelogVersionHeading.FindStringSubmatch(line); match != nil {
+			if strings.EqualFold(match[1], release.Version) || strings.EqualFold(strings.TrimPrefix(match[1], "v"), strings.TrimPrefix(release.Version, "v")) {
+				return "", fmt.Errorf("the changelog already has a section for %s", release.Version)
+			}
+			if insertAt < 0 && !strings.EqualFold(match[1], "Unreleased") {
+				insertAt = i
+			}
+		}
+		if linksAt < 0 && strings.HasPrefix(line, "[") && strings.Contains(line, "]: ") {
+			linksAt = i
+		}
+	}
+	// Without previous releases the section goes above the link
+	// definitions, or at the end
+	if insertAt < 0 {
+		insertAt = len(lines)
+		if linksAt >= 0 {
+			insertAt = linksAt
+		}
+	}
+
+	section := strings.Split(strings.TrimRight(release.String(), "\n"), "\n")
+	// Keep one blank line on each side of the new section
+	for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	updated := append([]string{}, lines[:insertAt]...)
+	if insertAt > 0 {
+		updated = append(updated, "")
+	}
+	updated = append(updated, section...)
+	rest := lines[insertAt:]
+	for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		updated = append(updated, "")
+	}
+	updated = append(updated, rest...)
+
+	// Move the Unreleased compare link to the new tag
+	for i, line := range updated {
+		match := unreleasedLink.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		base, previous := match[1], match[2]
+		tag := release.Version
+		if strings.HasPrefix(previous, "v") && !strings.HasPrefix(tag, "v") {
+			tag = "v" + tag
+		}
+		link := fmt.Sprintf("[%s]: %s%s...%s", release.Version, base, previous, tag)
+		updated[i] = fmt.Sprintf("%s%s%s...%s", line[:strings.Index(line, match[1])], base, tag, match[3])
+		updated = append(updated[:i+1], append([]string{link}, updated[i+1:]...)...)
+		break
+	}
+	return strings.Join(updated, "\n") + "\n", nil
+}
@@ -0,0 +1,130 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInsertChangelogRelease(t *testing.T) {
+	release := &ChangelogRelease{
+		Version: "1.3.0",
+		Date:    time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		Entries: map[string][]string{"Fixed": {"Empty pages no longer crash"}, "Added": {"Paging"}},
+	}
+	existing := `# Changelog
+
+Notes kept by hand.
+
+## [Unreleased]
+
+- Something in progress
+
+## [1.2.0] - 2025-12-01
+
+### Added
+
+- Search
+
+[unreleased]: https://github.com/acme/app/compare/v1.2.0...HEAD
+[1.2.0]: https://github.com/acme/app/compare/v1.1.0...v1.2.0
+`
+	want := `# Changelog
+
+Notes kept by hand.
+
+## [Unreleased]
+
+- Something in progress
+
+## [1.3.0] - 2026-01-31
+
+### Added
+
+- Paging
+
+### Fixed
+
+- Empty pages no longer crash
+
+## [1.2.0] - 2025-12-01
+
+### Added
+
+- Search
+
+[unreleased]: https://github.com/acme/app/compare/v1.3.0...HEAD
+[1.3.0]: https://github.com/acme/app/compare/v1.2.0...v1.3.0
+[1.2.0]: https://github.com/acme/app/compare/v1.1.0...v1.2.0
+`
+	got, err := InsertChangelogRelease(existing, release)
+	if err != nil {
+		t.Fatalf("InsertChangelogRelease returned error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Unexpected changelog:\n%s", got)
+	}
+
+	if _, err := InsertChangelogRelease(got, release); err == nil {
+		t.Error("Expected an error for a version already in the changelog")
+	}
+
+	created, err := InsertChangelogRelease("", release)
+	if err != nil {
+		t.Fatalf("InsertChangelogRelease returned error: %v", err)
+	}
+	if !strings.HasPrefix(created, "# Changelog\n") || !strings.HasSuffix(created, "## [Unreleased]\n\n## [1.3.0] - 2026-01-31\n\n### Added\n\n- Paging\n\n### Fixed\n\n- Empty pages no longer crash\n") {
+		t.Errorf("Unexpected new changelog:\n%s", created)
+	}
+}
+
+func TestGenerateChangelogRelease(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, message := range []string{"feat: first release", "feat(api): add paging", "chore: bump deps", "fix!: reject empty pages"} {
+		writeFile(t, dir, "log.txt", message)
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", message)
+		if i == 0 {
+			gitRun(t, dir, "tag", "v1.0.0")
+		}
+	}
+
+	response := "### Added\n- Paging for the API\n\n### Unknown\n- dropped\n\n### Fixed\n- **Breaking:** Empty pages are rejected"
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	release, err := gc.GenerateChangelogRelease("1.1.0")
+	if err != nil {
+		t.Fatalf("GenerateChangelogRelease returned error: %v", err)
+	}
+	if strings.Contains(prompt, "first release") || !strings.Contains(prompt, "feat(api): add paging") {
+		t.Errorf("Expected the commits since the last tag in the prompt:\n%s", prompt)
+	}
+	if len(release.Entries) != 2 || release.Entries["Added"][0] != "Paging for the API" || release.Entries["Fixed"][0] != "**Breaking:** Empty pages are rejected" {
+		t.Errorf("Unexpected entries %v", release.Entries)
+	}
+
+	// Without usable headings the commits are sorted by type
+	response = "Here are the changes."
+	release, err = gc.GenerateChangelogRelease("1.1.0")
+	if err != nil {
+		t.Fatalf("GenerateChangelogRelease returned error: %v", err)
+	}
+	if strings.Join(release.Entries["Added"], ",") != "add paging" || strings.Join(release.Entries["Fixed"], ",") != "**Breaking:** reject empty pages" || len(release.Entries) != 2 {
+		t.Errorf("Unexpected fallback entries %v", release.Entries)
+	}
+}
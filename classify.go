@@ -0,0 +1,175 @@
+package gitcommenter
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the broad kind of a changeset, used to tune generation
+type ChangeKind string
+
+// Change kinds returned by ClassifyChanges
+const (
+	KindRename       ChangeKind = "rename"
+	KindFormatting   ChangeKind = "formatting"
+	KindDependencies ChangeKind = "dependencies"
+	KindDocs         ChangeKind = "docs"
+	KindTests        ChangeKind = "tests"
+	KindFeature      ChangeKind = "feature"
+	KindChange       ChangeKind = "change"
+)
+
+// kindTemperatureFactors scale the configured temperature: mechanical
+// changes need precision, feature work benefits from more creative wording
+var kindTemperatureFactors = map[ChangeKind]float64{
+	KindRename:       0.3,
+	KindFormatting:   0.3,
+	KindDependencies: 0.3,
+	KindDocs:         0.7,
+	KindTests:        0.7,
+	KindFeature:      1.2,
+	KindChange:       1.0,
+}
+
+// dependencyFiles are manifests and lock files of common package managers
+var dependencyFiles = map[string]bool{
+	"go.mod": true, "go.sum": true,
+	"package.json": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.toml": true, "Cargo.lock": true,
+	"Pipfile": true, "Pipfile.lock": true, "poetry.lock": true, "pyproject.toml": true,
+	"Gemfile": true, "Gemfile.lock": true, "composer.json": true, "composer.lock": true,
+}
+
+// ClassifyChanges returns the kind of a changeset
+func ClassifyChanges(changes []FileChange) ChangeKind {
+	if len(changes) == 0 {
+		return KindChange
+	}
+
+	all := func(match func(FileChange) bool) bool {
+		for _, change := range changes {
+			if !match(change) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case all(func(c FileChange) bool { return c.ChangeType == "renamed" && c.LinesAdded == 0 && c.LinesRemoved == 0 }):
+		return KindRename
+	case all(isFormattingChange):
+		return KindFormatting
+	case all(func(c FileChange) bool { return isDependencyFile(c.FilePath) }):
+		return KindDependencies
+	case all(func(c FileChange) bool { return isDocFile(c.FilePath) }):
+		return KindDocs
+	case all(func(c FileChange) bool { return isTestFile(c.FilePath) }):
+		return KindTests
+	}
+
+	added, removed := 0, 0
+	for _, change := range changes {
+		if change.ChangeType == "added" && !isTestFile(change.FilePath) && !isDocFile(change.FilePath) {
+			return KindFeature
+		}
+		added += change.LinesAdded
+		removed += change.LinesRemoved
+	}
+	if added >= 20 && added >= 3*removed {
+		return KindFeature
+	}
+	return KindChange
+}
+
+// AdaptiveTemperature returns the kind of the changes and the temperature
+// used for them: the configured temperature scaled by the kind when
+// Config.AdaptiveTemperature is set
+func (gc *GitCommenter) AdaptiveTemperature(changes []FileChange) (ChangeKind, float64) {
+	kind := ClassifyChanges(changes)
+	if !gc.config.AdaptiveTemperature {
+		return kind, gc.config.Temperature
+	}
+	return kind, min(gc.config.Temperature*kindTemperatureFactors[kind], 1)
+}
+
+// isFormattingChange reports whether a modification only changes
+// whitespace: the removed and added lines are equal once it is stripped
+func isFormattingChange(change FileChange) bool {
+	if change.ChangeType != "modified" {
+		return false
+	}
+
+	var removed, added []string
+	for _, line := range strings.Split(change.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, strings.Join(strings.Fields(line[1:]), ""))
+		case strings.HasPrefix(line, "+"):
+			added = append(added, strings.Join(strings.Fields(line[1:]), ""))
+		}
+	}
+	if len(removed) == 0 && len(added) == 0 {
+		return false
+	}
+
+	// Reflowed code joins or splits lines; formatters may also reorder
+	// lines such as imports
+	if strings.Join(removed, "") == strings.Join(added, "") {
+		return true
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return strings.Join(removed, "\n") == strings.Join(added, "\n")
+}
+
+// isDependencyFile reports whether p is a package manifest or lock file
+func isDependencyFile(p string) bool {
+	base := path.Base(p)
+	return dependencyFiles[base] || strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt")
+}
+
+// isDocFile reports whether p is documentation
+func isDocFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".rst", ".adoc", ".txt":
+		return !isDependencyFile(p)
+	}
+	return strings.HasPrefix(p, "docs/") || strings.Contains(p, "/docs/")
+}
+
+// configFiles are configuration files recognized by name
+var configFiles = map[string]bool{
+	"Makefile": true, "Dockerfile": true, ".editorconfig": true, ".gitignore": true, ".gitattributes": true,
+	".dockerignore": true, ".env.example": true,
+}
+
+// isConfigFile reports whether p is configuration rather than source code
+func isConfigFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".json", ".yml", ".yaml", ".toml", ".ini", ".cfg", ".conf", ".properties", ".xml", ".env":
+		return true
+	}
+	return configFiles[path.Base(p)] || strings.HasPrefix(p, ".github/")
+}
+
+// ciFiles are CI configurations recognized by name
+var ciFiles = map[string]bool{
+	".gitlab-ci.yml": true, ".travis.yml": true, "Jenkinsfile": true, "azure-pipelines.yml": true,
+	"bitbucket-pipelines.yml": true, ".drone.yml": true, "appveyor.yml": true,
+}
+
+// isCIFile reports whether p configures continuous integration
+func isCIFile(p string) bool {
+	return ciFiles[path.Base(p)] || strings.HasPrefix(p, ".github/workflows/") || strings.HasPrefix(p, ".circleci/") ||
+		strings.HasPrefix(p, ".buildkite/")
+}
+
+// isTestFile reports whether p is a test file
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasPrefix(p, "testdata/") || strings.Contains(p, "/testdata/")
+}
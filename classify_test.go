@@ -0,0 +1,53 @@
+package gitcommenter
+
+import "testing"
+
+func TestClassifyChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []FileChange
+		want    ChangeKind
+	}{
+		{"rename", []FileChange{{FilePath: "util/strings.go", ChangeType: "renamed"}}, KindRename},
+		{"formatting", []FileChange{{FilePath: "main.go", ChangeType: "modified",
+			Diff: "-func main(){\n-  run( )\n+func main() {\n+\trun()"}}, KindFormatting},
+		{"reflow", []FileChange{{FilePath: "main.go", ChangeType: "modified",
+			Diff: "-call(a,\n-  b)\n+call(a, b)"}}, KindFormatting},
+		{"dependencies", []FileChange{
+			{FilePath: "go.mod", ChangeType: "modified", Diff: "-x v1\n+x v2", LinesAdded: 1, LinesRemoved: 1},
+			{FilePath: "go.sum", ChangeType: "modified", Diff: "+x v2 h1:abc", LinesAdded: 1},
+		}, KindDependencies},
+		{"docs", []FileChange{{FilePath: "README.md", ChangeType: "modified", Diff: "+More docs", LinesAdded: 1}}, KindDocs},
+		{"tests", []FileChange{{FilePath: "parser_test.go", ChangeType: "added", Diff: "+package parser", LinesAdded: 1}}, KindTests},
+		{"feature", []FileChange{
+			{FilePath: "wizard.go", ChangeType: "added", Diff: "+package main", LinesAdded: 1},
+			{FilePath: "wizard_test.go", ChangeType: "added", Diff: "+package main", LinesAdded: 1},
+		}, KindFeature},
+		{"change", []FileChange{{FilePath: "main.go", ChangeType: "modified", Diff: "-a()\n+b()", LinesAdded: 1, LinesRemoved: 1}}, KindChange},
+	}
+
+	for _, test := range tests {
+		if got := ClassifyChanges(test.changes); got != test.want {
+			t.Errorf("%s: ClassifyChanges = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAdaptiveTemperature(t *testing.T) {
+	config := DefaultConfig()
+	gc := New(config)
+	deps := []FileChange{{FilePath: "go.sum", ChangeType: "modified", Diff: "+x v2 h1:abc", LinesAdded: 1}}
+	feature := []FileChange{{FilePath: "wizard.go", ChangeType: "added", Diff: "+package main", LinesAdded: 1}}
+
+	if kind, temperature := gc.AdaptiveTemperature(deps); kind != KindDependencies || temperature >= config.Temperature {
+		t.Errorf("Expected a lower temperature for dependencies, got %s %.2f", kind, temperature)
+	}
+	if _, temperature := gc.AdaptiveTemperature(feature); temperature <= config.Temperature || temperature > 1 {
+		t.Errorf("Expected a higher temperature for features, got %.2f", temperature)
+	}
+
+	config.AdaptiveTemperature = false
+	if _, temperature := gc.AdaptiveTemperature(deps); temperature != config.Temperature {
+		t.Errorf("Expected the configured temperature when disabled, got %.2f", temperature)
+	}
+}
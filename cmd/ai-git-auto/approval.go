@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// approveCommit asks whether to commit. Answering c copies the message to
+// the clipboard, and x removes files from the commit and regenerates the
+// message for the rest; both ask again. It returns the answer with the
+// changes left in the commit and their message.
+func approveCommit(commenter *gitcommenter.GitCommenter, changes []gitcommenter.FileChange, suggestion *gitcommenter.CommitSuggestion,
+	regenerate func([]gitcommenter.FileChange) (*gitcommenter.CommitSuggestion, error), dryRun bool) (bool, []gitcommenter.FileChange, *gitcommenter.CommitSuggestion) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		ui.Print("❓ Do you want to commit with this message? (Y/n, c to copy, x to exclude files): ")
+		response, _ := reader.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(response)) {
+		case "", "y", "yes":
+			return true, changes, suggestion
+		case "c", "copy":
+			copyMessage(suggestion.Message())
+		case "x":
+			excluded := askExcludedFiles(reader, changes)
+			if len(excluded) == 0 {
+				continue
+			}
+			if len(excluded) == len(changes) {
+				ui.Println("   ❌ At least one file must stay in the commit")
+				continue
+			}
+
+			if dryRun {
+				ui.Printf("   [DRY RUN] Would unstage: %s\n", strings.Join(excluded, ", "))
+			} else if err := commenter.Unstage(excluded); err != nil {
+				ui.Printf("   ❌ %v\n", err)
+				continue
+			} else {
+				ui.Printf("   ➖ Unstaged: %s\n", strings.Join(excluded, ", "))
+			}
+
+			var remaining []gitcommenter.FileChange
+			for _, change := range changes {
+				if !containsPath(excluded, change.FilePath) {
+					remaining = append(remaining, change)
+				}
+			}
+			changes = remaining
+
+			ui.Println("   ➤ Regenerating the message for the remaining files...")
+			regenerated, err := regenerate(changes)
+			if err != nil {
+				ui.Printf("   ⚠️  Failed to regenerate the message: %v\n", err)
+				continue
+			}
+			suggestion = regenerated
+			displayCommitSuggestion(suggestion)
+		default:
+			return false, changes, suggestion
+		}
+	}
+}
+
+// askExcludedFiles lists the changes and returns the paths picked by number
+func askExcludedFiles(reader *bufio.Reader, changes []gitcommenter.FileChange) []string {
+	for i, change := range changes {
+		ui.Printf("      %d. %s %s\n", i+1, getChangeIcon(change.ChangeType), change.FilePath)
+	}
+	for {
+		answer := ask(reader, "Files to leave out of the commit (e.g. 1,3; empty to cancel)", "")
+		if answer == "" {
+			return nil
+		}
+
+		var paths []string
+		valid := true
+		for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			index, ok := candidateIndex(field, len(changes))
+			if !ok {
+				valid = false
+				break
+			}
+			if !containsPath(paths, changes[index].FilePath) {
+				paths = append(paths, changes[index].FilePath)
+			}
+		}
+		if valid {
+			return paths
+		}
+		ui.Printf("   ❌ Please answer numbers between 1 and %d\n", len(changes))
+	}
+}
+
+// containsPath reports whether paths contains path
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
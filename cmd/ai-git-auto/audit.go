@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runAudit scores recent commit messages and prints the worst ones
+func runAudit(args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	last := fs.Int("last", 50, "Number of recent commits to audit")
+	worst := fs.Int("worst", 10, "Number of lowest-scoring commits to report")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto audit [-last <n>] [-worst <n>] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 || *last < 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	if rules, err := gitcommenter.LoadCommitlintConfig("."); err != nil {
+		ui.Fprintf(os.Stderr, "⚠️  Ignoring commitlint config: %v\n", err)
+	} else if rules != nil {
+		config.Commitlint = rules
+	}
+	commenter := gitcommenter.New(config)
+
+	ui.Fprintf(os.Stderr, "🔎 Grading the last %d commit messages (using %s)...\n", *last, config.Model)
+	audits, err := commenter.AuditHistory(*last, func(done, total int) {
+		ui.Fprintf(os.Stderr, "\r   %d/%d", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+
+	fmt.Println(gitcommenter.AuditSummary(audits))
+	if *worst > len(audits) {
+		*worst = len(audits)
+	}
+	if *worst > 0 {
+		fmt.Printf("\nWorst %d:\n", *worst)
+	}
+	for _, audit := range audits[:*worst] {
+		fmt.Printf("%3d/100  %s  %s\n", audit.Score, audit.Commit.ShortHash(), audit.Commit.Subject)
+		if problems := audit.Problems(); len(problems) > 0 {
+			fmt.Println(indentLines(strings.Join(problems, "\n"), "         - "))
+		}
+	}
+}
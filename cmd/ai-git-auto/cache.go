@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	"github.com/TheRealMasterK/Ai-Git-Comments-Auto/cache/redis"
+	"github.com/TheRealMasterK/Ai-Git-Comments-Auto/cache/sqlite"
+)
+
+// openCache opens the response cache described by spec: "fs[:dir]",
+// "sqlite[:path]" or a redis:// URL. An empty spec or "off" returns nil.
+func openCache(spec string) (gitcommenter.Cache, error) {
+	kind, location, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "", "off":
+		return nil, nil
+	case "fs":
+		return gitcommenter.NewFileCache(location)
+	case "sqlite":
+		if location == "" {
+			dir, err := gitcommenter.DefaultCacheDir()
+			if err != nil {
+				return nil, err
+			}
+			location = filepath.Join(dir, "cache.db")
+		}
+		return sqlite.Open(location)
+	case "redis", "rediss":
+		return redis.Open(spec)
+	default:
+		return nil, fmt.Errorf("unknown cache %q (use fs, sqlite or a redis:// URL)", spec)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// displayCandidates lists ranked suggestions with their numbers
+func displayCandidates(candidates []*gitcommenter.CommitSuggestion) {
+	fmt.Println(strings.Repeat("=", 60))
+	ui.Printf("🎯 %d AI-GENERATED CANDIDATES (best first)\n", len(candidates))
+	fmt.Println(strings.Repeat("=", 60))
+	for i, candidate := range candidates {
+		marker := ""
+		if i == 0 {
+			marker = ui.Text("  ⭐ recommended")
+		}
+		ui.Printf("\n[%d] 📝 %s%s\n", i+1, candidate.Subject, marker)
+		for _, warning := range candidate.Warnings {
+			ui.Printf("    ⚠️  %s\n", warning)
+		}
+		if candidate.Body != "" {
+			fmt.Println(indentLines(candidate.Body, "    "))
+		}
+	}
+	fmt.Println(strings.Repeat("=", 60))
+}
+
+// chooseCandidate lets the user pick a whole candidate, or compose a message
+// from the subject of one candidate and the body of another
+func chooseCandidate(candidates []*gitcommenter.CommitSuggestion) *gitcommenter.CommitSuggestion {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		answer := ask(reader, fmt.Sprintf("Pick a candidate (1-%d) or 'm' to mix subject and body", len(candidates)), "1")
+		if strings.EqualFold(answer, "m") {
+			return composeCandidate(reader, candidates)
+		}
+		if index, ok := candidateIndex(answer, len(candidates)); ok {
+			return candidates[index]
+		}
+		ui.Printf("   ❌ Please answer a number between 1 and %d or 'm'\n", len(candidates))
+	}
+}
+
+// composeCandidate asks which candidate provides the subject and which the body
+func composeCandidate(reader *bufio.Reader, candidates []*gitcommenter.CommitSuggestion) *gitcommenter.CommitSuggestion {
+	subjectFrom := askCandidate(reader, "Subject from candidate", len(candidates), false)
+	bodyFrom := askCandidate(reader, "Body from candidate (0 for no body)", len(candidates), true)
+
+	var body *gitcommenter.CommitSuggestion
+	if bodyFrom >= 0 {
+		body = candidates[bodyFrom]
+	}
+	composed := gitcommenter.ComposeSuggestion(candidates[subjectFrom], body)
+	ui.Println("   ✅ Composed message:")
+	displayCommitSuggestion(composed)
+	return composed
+}
+
+// askCandidate prompts for a candidate number, returning its index or -1
+// for 0 when allowNone is set
+func askCandidate(reader *bufio.Reader, question string, count int, allowNone bool) int {
+	for {
+		answer := ask(reader, fmt.Sprintf("%s (1-%d)", question, count), "1")
+		if allowNone && answer == "0" {
+			return -1
+		}
+		if index, ok := candidateIndex(answer, count); ok {
+			return index
+		}
+		ui.Printf("   ❌ Please answer a number between 1 and %d\n", count)
+	}
+}
+
+// candidateIndex converts a 1-based answer into an index
+func candidateIndex(answer string, count int) (int, bool) {
+	number, err := strconv.Atoi(answer)
+	if err != nil || number < 1 || number > count {
+		return 0, false
+	}
+	return number - 1, true
+}
+
+// indentLines prefixes every line of text
+func indentLines(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
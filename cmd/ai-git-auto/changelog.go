@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runChangelog adds a generated section for a new version to CHANGELOG.md
+func runChangelog(args []string) {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	version := fs.String("version", "", "Version of the new section (default: the tag on HEAD without its leading v)")
+	path := fs.String("file", "CHANGELOG.md", "Changelog to update; created when missing")
+	dryRun := fs.Bool("dry-run", false, "Print the new section instead of writing the changelog")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto changelog [-version <version>] [-file CHANGELOG.md] [-dry-run] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	if *version == "" {
+		output, err := exec.Command("git", "describe", "--tags", "--exact-match", "HEAD").Output()
+		if err != nil {
+			ui.Fatalf("❌ HEAD is not tagged; name the new version with -version")
+		}
+		*version = strings.TrimPrefix(strings.TrimSpace(string(output)), "v")
+	}
+
+	// Check the file before asking the model
+	existing, err := os.ReadFile(*path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		ui.Fatalf("❌ Failed to read %s: %v", *path, err)
+	}
+	if _, err := gitcommenter.InsertChangelogRelease(string(existing), &gitcommenter.ChangelogRelease{Version: *version}); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	config := buildConfig()
+	// A changelog section is much longer than a commit message
+	if config.MaxTokens < 1024 {
+		config.MaxTokens = 1024
+	}
+	commenter := gitcommenter.New(config)
+
+	ui.Fprintf(os.Stderr, "📒 Writing the %s section from the commits since the last tag...\n", *version)
+	release, err := commenter.GenerateChangelogRelease(*version)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	if len(release.Entries) == 0 {
+		ui.Fprintln(os.Stderr, "⚠️  No user-facing changes found; the section is empty")
+	}
+
+	updated, err := gitcommenter.InsertChangelogRelease(string(existing), release)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	if *dryRun {
+		fmt.Print(release.String())
+		return
+	}
+	if err := os.WriteFile(*path, []byte(updated), 0o644); err != nil {
+		ui.Fatalf("❌ Failed to write %s: %v", *path, err)
+	}
+	ui.Fprintf(os.Stderr, "✅ Added %s to %s\n", *version, *path)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// ciReport is the outcome of a -ci run, printed as one JSON object when
+// the run ends, successfully or not
+type ciReport struct {
+	// Status is committed, pushed, generated (dry run or message only),
+	// no_changes, disabled or failed
+	Status     string   `json:"status"`
+	ExitCode   int      `json:"exit_code"`
+	Model      string   `json:"model,omitempty"`
+	Files      []string `json:"files,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Confidence float64  `json:"confidence,omitempty"`
+	Template   string   `json:"template,omitempty"`
+	Commit     string   `json:"commit,omitempty"`
+	Pushed     bool     `json:"pushed"`
+	// PullRequest is the URL of the pull request opened with -create-pr
+	PullRequest string   `json:"pull_request,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+	Error       string   `json:"error,omitempty"`
+
+	out io.Writer
+	// annotate writes GitHub workflow commands for warnings and errors
+	annotate bool
+	done     bool
+}
+
+// newCIReport reports to out, annotating the run on GitHub Actions
+func newCIReport(out io.Writer) *ciReport {
+	return &ciReport{out: out, annotate: os.Getenv("GITHUB_ACTIONS") == "true"}
+}
+
+// setSuggestion records the generated message and its warnings
+func (r *ciReport) setSuggestion(suggestion *gitcommenter.CommitSuggestion) {
+	r.Subject, r.Body, r.Message = suggestion.Subject, suggestion.Body, suggestion.Message()
+	r.Confidence, r.Template = suggestion.Confidence, suggestion.Template
+	for _, warning := range suggestion.Warnings {
+		r.warn("Commit message", warning)
+	}
+}
+
+// warn records a warning, which also becomes a workflow annotation
+func (r *ciReport) warn(title, message string) {
+	r.Warnings = append(r.Warnings, message)
+	if r.annotate {
+		ui.Printf("%s\n", workflowCommand("warning", title, message))
+	}
+}
+
+// finish prints the report once; a non-zero code with an error message
+// marks the run failed
+func (r *ciReport) finish(status string, code int, errText string) {
+	if r.done {
+		return
+	}
+	r.done = true
+	r.Status, r.ExitCode = status, code
+	if errText != "" {
+		r.Status, r.Error = "failed", errText
+	}
+
+	if r.annotate {
+		switch {
+		case r.Error != "":
+			ui.Printf("%s\n", workflowCommand("error", "ai-git-auto", r.Error))
+		case r.Subject != "":
+			ui.Printf("%s\n", workflowCommand("notice", "Commit message", r.Subject))
+		}
+	}
+
+	encoder := json.NewEncoder(r.out)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(r)
+}
+
+// failure finishes the report from a fatal CLI error
+func (r *ciReport) failure(code int, message string) {
+	message = strings.TrimSpace(message)
+	message = strings.TrimSpace(strings.TrimPrefix(message, "❌"))
+	r.finish("failed", code, message)
+}
+
+// workflowCommand formats a GitHub Actions workflow command such as
+// ::warning title=...::message
+func workflowCommand(command, title, message string) string {
+	// Workflow commands must be single-line
+	message = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(message)
+	return "::" + command + " title=" + title + "::" + message
+}
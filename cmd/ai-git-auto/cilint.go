@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runCILint grades the commit messages in a range and emits warnings for
+// vague ones. Vague messages never fail the build.
+func runCILint(args []string) {
+	fs := flag.NewFlagSet("ci-lint", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	minScore := fs.Int("min-score", 3, "Warn about messages scoring below this (1-5)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto ci-lint [flags] [range]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	revRange := fs.Arg(0)
+	if revRange == "" {
+		revRange = defaultCIRange()
+	}
+
+	commenter := gitcommenter.New(buildConfig())
+	commits, err := commenter.Commits(revRange)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	annotate := os.Getenv("GITHUB_ACTIONS") == "true"
+	ui.Printf("🔎 Grading %d commit message(s) in %s\n", len(commits), revRange)
+
+	vague := 0
+	for _, commit := range commits {
+		diff, err := commenter.CommitDiff(commit.Hash)
+		if err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+
+		grade, err := commenter.GradeCommitMessage(commit.Message(), diff)
+		if err != nil {
+			// Grading is advisory; never fail the build because of the model
+			ui.Printf("   ⚠️  %s: could not grade: %v\n", commit.ShortHash(), err)
+			continue
+		}
+
+		ui.Printf("   %d/5 %s %s\n", grade.Score, commit.ShortHash(), commit.Subject)
+		if grade.Score < *minScore {
+			vague++
+			emitWarning(annotate, "Vague commit message",
+				fmt.Sprintf("%s %q scored %d/5: %s", commit.ShortHash(), commit.Subject, grade.Score, grade.Reason))
+		}
+	}
+
+	ui.Printf("✅ %d of %d message(s) below the informativeness threshold\n", vague, len(commits))
+}
+
+// defaultCIRange uses the pull request base branch on GitHub Actions and the
+// last commit elsewhere
+func defaultCIRange() string {
+	if base := os.Getenv("GITHUB_BASE_REF"); base != "" {
+		return "origin/" + base + "..HEAD"
+	}
+	return "HEAD~1..HEAD"
+}
+
+// emitWarning prints a GitHub workflow annotation or a plain warning line
+func emitWarning(annotate bool, title, message string) {
+	if annotate {
+		ui.Printf("%s\n", workflowCommand("warning", title, message))
+		return
+	}
+	ui.Printf("   ⚠️  %s: %s\n", title, message)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands are the clipboard tools tried in order on each platform
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	},
+}
+
+// copyToClipboard puts text on the system clipboard. Without a clipboard
+// tool (e.g. over SSH) it falls back to the OSC 52 escape sequence, which
+// most terminals forward to the local clipboard.
+func copyToClipboard(text string) error {
+	for _, command := range clipboardCommands[runtime.GOOS] {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", command[0], err)
+		}
+		return nil
+	}
+
+	if os.Getenv("TERM") == "" || os.Getenv("TERM") == "dumb" {
+		return fmt.Errorf("no clipboard tool found")
+	}
+	fmt.Printf("\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return nil
+}
+
+// copyMessage copies the full commit message and reports the result
+func copyMessage(message string) {
+	if err := copyToClipboard(message); err != nil {
+		ui.Printf("   ⚠️  Could not copy the message: %v\n", err)
+		return
+	}
+	ui.Println("   📋 Commit message copied to the clipboard")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// applyConfigFiles loads the config files, git config and environment and
+// uses their values for every flag of fs that was not given on the command
+// line, so the precedence is flag > environment > profile > git config >
+// repository file > user file > default. With -analyze-only the repository
+// file is left out.
+func applyConfigFiles(fs *flag.FlagSet) *gitcommenter.FileConfig {
+	explicit := explicitFlags(fs)
+
+	profile := ""
+	if explicit["profile"] {
+		profile = fs.Lookup("profile").Value.String()
+	}
+
+	load := gitcommenter.LoadFileConfigs
+	if untrustedRepository(fs) {
+		load = gitcommenter.LoadUntrustedFileConfigs
+	}
+	fileConfig, err := load(".", profile)
+	if err != nil {
+		if profile != "" {
+			ui.Fatalf("❌ %v", err)
+		}
+		ui.Fprintf(os.Stderr, "⚠️  Ignoring config files: %v\n", err)
+		return &gitcommenter.FileConfig{}
+	}
+
+	for name, value := range fileConfig.Values() {
+		if explicit[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			ui.Fprintf(os.Stderr, "⚠️  Invalid config value for %s: %v\n", name, err)
+		}
+	}
+
+	return fileConfig
+}
+
+// applyFileSettings copies the settings that have no flag from the config
+// files and git config to config
+func applyFileSettings(config *gitcommenter.Config, fileConfig *gitcommenter.FileConfig) {
+	config.GitHubAPI = fileConfig.GitHubAPI
+	config.Redact = fileConfig.Redact
+	// "ai: off" makes OptOutReason turn generation off
+	config.Disabled = fileConfig.AI == "off"
+}
+
+// untrustedRepository reports whether -analyze-only was given, which
+// leaves the repository's config file out: the repository may be hostile
+func untrustedRepository(fs *flag.FlagSet) bool {
+	f := fs.Lookup("analyze-only")
+	return f != nil && explicitFlags(fs)["analyze-only"] && f.Value.String() == "true"
+}
+
+// commandLineFlags records the flags given on the command line of each
+// flag set, before applyConfigFiles sets the others
+var commandLineFlags = make(map[*flag.FlagSet]map[string]bool)
+
+// explicitFlags returns the names of the flags of fs given on the command
+// line
+func explicitFlags(fs *flag.FlagSet) map[string]bool {
+	if explicit, ok := commandLineFlags[fs]; ok {
+		return explicit
+	}
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	commandLineFlags[fs] = explicit
+	return explicit
+}
+
+// watchConfig reloads the config files, git config and environment of a
+// long-running mode whenever they change. The changed settings are logged
+// to stderr, and apply receives a commenter built by build from the new
+// values; flags given on the command line keep theirs.
+func watchConfig(fs *flag.FlagSet, commenter *gitcommenter.GitCommenter, build func() (*gitcommenter.Config, error), apply func(*gitcommenter.GitCommenter)) {
+	explicit := explicitFlags(fs)
+	profile := ""
+	if explicit["profile"] {
+		profile = fs.Lookup("profile").Value.String()
+	}
+	newWatcher := gitcommenter.NewConfigWatcher
+	if untrustedRepository(fs) {
+		newWatcher = gitcommenter.NewUntrustedConfigWatcher
+	}
+	watcher, err := newWatcher(".", profile, 0)
+	if err != nil {
+		ui.Fprintf(os.Stderr, "⚠️  Not watching the config files: %v\n", err)
+		return
+	}
+
+	go watcher.Watch(context.Background(), func(_ *gitcommenter.FileConfig, changes []gitcommenter.ConfigChange) {
+		for _, change := range changes {
+			if explicit[change.Key] {
+				ui.Fprintf(os.Stderr, "🔄 Config changed: %s, overridden by -%s\n", change, change.Key)
+				continue
+			}
+			ui.Fprintf(os.Stderr, "🔄 Config changed: %s\n", change)
+			// A removed setting goes back to the flag's default
+			if f := fs.Lookup(change.Key); f != nil {
+				value := change.New
+				if change.Source == "removed" {
+					value = f.DefValue
+				}
+				if err := fs.Set(change.Key, value); err != nil {
+					ui.Fprintf(os.Stderr, "⚠️  Invalid config value for %s: %v\n", change.Key, err)
+				}
+			}
+		}
+		config, err := build()
+		if err != nil {
+			ui.Fprintf(os.Stderr, "⚠️  Keeping the previous config: %v\n", err)
+			return
+		}
+		commenter = commenter.WithConfig(config)
+		apply(commenter)
+	}, func(err error) {
+		ui.Fprintf(os.Stderr, "⚠️  Keeping the previous config: %v\n", err)
+	})
+}
+
+// runHooks runs configured shell commands, stopping at the first failure
+func runHooks(stage string, commands []string) error {
+	for _, command := range commands {
+		ui.Printf("   ➤ Running %s hook: %s\n", stage, command)
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", stage, command, err)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
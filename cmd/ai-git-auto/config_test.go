@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+func TestRepositoryOptOutReachesTheConfig(t *testing.T) {
+	for name, setup := range map[string]func(t *testing.T, dir string){
+		"config file": func(t *testing.T, dir string) {
+			if err := os.WriteFile(filepath.Join(dir, gitcommenter.ConfigFileName), []byte("ai: off\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"git config": func(t *testing.T, dir string) {
+			if output, err := exec.Command("git", "-C", dir, "config", "ai-commit.ai", "off").CombinedOutput(); err != nil {
+				t.Fatalf("git config failed: %v\n%s", err, output)
+			}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+				t.Fatalf("git init failed: %v\n%s", err, output)
+			}
+			setup(t, dir)
+
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Chdir(wd) })
+
+			config := gitcommenter.DefaultConfig()
+			config.RepositoryPath = "."
+			applyFileSettings(config, applyConfigFiles(flag.NewFlagSet("ai-git-auto", flag.ContinueOnError)))
+			reason, err := gitcommenter.New(config).OptOutReason("")
+			if err != nil || reason == "" {
+				t.Errorf("OptOutReason() = %q, %v, want the repository opt-out", reason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runConfig shows the effective configuration and manages per-repository
+// overrides stored under git config ai-commit.*
+func runConfig(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	global := fs.Bool("global", false, "Use the global git config instead of the repository's")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto config show [-<key> <value>...]")
+		fmt.Fprintln(fs.Output(), "       ai-git-auto config [-global] get <key>")
+		fmt.Fprintln(fs.Output(), "       ai-git-auto config [-global] set <key> <value>")
+		fmt.Fprintln(fs.Output(), "       ai-git-auto config [-global] unset <key>")
+		fmt.Fprintf(fs.Output(), "\nKeys: %s\n\n", strings.Join(gitcommenter.ConfigKeys(), ", "))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	switch {
+	case fs.Arg(0) == "show":
+		showConfig(fs.Args()[1:])
+	case fs.Arg(0) == "get" && fs.NArg() == 2:
+		value, err := gitcommenter.GetGitConfig(".", fs.Arg(1))
+		if err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		if value == "" {
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	case fs.Arg(0) == "set" && fs.NArg() == 3:
+		if err := gitcommenter.SetGitConfig(".", fs.Arg(1), fs.Arg(2), *global); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		ui.Printf("✅ %s.%s = %s\n", gitcommenter.GitConfigSection, fs.Arg(1), fs.Arg(2))
+	case fs.Arg(0) == "unset" && fs.NArg() == 2:
+		if err := gitcommenter.UnsetGitConfig(".", fs.Arg(1), *global); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		ui.Printf("✅ %s.%s unset\n", gitcommenter.GitConfigSection, fs.Arg(1))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// showConfig prints every setting with its effective value and source.
+// Settings can be given as flags to preview how they would override the rest.
+func showConfig(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	flagValues := make(map[string]*string)
+	for _, key := range gitcommenter.ConfigKeys() {
+		flagValues[key] = fs.String(key, "", "Override "+key)
+	}
+	fs.Parse(args)
+
+	layers, err := gitcommenter.LoadConfigLayers(".", *flagValues["profile"])
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	flagConfig := &gitcommenter.FileConfig{}
+	fs.Visit(func(f *flag.Flag) {
+		if err := flagConfig.Set(f.Name, *flagValues[f.Name]); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+	})
+	layers = append(layers, gitcommenter.ConfigLayer{Source: "flag", Config: flagConfig})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range gitcommenter.ResolveSettings(layers) {
+		value := setting.Value
+		if value == "" {
+			value = "(unset)"
+		}
+		source := setting.Source
+		if source == "environment" {
+			source = "env " + gitcommenter.EnvVarName(setting.Key)
+		} else if source == "git config" {
+			source = "git config " + gitcommenter.GitConfigSection + "." + setting.Key
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, value, source)
+	}
+	w.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runCoverLetter generates a cover letter for a patch series and optionally
+// hands the series over to git format-patch and git send-email
+func runCoverLetter(args []string) {
+	fs := flag.NewFlagSet("cover-letter", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	output := fs.String("o", "", "Write the cover letter to this file instead of stdout")
+	patchDir := fs.String("format-patch", "", "Run git format-patch into this directory with the generated cover letter")
+	sendEmail := fs.Bool("send-email", false, "Send the series with git send-email after formatting it")
+	annotate := fs.Bool("annotate", false, "Let git send-email open each patch in your editor before sending")
+	to := fs.String("to", "", "Recipient passed to git send-email --to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto cover-letter [flags] <range>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	revRange := fs.Arg(0)
+
+	commenter := gitcommenter.New(buildConfig())
+	ui.Fprintf(os.Stderr, "✉️  Generating cover letter for %s...\n", revRange)
+
+	letter, err := commenter.GenerateCoverLetter(revRange)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	// Without format-patch or send-email, just print or save the letter
+	if *patchDir == "" && !*sendEmail {
+		if *output == "" {
+			fmt.Print(letter.String())
+			return
+		}
+
+		if err := os.WriteFile(*output, []byte(letter.String()), 0o644); err != nil {
+			ui.Fatalf("❌ Failed to write %s: %v", *output, err)
+		}
+		ui.Fprintf(os.Stderr, "✅ Wrote %s\n", *output)
+		return
+	}
+
+	dir := *patchDir
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "ai-git-auto-series-")
+		if err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+	}
+
+	files, err := commenter.FormatPatchSeries(revRange, dir, letter)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	ui.Fprintf(os.Stderr, "✅ Wrote %d patch file(s) to %s\n", len(files), dir)
+	for _, file := range files {
+		ui.Fprintf(os.Stderr, "   • %s\n", file)
+	}
+
+	if !*sendEmail {
+		return
+	}
+
+	sendArgs := []string{"send-email"}
+	if *annotate {
+		sendArgs = append(sendArgs, "--annotate")
+	}
+	if *to != "" {
+		sendArgs = append(sendArgs, "--to", *to)
+	}
+	sendArgs = append(sendArgs, files...)
+
+	// send-email is interactive (confirmation, --annotate editor), so hand
+	// over the terminal
+	cmd := exec.Command("git", sendArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		ui.Fatalf("❌ git send-email failed: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// addDependencyRisk adds the bumped versions and a risk summary based on
+// their release notes to the body of a dependency update
+func addDependencyRisk(commenter *gitcommenter.GitCommenter, suggestion *gitcommenter.CommitSuggestion, changes []gitcommenter.FileChange) {
+	bumps := commenter.DependencyBumps(changes)
+	if len(bumps) == 0 {
+		ui.Println("   ⚠️  No dependency versions found in the changes")
+		return
+	}
+
+	ui.Printf("   🔗 Reading the release notes of %d dependency update(s)...\n", len(bumps))
+	// A single bump fits on the heading line
+	updated := bumps[0].String()
+	if len(bumps) > 1 {
+		var lines []string
+		for _, bump := range bumps {
+			lines = append(lines, "- "+bump.String())
+		}
+		updated = strings.Join(lines, "\n")
+	}
+	suggestion.AddSection("Updated dependencies:", updated)
+
+	summary, err := commenter.DependencyRiskSummary(bumps)
+	if err != nil {
+		ui.Printf("   ⚠️  %v\n", err)
+		return
+	}
+	suggestion.AddSection("Release notes summary:", summary)
+}
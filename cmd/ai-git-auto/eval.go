@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runEval scores the configured model and prompt against a labeled dataset
+func runEval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	dataset := fs.String("dataset", "", "YAML dataset of labeled changes (default: the built-in dataset)")
+	style := fs.String("style", "", "Commit message style: "+strings.Join(gitcommenter.StyleNames(), ", "))
+	promptFile := fs.String("prompt-template", "", "Go text/template file replacing the built-in prompt")
+	fs.Parse(args)
+
+	config := buildConfig()
+	config.Style = *style
+	config.PromptTemplate = *promptFile
+
+	cases, err := gitcommenter.LoadEvalDataset(*dataset)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	commenter := gitcommenter.New(config)
+	ui.Printf("🧪 Evaluating %s on %d cases...\n\n", config.Model, len(cases))
+
+	report, err := commenter.Evaluate(cases)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	for _, result := range report.Results {
+		if result.Error != "" {
+			ui.Printf("💥 %-24s %s\n", result.Case, result.Error)
+			continue
+		}
+		icon := ui.Text("✅")
+		if !result.Valid {
+			icon = ui.Text("❌")
+		}
+		subject, _, _ := strings.Cut(result.Message, "\n")
+		ui.Printf("%s %-24s score %.2f  recall %.2f  length %.1f  %s\n",
+			icon, result.Case, result.Score, result.KeywordRecall, result.LengthScore, subject)
+		if result.Problem != "" {
+			ui.Printf("   ⚠️  %s\n", result.Problem)
+		}
+	}
+
+	valid, recall, length, score := report.Averages()
+	ui.Printf("\n📊 %s (%s style): score %.2f | valid %.0f%% | keyword recall %.2f | length %.2f\n",
+		report.Model, report.Style, score, valid*100, recall, length)
+}
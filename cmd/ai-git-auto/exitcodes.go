@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// Exit codes let wrappers and hooks react without parsing the output. They
+// are documented in the README and shared with git-ai-commit.
+const (
+	exitOK                = 0
+	exitError             = 1 // invalid flags or config, and anything not listed below
+	exitNoChanges         = 2
+	exitOllamaUnreachable = 3
+	exitGenerationFailed  = 4
+	exitAborted           = 5
+	exitGitFailed         = 6
+	exitLintFailed        = 7 // lint-message rejected the message
+	exitNeedsTerminal     = 8 // a prompt was needed but stdin is not a terminal
+	exitSecretsFound      = 9 // the changes look like they hold secrets
+)
+
+// generationExitCode tells an unreachable Ollama and changes blocked for
+// possible secrets apart from other generation failures
+func generationExitCode(err error) int {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return exitOllamaUnreachable
+	}
+	var secretsErr *gitcommenter.SecretsError
+	if errors.As(err, &secretsErr) {
+		return exitSecretsFound
+	}
+	return exitGenerationFailed
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// modelFlags registers the Ollama flags shared by subcommands and returns a
+// function building the resulting configuration after parsing
+func modelFlags(fs *flag.FlagSet) func() *gitcommenter.Config {
+	model := fs.String("model", "llama2", "Ollama model to use")
+	endpoint := fs.String("endpoint", "http://localhost:11434", "Ollama endpoint")
+	temperature := fs.Float64("temperature", 0.7, "Temperature for AI model (0.0-1.0)")
+	maxTokens := fs.Int("max-tokens", 150, "Maximum tokens for response")
+	anonymize := fs.Bool("anonymize", false, "Replace identifiers and string literals in prompts with placeholders, e.g. for a shared Ollama server")
+	fs.String("profile", "", "Named config profile to use (e.g. work, personal)")
+	noEmoji := fs.Bool("no-emoji", false, "Plain-text output without emoji (colors also honor NO_COLOR)")
+
+	return func() *gitcommenter.Config {
+		fileConfig := applyConfigFiles(fs)
+		configureOutput(*noEmoji)
+
+		config := gitcommenter.DefaultConfig()
+		fileConfig.Apply(config)
+		config.Model = *model
+		config.OllamaEndpoint = *endpoint
+		config.Temperature = *temperature
+		config.MaxTokens = *maxTokens
+		config.Anonymize = *anonymize
+		return config
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"net"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	"github.com/TheRealMasterK/Ai-Git-Comments-Auto/grpcapi"
+	"google.golang.org/grpc"
+)
+
+// runGRPC serves the gRPC API for the repository in the current directory
+func runGRPC(args []string) {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	listen := fs.String("listen", "127.0.0.1:50051", "Address to listen on")
+	analyzeOnly := fs.Bool("analyze-only", false, "Never modify the repository: refuse Commit and Push and run git read-only")
+	fs.Parse(args)
+
+	config := buildConfig()
+	config.ReadOnly = *analyzeOnly
+	commenter := gitcommenter.New(config)
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		ui.Fatalf("❌ Failed to listen on %s: %v", *listen, err)
+	}
+	server := grpc.NewServer()
+	api := grpcapi.NewServer(commenter)
+	grpcapi.RegisterCommenterServer(server, api)
+	watchConfig(fs, commenter, func() (*gitcommenter.Config, error) {
+		config := buildConfig()
+		config.ReadOnly = *analyzeOnly
+		return config, nil
+	}, api.SetCommenter)
+
+	ui.Printf("🛰️  Serving the gRPC API on %s (model %s)\n", listener.Addr(), config.Model)
+	if err := server.Serve(listener); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// hookMarker identifies hooks written by "ai-git-auto hook install"
+const hookMarker = "# ai-git-auto prepare-commit-msg hook"
+
+// runHookCommand installs or removes the prepare-commit-msg hook
+func runHookCommand(args []string) {
+	fs := flag.NewFlagSet("hook", flag.ExitOnError)
+	force := fs.Bool("force", false, "Replace an existing prepare-commit-msg hook")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto hook install [-force]")
+		fmt.Fprintln(fs.Output(), "       ai-git-auto hook uninstall")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	action := args[0]
+	fs.Parse(args[1:])
+
+	output, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ Not in a Git repository")
+	}
+	path := filepath.Join(strings.TrimSpace(string(output)), "prepare-commit-msg")
+	existing, err := os.ReadFile(path)
+	ours := err == nil && strings.Contains(string(existing), hookMarker)
+
+	switch action {
+	case "install":
+		if err == nil && !ours && !*force {
+			ui.Fatalf("❌ %s already exists; use -force to replace it", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		if err := os.WriteFile(path, []byte(hookScript()), 0o755); err != nil {
+			ui.Fatalf("❌ Failed to write the hook: %v", err)
+		}
+		ui.Printf("✅ Installed %s\n", path)
+		ui.Println("💡 'git commit' now opens the editor with a generated message")
+	case "uninstall":
+		if !ours {
+			ui.Fatalf("❌ No ai-git-auto hook at %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		ui.Printf("✅ Removed %s\n", path)
+	default:
+		fs.Usage()
+		os.Exit(exitError)
+	}
+}
+
+// hookScript returns the prepare-commit-msg hook that runs ai-git-auto -hook
+func hookScript() string {
+	return fmt.Sprintf("#!/bin/sh\n%s (remove with: ai-git-auto hook uninstall)\nexec %s -hook \"$@\"\n", hookMarker, hookExecutable())
+}
+
+// hookExecutable returns how the hook runs ai-git-auto: by name when it is
+// on PATH, so upgrades are picked up, otherwise by absolute path
+func hookExecutable() string {
+	if _, err := exec.LookPath("ai-git-auto"); err == nil {
+		return "ai-git-auto"
+	}
+	path, err := os.Executable()
+	if err != nil {
+		return "ai-git-auto"
+	}
+	return strconv.Quote(path)
+}
+
+// runPrepareCommitMsg is the -hook mode. args are the hook's arguments:
+// the message file, the message source and a commit. It never fails the
+// commit; problems are reported and the message is left to the author.
+// finish adjusts the suggestion before it is written, e.g. adding trailers.
+func runPrepareCommitMsg(commenter *gitcommenter.GitCommenter, args []string, finish func(*gitcommenter.CommitSuggestion)) {
+	if len(args) == 0 {
+		ui.Fatalf("❌ -hook expects the arguments of a prepare-commit-msg hook")
+	}
+	file, source := args[0], ""
+	if len(args) > 1 {
+		source = args[1]
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		ui.Printf("⚠️  ai-git-auto: %v\n", err)
+		return
+	}
+	if reason := gitcommenter.PrepareCommitMsgSkipReason(source, string(existing)); reason != "" {
+		return
+	}
+	if noVerifyRequested() {
+		return
+	}
+	if reason, err := commenter.OptOutReason(file); err != nil || reason != "" {
+		return
+	}
+
+	changes, err := commenter.ScanStagedChanges()
+	if err != nil || len(changes) == 0 {
+		return
+	}
+	ui.Println("🤖 ai-git-auto: generating a commit message...")
+	suggestion, err := commenter.GenerateCommitMessage(changes)
+	if err != nil {
+		ui.Printf("⚠️  ai-git-auto: %v\n", err)
+		return
+	}
+	finish(suggestion)
+	if err := gitcommenter.WriteCommitMessageFile(file, suggestion.Message()); err != nil {
+		ui.Printf("⚠️  ai-git-auto: %v\n", err)
+	}
+}
+
+// noVerifyRequested reports whether the git commit running the hook was
+// given --no-verify. git runs prepare-commit-msg regardless, so the command
+// line of the git process is read from /proc; elsewhere it returns false.
+func noVerifyRequested() bool {
+	pid := os.Getppid()
+	// The hook script runs us, and git runs the hook script
+	for i := 0; i < 3 && pid > 1; i++ {
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil {
+			return false
+		}
+		args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		if filepath.Base(args[0]) == "git" {
+			return commitNoVerify(args[1:])
+		}
+
+		// The parent PID follows the parenthesized command name
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return false
+		}
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		if len(fields) < 2 {
+			return false
+		}
+		if pid, err = strconv.Atoi(fields[1]); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// commitNoVerify reports whether the arguments of a git command line run
+// git commit with --no-verify or -n, which may be clustered as in -an. The
+// value of an option such as -m is never read as a flag, and a later
+// --verify wins.
+func commitNoVerify(args []string) bool {
+	// Global options come before the subcommand; these take a separate value
+	i := 0
+	for ; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+		switch args[i] {
+		case "-c", "-C", "--git-dir", "--work-tree", "--namespace", "--config-env", "--super-prefix":
+			i++
+		}
+	}
+	if i >= len(args) || args[i] != "commit" {
+		return false
+	}
+
+	noVerify := false
+	for i++; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return noVerify
+		case arg == "--no-verify":
+			noVerify = true
+		case arg == "--verify":
+			noVerify = false
+		case strings.HasPrefix(arg, "--"):
+			if commitLongValueOptions[arg] {
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for j := 1; j < len(arg); j++ {
+				if arg[j] == 'n' {
+					noVerify = true
+					continue
+				}
+				// The rest of the cluster, or else the next argument, is
+				// the value of the option
+				if strings.IndexByte("mFcCt", arg[j]) >= 0 {
+					if j == len(arg)-1 {
+						i++
+					}
+					break
+				}
+				// -u and -S only take a value attached to them
+				if arg[j] == 'u' || arg[j] == 'S' {
+					break
+				}
+			}
+		}
+	}
+	return noVerify
+}
+
+// commitLongValueOptions are the long options of git commit whose value
+// may follow as a separate argument
+var commitLongValueOptions = map[string]bool{
+	"--message":            true,
+	"--file":               true,
+	"--reuse-message":      true,
+	"--reedit-message":     true,
+	"--fixup":              true,
+	"--squash":             true,
+	"--author":             true,
+	"--date":               true,
+	"--template":           true,
+	"--cleanup":            true,
+	"--trailer":            true,
+	"--pathspec-from-file": true,
+}
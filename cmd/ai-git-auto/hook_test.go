@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitNoVerify(t *testing.T) {
+	for _, test := range []struct {
+		args string
+		want bool
+	}{
+		{"commit --no-verify", true},
+		{"commit -n", true},
+		{"commit -an", true},
+		{"commit -anm wip", true},
+		{"-C repo -c core.hooksPath=x commit -n", true},
+		{"commit", false},
+		{"commit -m -n", false},
+		{"commit -am -n", false},
+		{"commit -m-n", false},
+		{"commit --message -n", false},
+		{"commit --no-verify --verify", false},
+		{"commit -- -n", false},
+		{"log -n 1", false},
+		{"-c commit -n", false},
+	} {
+		if got := commitNoVerify(strings.Fields(test.args)); got != test.want {
+			t.Errorf("commitNoVerify(%q) = %v, want %v", test.args, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runIndex embeds the history into the commit index used to find similar
+// past commits
+func runIndex(args []string) {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	embedModel := fs.String("embedding-model", "", "Ollama embedding model, e.g. nomic-embed-text")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto index -embedding-model <model> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	config.EmbeddingModel = *embedModel
+	if config.EmbeddingModel == "" {
+		ui.Exitf(exitError, "❌ Choose an embedding model with -embedding-model (or 'ai-git-auto config set embedding-model <name>')")
+	}
+	commenter := gitcommenter.New(config)
+
+	ui.Fprintf(os.Stderr, "🧮 Indexing the history with %s...\n", config.EmbeddingModel)
+	indexed, err := commenter.IndexHistory()
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ Indexed %d commit(s) before failing: %v", indexed, err)
+	}
+	ui.Printf("✅ Indexed %d new commit(s)\n", indexed)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runInit walks the user through creating the user and repository config files
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "http://localhost:11434", "Ollama endpoint")
+	fs.Parse(args)
+
+	reader := bufio.NewReader(os.Stdin)
+	userConfig := &gitcommenter.FileConfig{Endpoint: *endpoint}
+	repoConfig := &gitcommenter.FileConfig{}
+
+	ui.Println("🧙 AI Git Auto - Setup Wizard")
+	ui.Println("=============================")
+
+	// Detect Ollama and models
+	ui.Printf("\n🔍 Looking for Ollama at %s...\n", *endpoint)
+	config := gitcommenter.DefaultConfig()
+	config.OllamaEndpoint = *endpoint
+	models, err := gitcommenter.New(config).ListAvailableModels()
+	switch {
+	case err != nil:
+		ui.Println("   ⚠️  Ollama is not reachable. Start it with: ollama serve")
+		userConfig.Model = ask(reader, "Model to use once Ollama is running", "llama3.2:3b")
+	case len(models) == 0:
+		ui.Println("   ⚠️  Ollama is running but has no models. Pull one with: ollama pull llama3.2")
+		userConfig.Model = ask(reader, "Model to use once it is pulled", "llama3.2:3b")
+	default:
+		ui.Printf("   ✅ Found %d model(s):\n", len(models))
+		for i, model := range models {
+			ui.Printf("      %d. %s%s\n", i+1, model, getModelRecommendation(model))
+		}
+		userConfig.Model = chooseModel(reader, models)
+	}
+
+	// Workflow, commit style and ticket prefix are repository conventions
+	ui.Println("\n🧭 Team workflow")
+	for _, name := range gitcommenter.WorkflowNames() {
+		ui.Printf("      • %s: %s\n", name, gitcommenter.Workflows[name].Description)
+	}
+	styleDefault, pushDefault := "conventional", "ask"
+	workflowChoice := askChoice(reader, "Workflow preset", append([]string{"none"}, gitcommenter.WorkflowNames()...), "none")
+	if workflow, ok := gitcommenter.Workflows[workflowChoice]; ok {
+		repoConfig.Workflow = workflow.Name
+		styleDefault, pushDefault = workflow.Style, workflow.Push
+	}
+
+	ui.Println("\n📝 Commit message conventions")
+	for _, name := range gitcommenter.StyleNames() {
+		ui.Printf("      • %s: %s\n", name, gitcommenter.Styles[name].Description)
+	}
+	repoConfig.Style = askChoice(reader, "Commit style", gitcommenter.StyleNames(), styleDefault)
+	repoConfig.TicketPrefix = ask(reader, "Ticket prefix for subjects (e.g. PROJ-123, empty for none)", "")
+
+	// Signing and pushing are personal preferences
+	ui.Println("\n🔐 Commit and push behavior")
+	sign := askYesNo(reader, "Sign commits (git commit -S)?", false)
+	userConfig.Sign = &sign
+	userConfig.Push = askChoice(reader, "Push after committing", []string{"ask", "always", "never"}, pushDefault)
+
+	// Write the files
+	ui.Println("\n💾 Writing configuration...")
+	userPath, err := gitcommenter.UserConfigPath()
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	writeConfigWithConfirm(reader, userPath, userConfig)
+
+	repoPath, err := gitcommenter.RepoConfigPath(".")
+	if err != nil {
+		ui.Println("   ⚠️  Not in a Git repository, skipping repository config")
+	} else {
+		writeConfigWithConfirm(reader, repoPath, repoConfig)
+	}
+
+	ui.Println("\n🎉 Setup complete! Run 'ai-git-auto' in any repository to get started.")
+}
+
+// writeConfigWithConfirm writes a config file, asking before overwriting an existing one
+func writeConfigWithConfirm(reader *bufio.Reader, path string, fc *gitcommenter.FileConfig) {
+	if _, err := os.Stat(path); err == nil {
+		if !askYesNo(reader, fmt.Sprintf("%s already exists. Overwrite?", path), false) {
+			ui.Printf("   ➤ Kept existing %s\n", path)
+			return
+		}
+	}
+
+	if err := gitcommenter.WriteConfigFile(path, fc); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	ui.Printf("   ✅ Wrote %s\n", path)
+}
+
+// chooseModel asks the user to pick one of the available models
+func chooseModel(reader *bufio.Reader, models []string) string {
+	input := ask(reader, fmt.Sprintf("Select a model (1-%d)", len(models)), "1")
+	selection, err := strconv.Atoi(input)
+	if err != nil || selection < 1 || selection > len(models) {
+		ui.Printf("   ❌ Invalid selection. Using %s\n", models[0])
+		return models[0]
+	}
+	return models[selection-1]
+}
+
+// ask prompts for a free-form answer, returning def on empty input
+func ask(reader *bufio.Reader, question, def string) string {
+	if def != "" {
+		ui.Printf("❓ %s [%s]: ", question, def)
+	} else {
+		ui.Printf("❓ %s: ", question)
+	}
+
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return def
+	}
+	return input
+}
+
+// askChoice prompts until the answer is one of choices
+func askChoice(reader *bufio.Reader, question string, choices []string, def string) string {
+	for {
+		answer := ask(reader, fmt.Sprintf("%s (%s)", question, strings.Join(choices, "/")), def)
+		for _, choice := range choices {
+			if strings.EqualFold(answer, choice) {
+				return choice
+			}
+		}
+		ui.Printf("   ❌ Please answer one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// askYesNo prompts for a yes/no answer
+func askYesNo(reader *bufio.Reader, question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	ui.Printf("❓ %s (%s): ", question, hint)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runLintMessage checks a hand-written commit message against the staged
+// changes, or a commit's changes, and the repository's conventions
+func runLintMessage(args []string) {
+	fs := flag.NewFlagSet("lint-message", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	minScore := fs.Int("min-score", 3, "Fail messages the model scores below this (1-5)")
+	commit := fs.String("commit", "", "Lint against this commit's changes instead of the staged ones; without a file, lint its message")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto lint-message [flags] <file|->")
+		fmt.Fprintln(fs.Output(), "       ai-git-auto lint-message -commit <rev>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 || (fs.NArg() == 0 && *commit == "") {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	if rules, err := gitcommenter.LoadCommitlintConfig("."); err != nil {
+		ui.Fprintf(os.Stderr, "⚠️  Ignoring commitlint config: %v\n", err)
+	} else if rules != nil {
+		config.Commitlint = rules
+	}
+	commenter := gitcommenter.New(config)
+
+	var message string
+	switch file := fs.Arg(0); file {
+	case "":
+		commits, err := commenter.Commits(*commit + "^!")
+		if err != nil || len(commits) != 1 {
+			ui.Exitf(exitGitFailed, "❌ Failed to read commit %s: %v", *commit, err)
+		}
+		message = commits[0].Message()
+	case "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			ui.Fatalf("❌ Failed to read the message: %v", err)
+		}
+		message = string(data)
+	default:
+		data, err := os.ReadFile(file)
+		if err != nil {
+			ui.Fatalf("❌ Failed to read the message: %v", err)
+		}
+		message = string(data)
+	}
+
+	var changes []gitcommenter.FileChange
+	var err error
+	if *commit != "" {
+		changes, err = commenter.CommitChanges(*commit)
+	} else {
+		changes, err = commenter.ScanStagedChanges()
+	}
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+
+	ui.Fprintln(os.Stderr, "🔎 Checking the commit message...")
+	lint, err := commenter.LintCommitMessage(message, changes, *minScore)
+	if err != nil {
+		// Without the model only the conventions are checked; an
+		// unreachable model never blocks a commit on its own
+		ui.Fprintf(os.Stderr, "⚠️  Could not grade the message: %v\n", err)
+		if err := commenter.CheckMessageConventions(message); err != nil {
+			ui.Fprintf(os.Stderr, "❌ The message fails: %v\n", err)
+			os.Exit(exitLintFailed)
+		}
+		ui.Fprintln(os.Stderr, "✅ The message follows the conventions")
+		return
+	}
+
+	if lint.Passed() {
+		if lint.Grade != nil {
+			ui.Fprintf(os.Stderr, "✅ The message passes (%d/5)\n", lint.Grade.Score)
+		} else {
+			ui.Fprintln(os.Stderr, "✅ The message passes")
+		}
+		return
+	}
+
+	ui.Fprintln(os.Stderr, "❌ The message fails:")
+	for _, problem := range lint.Problems {
+		ui.Fprintf(os.Stderr, "   • %s\n", problem)
+	}
+	if lint.Rewrite != nil {
+		ui.Fprintln(os.Stderr, "💡 Suggested rewrite:")
+		fmt.Println(lint.Rewrite.Message())
+	}
+	os.Exit(exitLintFailed)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runLSP serves the Language Server Protocol on stdin and stdout for
+// editors started in the repository
+func runLSP(args []string) {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	fs.Parse(args)
+
+	// stdout carries the protocol; warnings go to the editor's log instead
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+
+	commenter := gitcommenter.New(buildConfig())
+	server := gitcommenter.NewLSPServer(commenter, os.Stdin, protocolOut)
+	watchConfig(fs, commenter, func() (*gitcommenter.Config, error) {
+		return buildConfig(), nil
+	}, server.SetCommenter)
+	if err := server.Serve(); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+}
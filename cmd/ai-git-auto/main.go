@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
@@ -16,7 +19,49 @@ const (
 	version = "1.0.0"
 )
 
+// newSymbolAnalyzer returns the analyzer of non-Go files used by
+// -tree-sitter; it is set only in builds with cgo and the treesitter tag
+// (see treesitter.go), so the default build stays pure Go
+var newSymbolAnalyzer func() gitcommenter.SymbolAnalyzer
+
+// subcommands maps the first command-line argument to its handler
+var subcommands = map[string]func(args []string){
+	"init":             runInit,
+	"ci-lint":          runCILint,
+	"cover-letter":     runCoverLetter,
+	"config":           runConfig,
+	"eval":             runEval,
+	"onboard":          runOnboard,
+	"lsp":              runLSP,
+	"mcp":              runMCP,
+	"grpc":             runGRPC,
+	"hook":             runHookCommand,
+	"squash-merge-msg": runSquashMergeMessage,
+	"squash-message":   runSquashMessage,
+	"lint-message":     runLintMessage,
+	"ship":             runShip,
+	"mr-description":   runMRDescription,
+	"pr-description":   runPRDescription,
+	"release-notes":    runReleaseNotes,
+	"changelog":        runChangelog,
+	"reword":           runReword,
+	"audit":            runAudit,
+	"index":            runIndex,
+	"split":            runSplit,
+	"stage":            runStage,
+}
+
 func main() {
+	configureOutput(false)
+
+	// Dispatch subcommands before parsing workflow flags
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	var (
 		model       = flag.String("model", "llama2", "Ollama model to use")
 		endpoint    = flag.String("endpoint", "http://localhost:11434", "Ollama endpoint")
@@ -25,65 +70,406 @@ func main() {
 		listModels  = flag.Bool("list-models", false, "List available Ollama models")
 		interactive = flag.Bool("interactive", true, "Interactive mode to approve commit message (default: true)")
 		skipAdd     = flag.Bool("skip-add", false, "Skip 'git add .' and only commit staged files")
+		unstaged    = flag.Bool("unstaged", false, "Analyze the unstaged changes and untracked files instead of 'git add .', then stage exactly those files")
+		allChanges  = flag.Bool("all", false, "Analyze every change in the working tree, staged or not, and untracked files, then stage exactly those files")
+		fromRef     = flag.String("from", "", "Print a message describing the diff from this ref to -to, e.g. for a backport or a deploy (nothing is committed)")
+		toRef       = flag.String("to", "", "End of the -from range (default HEAD)")
+		perPackage  = flag.Bool("per-package", false, "Commit the changes of each package of a monorepo separately, each with its own message and scope")
 		skipPush    = flag.Bool("skip-push", false, "Skip 'git push' after committing")
 		dryRun      = flag.Bool("dry-run", false, "Show what would be done without executing")
 		showVersion = flag.Bool("version", false, "Show version information")
 		force       = flag.Bool("force", false, "Skip confirmation prompts")
+		candidates  = flag.Int("n", 1, "Number of candidate messages to generate and choose from")
+		maxWait     = flag.Duration("max-wait", 0, "Offer the partial message if the model is still running after this long (e.g. 15s)")
+		workflowArg = flag.String("workflow", "", "Workflow preset: "+strings.Join(gitcommenter.WorkflowNames(), ", "))
+		style       = flag.String("style", "conventional", "Commit message style: "+strings.Join(gitcommenter.StyleNames(), ", "))
+		gitmoji     = flag.Bool("gitmoji", false, "Prefix the subject with a gitmoji (e.g. ✨ feat: ...)")
+		ticket      = flag.String("ticket-prefix", "", "Ticket reference prepended to the subject (e.g. PROJ-123)")
+		anonymize   = flag.Bool("anonymize", false, "Replace identifiers and string literals in prompts with placeholders, e.g. for a shared Ollama server")
+		language    = flag.String("language", "", "Language of the message, e.g. de, or auto to follow the recent commits (default English)")
+		types       = flag.String("types", "", "Comma-separated conventional commit types the message may use")
+		scopes      = flag.String("scopes", "", "Comma-separated conventional commit scopes the message may use")
+		adaptive    = flag.Bool("adaptive-temperature", true, "Lower the temperature for mechanical changes and raise it for features")
+		inferTypes  = flag.Bool("infer-types", true, "Use the type test, docs, or ci or chore when only tests, docs or configuration change")
+		trivialMax  = flag.Int("trivial-max-lines", gitcommenter.DefaultTrivialMaxLines, "Write typo fixes, reformats and version bumps of one file up to this many changed lines from templates, without the model (0 disables)")
+		fileHistory = flag.Bool("history-context", false, "Show the model the subjects of the last 5 commits touching the staged files")
+		embedModel  = flag.String("embedding-model", "", "Ollama embedding model (e.g. nomic-embed-text) used to show the model past commits with similar diffs")
+		examples    = flag.Int("history-examples", gitcommenter.DefaultHistoryExamples, "Show the model example messages from this many recent commits, to follow the repository's conventions (0 disables)")
+		verbose     = flag.Bool("v", false, "Verbose output")
+		veryVerbose = flag.Bool("vv", false, "Very verbose output, including every raw model response")
+		showPrompt  = flag.Bool("show-prompt", false, "Print every prompt sent to the model")
+		dumpPrompt  = flag.String("dump-prompt", "", "Write every prompt and response to this file")
+		maxSubject  = flag.Int("max-subject-length", 72, "Longest accepted subject; longer ones are shortened (0 disables)")
+		maxPrompt   = flag.Int("max-prompt-bytes", gitcommenter.DefaultMaxPromptBytes, "Most diff content shown to the model, in bytes")
+		maxFiles    = flag.Int("max-prompt-files", gitcommenter.DefaultMaxPromptFiles, "Most files whose diffs are shown to the model, largest changes first")
+		maxFileSize = flag.Int("max-file-bytes", gitcommenter.DefaultMaxFileBytes, "Most diff content shown to the model of a file of average weight, in bytes")
+		ctxWindow   = flag.Int("context-window", 0, "Model context window in tokens the prompt is fitted to (-1 reads it from the model, 0 keeps the byte limits only)")
+		scopeMap    = flag.String("scope-map", "", "Comma-separated path to scope mappings, e.g. 'cmd/** -> cli'")
+		quiet       = flag.Bool("quiet", false, "Print only the final commit message on stdout; progress goes to stderr")
+		messageOnly = flag.Bool("message-only", false, "Only print a message for the staged changes on stdout (no commit or push)")
+		depRisk     = flag.Bool("dependency-risk", false, "For dependency updates (e.g. Dependabot or Renovate branches), add the new versions and a risk summary from their release notes")
+		provenance  = flag.Bool("provenance", false, "Add Generated-by and Prompt-hash trailers recording the tool, model and prompt")
+		copyFlag    = flag.Bool("copy", false, "Copy the commit message to the clipboard")
+		noEmoji     = flag.Bool("no-emoji", false, "Plain-text output without emoji (colors also honor NO_COLOR)")
+		tuiMode     = flag.Bool("tui", false, "Full-screen mode with file, diff and message panes")
+		sections    = flag.String("manual-sections", "", "Comma-separated body sections you fill in yourself, e.g. 'Testing done:'")
+		exclude     = flag.String("exclude", "", "Comma-separated glob patterns of files to leave out of the prompt, on top of .aiignore")
+		countExcl   = flag.Bool("count-excluded", false, "Tell the model how many files and lines were left out of the prompt")
+		summarize   = flag.Bool("summarize-files", false, "Show the model a cached one-line summary of each file whose diff does not fit in the prompt")
+		treeSitter  = flag.Bool("tree-sitter", false, "List changed functions and classes of JavaScript, TypeScript, Python, Rust and Java files in the prompt (builds with -tags treesitter)")
+		secretScan  = flag.String("secret-scan", gitcommenter.SecretScanBlock, "What to do when the changes look like they hold API keys, tokens or private keys: block, warn or off")
+		sign        = flag.Bool("sign", false, "Sign the commit (git commit -S)")
+		signingKey  = flag.String("signing-key", "", "Key to sign with (defaults to user.signingkey)")
+		profile     = flag.String("profile", "", "Named config profile to use (e.g. work, personal)")
+		promptFile  = flag.String("prompt-template", "", "Go text/template file replacing the built-in prompt")
+		cacheSpec   = flag.String("cache", "", "Cache model responses: fs[:dir], sqlite[:path] or a redis:// URL")
+		pushMode    = flag.String("push", "", "Push behavior after committing: ask, always or never")
+		pushTargets = flag.String("push-remotes", "", "Comma-separated remotes to push to in parallel, e.g. 'origin,mirror' (default: origin)")
+		createPR    = flag.Bool("create-pr", false, "After pushing, open a GitHub pull request with a generated title and description (token from GITHUB_TOKEN or git's credential helper)")
+		prBase      = flag.String("pr-base", "", "Base branch of the pull request (default: the remote's default branch)")
+		journalPath = flag.String("journal", "", "Write events (staged, generated, committed, pushed) as JSON lines to a file, unix:<path> or tcp:<host:port>")
+		hookMode    = flag.Bool("hook", false, "Run as a prepare-commit-msg hook (see 'ai-git-auto hook install')")
+		analyzeOnly = flag.Bool("analyze-only", false, "Never modify the repository: print the message without staging, committing or pushing, and run git read-only")
+		ciMode      = flag.Bool("ci", false, "For CI jobs: never prompt, print the result as JSON on stdout, annotate GitHub Actions runs, and only push with -push always")
+		tutorial    = flag.Bool("tutorial", false, "Learn the workflow step by step in a throwaway demo repository")
+		stdioMode   = flag.Bool("stdio", false, "Serve JSON-RPC requests (suggest, regenerate, listModels, cancel) on stdin/stdout for editor plugins")
 	)
 	flag.Parse()
 
+	// Settings from config files apply to every flag not given explicitly
+	fileConfig := applyConfigFiles(flag.CommandLine)
+	configureOutput(*noEmoji)
+
 	// Show version
 	if *showVersion {
-		fmt.Printf("AI Git Auto v%s\n", version)
-		fmt.Println("Automated Git workflow with AI-generated commit messages")
+		ui.Printf("AI Git Auto v%s\n", version)
+		ui.Println("Automated Git workflow with AI-generated commit messages")
 		return
 	}
 
+	// The tutorial only uses the model settings
+	if *tutorial {
+		if err := gitcommenter.ValidateLanguage(*language); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		config := gitcommenter.DefaultConfig()
+		config.OllamaEndpoint, config.Model = *endpoint, *model
+		config.Temperature, config.MaxTokens = *temperature, *maxTokens
+		config.Style, config.Language = *style, *language
+		runTutorial(config)
+		return
+	}
+
+	// The working tree modes stage what they analyzed instead of everything
+	worktreeMode := *unstaged || *allChanges
+	if *unstaged && *allChanges {
+		ui.Fatalf("❌ -unstaged and -all cannot be combined")
+	}
+	if worktreeMode && (*hookMode || *stdioMode) {
+		ui.Fatalf("❌ -unstaged and -all cannot be combined with -hook or -stdio")
+	}
+
+	// A range of history is only described, never committed
+	rangeMode := *fromRef != ""
+	if *toRef != "" && !rangeMode {
+		ui.Fatalf("❌ -to needs -from")
+	}
+	if rangeMode {
+		if worktreeMode || *hookMode || *stdioMode {
+			ui.Fatalf("❌ -from cannot be combined with -unstaged, -all, -hook or -stdio")
+		}
+		if *toRef == "" {
+			*toRef = "HEAD"
+		}
+		*messageOnly = true
+	}
+
+	// Analyzing an untrusted repository only ever prints the message
+	if *analyzeOnly {
+		if *hookMode {
+			ui.Fatalf("❌ -analyze-only cannot run as a hook, which writes the message file")
+		}
+		*messageOnly = !*stdioMode
+	}
+
+	switch *secretScan {
+	case gitcommenter.SecretScanBlock, gitcommenter.SecretScanWarn, gitcommenter.SecretScanOff:
+	default:
+		ui.Fatalf("❌ Invalid -secret-scan %q (expected block, warn or off)", *secretScan)
+	}
+
+	// Every package of a monorepo gets a commit of its own
+	if *perPackage && (*messageOnly || *hookMode || *stdioMode || *tuiMode || *candidates > 1) {
+		ui.Fatalf("❌ -per-package commits, so it cannot be combined with -from, -message-only, -analyze-only, -hook, -stdio, -tui or -n")
+	}
+
+	// In quiet and message-only mode stdout carries only the final message,
+	// e.g. for a prepare-commit-msg hook; progress goes to stderr. With
+	// -stdio it carries the protocol, and with -ci the JSON report.
+	messageOut := os.Stdout
+	if *quiet || *messageOnly || *hookMode || *stdioMode || *ciMode {
+		os.Stdout = os.Stderr
+	}
+
+	// CI jobs never prompt and only push when told to with -push always;
+	// workflow presets do not count
+	var report *ciReport
+	if *ciMode {
+		if *hookMode || *stdioMode || *tuiMode {
+			ui.Fatalf("❌ -ci cannot be combined with -hook, -stdio or -tui")
+		}
+		report = newCIReport(messageOut)
+		ui.onExit = report.failure
+		*interactive = false
+		if *pushMode != "always" {
+			*skipPush = true
+		}
+	}
+	if *messageOnly {
+		*skipAdd, *skipPush, *interactive = true, true, false
+	}
+	if *veryVerbose {
+		*verbose = true
+	}
+
 	// Print header
-	fmt.Println("🚀 AI Git Auto - Automated Git Workflow")
-	fmt.Println("======================================")
+	if !*quiet && !*messageOnly && !*hookMode && !*stdioMode && !*ciMode {
+		ui.Println("🚀 AI Git Auto - Automated Git Workflow")
+		ui.Println("======================================")
+	}
+
+	// Without a terminal (e.g. in a Husky or lefthook hook) prompts would
+	// block or read garbage, so run non-interactively unless -interactive
+	// was given explicitly, e.g. to answer from a pipe
+	interactiveGiven := false
+	flag.Visit(func(f *flag.Flag) { interactiveGiven = interactiveGiven || f.Name == "interactive" })
+	if !stdinIsTerminal() {
+		if *tuiMode {
+			ui.Exitf(exitNeedsTerminal, "❌ -tui needs an interactive terminal")
+		}
+		if *interactive && !interactiveGiven {
+			*interactive = false
+			if !*quiet && !*messageOnly && !*hookMode && !*stdioMode && !*ciMode {
+				ui.Println("ℹ️  stdin is not a terminal: running non-interactively")
+			}
+		}
+	}
+
+	if *profile != "" {
+		ui.Printf("👤 Profile: %s\n", *profile)
+	}
+
+	// Resolve workflow preset
+	var workflow *gitcommenter.Workflow
+	autoPush := false
+	if *workflowArg != "" {
+		w, err := gitcommenter.LookupWorkflow(*workflowArg)
+		if err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		workflow = &w
+		ui.Printf("🧭 Workflow: %s (%s)\n", w.Name, w.Description)
+
+		if *pushMode == "" {
+			*pushMode = w.Push
+		}
+		// The preset's style applies unless -style or a config file chose one
+		styleGiven := false
+		flag.Visit(func(f *flag.Flag) { styleGiven = styleGiven || f.Name == "style" })
+		if !styleGiven {
+			*style = w.Style
+		}
+	}
+
+	// A pull request needs the branch on the remote, so push new branches
+	// with -u like pr-flow does
+	if *createPR {
+		if workflow == nil {
+			workflow = &gitcommenter.Workflow{}
+		}
+		workflow.SetUpstream = workflow.PushRefspec == ""
+	}
 
-	// Create configuration
-	config := &gitcommenter.Config{
-		OllamaEndpoint: *endpoint,
-		Model:         *model,
-		MaxTokens:     *maxTokens,
-		Temperature:   *temperature,
-		RepositoryPath: ".",
+	if _, err := gitcommenter.LookupStyle(*style); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	if err := gitcommenter.ValidateLanguage(*language); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	switch *pushMode {
+	case "never":
+		*skipPush = true
+	case "always":
+		autoPush = true
+	}
+
+	// Create configuration; the stdio server builds it again when the
+	// config files change
+	newConfig := func(fileConfig *gitcommenter.FileConfig) *gitcommenter.Config {
+		config := &gitcommenter.Config{
+			OllamaEndpoint:        *endpoint,
+			Model:                 *model,
+			MaxTokens:             *maxTokens,
+			Temperature:           *temperature,
+			RepositoryPath:        ".",
+			Style:                 *style,
+			TicketPrefix:          *ticket,
+			Language:              *language,
+			Anonymize:             *anonymize,
+			Exclude:               splitList(*exclude),
+			CountExcluded:         *countExcl,
+			SummarizeFiles:        *summarize,
+			SecretScan:            *secretScan,
+			Types:                 splitList(*types),
+			Scopes:                splitList(*scopes),
+			ScopeMap:              splitList(*scopeMap),
+			ManualSections:        splitList(*sections),
+			MaxSubjectLength:      *maxSubject,
+			MaxPromptBytes:        *maxPrompt,
+			MaxPromptFiles:        *maxFiles,
+			MaxFileBytes:          *maxFileSize,
+			ContextWindow:         *ctxWindow,
+			AdaptiveTemperature:   *adaptive,
+			InferTypes:            *inferTypes,
+			TrivialMaxLines:       *trivialMax,
+			HistoryExamples:       *examples,
+			IncludeHistoryContext: *fileHistory,
+			EmbeddingModel:        *embedModel,
+			PromptTemplate:        *promptFile,
+			Gitmoji:               *gitmoji,
+			ReadOnly:              *analyzeOnly,
+		}
+		applyFileSettings(config, fileConfig)
+		return config
+	}
+	config := newConfig(fileConfig)
+	if err := gitcommenter.ValidateRedactionRules(config.Redact); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+
+	// Follow the repository's commitlint rules, if it has any
+	if rules, err := gitcommenter.LoadCommitlintConfig("."); err != nil {
+		ui.Printf("⚠️  Ignoring commitlint config: %v\n", err)
+	} else if rules != nil {
+		config.Commitlint = rules
+		ui.Printf("📏 Commitlint rules: %s\n", rules.Source)
 	}
 
 	// Create commenter
 	commenter := gitcommenter.New(config)
+	commenter.SetGitBackend(&gitcommenter.ExecBackend{Dir: ".", Stdout: os.Stdout, Stderr: os.Stderr, Sign: *sign, SigningKey: *signingKey, ReadOnly: *analyzeOnly})
+
+	cache, err := openCache(*cacheSpec)
+	if err != nil {
+		ui.Printf("⚠️  Response cache disabled: %v\n", err)
+	} else if cache != nil {
+		commenter.SetCache(cache)
+	}
+	if *treeSitter {
+		if newSymbolAnalyzer == nil {
+			ui.Printf("⚠️  -tree-sitter ignored: this binary was built without the treesitter tag\n")
+		} else {
+			commenter.SetSymbolAnalyzer(newSymbolAnalyzer())
+		}
+	}
+
+	// Report model requests; the full-screen view only leaves room for a dump
+	verbosity := 0
+	if *veryVerbose {
+		verbosity = 2
+	} else if *verbose {
+		verbosity = 1
+	}
+	var traceOut io.Writer = os.Stdout
+	if *tuiMode {
+		traceOut = io.Discard
+	}
+	tracer, err := newModelTracer(traceOut, verbosity, *showPrompt, *dumpPrompt)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	if tracer != nil {
+		commenter.SetTrace(tracer.trace)
+		defer tracer.close()
+	}
+	if *journalPath != "" {
+		journal, err := gitcommenter.OpenJournal(*journalPath)
+		if err != nil {
+			ui.Printf("⚠️  %v; continuing without the journal\n", err)
+		} else {
+			commenter.SetJournal(journal)
+			defer journal.Close()
+		}
+	}
+
+	// As a prepare-commit-msg hook the message goes into git's message file
+	if *hookMode {
+		runPrepareCommitMsg(commenter, flag.Args(), func(suggestion *gitcommenter.CommitSuggestion) {
+			if *provenance {
+				suggestion.AddProvenance("ai-git-auto v"+version, config.Model)
+			}
+		})
+		return
+	}
+
+	// As a child process of an editor plugin, answer its requests
+	if *stdioMode {
+		server := gitcommenter.NewRPCServer(commenter, os.Stdin, messageOut)
+		watchConfig(flag.CommandLine, commenter, func() (*gitcommenter.Config, error) {
+			next := newConfig(applyConfigFiles(flag.CommandLine))
+			next.Commitlint = config.Commitlint
+			return next, gitcommenter.ValidateRedactionRules(next.Redact)
+		}, server.SetCommenter)
+		if err := server.Serve(); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		return
+	}
 
 	// List models if requested
 	if *listModels {
 		models, err := commenter.ListAvailableModels()
 		if err != nil {
-			log.Fatalf("❌ Failed to list models: %v", err)
+			ui.Exitf(exitOllamaUnreachable, "❌ Failed to list models: %v", err)
 		}
 
-		fmt.Println("📚 Available Ollama models:")
+		ui.Println("📚 Available Ollama models:")
 		for _, model := range models {
-			fmt.Printf("  - %s\n", model)
+			ui.Printf("  - %s\n", model)
+		}
+		return
+	}
+
+	// Repositories and branches can opt out with "ai: off"
+	reason, err := commenter.OptOutReason("")
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	if reason != "" {
+		ui.Printf("⏭️  AI commit messages are off: %s\n", reason)
+		ui.Println("💡 Commit by hand with 'git commit'")
+		if report != nil {
+			report.finish("disabled", exitOK, "")
 		}
 		return
 	}
 
 	// Verify prerequisites
-	fmt.Println("🔍 Verifying prerequisites...")
-	fmt.Println("   ➤ Checking Git repository...")
-	if err := verifyPrerequisites(); err != nil {
-		log.Fatalf("❌ %v", err)
+	ui.Println("🔍 Verifying prerequisites...")
+	ui.Println("   ➤ Checking Git repository...")
+	if commenter.Backend().IsRepository() != nil {
+		ui.Exitf(exitGitFailed, "❌ Not in a Git repository")
 	}
-	fmt.Printf("   ✅ Git repository confirmed\n")
+	ui.Printf("   ✅ Git repository confirmed\n")
 
 	// Check Ollama connection and model
-	fmt.Printf("   ➤ Testing connection to Ollama at %s...\n", *endpoint)
+	ui.Printf("   ➤ Testing connection to Ollama at %s...\n", *endpoint)
 	availableModels, err := commenter.ListAvailableModels()
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to Ollama: %v", err)
+		ui.Exitf(exitOllamaUnreachable, "❌ Failed to connect to Ollama: %v\n   Start it with: ollama serve", err)
 	}
-	fmt.Printf("   ✅ Connected successfully (%d models available)\n", len(availableModels))
+	ui.Printf("   ✅ Connected successfully (%d models available)\n", len(availableModels))
 
 	// Verify selected model exists or let user choose
 	modelExists := false
@@ -95,192 +481,547 @@ func main() {
 	}
 
 	if !modelExists {
-		fmt.Printf("   ⚠️  Model '%s' not found.\n", *model)
+		ui.Printf("   ⚠️  Model '%s' not found.\n", *model)
 
 		if len(availableModels) == 0 {
-			log.Fatalf("❌ No Ollama models available. Please pull a model first:\n   ollama pull llama3.2")
+			ui.Exitf(exitOllamaUnreachable, "❌ No Ollama models available. Please pull a model first:\n   ollama pull llama3.2")
 		}
 
 		// Interactive model selection
-		fmt.Println("   📚 Available models:")
+		if *ciMode {
+			ui.Exitf(exitNeedsTerminal, "❌ Model '%s' not found; choose one of %s with -model", *model, strings.Join(availableModels, ", "))
+		}
+		if !*interactive {
+			ui.Printf("   📚 Available models: %s\n", strings.Join(availableModels, ", "))
+			ui.Exitf(exitNeedsTerminal, "❌ Choose one with -model (or 'ai-git-auto config set model <name>'); not prompting without -interactive")
+		}
+		ui.Println("   📚 Available models:")
 		for i, availableModel := range availableModels {
-			recommendation := getModelRecommendation(availableModel)
-			fmt.Printf("      %d. %s%s\n", i+1, availableModel, recommendation)
+			recommendation := ui.Text(getModelRecommendation(availableModel))
+			ui.Printf("      %d. %s%s\n", i+1, availableModel, recommendation)
 		}
 
 		selectedModel, err := promptUserForModel(availableModels)
 		if err != nil {
-			log.Fatalf("❌ Model selection cancelled")
+			ui.Exitf(exitAborted, "❌ Model selection cancelled")
 		}
 		*model = selectedModel
 	}
 
-	fmt.Printf("   ✅ Using AI model: %s\n", *model)
+	ui.Printf("   ✅ Using AI model: %s\n", *model)
+	if report != nil {
+		report.Model = *model
+	}
 
 	// Update config with selected model
 	config.Model = *model
 
 	// Get current directory for display
 	pwd, _ := os.Getwd()
-	fmt.Printf("   📂 Working directory: %s\n", pwd)
+	ui.Printf("   📂 Working directory: %s\n", pwd)
+
+	// Keep the history in one language
+	if *language == gitcommenter.LanguageAuto {
+		if history, err := commenter.HistoryLanguage(); err == nil && history != "" {
+			ui.Printf("   🌐 Writing in %s, like the recent commits\n", gitcommenter.Languages[history])
+		}
+	} else if history, err := commenter.LanguageMismatch(); err != nil {
+		ui.Printf("   ⚠️  Warning: Could not check the language of recent commits: %v\n", err)
+	} else if history != "" {
+		current := "English"
+		if *language != "" {
+			current = gitcommenter.Languages[*language]
+		}
+		ui.Printf("   ⚠️  Recent commits are written in %s, but messages are generated in %s\n", gitcommenter.Languages[history], current)
+		ui.Printf("   💡 Use -language %s, or -language auto to follow the history\n", history)
+		if report != nil {
+			report.warn("Message language", fmt.Sprintf("recent commits are written in %s, but messages are generated in %s", gitcommenter.Languages[history], current))
+		}
+	}
 
 	// Step 1: Git add (unless skipped)
-	if !*skipAdd {
-		fmt.Println("\n📝 Step 1: Staging changes (git add .)...")
+	if rangeMode {
+		ui.Printf("\n📝 Step 1: Describing the changes from %s to %s...\n", *fromRef, *toRef)
+	} else if worktreeMode {
+		ui.Println("\n📝 Step 1: Analyzing the working tree; the analyzed files are staged when committing...")
+	} else if !*skipAdd {
+		ui.Println("\n📝 Step 1: Staging changes (git add .)...")
 
 		// Show what files will be staged
-		fmt.Println("   ➤ Checking for unstaged changes...")
+		ui.Println("   ➤ Checking for unstaged changes...")
 		unstagedFiles, err := getUnstagedFiles()
 		if err != nil {
-			fmt.Printf("   ⚠️  Warning: Could not list unstaged files: %v\n", err)
+			ui.Printf("   ⚠️  Warning: Could not list unstaged files: %v\n", err)
 		} else if len(unstagedFiles) > 0 {
-			fmt.Printf("   ➤ Found %d unstaged file(s):\n", len(unstagedFiles))
+			ui.Printf("   ➤ Found %d unstaged file(s):\n", len(unstagedFiles))
 			for i, file := range unstagedFiles {
 				if i >= 5 { // Limit display to first 5 files
-					fmt.Printf("      ... and %d more files\n", len(unstagedFiles)-5)
+					ui.Printf("      ... and %d more files\n", len(unstagedFiles)-5)
 					break
 				}
-				fmt.Printf("      • %s\n", file)
+				ui.Printf("      • %s\n", file)
 			}
 		} else {
-			fmt.Println("   ➤ No unstaged files found")
+			ui.Println("   ➤ No unstaged files found")
 		}
 
 		if *dryRun {
-			fmt.Println("   [DRY RUN] Would run: git add .")
+			ui.Println("   [DRY RUN] Would run: git add .")
 		} else {
-			fmt.Println("   ➤ Running: git add .")
+			ui.Println("   ➤ Running: git add .")
 			if err := runGitAdd(); err != nil {
-				log.Fatalf("❌ Failed to stage changes: %v", err)
+				ui.Exitf(exitGitFailed, "❌ Failed to stage changes: %v", err)
 			}
-			fmt.Println("   ✅ Changes staged successfully")
+			ui.Println("   ✅ Changes staged successfully")
 		}
 	} else {
-		fmt.Println("\n📝 Step 1: Using already staged changes...")
+		ui.Println("\n📝 Step 1: Using already staged changes...")
+	}
+
+	if !worktreeMode && !rangeMode {
+		// Files edited after staging would make the message describe
+		// something other than what gets committed
+		if stale, err := commenter.StaleStagedFiles(); err != nil {
+			ui.Printf("   ⚠️  Warning: Could not compare staged and working tree files: %v\n", err)
+		} else if len(stale) > 0 {
+			ui.Printf("   ⚠️  %d staged file(s) were edited after staging:\n", len(stale))
+			for _, file := range stale {
+				ui.Printf("      • %s\n", file)
+			}
+			if *dryRun {
+				ui.Println("   [DRY RUN] Would offer to re-stage them")
+			} else if *interactive && !*force && askForApproval("re-stage them so the message matches the commit") {
+				if err := commenter.Restage(stale); err != nil {
+					ui.Exitf(exitGitFailed, "❌ %v", err)
+				}
+				ui.Println("   ✅ Files re-staged")
+			} else {
+				ui.Println("   ➤ Committing the staged versions")
+			}
+		}
 	}
 
 	// Step 2: Scan changes and generate commit message
-	fmt.Println("\n🔍 Step 2: Scanning staged changes...")
-	changes, err := commenter.ScanStagedChanges()
+	scan := commenter.ScanStagedChanges
+	switch {
+	case rangeMode:
+		ui.Println("\n🔍 Step 2: Scanning the range...")
+		scan = func() ([]gitcommenter.FileChange, error) { return commenter.ScanRefRange(*fromRef, *toRef) }
+	case *unstaged:
+		ui.Println("\n🔍 Step 2: Scanning unstaged changes and untracked files...")
+		scan = commenter.ScanUnstagedChanges
+	case *allChanges:
+		ui.Println("\n🔍 Step 2: Scanning all working tree changes and untracked files...")
+		scan = commenter.ScanAllChanges
+	default:
+		ui.Println("\n🔍 Step 2: Scanning staged changes...")
+	}
+	changes, err := scan()
+	if errors.Is(err, gitcommenter.ErrOnlyIgnored) {
+		ui.Exitf(exitNoChanges, "📄 Every changed file is listed in %s; commit them with a message of your own.", gitcommenter.AIIgnoreFile)
+	}
 	if err != nil {
-		log.Fatalf("❌ Failed to scan changes: %v", err)
+		ui.Exitf(exitGitFailed, "❌ Failed to scan changes: %v", err)
+	}
+
+	// Files staged earlier are committed along with the analyzed ones
+	if *unstaged && !*messageOnly {
+		if files, err := commenter.Backend().StagedFiles(); err == nil && len(files) > 0 {
+			ui.Printf("   ⚠️  %d file(s) already staged will be committed too; use -all to describe them as well\n", len(files))
+		}
 	}
 
 	if len(changes) == 0 {
-		fmt.Println("📄 No staged changes found.")
-		if !*skipAdd {
-			fmt.Println("💡 Tip: Make sure you have changes to commit")
-		} else {
-			fmt.Println("💡 Tip: Stage your changes first with 'git add <files>'")
+		switch {
+		case rangeMode:
+			ui.Printf("📄 No changes between %s and %s.\n", *fromRef, *toRef)
+		case worktreeMode:
+			ui.Println("📄 No changes found in the working tree.")
+		case !*skipAdd:
+			ui.Println("📄 No staged changes found.")
+			ui.Println("💡 Tip: Make sure you have changes to commit")
+		default:
+			ui.Println("📄 No staged changes found.")
+			ui.Println("💡 Tip: Stage your changes first with 'git add <files>'")
 		}
-		return
+		if report != nil {
+			report.finish("no_changes", exitNoChanges, "")
+		}
+		ui.Exit(exitNoChanges)
+	}
+	staged := make([]string, len(changes))
+	for i, change := range changes {
+		staged[i] = change.FilePath
+	}
+	commenter.RecordEvent(gitcommenter.Event{Type: gitcommenter.EventStaged, Files: staged})
+	if report != nil {
+		report.Files = staged
+	}
+
+	// Possible secrets stop the run before they reach the model or a commit.
+	// Files listed in .aiignore never reach the model but are committed
+	// with the rest, so they are checked too; the working tree modes stage
+	// them only before committing.
+	var ignored []gitcommenter.FileChange
+	if !rangeMode && !worktreeMode {
+		if ignored, err = commenter.ScanIgnoredChanges(); err != nil {
+			ui.Exitf(exitGitFailed, "❌ Failed to scan the files listed in %s: %v", gitcommenter.AIIgnoreFile, err)
+		}
+	}
+	checkSecrets(commenter, append(slices.Clip(changes), ignored...), report)
+
+	// Changes to code that a recent unpushed commit changed can become a
+	// fixup! commit for it
+	var fixupTarget *gitcommenter.Commit
+	if *interactive && !*force && !*messageOnly && !*perPackage {
+		fixupTarget, _ = commenter.FindFixupTarget(changes)
+	}
+
+	// Start generating while the user reviews the staged files, which
+	// hides most of the model latency. Traced requests would print over the
+	// confirmation prompt, so tracing generates after it instead.
+	var prefetch *gitcommenter.Generation
+	single := !*tuiMode && *candidates <= 1
+	if single && !tracer.prints() && fixupTarget == nil && !*perPackage {
+		prefetch = commenter.StartCommitMessage(changes)
 	}
 
 	// Display changes summary
 	displayChangesSummary(changes)
+	var suggestion *gitcommenter.CommitSuggestion
+	if fixupTarget != nil {
+		ui.Printf("   🩹 These changes edit the same code as %s %s\n", fixupTarget.ShortHash(), fixupTarget.Subject)
+		if askYesNo(bufio.NewReader(os.Stdin), "Commit them as a fixup! for it, to fold in later with git rebase -i --autosquash?", false) {
+			suggestion = gitcommenter.FixupSuggestion(*fixupTarget, changes)
+		}
+	}
+	if suggestion == nil && single && *interactive && !*force && !askForApproval("generate a commit message for these changes") {
+		ui.Println("   ❌ Commit cancelled by user")
+		os.Exit(exitAborted)
+	}
+
+	tuiApproved := false
+	var packages []gitcommenter.PackageGroup
+	if *perPackage {
+		ui.Printf("\n📦 Step 3: Generating a commit message per package (using %s)...\n", *model)
+		packages = generatePackageMessages(commenter, changes)
+		suggestion = packages[len(packages)-1].Suggestion
+	} else if suggestion != nil {
+		ui.Println("\n🩹 Step 3: Using the fixup! message (no model needed)")
+		displayCommitSuggestion(suggestion)
+	} else if *tuiMode {
+		// The TUI generates, edits and approves the message itself
+		ui.Println("\n🖥️  Step 3: Opening the full-screen view...")
+		var action tuiAction
+		suggestion, action, err = runTUI(commenter, changes)
+		if err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		if action == tuiQuit {
+			ui.Println("   ❌ Commit cancelled by user")
+			os.Exit(exitAborted)
+		}
+		tuiApproved = true
+		*skipPush = action != tuiCommitAndPush
+		autoPush = action == tuiCommitAndPush
+		displayCommitSuggestion(suggestion)
+	} else {
+		ui.Printf("\n🤖 Step 3: Generating AI commit message (using %s)...\n", *model)
+		ui.Println("   ➤ Analyzing file changes and diffs...")
+		if *candidates > 1 || commenter.TrivialMessage(changes) == nil {
+			ui.Printf("   ➤ Sending context to Ollama model '%s'...\n", *model)
+		}
+		if *verbose {
+			kind, temperature := commenter.AdaptiveTemperature(changes)
+			ui.Printf("   🌡️  Change kind: %s, temperature %.2f\n", kind, temperature)
+		}
+		if single && prefetch == nil {
+			prefetch = commenter.StartCommitMessage(changes)
+		}
 
-	fmt.Printf("\n🤖 Step 3: Generating AI commit message (using %s)...\n", *model)
-	fmt.Println("   ➤ Analyzing file changes and diffs...")
-	fmt.Printf("   ➤ Sending context to Ollama model '%s'...\n", *model)
+		if *candidates > 1 {
+			suggestions, err := commenter.GenerateCommitMessages(changes, *candidates)
+			if err != nil {
+				ui.Exitf(generationExitCode(err), "❌ Failed to generate commit messages: %v", err)
+			}
+			ui.Printf("   ✅ %d distinct AI commit message(s) generated\n", len(suggestions))
 
-	suggestion, err := commenter.GenerateCommitMessage(changes)
-	if err != nil {
-		log.Fatalf("❌ Failed to generate commit message: %v", err)
+			// Let the user pick or mix candidates; without prompts take the first
+			displayCandidates(suggestions)
+			suggestion = suggestions[0]
+			if *interactive && !*force {
+				suggestion = chooseCandidate(suggestions)
+			}
+		} else if *maxWait > 0 {
+			suggestion = waitForSuggestion(prefetch, *maxWait, *interactive && !*force)
+			displayCommitSuggestion(suggestion)
+		} else {
+			if prefetch.Ready() {
+				ui.Println("   ⚡ The message was ready while you reviewed the changes")
+			}
+			suggestion, _, err = prefetch.Wait(0)
+			if err != nil {
+				ui.Exitf(generationExitCode(err), "❌ Failed to generate commit message: %v", err)
+			}
+
+			if suggestion.Template != "" {
+				ui.Printf("   ⚡ Trivial change (%s): the message comes from a template, not the model\n", suggestion.Template)
+			} else {
+				ui.Printf("   ✅ AI commit message generated (confidence: %.0f%%)\n", suggestion.Confidence*100)
+			}
+
+			// Display the suggestion
+			displayCommitSuggestion(suggestion)
+		}
 	}
 
-	fmt.Printf("   ✅ AI commit message generated (confidence: %.0f%%)\n", suggestion.Confidence*100)
+	fixup := suggestion.Template == gitcommenter.TemplateFixup
+	if *depRisk && !fixup && !*perPackage && commenter.IsDependencyUpdate(changes) {
+		addDependencyRisk(commenter, suggestion, changes)
+		displayCommitSuggestion(suggestion)
+	}
 
-	// Display the suggestion
-	displayCommitSuggestion(suggestion)
+	// Sections reserved for the author are never generated
+	var sectionAnswers map[string]string
+	if len(config.ManualSections) > 0 && !fixup && !*perPackage {
+		if *interactive && !*force {
+			sectionAnswers = fillManualSections(suggestion, config.ManualSections)
+			displayCommitSuggestion(suggestion)
+		} else {
+			ui.Printf("⚠️  Leaving out %s: manual sections are only asked for interactively\n", strings.Join(config.ManualSections, ", "))
+		}
+	}
+
+	if *messageOnly {
+		if *provenance {
+			suggestion.AddProvenance("ai-git-auto v"+version, config.Model)
+		}
+		if report != nil {
+			report.setSuggestion(suggestion)
+			report.finish("generated", exitOK, "")
+		} else {
+			fmt.Fprintln(messageOut, suggestion.Message())
+		}
+		return
+	}
 
 	// Step 4: Commit
-	fmt.Println("\n💾 Step 4: Committing changes...")
-	commitApproved := !*interactive || *force || askForApproval("commit with this message")
+	ui.Println("\n💾 Step 4: Committing changes...")
+	if *copyFlag {
+		copyMessage(suggestion.Message())
+	}
+	commitApproved := tuiApproved || !*interactive || *force
+	if !commitApproved && *perPackage {
+		commitApproved = askForApproval(fmt.Sprintf("create these %d commits", len(packages)))
+	} else if !commitApproved {
+		// Excluding files regenerates the message, keeping the manual sections
+		regenerate := func(changes []gitcommenter.FileChange) (*gitcommenter.CommitSuggestion, error) {
+			suggestion, err := commenter.GenerateCommitMessage(changes)
+			if err == nil {
+				addSections(suggestion, config.ManualSections, sectionAnswers)
+			}
+			return suggestion, err
+		}
+		commitApproved, changes, suggestion = approveCommit(commenter, changes, suggestion, regenerate, *dryRun)
+	}
 
 	if *dryRun {
-		fmt.Printf("   [DRY RUN] Would run: git commit -m \"%s\"", suggestion.Subject)
-		if suggestion.Body != "" {
-			fmt.Printf(" -m \"%s\"", suggestion.Body)
+		if worktreeMode {
+			ui.Printf("   [DRY RUN] Would stage the %d analyzed file(s)\n", len(changes))
+		}
+		if *perPackage {
+			for _, group := range packages {
+				ui.Printf("   [DRY RUN] Would commit %s: %s\n", packageLabel(group), group.Suggestion.Subject)
+			}
+		} else {
+			ui.Printf("   [DRY RUN] Would run: git commit -m \"%s\"", suggestion.Subject)
+			if suggestion.Body != "" {
+				ui.Printf(" -m \"%s\"", suggestion.Body)
+			}
+			fmt.Println()
 		}
-		fmt.Println()
 	} else if commitApproved {
-		fmt.Println("   ➤ Running git commit...")
-		if err := runGitCommit(suggestion); err != nil {
-			log.Fatalf("❌ Failed to commit: %v", err)
+		messages := []*gitcommenter.CommitSuggestion{suggestion}
+		if *perPackage {
+			messages = messages[:0]
+			for _, group := range packages {
+				messages = append(messages, group.Suggestion)
+			}
+		}
+		for _, message := range messages {
+			if *provenance {
+				message.AddProvenance("ai-git-auto v"+version, config.Model)
+			}
+			if workflow != nil {
+				if err := applyWorkflow(workflow, message); err != nil {
+					ui.Fatalf("❌ %v", err)
+				}
+			}
+		}
+
+		if worktreeMode {
+			ui.Printf("   ➤ Staging the %d analyzed file(s)...\n", len(changes))
+			if err := commenter.StageChanges(changes); err != nil {
+				ui.Exitf(exitGitFailed, "❌ %v", err)
+			}
+			// Files listed in .aiignore were left out of the scan; they are
+			// committed all the same, so the verification expects them
+			if err := commenter.StageIgnored(); err != nil {
+				ui.Exitf(exitGitFailed, "❌ %v", err)
+			}
+			ignored, err = commenter.ScanIgnoredChanges()
+			if err != nil {
+				ui.Exitf(exitGitFailed, "❌ Failed to scan the files listed in %s: %v", gitcommenter.AIIgnoreFile, err)
+			}
+			checkSecrets(commenter, ignored, report)
+		}
+
+		if gitcommenter.RepoHooksIgnored(".") {
+			ui.Printf("   ⚠️  Not running the hooks of %s; run 'git config %s true' if you trust them\n", gitcommenter.ConfigFileName, gitcommenter.TrustHooksKey)
+		}
+		if err := runHooks("pre-commit", fileConfig.Hooks.PreCommit); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+
+		if *perPackage {
+			ui.Printf("   ➤ Committing %d package(s)...\n", len(packages))
+			made, err := commenter.CommitPackages(packages)
+			if err != nil {
+				ui.Exitf(exitGitFailed, "❌ Failed to commit after %d of %d package(s): %v", made, len(packages), err)
+			}
+			ui.Println("   ✅ Packages committed separately")
+		} else {
+			ui.Println("   ➤ Running git commit...")
+			if err := commenter.Commit(suggestion); err != nil {
+				ui.Exitf(exitGitFailed, "❌ Failed to commit: %v", err)
+			}
+			ui.Println("   ✅ Changes committed successfully")
+		}
+
+		if err := runHooks("post-commit", fileConfig.Hooks.PostCommit); err != nil {
+			ui.Printf("   ⚠️  %v\n", err)
 		}
-		fmt.Println("   ✅ Changes committed successfully")
 
 		// Show commit hash
 		if hash, err := getLastCommitHash(); err == nil {
-			fmt.Printf("   📝 Commit hash: %s\n", hash)
+			ui.Printf("   📝 Commit hash: %s\n", hash)
+			if report != nil {
+				report.Commit = hash
+			}
 		}
 	} else {
-		fmt.Println("   ❌ Commit cancelled by user")
-		return
+		ui.Println("   ❌ Commit cancelled by user")
+		os.Exit(exitAborted)
+	}
+	if report != nil {
+		report.setSuggestion(suggestion)
 	}
 
 	// Step 5: Push (unless skipped)
+	pushFailed, pushed := false, false
 	if !*skipPush {
-		fmt.Println("\n📤 Step 5: Pushing to remote...")
+		ui.Println("\n📤 Step 5: Pushing to remote...")
 
 		// Check if there's a remote configured
-		fmt.Println("   ➤ Checking for remote repositories...")
+		ui.Println("   ➤ Checking for remote repositories...")
 		remotes, err := getConfiguredRemotes()
 		if err != nil || len(remotes) == 0 {
-			fmt.Println("   ⚠️  No remote repository configured, skipping push")
-			fmt.Println("   💡 Add a remote with: git remote add origin <url>")
+			ui.Println("   ⚠️  No remote repository configured, skipping push")
+			ui.Println("   💡 Add a remote with: git remote add origin <url>")
 		} else {
-			fmt.Printf("   ➤ Found remote(s): %s\n", strings.Join(remotes, ", "))
+			ui.Printf("   ➤ Found remote(s): %s\n", strings.Join(remotes, ", "))
 
 			// Check current branch
 			branch, err := getCurrentBranch()
 			if err == nil {
-				fmt.Printf("   ➤ Current branch: %s\n", branch)
+				ui.Printf("   ➤ Current branch: %s\n", branch)
+			}
+
+			// Check the commit holds what was reviewed before it leaves the machine
+			verified := true
+			if !*dryRun && !*perPackage {
+				verified = displayVerification(commenter, append(slices.Clip(changes), ignored...))
 			}
 
-			pushApproved := !*interactive || *force || askForApproval("push to remote")
+			pushApproved := *force || (verified && (!*interactive || autoPush)) || (*interactive && askForApproval("push this commit to remote"))
 
+			targets := splitList(*pushTargets)
 			if *dryRun {
-				fmt.Println("   [DRY RUN] Would run: git push")
+				if len(targets) > 0 {
+					ui.Printf("   [DRY RUN] Would push %s to %s\n", branch, strings.Join(targets, ", "))
+				} else {
+					ui.Println("   [DRY RUN] Would run: git push")
+				}
+			} else if pushApproved && len(targets) > 0 {
+				if !pushToRemotes(commenter, workflow, targets, branch, *verbose) {
+					pushFailed = true
+					ui.Println("   💡 Push the failed remotes manually with: git push <remote>")
+				} else {
+					pushed = true
+				}
 			} else if pushApproved {
-				fmt.Println("   ➤ Running: git push")
-				if err := runGitPush(); err != nil {
-					log.Printf("   ⚠️  Failed to push: %v", err)
-					fmt.Println("   💡 You can push manually later with: git push")
+				ui.Println("   ➤ Running: git push")
+				if err := pushWithWorkflow(commenter, workflow, pickRemote(remotes), branch); err != nil {
+					log.Printf(ui.Text("   ⚠️  Failed to push: %v"), err)
+					pushFailed = true
+					ui.Println("   💡 You can push manually later with: git push")
 				} else {
-					fmt.Println("   ✅ Changes pushed successfully")
+					ui.Println("   ✅ Changes pushed successfully")
+					pushed = true
 				}
 			} else {
-				fmt.Println("   📝 Push skipped. You can push manually with: git push")
+				ui.Println("   📝 Push skipped. You can push manually with: git push")
 			}
 		}
+	} else if *ciMode {
+		ui.Println("\n📤 Step 5: Skipping push (CI mode pushes only with -push always)")
 	} else {
-		fmt.Println("\n📤 Step 5: Skipping push (--skip-push flag used)")
+		ui.Println("\n📤 Step 5: Skipping push (--skip-push flag used)")
 	}
 
-	fmt.Println("\n🎉 Workflow completed!")
-}
-
-func verifyPrerequisites() error {
-	// Check if in git repository
-	if !isGitRepository() {
-		return fmt.Errorf("not in a Git repository")
+	// Step 6: Pull request, for the branch just pushed
+	if *createPR {
+		branch, _ := getCurrentBranch()
+		switch {
+		case *dryRun:
+			ui.Printf("\n🔀 [DRY RUN] Would open a pull request for %s\n", branch)
+		case !pushed:
+			ui.Println("\n🔀 Not opening a pull request: the branch was not pushed")
+		default:
+			url, err := openPullRequest(commenter, branch, *prBase, *interactive && !*force)
+			if err != nil {
+				log.Printf(ui.Text("   ⚠️  Failed to open a pull request: %v"), err)
+				if report != nil {
+					report.warn("Pull request", err.Error())
+				}
+			} else if report != nil {
+				report.PullRequest = url
+			}
+		}
 	}
 
-	// Check if Ollama is running
-	config := gitcommenter.DefaultConfig()
-	commenter := gitcommenter.New(config)
-	if _, err := commenter.ListAvailableModels(); err != nil {
-		return fmt.Errorf("Ollama is not running or not accessible at %s. Please start it with: ollama serve", config.OllamaEndpoint)
+	ui.Println("\n🎉 Workflow completed!")
+	if *quiet && report == nil {
+		fmt.Fprintln(messageOut, suggestion.Message())
+	}
+	if report != nil {
+		status, code := "committed", exitOK
+		switch {
+		case *dryRun:
+			status = "generated"
+		case pushFailed:
+			report.warn("Push", "the commit was created but could not be pushed")
+			code = exitGitFailed
+		case pushed:
+			status = "pushed"
+		}
+		report.Pushed = pushed
+		report.finish(status, code, "")
+	}
+	// The commit exists, but wrappers should know it was not pushed
+	if pushFailed {
+		ui.Exit(exitGitFailed)
 	}
-
-	return nil
-}
-
-func isGitRepository() bool {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	_, err := cmd.Output()
-	return err == nil
 }
 
 func hasRemoteConfigured() bool {
@@ -296,41 +1037,26 @@ func runGitAdd() error {
 	return cmd.Run()
 }
 
-func runGitCommit(suggestion *gitcommenter.CommitSuggestion) error {
-	args := []string{"commit", "-m", suggestion.Subject}
-	if suggestion.Body != "" {
-		args = append(args, "-m", suggestion.Body)
-	}
-
-	cmd := exec.Command("git", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
-func runGitPush() error {
-	cmd := exec.Command("git", "push")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
 func displayChangesSummary(changes []gitcommenter.FileChange) {
-	fmt.Printf("   📊 Found %d staged file(s):\n", len(changes))
+	ui.Printf("   📊 Found %d staged file(s):\n", len(changes))
 
 	totalAdded, totalRemoved := 0, 0
 	filesByType := make(map[string]int)
 
 	for _, change := range changes {
 		icon := getChangeIcon(change.ChangeType)
-		fmt.Printf("      %s %s (+%d -%d lines)\n",
-			icon, change.FilePath, change.LinesAdded, change.LinesRemoved)
+		name := change.FilePath
+		if change.OldPath != "" {
+			name = change.OldPath + " → " + change.FilePath
+		}
+		ui.Printf("      %s %s (+%d -%d lines)\n",
+			icon, name, change.LinesAdded, change.LinesRemoved)
 		totalAdded += change.LinesAdded
 		totalRemoved += change.LinesRemoved
 		filesByType[change.ChangeType]++
 	}
 
-	fmt.Printf("   📈 Total changes: +%d -%d lines\n", totalAdded, totalRemoved)
+	ui.Printf("   📈 Total changes: +%d -%d lines\n", totalAdded, totalRemoved)
 
 	// Show summary by change type
 	var summary []string
@@ -340,11 +1066,14 @@ func displayChangesSummary(changes []gitcommenter.FileChange) {
 		}
 	}
 	if len(summary) > 0 {
-		fmt.Printf("   📋 Summary: %s\n", strings.Join(summary, ", "))
+		ui.Printf("   📋 Summary: %s\n", strings.Join(summary, ", "))
 	}
 }
 
 func getChangeIcon(changeType string) string {
+	if !ui.emoji {
+		return "[" + changeType + "]"
+	}
 	switch changeType {
 	case "added":
 		return "➕"
@@ -361,23 +1090,31 @@ func getChangeIcon(changeType string) string {
 
 func displayCommitSuggestion(suggestion *gitcommenter.CommitSuggestion) {
 	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("🎯 AI-GENERATED COMMIT MESSAGE")
+	ui.Println("🎯 AI-GENERATED COMMIT MESSAGE")
 	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("📝 Subject: %s\n", suggestion.Subject)
+	ui.Printf("📝 Subject: %s\n", suggestion.Subject)
 
 	if suggestion.Body != "" {
-		fmt.Printf("\n📄 Body:\n%s\n", suggestion.Body)
+		ui.Printf("\n📄 Body:\n%s\n", suggestion.Body)
 	}
 
-	fmt.Printf("\n📊 Confidence: %.0f%%\n", suggestion.Confidence*100)
-	fmt.Printf("📁 Files: %s\n", strings.Join(suggestion.FilesAffected, ", "))
+	ui.Printf("\n📊 Confidence: %.0f%%\n", suggestion.Confidence*100)
+	ui.Printf("📁 Files: %s\n", strings.Join(suggestion.FilesAffected, ", "))
+	for _, warning := range suggestion.Warnings {
+		ui.Printf("⚠️  %s\n", warning)
+	}
 	fmt.Println(strings.Repeat("=", 60))
 }
 
 func askForApproval(action string) bool {
-	fmt.Printf("❓ Do you want to %s? (Y/n): ", action)
+	ui.Printf("❓ Do you want to %s? (Y/n): ", action)
 	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
+	response, err := reader.ReadString('\n')
+	if err != nil && response == "" {
+		// End of input is no answer, not consent
+		fmt.Println()
+		return false
+	}
 	response = strings.ToLower(strings.TrimSpace(response))
 
 	// Default to yes if empty response
@@ -454,7 +1191,7 @@ func promptUserForModel(availableModels []string) (string, error) {
 		return "", fmt.Errorf("no models available")
 	}
 
-	fmt.Print("\n   🤖 Please select a model (1-", len(availableModels), ") or press Enter for default: ")
+	ui.Print("\n   🤖 Please select a model (1-", len(availableModels), ") or press Enter for default: ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -466,7 +1203,7 @@ func promptUserForModel(availableModels []string) (string, error) {
 
 	// If empty input, use first available model
 	if input == "" {
-		fmt.Printf("   ➤ Using default model: %s\n", availableModels[0])
+		ui.Printf("   ➤ Using default model: %s\n", availableModels[0])
 		return availableModels[0], nil
 	}
 
@@ -474,12 +1211,12 @@ func promptUserForModel(availableModels []string) (string, error) {
 	var selection int
 	n, err := fmt.Sscanf(input, "%d", &selection)
 	if n != 1 || err != nil || selection < 1 || selection > len(availableModels) {
-		fmt.Printf("   ❌ Invalid selection. Using default model: %s\n", availableModels[0])
+		ui.Printf("   ❌ Invalid selection. Using default model: %s\n", availableModels[0])
 		return availableModels[0], nil
 	}
 
 	selectedModel := availableModels[selection-1]
-	fmt.Printf("   ➤ Selected model: %s\n", selectedModel)
+	ui.Printf("   ➤ Selected model: %s\n", selectedModel)
 	return selectedModel, nil
 }
 
@@ -512,3 +1249,29 @@ func getModelRecommendation(modelName string) string {
 		return ""
 	}
 }
+
+// displayVerification compares the new commit with the scanned changes and
+// shows its diffstat. It reports whether the commit matched.
+func displayVerification(commenter *gitcommenter.GitCommenter, changes []gitcommenter.FileChange) bool {
+	ui.Println("   ➤ Verifying the commit...")
+	verification, err := commenter.VerifyCommit(changes)
+	if err != nil {
+		ui.Printf("   ⚠️  Could not verify the commit: %v\n", err)
+		return false
+	}
+
+	for _, file := range verification.Missing {
+		ui.Printf("   ⚠️  Scanned but not committed: %s\n", file)
+	}
+	for _, file := range verification.Unexpected {
+		ui.Printf("   ⚠️  Committed but not scanned: %s\n", file)
+	}
+	ui.Println("   📊 Commit contents:")
+	for _, line := range strings.Split(verification.Diffstat, "\n") {
+		ui.Printf("      %s\n", line)
+	}
+	if verification.OK() {
+		ui.Println("   ✅ The commit contains exactly the reviewed files")
+	}
+	return verification.OK()
+}
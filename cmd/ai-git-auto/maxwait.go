@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"time"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// waitForSuggestion waits for a generation in steps of maxWait. When the
+// model is slow the partial message is shown and, if prompting is allowed,
+// the user decides whether to keep waiting; otherwise the partial message
+// is used.
+func waitForSuggestion(generation *gitcommenter.Generation, maxWait time.Duration, prompt bool) *gitcommenter.CommitSuggestion {
+	reader := bufio.NewReader(os.Stdin)
+	waited := time.Duration(0)
+	for {
+		suggestion, done, err := generation.Wait(maxWait)
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ Failed to generate commit message: %v", err)
+		}
+		if done {
+			ui.Printf("   ✅ AI commit message generated (confidence: %.0f%%)\n", suggestion.Confidence*100)
+			return suggestion
+		}
+
+		waited += maxWait
+		ui.Printf("   ⏳ The model is still running after %s. Partial message so far:\n", waited)
+		ui.Printf("      📝 %s\n", suggestion.Subject)
+		if !prompt || !askYesNo(reader, "Keep waiting for the full message?", false) {
+			ui.Println("   ✂️  Using the partial message")
+			return suggestion
+		}
+	}
+}
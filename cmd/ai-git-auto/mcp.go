@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runMCP serves the Model Context Protocol on stdin and stdout for IDE
+// assistants started in the repository
+func runMCP(args []string) {
+	fs := flag.NewFlagSet("mcp", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	analyzeOnly := fs.Bool("analyze-only", false, "Never modify the repository: offer no commit tool and run git read-only")
+	fs.Parse(args)
+
+	// stdout carries the protocol; warnings go to the assistant's log instead
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+
+	config := buildConfig()
+	config.ReadOnly = *analyzeOnly
+	commenter := gitcommenter.New(config)
+	server := gitcommenter.NewMCPServer(commenter, os.Stdin, protocolOut)
+	watchConfig(fs, commenter, func() (*gitcommenter.Config, error) {
+		config := buildConfig()
+		config.ReadOnly = *analyzeOnly
+		return config, nil
+	}, server.SetCommenter)
+	if err := server.Serve(); err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runMRDescription prints a generated GitLab merge request description for
+// the current branch and optionally posts it
+func runMRDescription(args []string) {
+	fs := flag.NewFlagSet("mr-description", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	target := fs.String("target", "", "Target branch (default: the remote's default branch)")
+	source := fs.String("source", "", "Source branch (default: the current branch)")
+	post := fs.Bool("post", false, "Set the description of the branch's open merge request, or open one, via the GitLab API (token from GITLAB_TOKEN or git's credential helper)")
+	project := fs.String("project", "", "GitLab project as group/project (defaults to the origin remote)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto mr-description [-target <branch>] [-post] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	// A description with sections is much longer than a commit message
+	if config.MaxTokens < 1024 {
+		config.MaxTokens = 1024
+	}
+	commenter := gitcommenter.New(config)
+
+	if *source == "" {
+		branch, err := getCurrentBranch()
+		if err != nil || branch == "" {
+			ui.Exitf(exitGitFailed, "❌ HEAD is detached; name the branch with -source")
+		}
+		*source = branch
+	}
+	if *post && *project == "" {
+		var err error
+		if *project, err = commenter.GitLabProject(); err != nil {
+			ui.Fatalf("❌ %v (use -project group/project)", err)
+		}
+	}
+
+	ui.Fprintf(os.Stderr, "🦊 Summarizing %s...\n", *source)
+	draft, err := commenter.GenerateMergeRequest(*target, *source)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	ui.Fprintf(os.Stderr, "📝 %s → %s (%d commit(s)): %s\n", draft.Head, draft.Base, len(draft.Commits), draft.Title)
+	fmt.Println(draft.Body)
+
+	if *post {
+		if err := commenter.PostMergeRequest(*project, draft); err != nil {
+			ui.Fatalf("❌ %v", err)
+		}
+		ui.Fprintf(os.Stderr, "✅ Posted to merge request !%d: %s\n", draft.Number, draft.URL)
+	}
+}
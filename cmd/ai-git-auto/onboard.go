@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runOnboard prints an AI-written orientation guide for the repository
+func runOnboard(args []string) {
+	fs := flag.NewFlagSet("onboard", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	fs.Parse(args)
+
+	config := buildConfig()
+	// A guide is much longer than a commit message
+	if config.MaxTokens < 1024 {
+		config.MaxTokens = 1024
+	}
+
+	commenter := gitcommenter.New(config)
+	ui.Fprintln(os.Stderr, "🧭 Summarizing repository structure and recent activity...")
+
+	summary, err := commenter.GenerateOnboardingSummary()
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	fmt.Println(summary)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// output prints the CLI's own text. Emoji and ANSI colors go through it so
+// they can be turned off for dumb terminals, CI logs and screen readers.
+// Only the format strings and literal text are decorated; commit messages
+// and other content passed as arguments are printed unchanged, gitmoji
+// included.
+type output struct {
+	emoji bool
+	color bool
+	// onExit, if set, runs before Fatalf, Exitf and Exit end the process,
+	// e.g. to print the -ci report
+	onExit func(code int, message string)
+}
+
+var ui = &output{emoji: true, color: true}
+
+// plainEmoji are the emoji that carry meaning, replaced by text when emoji
+// are off; all others are dropped
+var plainEmoji = map[rune]string{
+	'✅': "[ok]",
+	'❌': "[error]",
+	'⚠': "[warning]",
+	'💥': "[failed]",
+	'➤': "-",
+}
+
+// configureOutput applies -no-emoji and the NO_COLOR convention
+// (https://no-color.org). A dumb terminal gets neither.
+func configureOutput(noEmoji bool) {
+	dumb := os.Getenv("TERM") == "dumb"
+	ui.emoji = !noEmoji && !dumb
+	ui.color = os.Getenv("NO_COLOR") == "" && !dumb
+}
+
+// Text returns s with emoji replaced or removed when they are off
+func (o *output) Text(s string) string {
+	if o.emoji {
+		return s
+	}
+	var text strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !isEmoji(r) {
+			text.WriteRune(r)
+			continue
+		}
+		// Skip the variation selectors and spacing that follow the emoji
+		for i+1 < len(runes) && (isEmoji(runes[i+1]) && plainEmoji[runes[i+1]] == "" || runes[i+1] == ' ') {
+			i++
+		}
+		if plain, ok := plainEmoji[r]; ok {
+			text.WriteString(plain)
+			if i+1 < len(runes) && runes[i+1] != '\n' {
+				text.WriteByte(' ')
+			}
+		}
+	}
+	return text.String()
+}
+
+// isEmoji reports whether r is an emoji or a character joining emoji
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, symbols
+		r >= 0x2600 && r <= 0x27BF,             // miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF,             // stars, arrows
+		r >= 0x2300 && r <= 0x23FF && r != '⌘', // hourglass, stopwatch
+		r == 0x200D, r == 0xFE0F:               // joiner, emoji presentation selector
+		return true
+	}
+	return unicode.Is(unicode.Variation_Selector, r)
+}
+
+// Paint wraps s in an ANSI SGR sequence such as "32" (green) when colors
+// are on
+func (o *output) Paint(code, s string) string {
+	if !o.color {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// Printf is fmt.Printf with a decorated format
+func (o *output) Printf(format string, a ...any) {
+	fmt.Printf(o.Text(format), a...)
+}
+
+// Println is fmt.Println with decorated string arguments
+func (o *output) Println(a ...any) {
+	fmt.Println(o.texts(a)...)
+}
+
+// Print is fmt.Print with decorated string arguments
+func (o *output) Print(a ...any) {
+	fmt.Print(o.texts(a)...)
+}
+
+// Fprintf is fmt.Fprintf with a decorated format
+func (o *output) Fprintf(w io.Writer, format string, a ...any) {
+	fmt.Fprintf(w, o.Text(format), a...)
+}
+
+// Fprintln is fmt.Fprintln with decorated string arguments
+func (o *output) Fprintln(w io.Writer, a ...any) {
+	fmt.Fprintln(w, o.texts(a)...)
+}
+
+// Fatalf is log.Fatalf with a decorated format
+func (o *output) Fatalf(format string, a ...any) {
+	o.Exitf(exitError, format, a...)
+}
+
+// Exitf is Fatalf ending with the given exit code
+func (o *output) Exitf(code int, format string, a ...any) {
+	log.Printf(o.Text(format), a...)
+	if o.onExit != nil {
+		o.onExit(code, fmt.Sprintf(format, a...))
+	}
+	os.Exit(code)
+}
+
+// Exit ends the process with the given code after output already printed
+func (o *output) Exit(code int) {
+	if o.onExit != nil {
+		o.onExit(code, "")
+	}
+	os.Exit(code)
+}
+
+func (o *output) texts(a []any) []any {
+	decorated := make([]any, len(a))
+	for i, arg := range a {
+		if s, ok := arg.(string); ok {
+			arg = o.Text(s)
+		}
+		decorated[i] = arg
+	}
+	return decorated
+}
+
+// stdinIsTerminal reports whether prompts can be answered. Git hooks run by
+// Husky or lefthook get a pipe or /dev/null instead.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
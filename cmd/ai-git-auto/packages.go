@@ -0,0 +1,32 @@
+package main
+
+import (
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// generatePackageMessages groups the changes by package and generates and
+// shows the message of each group
+func generatePackageMessages(commenter *gitcommenter.GitCommenter, changes []gitcommenter.FileChange) []gitcommenter.PackageGroup {
+	groups, err := commenter.GroupByPackage(changes)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	for i := range groups {
+		ui.Printf("\n📦 [%d/%d] %s (%d file(s))\n", i+1, len(groups), packageLabel(groups[i]), len(groups[i].Changes))
+		suggestion, err := commenter.GeneratePackageMessage(groups[i])
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ Failed to generate the message for %s: %v", packageLabel(groups[i]), err)
+		}
+		groups[i].Suggestion = suggestion
+		displayCommitSuggestion(suggestion)
+	}
+	return groups
+}
+
+// packageLabel names a package group for display
+func packageLabel(group gitcommenter.PackageGroup) string {
+	if group.Package == "" {
+		return "repository root"
+	}
+	return group.Package
+}
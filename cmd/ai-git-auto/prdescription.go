@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runPRDescription prints a generated pull request description for a branch
+func runPRDescription(args []string) {
+	fs := flag.NewFlagSet("pr-description", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	base := fs.String("base", "", "Branch merged into (default: the remote's default branch)")
+	head := fs.String("head", "", "Branch with the changes (default: the current branch)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto pr-description [-base <branch>] [-head <branch>] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	// A description with sections is much longer than a commit message
+	if config.MaxTokens < 1024 {
+		config.MaxTokens = 1024
+	}
+	commenter := gitcommenter.New(config)
+
+	if *head == "" {
+		branch, err := getCurrentBranch()
+		if err != nil || branch == "" {
+			ui.Exitf(exitGitFailed, "❌ HEAD is detached; name the branch with -head")
+		}
+		*head = branch
+	}
+
+	ui.Fprintf(os.Stderr, "🔀 Summarizing the commits and diff of %s...\n", *head)
+	description, err := commenter.GeneratePRDescription(*base, *head)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	fmt.Println(description)
+}
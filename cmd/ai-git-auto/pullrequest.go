@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// openPullRequest generates a pull request for the pushed branch and opens
+// it on GitHub after asking, unless prompts are off. It returns the URL of
+// the new pull request, or "" when none was opened.
+func openPullRequest(commenter *gitcommenter.GitCommenter, branch, base string, ask bool) (string, error) {
+	ui.Println("\n🔀 Step 6: Opening a pull request...")
+	repo, err := commenter.GitHubRepository()
+	if err != nil {
+		return "", err
+	}
+
+	ui.Printf("   ➤ Summarizing %s for %s...\n", branch, repo)
+	draft, err := commenter.GeneratePullRequest(base, branch)
+	if err != nil {
+		return "", err
+	}
+
+	displayPullRequest(draft)
+
+	if ask && !askForApproval("open this pull request") {
+		ui.Println("   📝 Pull request skipped")
+		return "", nil
+	}
+	if err := commenter.CreatePullRequest(repo, draft); err != nil {
+		return "", err
+	}
+	ui.Printf("   ✅ Opened pull request #%d: %s\n", draft.Number, draft.URL)
+	return draft.URL, nil
+}
+
+// displayPullRequest prints a generated pull or merge request
+func displayPullRequest(draft *gitcommenter.PullRequestDraft) {
+	fmt.Println(strings.Repeat("=", 60))
+	ui.Printf("🔀 %s → %s (%d commit(s))\n", draft.Head, draft.Base, len(draft.Commits))
+	fmt.Println(strings.Repeat("=", 60))
+	ui.Printf("📝 Title: %s\n", draft.Title)
+	if draft.Body != "" {
+		ui.Printf("\n📄 Description:\n%s\n", draft.Body)
+	}
+	fmt.Println(strings.Repeat("=", 60))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runReleaseNotes prints markdown release notes for a range of commits
+func runReleaseNotes(args []string) {
+	fs := flag.NewFlagSet("release-notes", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto release-notes [<from>..<to> | <to>] [flags]")
+		fmt.Fprintln(fs.Output(), "With only <to>, or no range, the notes start at the tag before <to> (default HEAD).")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	from, to := "", fs.Arg(0)
+	if before, after, ok := strings.Cut(to, ".."); ok {
+		from, to = before, after
+	}
+
+	config := buildConfig()
+	// The overview is a few sentences, longer than a commit subject
+	if config.MaxTokens < 512 {
+		config.MaxTokens = 512
+	}
+	commenter := gitcommenter.New(config)
+
+	ui.Fprintln(os.Stderr, "📦 Summarizing the release...")
+	notes, err := commenter.GenerateReleaseNotes(from, to)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	fmt.Println(notes.String())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runReword walks the commits of a range, offers a generated message for
+// each and rewrites the accepted ones with git rebase
+func runReword(args []string) {
+	fs := flag.NewFlagSet("reword", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto reword <range> [flags]")
+		fmt.Fprintln(fs.Output(), "The range is e.g. main..HEAD; a single revision such as HEAD~3 means HEAD~3..HEAD.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	revRange := fs.Arg(0)
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	if !stdinIsTerminal() {
+		ui.Exitf(exitNeedsTerminal, "❌ reword needs an interactive terminal")
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+	ui.Println("✏️  AI Git Auto - Reword")
+	ui.Println("=======================")
+
+	commits, err := commenter.Commits(revRange)
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+	if len(commits) == 0 {
+		ui.Exitf(exitNoChanges, "❌ No commits in %s", revRange)
+	}
+	head, err := commenter.HeadCommit()
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	messages := make(map[string]string)
+walk:
+	for i, commit := range commits {
+		ui.Printf("\n📝 [%d/%d] %s %s\n", i+1, len(commits), commit.ShortHash(), commit.Subject)
+		changes, err := commenter.CommitChanges(commit.Hash)
+		if err != nil {
+			ui.Exitf(exitGitFailed, "❌ %v", err)
+		}
+		if len(changes) == 0 {
+			ui.Println("   ⏭️  No file changes; keeping the message")
+			continue
+		}
+		displayChangesSummary(changes)
+		ui.Printf("\n   Current message:\n%s\n", indentLines(commit.Message(), "      "))
+
+		ui.Printf("\n🤖 Generating a message (using %s)...\n", config.Model)
+		suggestion, err := commenter.GenerateReword(commit)
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ %v", err)
+		}
+		ui.Printf("\n   Suggested message:\n%s\n\n", indentLines(suggestion.Message(), "      "))
+
+		switch askChoice(reader, "Use the suggestion, keep, edit or quit?", []string{"use", "keep", "edit", "quit"}, "use") {
+		case "use":
+			messages[commit.Hash] = suggestion.Message()
+		case "edit":
+			edited, err := editMessage(suggestion)
+			if err != nil {
+				ui.Exitf(exitError, "❌ %v", err)
+			}
+			messages[commit.Hash] = edited.Message()
+		case "quit":
+			break walk
+		}
+	}
+
+	if len(messages) == 0 {
+		ui.Println("\n✅ No messages changed; history left as it is")
+		return
+	}
+	if !askYesNo(reader, fmt.Sprintf("Rewrite %d commit message(s) in %s?", len(messages), revRange), true) {
+		ui.Println("   ❌ Cancelled by user")
+		os.Exit(exitAborted)
+	}
+	ui.Println("\n🔁 Rewriting history with git rebase...")
+	if err := commenter.RewordCommits(revRange, messages); err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+	ui.Printf("   ✅ Reworded %d commit(s); the previous history is %s (git reset --hard %s to undo)\n", len(messages), head[:7], head[:7])
+}
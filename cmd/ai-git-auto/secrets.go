@@ -0,0 +1,27 @@
+package main
+
+import (
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// checkSecrets lists the added lines that look like secrets and, unless
+// -secret-scan allows them, stops before the model or a commit sees them
+func checkSecrets(commenter *gitcommenter.GitCommenter, changes []gitcommenter.FileChange, report *ciReport) {
+	findings, err := commenter.CheckSecrets(changes)
+	if len(findings) == 0 {
+		return
+	}
+	ui.Printf("   🔐 %d line(s) look like they hold secrets:\n", len(findings))
+	for _, finding := range findings {
+		ui.Printf("      • %s\n", finding)
+	}
+	if err != nil {
+		ui.Println("   💡 Remove them, mark false positives with a 'pragma: allowlist secret' comment, or use -secret-scan warn")
+		ui.Exitf(exitSecretsFound, "❌ Refusing to send possible secrets to the model or commit them")
+	}
+	if report != nil {
+		for _, finding := range findings {
+			report.warn("Possible secret", finding.String())
+		}
+	}
+}
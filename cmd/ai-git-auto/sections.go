@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// fillManualSections asks the author for each manual section and adds the
+// answers to the message. A section is required, so empty answers are asked
+// again. The answers are returned to be added to regenerated messages.
+func fillManualSections(suggestion *gitcommenter.CommitSuggestion, sections []string) map[string]string {
+	answers := make(map[string]string)
+	reader := bufio.NewReader(os.Stdin)
+	ui.Println("\n✍️  Sections to fill in yourself (finish each with an empty line):")
+	for _, section := range sections {
+		for {
+			content, err := askLines(reader, section)
+			if content != "" {
+				answers[section] = content
+				break
+			}
+			if err != nil {
+				ui.Printf("   ⚠️  No input, leaving out %s\n", section)
+				break
+			}
+			ui.Printf("   ⚠️  %s is required\n", section)
+		}
+	}
+	addSections(suggestion, sections, answers)
+	return answers
+}
+
+// addSections adds the answered sections in their configured order
+func addSections(suggestion *gitcommenter.CommitSuggestion, sections []string, answers map[string]string) {
+	for _, section := range sections {
+		suggestion.AddSection(section, answers[section])
+	}
+}
+
+// askLines reads lines until an empty one or the end of the input
+func askLines(reader *bufio.Reader, question string) (string, error) {
+	ui.Printf("❓ %s\n", question)
+	var lines []string
+	for {
+		ui.Print("   > ")
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+		if strings.TrimSpace(line) == "" || err != nil {
+			return strings.Join(lines, "\n"), err
+		}
+	}
+}
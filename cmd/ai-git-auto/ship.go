@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// forgeCLI is a command-line client that opens pull or merge requests
+type forgeCLI struct {
+	// Name is the executable and Command its subcommand for requests,
+	// which the forge calls Request
+	Name    string
+	Command string
+	Request string
+}
+
+var (
+	ghCLI   = forgeCLI{Name: "gh", Command: "pr", Request: "pull request"}
+	glabCLI = forgeCLI{Name: "glab", Command: "mr", Request: "merge request"}
+)
+
+// detectForgeCLI picks gh or glab for the remote: github.com needs gh and
+// gitlab hosts need glab; for other hosts, e.g. GitHub Enterprise, the
+// first one on PATH is used
+func detectForgeCLI(remoteURL string) (forgeCLI, error) {
+	candidates := []forgeCLI{ghCLI, glabCLI}
+	switch {
+	case strings.Contains(remoteURL, "github.com"):
+		candidates = []forgeCLI{ghCLI}
+	case strings.Contains(remoteURL, "gitlab"):
+		candidates = []forgeCLI{glabCLI}
+	}
+
+	var names []string
+	for _, cli := range candidates {
+		if _, err := exec.LookPath(cli.Name); err == nil {
+			return cli, nil
+		}
+		names = append(names, cli.Name)
+	}
+	return forgeCLI{}, fmt.Errorf("%s is not on PATH; install it and log in to open requests for %s", strings.Join(names, " or "), remoteURL)
+}
+
+// createArgs returns the arguments opening a request from head into base
+func (cli forgeCLI) createArgs(draft *gitcommenter.PullRequestDraft, asDraft bool) []string {
+	var args []string
+	if cli.Name == "glab" {
+		args = []string{cli.Command, "create", "--source-branch", draft.Head, "--target-branch", draft.Base,
+			"--title", draft.Title, "--description", draft.Body, "--yes"}
+	} else {
+		args = []string{cli.Command, "create", "--head", draft.Head, "--base", draft.Base,
+			"--title", draft.Title, "--body", draft.Body}
+	}
+	if asDraft {
+		args = append(args, "--draft")
+	}
+	return args
+}
+
+// runShip stages everything, commits with a generated message, pushes the
+// branch with -u and opens a pull or merge request through gh or glab
+func runShip(args []string) {
+	fs := flag.NewFlagSet("ship", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	base := fs.String("base", "", "Branch to merge into (default: the remote's default branch)")
+	remote := fs.String("remote", "origin", "Remote to push to")
+	asDraft := fs.Bool("draft", false, "Open the request as a draft")
+	force := fs.Bool("force", false, "Skip confirmation prompts")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto ship [-base <branch>] [-draft] [-force] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+	ui.Println("🚢 AI Git Auto - Ship")
+	ui.Println("=====================")
+
+	// Check everything needed for the last step before the first one
+	ui.Println("🔍 Verifying prerequisites...")
+	if commenter.Backend().IsRepository() != nil {
+		ui.Exitf(exitGitFailed, "❌ Not in a Git repository")
+	}
+	output, err := exec.Command("git", "remote", "get-url", *remote).Output()
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ No remote named %s; add it with: git remote add %s <url>", *remote, *remote)
+	}
+	remoteURL := strings.TrimSpace(string(output))
+	cli, err := detectForgeCLI(remoteURL)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	ui.Printf("   ✅ Opening the %s with %s\n", cli.Request, cli.Name)
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "" {
+		ui.Exitf(exitGitFailed, "❌ HEAD is detached; check out a branch first")
+	}
+	onBase := branch == *base || (*base == "" && isDefaultBranch(branch))
+
+	// Step 1: stage
+	ui.Println("\n📝 Step 1: Staging changes (git add .)...")
+	if err := runGitAdd(); err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to stage changes: %v", err)
+	}
+	changes, err := commenter.ScanStagedChanges()
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to scan changes: %v", err)
+	}
+
+	// Step 2: commit, on a topic branch when on the base branch
+	if len(changes) == 0 {
+		if onBase {
+			ui.Exitf(exitNoChanges, "❌ No changes to commit, and %s is the base branch", branch)
+		}
+		ui.Printf("\n💾 Step 2: No changes to commit; shipping the commits on %s\n", branch)
+	} else {
+		displayChangesSummary(changes)
+		ui.Printf("\n💾 Step 2: Committing (using %s)...\n", config.Model)
+		suggestion, err := commenter.GenerateCommitMessage(changes)
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ Failed to generate commit message: %v", err)
+		}
+		displayCommitSuggestion(suggestion)
+		if !*force && !askForApproval("commit with this message") {
+			ui.Println("   ❌ Cancelled by user")
+			os.Exit(exitAborted)
+		}
+		if onBase {
+			if err := applyWorkflow(&gitcommenter.Workflow{CreateBranch: true}, suggestion); err != nil {
+				ui.Exitf(exitGitFailed, "❌ %v", err)
+			}
+			branch, _ = getCurrentBranch()
+		}
+		if err := commenter.Commit(suggestion); err != nil {
+			ui.Exitf(exitGitFailed, "❌ Failed to commit: %v", err)
+		}
+		ui.Println("   ✅ Changes committed")
+	}
+
+	// Step 3: push
+	ui.Println("\n📤 Step 3: Pushing...")
+	if err := pushWithWorkflow(commenter, &gitcommenter.Workflow{SetUpstream: true}, *remote, branch); err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to push: %v", err)
+	}
+	ui.Printf("   ✅ Pushed %s to %s\n", branch, *remote)
+
+	// Step 4: pull or merge request
+	ui.Printf("\n🔀 Step 4: Opening a %s...\n", cli.Request)
+	draft, err := commenter.GeneratePullRequest(*base, branch)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ Failed to describe the %s: %v", cli.Request, err)
+	}
+	displayPullRequest(draft)
+	if !*force && !askForApproval("open this "+cli.Request) {
+		ui.Printf("   📝 Skipped; the branch is pushed, open the %s with: %s %s create\n", cli.Request, cli.Name, cli.Command)
+		return
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(cli.Name, cli.createArgs(draft, *asDraft)...)
+	cmd.Stdout, cmd.Stderr = &stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		ui.Fatalf("❌ %s failed to open the %s: %v", cli.Name, cli.Request, err)
+	}
+	ui.Printf("   ✅ Opened %s\n", lastLine(stdout.String()))
+	ui.Println("\n🎉 Shipped!")
+}
+
+// lastLine returns the last non-empty line of output, where gh and glab
+// print the URL of the new request
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runSplit groups the staged hunks into logically related changes and
+// commits each group with its own generated message
+func runSplit(args []string) {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	force := fs.Bool("force", false, "Commit without asking for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto split [-force] [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if !*force && !stdinIsTerminal() {
+		ui.Exitf(exitNeedsTerminal, "❌ split needs an interactive terminal to confirm the commits; pass -force to commit without asking")
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+	ui.Println("✂️  AI Git Auto - Split")
+	ui.Println("======================")
+
+	hunks, err := commenter.StagedHunks()
+	if errors.Is(err, gitcommenter.ErrOnlyIgnored) {
+		ui.Exitf(exitNoChanges, "❌ Every changed file is listed in %s", gitcommenter.AIIgnoreFile)
+	}
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+	if len(hunks) == 0 {
+		ui.Exitf(exitNoChanges, "❌ No staged changes to split")
+	}
+
+	ui.Printf("\n🧩 Grouping %d staged hunk(s) (using %s)...\n", len(hunks), config.Model)
+	groups, err := commenter.GroupHunks(hunks)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+
+	var commits []gitcommenter.SplitCommit
+	for i, group := range groups {
+		ui.Printf("\n📝 Commit %d of %d:\n", i+1, len(groups))
+		changes := commenter.HunkChanges(group)
+		for _, change := range changes {
+			ui.Printf("   %s (+%d -%d)\n", change.FilePath, change.LinesAdded, change.LinesRemoved)
+		}
+		suggestion, err := commenter.GenerateCommitMessage(changes)
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ %v", err)
+		}
+		ui.Printf("\n%s\n", indentLines(suggestion.Message(), "      "))
+		commits = append(commits, gitcommenter.SplitCommit{Hunks: group, Suggestion: suggestion})
+	}
+
+	if len(commits) == 1 {
+		ui.Println("\n💡 The staged changes belong together")
+	}
+	if !*force && !askYesNo(bufio.NewReader(os.Stdin), fmt.Sprintf("\nCreate %d commit(s)?", len(commits)), true) {
+		ui.Println("   ❌ Cancelled by user")
+		os.Exit(exitAborted)
+	}
+
+	ui.Println("\n💾 Committing...")
+	made, err := commenter.CommitSplit(commits)
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ Created %d commit(s) before failing: %v", made, err)
+	}
+	ui.Printf("   ✅ Created %d commit(s)\n", made)
+}
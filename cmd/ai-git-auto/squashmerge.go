@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runSquashMergeMessage prints the commit message for squash-merging a
+// GitHub pull request
+func runSquashMergeMessage(args []string) {
+	fs := flag.NewFlagSet("squash-merge-msg", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	number := fs.Int("pr", 0, "Pull request number")
+	repo := fs.String("repo", "", "GitHub repository as owner/repo (defaults to the origin remote)")
+	provenance := fs.Bool("provenance", false, "Add Generated-by and Prompt-hash trailers recording the tool, model and prompt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto squash-merge-msg -pr <number> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *number <= 0 || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+	if *repo == "" {
+		var err error
+		if *repo, err = commenter.GitHubRepository(); err != nil {
+			ui.Fatalf("❌ %v (use -repo owner/repo)", err)
+		}
+	}
+
+	ui.Fprintf(os.Stderr, "📥 Fetching pull request #%d from %s...\n", *number, *repo)
+	pr, err := commenter.FetchPullRequest(*repo, *number)
+	if err != nil {
+		ui.Fatalf("❌ %v", err)
+	}
+	ui.Fprintf(os.Stderr, "🤖 Summarizing %d commit(s) and %d file(s)...\n", len(pr.Commits), len(pr.Changes))
+
+	suggestion, err := commenter.GenerateSquashMergeMessage(pr)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	if *provenance {
+		suggestion.AddProvenance("ai-git-auto v"+version, config.Model)
+	}
+	fmt.Println(suggestion.Message())
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runSquashMessage prints one commit message for squashing a range of
+// commits
+func runSquashMessage(args []string) {
+	fs := flag.NewFlagSet("squash-message", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	subjectOnly := fs.Bool("subject", false, "Print only the subject, e.g. for a squash-merge title")
+	provenance := fs.Bool("provenance", false, "Add Generated-by and Prompt-hash trailers recording the tool, model and prompt")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto squash-message <range> [flags]")
+		fmt.Fprintln(fs.Output(), "The range is e.g. main..HEAD; a single revision such as HEAD~3 means HEAD~3..HEAD.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	revRange := fs.Arg(0)
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+
+	ui.Fprintf(os.Stderr, "🧩 Combining the commits in %s...\n", revRange)
+	suggestion, err := commenter.GenerateSquashMessage(revRange)
+	if err != nil {
+		ui.Exitf(generationExitCode(err), "❌ %v", err)
+	}
+	if *subjectOnly {
+		fmt.Println(suggestion.Subject)
+		return
+	}
+	if *provenance {
+		suggestion.AddProvenance("ai-git-auto v"+version, config.Model)
+	}
+	fmt.Println(suggestion.Message())
+}
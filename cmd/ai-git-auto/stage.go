@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// runStage walks the unstaged hunks like git add -p, describing each one
+// in a line, and stages the hunks the user picks
+func runStage(args []string) {
+	fs := flag.NewFlagSet("stage", flag.ExitOnError)
+	buildConfig := modelFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ai-git-auto stage [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if !stdinIsTerminal() {
+		ui.Exitf(exitNeedsTerminal, "❌ stage needs an interactive terminal")
+	}
+
+	config := buildConfig()
+	commenter := gitcommenter.New(config)
+	ui.Println("🧩 AI Git Auto - Stage")
+	ui.Println("======================")
+
+	hunks, err := commenter.UnstagedHunks()
+	if errors.Is(err, gitcommenter.ErrOnlyIgnored) {
+		ui.Exitf(exitNoChanges, "❌ Every changed file is listed in %s", gitcommenter.AIIgnoreFile)
+	}
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+	if len(hunks) == 0 {
+		ui.Exitf(exitNoChanges, "❌ No unstaged changes")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var picked []gitcommenter.StagedHunk
+walk:
+	for i, hunk := range hunks {
+		change := commenter.HunkChanges([]gitcommenter.StagedHunk{hunk})[0]
+		ui.Printf("\n📄 [%d/%d] %s (%s, +%d -%d)\n", i+1, len(hunks), hunk.Path, hunk.ChangeType, change.LinesAdded, change.LinesRemoved)
+		description, err := commenter.DescribeHunk(hunk)
+		if err != nil {
+			ui.Exitf(generationExitCode(err), "❌ %v", err)
+		}
+		ui.Printf("   💬 %s\n", description)
+
+		for {
+			switch askChoice(reader, "Stage this hunk?", []string{"yes", "no", "diff", "quit"}, "yes") {
+			case "yes":
+				picked = append(picked, hunk)
+			case "diff":
+				fmt.Println(indentLines(strings.TrimRight(change.Diff, "\n"), "      "))
+				continue
+			case "quit":
+				break walk
+			}
+			break
+		}
+	}
+
+	if len(picked) == 0 {
+		ui.Println("\n✅ Nothing staged")
+		return
+	}
+	if err := commenter.StageHunks(picked); err != nil {
+		ui.Exitf(exitGitFailed, "❌ %v", err)
+	}
+	ui.Printf("\n✅ Staged %d of %d hunk(s); commit them with: ai-git-auto -skip-add\n", len(picked), len(hunks))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// modelTracer reports model requests for -v, -vv, -show-prompt and
+// -dump-prompt. Candidates are generated in parallel, so reports are
+// serialized.
+type modelTracer struct {
+	mu         sync.Mutex
+	out        io.Writer
+	verbosity  int
+	showPrompt bool
+	dump       *os.File
+	calls      int
+}
+
+// newModelTracer creates a tracer printing to out, or nil when no option
+// asks for tracing. dumpPath is truncated so it only holds this run.
+func newModelTracer(out io.Writer, verbosity int, showPrompt bool, dumpPath string) (*modelTracer, error) {
+	if verbosity == 0 && !showPrompt && dumpPath == "" {
+		return nil, nil
+	}
+	tracer := &modelTracer{out: out, verbosity: verbosity, showPrompt: showPrompt}
+	if dumpPath != "" {
+		file, err := os.Create(dumpPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prompt dump: %w", err)
+		}
+		tracer.dump = file
+	}
+	return tracer, nil
+}
+
+// prints reports whether the tracer writes to the terminal, which would
+// interleave with prompts while a message is prefetched
+func (t *modelTracer) prints() bool {
+	return t != nil && t.out != io.Discard && (t.verbosity > 0 || t.showPrompt)
+}
+
+// trace is the function passed to SetTrace
+func (t *modelTracer) trace(call gitcommenter.ModelCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls++
+
+	if t.verbosity > 0 {
+		status := fmt.Sprintf("%d-char response in %s", len(call.Response), call.Duration.Round(time.Millisecond))
+		if call.Cached {
+			status = fmt.Sprintf("%d-char response from the cache", len(call.Response))
+		}
+		if call.Err != nil {
+			status = fmt.Sprintf("failed after %s: %v", call.Duration.Round(time.Millisecond), call.Err)
+		}
+		ui.Fprintf(t.out, "   🔎 Model call %d (%s, temperature %.2f): %d-char prompt, %s\n",
+			t.calls, call.Model, call.Temperature, len(call.Prompt), status)
+	}
+	if t.showPrompt {
+		ui.Fprintf(t.out, "\n📜 Prompt of model call %d:\n%s\n", t.calls, fence(call.Prompt))
+	}
+	if t.verbosity > 1 && call.Err == nil {
+		ui.Fprintf(t.out, "\n💬 Response of model call %d:\n%s\n", t.calls, fence(call.Response))
+	}
+
+	if t.dump != nil {
+		fmt.Fprintf(t.dump, "### Model call %d: %s, temperature %.2f, candidate %d\n\n%s\n\n### Response\n\n",
+			t.calls, call.Model, call.Temperature, call.Candidate, call.Prompt)
+		if call.Err != nil {
+			fmt.Fprintf(t.dump, "(error: %v)\n\n", call.Err)
+		} else {
+			fmt.Fprintf(t.dump, "%s\n\n", call.Response)
+		}
+	}
+}
+
+// close finishes the prompt dump
+func (t *modelTracer) close() {
+	if t == nil || t.dump == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.dump.Close(); err != nil {
+		ui.Printf("⚠️  Failed to write the prompt dump: %v\n", err)
+	}
+}
+
+// fence frames text between rules so its own blank lines stay visible
+func fence(text string) string {
+	rule := strings.Repeat("─", 60)
+	return rule + "\n" + strings.TrimRight(text, "\n") + "\n" + rule
+}
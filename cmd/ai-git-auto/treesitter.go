@@ -0,0 +1,14 @@
+//go:build cgo && treesitter
+
+package main
+
+import (
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	"github.com/TheRealMasterK/Ai-Git-Comments-Auto/treesitter"
+)
+
+// The tree-sitter grammars need cgo, so -tree-sitter only works in builds
+// made with "go build -tags treesitter"
+func init() {
+	newSymbolAnalyzer = func() gitcommenter.SymbolAnalyzer { return treesitter.New() }
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tuiAction is how the full-screen mode was left
+type tuiAction int
+
+const (
+	tuiQuit tuiAction = iota
+	tuiCommit
+	tuiCommitAndPush
+)
+
+// tuiKeys is the help line shown at the bottom of the screen. Text drawn in
+// the panes avoids emoji, whose width varies between terminals.
+const tuiKeys = "↑/↓ file  space toggle  u/d scroll diff  g regenerate  e edit  c commit  p commit+push  q quit"
+
+// tui is the tea.Model of the full-screen mode: the staged files (and
+// which of them feed the prompt), the diff of the selected file and the
+// message
+type tui struct {
+	commenter  *gitcommenter.GitCommenter
+	changes    []gitcommenter.FileChange
+	included   []bool
+	cursor     int
+	scroll     int
+	suggestion *gitcommenter.CommitSuggestion
+	generating bool
+	generation int
+	status     string
+	width      int
+	height     int
+	action     tuiAction
+}
+
+// tuiGenerated is a finished generation, numbered so that results of
+// superseded requests are dropped
+type tuiGenerated struct {
+	generation int
+	suggestion *gitcommenter.CommitSuggestion
+	err        error
+}
+
+// tuiEdited is the message back from the editor
+type tuiEdited struct {
+	suggestion *gitcommenter.CommitSuggestion
+	err        error
+}
+
+// runTUI shows the full-screen mode until the user commits or quits. It
+// returns the message to commit, or nil when the user quit.
+func runTUI(commenter *gitcommenter.GitCommenter, changes []gitcommenter.FileChange) (*gitcommenter.CommitSuggestion, tuiAction, error) {
+	t := &tui{commenter: commenter, changes: changes, included: make([]bool, len(changes))}
+	for i := range t.included {
+		t.included[i] = true
+	}
+
+	model, err := tea.NewProgram(t, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, tuiQuit, fmt.Errorf("the TUI failed: %w", err)
+	}
+	t = model.(*tui)
+	if t.action == tuiQuit {
+		return nil, tuiQuit, nil
+	}
+	return t.suggestion, t.action, nil
+}
+
+// Init starts generating from every staged file
+func (t *tui) Init() tea.Cmd {
+	return t.regenerate()
+}
+
+// Update handles keys, resizes and the results of generations and edits
+func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width, t.height = msg.Width, msg.Height
+	case tuiGenerated:
+		if msg.generation != t.generation {
+			break
+		}
+		t.generating = false
+		if msg.err != nil {
+			t.status = "Error: " + msg.err.Error()
+		} else {
+			t.suggestion = msg.suggestion
+			t.status = fmt.Sprintf("Generated (confidence %.0f%%)", msg.suggestion.Confidence*100)
+		}
+	case tuiEdited:
+		if msg.err != nil {
+			t.status = "Error: " + msg.err.Error()
+		} else {
+			t.suggestion = msg.suggestion
+			t.status = "Message edited"
+		}
+	case tea.KeyMsg:
+		switch key := msg.String(); key {
+		case "q", "ctrl+c":
+			t.action = tuiQuit
+			return t, tea.Quit
+		case "c", "p":
+			if t.suggestion == nil {
+				t.status = "No message yet"
+				break
+			}
+			t.action = tuiCommit
+			if key == "p" {
+				t.action = tuiCommitAndPush
+			}
+			return t, tea.Quit
+		case "e":
+			if t.suggestion == nil {
+				t.status = "No message yet"
+				break
+			}
+			// The editor gets the terminal until it exits
+			cmd, finish, err := editorCommand(t.suggestion)
+			if err != nil {
+				t.status = "Error: " + err.Error()
+				break
+			}
+			return t, tea.ExecProcess(cmd, func(err error) tea.Msg {
+				edited, finishErr := finish(err)
+				return tuiEdited{suggestion: edited, err: finishErr}
+			})
+		case "g":
+			return t, t.regenerate()
+		default:
+			t.navigate(key)
+		}
+	}
+	return t, nil
+}
+
+// regenerate starts a generation from the included files
+func (t *tui) regenerate() tea.Cmd {
+	var selected []gitcommenter.FileChange
+	for i, change := range t.changes {
+		if t.included[i] {
+			selected = append(selected, change)
+		}
+	}
+	if len(selected) == 0 {
+		t.status = "Select at least one file to generate from"
+		return nil
+	}
+
+	t.generation++
+	t.generating = true
+	t.status = fmt.Sprintf("Generating from %d file(s)...", len(selected))
+	generation, commenter := t.generation, t.commenter
+	return func() tea.Msg {
+		suggestion, err := commenter.GenerateCommitMessage(selected)
+		return tuiGenerated{generation: generation, suggestion: suggestion, err: err}
+	}
+}
+
+// navigate handles the keys that move around the file list and diff
+func (t *tui) navigate(key string) {
+	switch key {
+	case "up", "k":
+		if t.cursor > 0 {
+			t.cursor--
+			t.scroll = 0
+		}
+	case "down", "j":
+		if t.cursor < len(t.changes)-1 {
+			t.cursor++
+			t.scroll = 0
+		}
+	case " ":
+		t.included[t.cursor] = !t.included[t.cursor]
+		t.status = "Press g to regenerate from the selected files"
+	case "d", "pgdown":
+		t.scroll += max(t.paneHeight()/2, 1)
+	case "u", "pgup":
+		t.scroll = max(t.scroll-max(t.paneHeight()/2, 1), 0)
+	}
+}
+
+// paneHeight is the height of the file and diff panes; the message pane
+// takes a third of the screen
+func (t *tui) paneHeight() int {
+	return max(t.height-t.messageHeight()-4, 1)
+}
+
+// messageHeight is the height of the message pane
+func (t *tui) messageHeight() int {
+	return max(t.height/3, 3)
+}
+
+// View draws the whole screen
+func (t *tui) View() string {
+	if t.width == 0 {
+		return "" // Until the size is known
+	}
+	listWidth := min(max(t.width/3, 20), 50)
+	diffWidth := max(t.width-listWidth-1, 10)
+
+	var files []string
+	for i, change := range t.changes {
+		mark := "[ ]"
+		if t.included[i] {
+			mark = "[x]"
+		}
+		pointer := "  "
+		if i == t.cursor {
+			pointer = "> "
+		}
+		files = append(files, fmt.Sprintf("%s%s %s", pointer, mark, change.FilePath))
+	}
+	// Keep the cursor visible in long file lists
+	height := t.paneHeight()
+	if offset := t.cursor - height + 1; offset > 0 {
+		files = files[offset:]
+	}
+
+	var diff []string
+	if len(t.changes) > 0 {
+		diff = strings.Split(t.changes[t.cursor].Diff, "\n")
+		t.scroll = min(t.scroll, max(len(diff)-1, 0))
+		diff = diff[t.scroll:]
+	}
+
+	var screen strings.Builder
+	screen.WriteString(tuiLine(fmt.Sprintf(" Staged files (%d)", len(t.changes)), listWidth) + "│" + tuiLine(" Diff", diffWidth) + "\n")
+	for row := 0; row < height; row++ {
+		screen.WriteString(tuiLine(lineAt(files, row), listWidth) + "│" + colorDiffLine(tuiLine(lineAt(diff, row), diffWidth)) + "\n")
+	}
+
+	title := " Message "
+	if t.generating {
+		title = " Message (generating...) "
+	}
+	screen.WriteString(title + strings.Repeat("─", max(t.width-len(title), 0)) + "\n")
+	var message []string
+	if t.suggestion != nil {
+		message = strings.Split(t.suggestion.Message(), "\n")
+	}
+	for row := 0; row < t.messageHeight(); row++ {
+		screen.WriteString(tuiLine(lineAt(message, row), t.width) + "\n")
+	}
+
+	screen.WriteString(tuiLine(t.status, t.width) + "\n")
+	screen.WriteString(ui.Paint("7", tuiLine(tuiKeys, t.width)))
+	return screen.String()
+}
+
+// lineAt returns lines[row], or "" past the end
+func lineAt(lines []string, row int) string {
+	if row < len(lines) {
+		return lines[row]
+	}
+	return ""
+}
+
+// tuiLine pads or truncates s to exactly width columns
+func tuiLine(s string, width int) string {
+	runes := []rune(strings.ReplaceAll(s, "\t", "    "))
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+// colorDiffLine colors added lines green and removed lines red
+func colorDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return line
+	case strings.HasPrefix(line, "+"):
+		return ui.Paint("32", line)
+	case strings.HasPrefix(line, "-"):
+		return ui.Paint("31", line)
+	case strings.HasPrefix(line, "@@"):
+		return ui.Paint("36", line)
+	}
+	return line
+}
+
+// editMessage opens the message in $VISUAL or $EDITOR (vi by default).
+// Lines starting with '#' are dropped like git does.
+func editMessage(suggestion *gitcommenter.CommitSuggestion) (*gitcommenter.CommitSuggestion, error) {
+	cmd, finish, err := editorCommand(suggestion)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return finish(cmd.Run())
+}
+
+// editorCommand writes the message to a file and returns the editor
+// command for it, for editMessage or the TUI to run, and a function that
+// takes the error of the run and reads the edited message back
+func editorCommand(suggestion *gitcommenter.CommitSuggestion) (*exec.Cmd, func(error) (*gitcommenter.CommitSuggestion, error), error) {
+	file, err := os.CreateTemp("", "ai-git-auto-*.txt")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a file to edit: %w", err)
+	}
+	if _, err := file.WriteString(suggestion.Message() + "\n"); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, nil, fmt.Errorf("failed to write the message: %w", err)
+	}
+	file.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+
+	finish := func(runErr error) (*gitcommenter.CommitSuggestion, error) {
+		defer os.Remove(file.Name())
+		if runErr != nil {
+			return nil, fmt.Errorf("editor failed: %w", runErr)
+		}
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the edited message: %w", err)
+		}
+		var lines []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(line, "#") {
+				lines = append(lines, line)
+			}
+		}
+		subject, body, _ := strings.Cut(strings.TrimSpace(strings.Join(lines, "\n")), "\n")
+		if strings.TrimSpace(subject) == "" {
+			return nil, fmt.Errorf("the edited message is empty")
+		}
+
+		edited := *suggestion
+		edited.Subject = strings.TrimSpace(subject)
+		edited.Body = strings.TrimSpace(body)
+		return &edited, nil
+	}
+	return cmd, finish, nil
+}
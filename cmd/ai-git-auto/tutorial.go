@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// tutorialFiles are the files of the demo repository's first commit
+var tutorialFiles = map[string]string{
+	"README.md": "# greeter\n\nA tiny program for trying out ai-git-auto.\n",
+	"main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(greeting(\"\"))\n}\n",
+	"greeting.go": "package main\n\n// greeting returns the text printed by main\n" +
+		"func greeting(name string) string {\n\treturn \"Hello, world!\"\n}\n",
+}
+
+// tutorialChange is the change the tutorial stages and commits
+const tutorialChange = "package main\n\n// greeting returns the text printed by main, addressing name when given\n" +
+	"func greeting(name string) string {\n\tif name == \"\" {\n\t\tname = \"world\"\n\t}\n\treturn \"Hello, \" + name + \"!\"\n}\n"
+
+// tutorialExample is shown instead of a generated message when the model
+// cannot be reached
+var tutorialExample = gitcommenter.CommitSuggestion{
+	Subject:       "feat(greeting): greet people by name",
+	Body:          "Fall back to \"world\" when no name is given, so the\ndefault output stays the same.",
+	Confidence:    0.9,
+	FilesAffected: []string{"greeting.go"},
+}
+
+// runTutorial walks through staging, generating, editing and committing in
+// a throwaway demo repository, then shows how the hook works, so the first
+// run never touches a real repository
+func runTutorial(config *gitcommenter.Config) {
+	reader := bufio.NewReader(os.Stdin)
+	ui.Println("🎓 AI Git Auto - Tutorial")
+	ui.Println("=========================")
+	ui.Println("This tutorial works in a demo repository created just for it;")
+	ui.Println("none of your repositories are touched.")
+
+	dir, err := createTutorialRepo()
+	if err != nil {
+		ui.Fatalf("❌ Failed to create the demo repository: %v", err)
+	}
+	ui.Printf("\n📂 Demo repository: %s\n", dir)
+
+	config.RepositoryPath = dir
+	commenter := gitcommenter.New(config)
+	commenter.SetGitBackend(gitcommenter.NewExecBackend(dir))
+
+	// Step 1: staging
+	ui.Println("\n📝 Step 1: Staging changes")
+	ui.Println("   ai-git-auto describes the staged changes, i.e. what 'git commit' would record.")
+	ui.Println("   By default it runs 'git add .' first; with -skip-add it uses what you staged yourself.")
+	if err := os.WriteFile(filepath.Join(dir, "greeting.go"), []byte(tutorialChange), 0o644); err != nil {
+		ui.Fatalf("❌ Failed to edit greeting.go: %v", err)
+	}
+	ui.Println("   ➤ We changed greeting.go so it greets people by name:")
+	if diff, err := tutorialGit(dir, "diff", "--stat"); err == nil {
+		ui.Printf("%s\n", indentLines(diff, "      "))
+	}
+	ask(reader, "Press Enter to stage it with 'git add greeting.go'", "")
+	if _, err := tutorialGit(dir, "add", "greeting.go"); err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to stage greeting.go: %v", err)
+	}
+	changes, err := commenter.ScanStagedChanges()
+	if err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to scan changes: %v", err)
+	}
+	ui.Println("   ✅ Staged")
+	displayChangesSummary(changes)
+
+	// Step 2: generating
+	ui.Println("\n🤖 Step 2: Generating the message")
+	ui.Printf("   The staged diff is sent to the model '%s' on your Ollama server at %s.\n", config.Model, config.OllamaEndpoint)
+	ask(reader, "Press Enter to generate the message", "")
+	suggestion := generateTutorialMessage(commenter, config.Model, changes)
+	displayCommitSuggestion(suggestion)
+	ui.Println("   💡 -n 3 generates three candidates to pick from, and -style switches the conventions")
+
+	// Step 3: editing and committing
+	ui.Println("\n✏️  Step 3: Editing and committing")
+	ui.Println("   The message is a suggestion: before committing you can approve it, copy it,")
+	ui.Println("   leave files out, or rewrite it (the -tui view has an editor key).")
+	if subject := ask(reader, "Type a new subject, or press Enter to keep it", ""); subject != "" {
+		suggestion.Subject = subject
+	}
+	if askYesNo(reader, "Edit the whole message in your editor?", false) {
+		if edited, err := editMessage(suggestion); err != nil {
+			ui.Printf("   ⚠️  %v; keeping the message\n", err)
+		} else {
+			suggestion = edited
+		}
+	}
+	if err := commenter.Commit(suggestion); err != nil {
+		ui.Exitf(exitGitFailed, "❌ Failed to commit: %v", err)
+	}
+	ui.Println("   ✅ Committed. The demo history is now:")
+	if log, err := tutorialGit(dir, "log", "--oneline"); err == nil {
+		ui.Printf("%s\n", indentLines(log, "      "))
+	}
+	ui.Println("   💡 In a real repository ai-git-auto then pushes; -push never or -skip-push keeps commits local")
+
+	// Step 4: hooks
+	ui.Println("\n🪝 Step 4: Using the hook")
+	ui.Println("   If you prefer plain 'git commit', 'ai-git-auto hook install' adds a prepare-commit-msg")
+	ui.Println("   hook: git then opens your editor with a generated message already filled in.")
+	if askYesNo(reader, "Install the hook in the demo repository?", true) {
+		if err := installTutorialHook(dir); err != nil {
+			ui.Printf("   ⚠️  Failed to install the hook: %v\n", err)
+		} else {
+			ui.Println("   ✅ Installed; edit a file there and run 'git commit -a' to see it")
+			ui.Println("   💡 The hook reads the model from your config; set it with 'ai-git-auto init'")
+		}
+	}
+
+	ui.Println("\n🎉 That's the workflow! In your own repositories run 'ai-git-auto', or 'ai-git-auto init' first to save your settings.")
+	if askYesNo(reader, "Keep the demo repository to experiment in?", false) {
+		ui.Printf("   📂 Kept %s\n", dir)
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		ui.Printf("   ⚠️  Failed to remove %s: %v\n", dir, err)
+		return
+	}
+	ui.Println("   🧹 Removed the demo repository")
+}
+
+// generateTutorialMessage generates the message for the demo change,
+// falling back to an example when Ollama or the model is missing so the
+// tutorial can go on
+func generateTutorialMessage(commenter *gitcommenter.GitCommenter, model string, changes []gitcommenter.FileChange) *gitcommenter.CommitSuggestion {
+	example := tutorialExample
+	models, err := commenter.ListAvailableModels()
+	switch {
+	case err != nil:
+		ui.Println("   ⚠️  Ollama is not reachable (start it with: ollama serve); showing an example message instead")
+		return &example
+	case !containsPath(models, model):
+		ui.Printf("   ⚠️  Model '%s' is not pulled (ollama pull %s, or pick one with -model); showing an example message instead\n", model, model)
+		return &example
+	}
+
+	ui.Printf("   ➤ Sending the diff to '%s'...\n", model)
+	suggestion, err := commenter.GenerateCommitMessage(changes)
+	if err != nil {
+		ui.Printf("   ⚠️  Failed to generate the message: %v; showing an example message instead\n", err)
+		return &example
+	}
+	ui.Println("   ✅ Message generated")
+	return suggestion
+}
+
+// createTutorialRepo creates a temporary repository holding tutorialFiles
+// in one commit, with its own author so commits work without a git identity
+func createTutorialRepo() (string, error) {
+	dir, err := os.MkdirTemp("", "ai-git-auto-tutorial-")
+	if err != nil {
+		return "", err
+	}
+	for name, content := range tutorialFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "AI Git Auto Tutorial"},
+		{"config", "user.email", "tutorial@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "."},
+		{"commit", "-q", "-m", "feat: add greeter"},
+	} {
+		if _, err := tutorialGit(dir, args...); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// installTutorialHook writes the prepare-commit-msg hook into the demo
+// repository, as 'ai-git-auto hook install' does in the current one
+func installTutorialHook(dir string) error {
+	hooks, err := tutorialGit(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	if err := os.MkdirAll(hooks, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(hooks, "prepare-commit-msg"), []byte(hookScript()), 0o755)
+}
+
+// tutorialGit runs git in the demo repository and returns its trimmed output
+func tutorialGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// applyWorkflow performs the pre-commit steps of a workflow preset: creating
+// a topic branch and adding a Change-Id trailer
+func applyWorkflow(workflow *gitcommenter.Workflow, suggestion *gitcommenter.CommitSuggestion) error {
+	if workflow.CreateBranch {
+		branch, err := getCurrentBranch()
+		if err == nil && isDefaultBranch(branch) {
+			topic := gitcommenter.BranchName(suggestion.Subject)
+			ui.Printf("   ➤ On %s, creating topic branch %s\n", branch, topic)
+
+			cmd := exec.Command("git", "checkout", "-b", topic)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("failed to create branch %s: %w", topic, err)
+			}
+		}
+	}
+
+	if workflow.ChangeID {
+		suggestion.AddTrailer("Change-Id", gitcommenter.ChangeID(suggestion.Message()))
+	}
+
+	return nil
+}
+
+// pushWithWorkflow pushes using the preset's refspec and upstream settings,
+// falling back to a plain push without a preset
+func pushWithWorkflow(commenter *gitcommenter.GitCommenter, workflow *gitcommenter.Workflow, remote, branch string) error {
+	if workflow == nil || (workflow.PushRefspec == "" && !workflow.SetUpstream) {
+		return commenter.Push()
+	}
+
+	args := []string{"push"}
+	if workflow.SetUpstream {
+		args = append(args, "-u")
+	}
+	args = append(args, remote)
+	if refspec := workflow.Refspec(branch); refspec != "" {
+		args = append(args, refspec)
+	} else {
+		args = append(args, branch)
+	}
+
+	ui.Printf("   ➤ git %s\n", strings.Join(args, " "))
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	event := gitcommenter.Event{Type: gitcommenter.EventPushed, Remote: remote}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	commenter.RecordEvent(event)
+	return err
+}
+
+// pushToRemotes pushes the branch to several remotes in parallel and
+// prints one result per remote. With a preset setting the upstream, the
+// first remote becomes the upstream. It reports whether every push worked.
+func pushToRemotes(commenter *gitcommenter.GitCommenter, workflow *gitcommenter.Workflow, remotes []string, branch string, verbose bool) bool {
+	if branch == "" {
+		ui.Println("   ⚠️  HEAD is detached; push manually to the remotes you need")
+		return false
+	}
+	refspec := branch
+	if workflow != nil && workflow.Refspec(branch) != "" {
+		refspec = workflow.Refspec(branch)
+	}
+
+	ui.Printf("   ➤ Pushing %s to %s in parallel...\n", refspec, strings.Join(remotes, ", "))
+	ok := true
+	for _, result := range commenter.PushToRemotes(remotes, refspec) {
+		if result.Err != nil {
+			ok = false
+			ui.Printf("   ❌ %s: %s\n", result.Remote, pushError(result.Output, result.Err))
+		} else {
+			ui.Printf("   ✅ %s\n", result.Remote)
+		}
+		if verbose && result.Output != "" {
+			ui.Println(indentLines(result.Output, "      "))
+		}
+	}
+
+	if ok && workflow != nil && workflow.SetUpstream && workflow.PushRefspec == "" {
+		cmd := exec.Command("git", "branch", "--set-upstream-to="+remotes[0]+"/"+branch)
+		if err := cmd.Run(); err != nil {
+			ui.Printf("   ⚠️  Could not set the upstream to %s/%s: %v\n", remotes[0], branch, err)
+		}
+	}
+	return ok
+}
+
+// pushError picks the line of git push output explaining a failure
+func pushError(output string, err error) string {
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") || strings.Contains(line, "[rejected]") {
+			return strings.TrimSpace(line)
+		}
+	}
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return err.Error()
+}
+
+// isDefaultBranch reports whether branch is the repository's main branch
+func isDefaultBranch(branch string) bool {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if output, err := cmd.Output(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/") == branch
+	}
+	return branch == "main" || branch == "master"
+}
+
+// pickRemote prefers origin when several remotes are configured
+func pickRemote(remotes []string) string {
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote
+		}
+	}
+	return remotes[0]
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"os"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// Exit codes let wrappers and hooks react without parsing the output. They
+// are documented in the README and shared with ai-git-auto.
+const (
+	exitOK                = 0
+	exitError             = 1 // invalid flags or config, and anything not listed below
+	exitNoChanges         = 2
+	exitOllamaUnreachable = 3
+	exitGenerationFailed  = 4
+	exitAborted           = 5
+	exitGitFailed         = 6
+	exitSecretsFound      = 9 // the changes look like they hold secrets
+)
+
+// exitf logs like log.Fatalf and exits with the given code
+func exitf(code int, format string, a ...any) {
+	log.Printf(format, a...)
+	os.Exit(code)
+}
+
+// generationExitCode tells an unreachable Ollama and changes blocked for
+// possible secrets apart from other generation failures
+func generationExitCode(err error) int {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return exitOllamaUnreachable
+	}
+	var secretsErr *gitcommenter.SecretsError
+	if errors.As(err, &secretsErr) {
+		return exitSecretsFound
+	}
+	return exitGenerationFailed
+}
@@ -3,11 +3,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
 )
@@ -21,26 +23,52 @@ func main() {
 		maxTokens   = flag.Int("max-tokens", 150, "Maximum tokens for response")
 		listModels  = flag.Bool("list-models", false, "List available Ollama models")
 		interactive = flag.Bool("interactive", false, "Interactive mode to approve commit message")
+		profile     = flag.String("profile", "", "Named config profile to use (e.g. work, personal)")
+		output      = flag.String("output", "text", "Output format: text or json")
+		provenance  = flag.Bool("provenance", false, "Add Generated-by and Prompt-hash trailers recording the tool, model and prompt")
+		messageOnly = flag.Bool("message-only", false, "Print only the commit message on stdout; progress goes to stderr")
+		messageFile = flag.String("commit-msg-file", "", "Commit message file checked for an 'ai: off' line, e.g. $1 in a prepare-commit-msg hook")
 	)
 	flag.Parse()
 
+	// JSON output keeps stdout for the result alone
+	if *output != "text" && *output != "json" {
+		log.Fatalf("Invalid output format %q (use text or json)", *output)
+	}
+	jsonOutput := *output == "json"
+	stdout := os.Stdout
+	out := io.Writer(os.Stdout)
+	if jsonOutput {
+		out = io.Discard
+	} else if *messageOnly {
+		// Library warnings are printed too, so all of stdout is redirected
+		os.Stdout = os.Stderr
+		out = os.Stderr
+	}
+
 	// Create configuration
 	config := &gitcommenter.Config{
-		OllamaEndpoint: *endpoint,
-		Model:         *model,
-		MaxTokens:     *maxTokens,
-		Temperature:   *temperature,
-		RepositoryPath: *repoPath,
+		OllamaEndpoint:      *endpoint,
+		Model:               *model,
+		MaxTokens:           *maxTokens,
+		Temperature:         *temperature,
+		RepositoryPath:      *repoPath,
+		MaxSubjectLength:    gitcommenter.DefaultConfig().MaxSubjectLength,
+		AdaptiveTemperature: gitcommenter.DefaultConfig().AdaptiveTemperature,
+		InferTypes:          gitcommenter.DefaultConfig().InferTypes,
+		TrivialMaxLines:     gitcommenter.DefaultTrivialMaxLines,
 	}
+	applyFileConfig(config, *repoPath, *profile, provenance)
 
 	// Create commenter
 	commenter := gitcommenter.New(config)
+	commenter.SetGitBackend(&gitcommenter.ExecBackend{Dir: *repoPath, Stdout: out, Stderr: os.Stderr})
 
 	// List models if requested
 	if *listModels {
 		models, err := commenter.ListAvailableModels()
 		if err != nil {
-			log.Fatalf("Failed to list models: %v", err)
+			exitf(exitOllamaUnreachable, "Failed to list models: %v", err)
 		}
 
 		fmt.Println("Available Ollama models:")
@@ -50,38 +78,77 @@ func main() {
 		return
 	}
 
+	// Repositories, branches and single commits can opt out with "ai: off"
+	reason, err := commenter.OptOutReason(*messageFile)
+	if err != nil {
+		log.Fatalf("Failed to check for an opt-out: %v", err)
+	}
+	if reason != "" {
+		fmt.Fprintf(os.Stderr, "AI commit messages are off: %s\n", reason)
+		return
+	}
+
 	// Get absolute path for better error messages
 	absPath, err := filepath.Abs(*repoPath)
 	if err != nil {
 		log.Fatalf("Invalid repository path: %v", err)
 	}
 
-	fmt.Printf("Scanning staged changes in: %s\n", absPath)
+	fmt.Fprintf(out, "Scanning staged changes in: %s\n", absPath)
 
 	// Scan staged changes
 	changes, err := commenter.ScanStagedChanges()
 	if err != nil {
-		log.Fatalf("Failed to scan changes: %v", err)
+		exitf(exitGitFailed, "Failed to scan changes: %v", err)
 	}
 
 	if len(changes) == 0 {
-		fmt.Println("No staged changes found. Run 'git add .' first to stage your changes.")
-		return
+		fmt.Fprintln(os.Stderr, "No staged changes found. Run 'git add .' first to stage your changes.")
+		os.Exit(exitNoChanges)
 	}
 
 	// Display found changes
-	fmt.Printf("\nFound %d staged file(s):\n", len(changes))
+	fmt.Fprintf(out, "\nFound %d staged file(s):\n", len(changes))
 	for _, change := range changes {
-		fmt.Printf("  %s: %s (+%d -%d lines)\n",
+		fmt.Fprintf(out, "  %s: %s (+%d -%d lines)\n",
 			change.ChangeType, change.FilePath, change.LinesAdded, change.LinesRemoved)
 	}
 
-	fmt.Println("\nGenerating commit message...")
+	// Possible secrets stop the run before they reach the model or a commit,
+	// including those in files listed in .aiignore, which are committed too
+	ignored, err := commenter.ScanIgnoredChanges()
+	if err != nil {
+		exitf(exitGitFailed, "Failed to scan changes: %v", err)
+	}
+	findings, err := commenter.CheckSecrets(append(slices.Clip(changes), ignored...))
+	for _, finding := range findings {
+		fmt.Fprintf(os.Stderr, "Possible secret: %s\n", finding)
+	}
+	if err != nil {
+		exitf(exitSecretsFound, "Refusing to continue: %v (remove them, mark false positives with 'pragma: allowlist secret', or set secret_scan: warn)", err)
+	}
+
+	fmt.Fprintln(out, "\nGenerating commit message...")
 
 	// Generate commit message
+	start := time.Now()
 	suggestion, err := commenter.GenerateCommitMessage(changes)
 	if err != nil {
-		log.Fatalf("Failed to generate commit message: %v", err)
+		exitf(generationExitCode(err), "Failed to generate commit message: %v", err)
+	}
+	if *provenance {
+		suggestion.AddProvenance("git-ai-commit", config.Model)
+	}
+
+	if jsonOutput {
+		if err := writeJSON(os.Stdout, config, suggestion, time.Since(start)); err != nil {
+			log.Fatalf("Failed to write JSON: %v", err)
+		}
+		return
+	}
+	if *messageOnly {
+		fmt.Fprintln(stdout, suggestion.Message())
+		return
 	}
 
 	// Display the suggestion
@@ -105,13 +172,14 @@ func main() {
 		fmt.Scanln(&response)
 
 		if strings.ToLower(response) == "y" || strings.ToLower(response) == "yes" {
-			if err := commitChanges(suggestion.Subject, suggestion.Body, *repoPath); err != nil {
-				log.Fatalf("Failed to commit changes: %v", err)
+			if err := commenter.Commit(suggestion); err != nil {
+				exitf(exitGitFailed, "Failed to commit changes: %v", err)
 			}
 			fmt.Println("✅ Changes committed successfully!")
 		} else {
 			fmt.Println("Commit cancelled. You can manually commit with:")
 			fmt.Printf("git commit -m \"%s\"\n", suggestion.Subject)
+			os.Exit(exitAborted)
 		}
 	} else {
 		fmt.Println("\nTo commit with this message, run:")
@@ -123,17 +191,37 @@ func main() {
 	}
 }
 
-// commitChanges commits the staged changes with the generated message
-func commitChanges(subject, body, repoPath string) error {
-	args := []string{"commit", "-m", subject}
-	if body != "" {
-		args = append(args, "-m", body)
+// applyFileConfig applies the user and repository config files to every
+// option that was not given as a flag
+func applyFileConfig(config *gitcommenter.Config, repoPath, profile string, provenance *bool) {
+	fileConfig, err := gitcommenter.LoadFileConfigs(repoPath, profile)
+	if err != nil {
+		if profile != "" {
+			log.Fatalf("Failed to load profile: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config files: %v\n", err)
+		return
 	}
 
-	cmd := exec.Command("git", args...)
-	cmd.Dir = repoPath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	flagConfig := *config
+	fileConfig.Apply(config)
 
-	return cmd.Run()
+	if explicit["model"] {
+		config.Model = flagConfig.Model
+	}
+	if explicit["endpoint"] {
+		config.OllamaEndpoint = flagConfig.OllamaEndpoint
+	}
+	if explicit["temperature"] {
+		config.Temperature = flagConfig.Temperature
+	}
+	if explicit["max-tokens"] {
+		config.MaxTokens = flagConfig.MaxTokens
+	}
+	if !explicit["provenance"] && fileConfig.Provenance != nil {
+		*provenance = *fileConfig.Provenance
+	}
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// jsonResult is the result printed by -output json
+type jsonResult struct {
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body"`
+	Type       string   `json:"type"`
+	Scope      string   `json:"scope"`
+	Confidence float64  `json:"confidence"`
+	Files      []string `json:"files"`
+	Model      string   `json:"model"`
+	LatencyMS  int64    `json:"latency_ms"`
+}
+
+// writeJSON writes the suggestion as one JSON object. Type and scope are
+// empty unless the subject is a conventional commit subject.
+func writeJSON(w io.Writer, config *gitcommenter.Config, suggestion *gitcommenter.CommitSuggestion, latency time.Duration) error {
+	result := jsonResult{
+		Subject:    suggestion.Subject,
+		Body:       suggestion.Body,
+		Confidence: suggestion.Confidence,
+		Files:      suggestion.FilesAffected,
+		Model:      config.Model,
+		LatencyMS:  latency.Milliseconds(),
+	}
+	if result.Files == nil {
+		result.Files = []string{}
+	}
+
+	// The ticket prefix and gitmoji come before the conventional part
+	subject := strings.TrimSpace(strings.TrimPrefix(suggestion.Subject, config.TicketPrefix))
+	if _, rest, ok := gitcommenter.SplitGitmoji(subject); ok {
+		subject = rest
+	}
+	if parsed, ok := gitcommenter.ParseConventionalSubject(subject); ok {
+		result.Type = parsed.Type
+		result.Scope = strings.Join(parsed.Scopes, ",")
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(result)
+}
@@ -0,0 +1,327 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommitlintRules are the commitlint rules that shape generated messages.
+// Rules at warning level are only used in the prompt; error-level rules
+// are also enforced on the result.
+type CommitlintRules struct {
+	// Source is the file the rules were read from
+	Source string
+	// Types and Scopes come from type-enum and scope-enum
+	Types  []string
+	Scopes []string
+	// HeaderMaxLength and SubjectMaxLength are 0 when unlimited
+	HeaderMaxLength  int
+	SubjectMaxLength int
+	// SubjectCase lists cases the description must use, or must not use
+	// when SubjectCaseNever is set (e.g. "lower-case", "sentence-case")
+	SubjectCase      []string
+	SubjectCaseNever bool
+
+	// enforced holds the names of error-level rules
+	enforced map[string]bool
+}
+
+// commitlintFiles are the configuration files looked up at the repository
+// root, in commitlint's order of precedence
+var commitlintFiles = []string{
+	"package.json",
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc.js",
+	".commitlintrc.cjs",
+	".commitlintrc.mjs",
+	"commitlint.config.js",
+	"commitlint.config.cjs",
+	"commitlint.config.mjs",
+}
+
+// conventionalRules are the rules of @commitlint/config-conventional used
+// here, applied when a configuration extends it
+var conventionalRules = map[string][]any{
+	"type-enum":         {2, "always", []any{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}},
+	"header-max-length": {2, "always", 100},
+	"subject-case":      {2, "never", []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+}
+
+// commitlintFile is the part of a commitlint configuration that is read
+type commitlintFile struct {
+	Extends any              `yaml:"extends" json:"extends"`
+	Rules   map[string][]any `yaml:"rules" json:"rules"`
+}
+
+// LoadCommitlintConfig reads the commitlint configuration at the root of the
+// repository containing repoPath. JSON and YAML files are supported, as well
+// as JavaScript files exporting a plain object literal. It returns nil
+// without an error when the repository has no commitlint configuration.
+func LoadCommitlintConfig(repoPath string) (*CommitlintRules, error) {
+	configPath, err := RepoConfigPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(configPath)
+
+	for _, name := range commitlintFiles {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commitlint config %s: %w", path, err)
+		}
+
+		file, err := parseCommitlintFile(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commitlint config %s: %w", path, err)
+		}
+		if file == nil {
+			continue // package.json without a commitlint section
+		}
+
+		rules := file.rules()
+		rules.Source = path
+		return rules, nil
+	}
+	return nil, nil
+}
+
+var (
+	jsExport        = regexp.MustCompile(`(?s)(?:module\.exports\s*=|export\s+default)\s*(\{.*\})`)
+	jsLineComment   = regexp.MustCompile(`(?m)^\s*//.*$`)
+	jsTrailingComma = regexp.MustCompile(`,(\s*[}\]])`)
+)
+
+// parseCommitlintFile parses one configuration file by its name
+func parseCommitlintFile(name string, data []byte) (*commitlintFile, error) {
+	file := &commitlintFile{}
+	switch {
+	case name == "package.json":
+		var pkg struct {
+			Commitlint *commitlintFile `json:"commitlint"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, err
+		}
+		return pkg.Commitlint, nil
+	case strings.HasSuffix(name, "js"):
+		// A plain object literal is valid YAML flow syntax once comments
+		// and trailing commas are removed
+		match := jsExport.FindSubmatch(data)
+		if match == nil {
+			return nil, fmt.Errorf("no exported object literal found")
+		}
+		literal := jsLineComment.ReplaceAll(match[1], nil)
+		literal = jsTrailingComma.ReplaceAll(literal, []byte("$1"))
+		if err := yaml.Unmarshal(literal, file); err != nil {
+			return nil, err
+		}
+	default:
+		// YAML is a superset of JSON
+		if err := yaml.Unmarshal(data, file); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// rules interprets the configuration, including the rules of
+// @commitlint/config-conventional when it is extended
+func (f *commitlintFile) rules() *CommitlintRules {
+	merged := make(map[string][]any)
+	extends, _ := f.Extends.([]any)
+	if name, ok := f.Extends.(string); ok {
+		extends = []any{name}
+	}
+	for _, name := range extends {
+		if name, _ := name.(string); strings.Contains(name, "config-conventional") {
+			for rule, value := range conventionalRules {
+				merged[rule] = value
+			}
+		}
+	}
+	for rule, value := range f.Rules {
+		merged[rule] = value
+	}
+
+	rules := &CommitlintRules{enforced: make(map[string]bool)}
+	for name, rule := range merged {
+		level, always, value, ok := commitlintRule(rule)
+		if !ok || level == 0 {
+			continue
+		}
+
+		switch name {
+		case "type-enum":
+			if always {
+				rules.Types = anyStrings(value)
+			}
+		case "scope-enum":
+			if always {
+				rules.Scopes = anyStrings(value)
+			}
+		case "header-max-length":
+			rules.HeaderMaxLength = anyInt(value)
+		case "subject-max-length":
+			rules.SubjectMaxLength = anyInt(value)
+		case "subject-case":
+			rules.SubjectCase = anyStrings(value)
+			if cases, ok := value.(string); ok {
+				rules.SubjectCase = []string{cases}
+			}
+			rules.SubjectCaseNever = !always
+		default:
+			continue
+		}
+		if level == 2 {
+			rules.enforced[name] = true
+		}
+	}
+	return rules
+}
+
+// commitlintRule splits a [level, "always"|"never", value] rule
+func commitlintRule(rule []any) (level int, always bool, value any, ok bool) {
+	if len(rule) == 0 {
+		return 0, false, nil, false
+	}
+	level = anyInt(rule[0])
+	always = true
+	if len(rule) > 1 {
+		always = rule[1] != "never"
+	}
+	if len(rule) > 2 {
+		value = rule[2]
+	}
+	return level, always, value, true
+}
+
+// anyInt converts a decoded JSON or YAML number
+func anyInt(value any) int {
+	switch value := value.(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+// anyStrings converts a decoded JSON or YAML list of strings
+func anyStrings(value any) []string {
+	list, _ := value.([]any)
+	var items []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			items = append(items, s)
+		}
+	}
+	return items
+}
+
+// enforcedList returns list when the named rule is at error level, or
+// regardless of the level when prompting
+func (r *CommitlintRules) enforcedList(rule string, list []string, prompting bool) []string {
+	if prompting || r.enforced[rule] {
+		return list
+	}
+	return nil
+}
+
+// promptLines describes the rules for the prompt
+func (r *CommitlintRules) promptLines() []string {
+	var lines []string
+	if r.HeaderMaxLength > 0 {
+		lines = append(lines, fmt.Sprintf("The first line must be at most %d characters", r.HeaderMaxLength))
+	}
+	if r.SubjectMaxLength > 0 {
+		lines = append(lines, fmt.Sprintf("The description after the colon must be at most %d characters", r.SubjectMaxLength))
+	}
+	if len(r.SubjectCase) > 0 {
+		if r.SubjectCaseNever {
+			lines = append(lines, "Do not write the description in "+strings.Join(r.SubjectCase, ", "))
+		} else {
+			lines = append(lines, "Write the description in "+strings.Join(r.SubjectCase, " or "))
+		}
+	}
+	return lines
+}
+
+// validate checks the error-level length and case rules. The header is the
+// full subject line and subject its conventional part, without a ticket
+// prefix or gitmoji. Type and scope enums are checked with the whitelists.
+func (r *CommitlintRules) validate(header, subject string) error {
+	if r.enforced["header-max-length"] && r.HeaderMaxLength > 0 && utf8.RuneCountInString(header) > r.HeaderMaxLength {
+		return fmt.Errorf("subject line is %d characters long (commitlint allows %d)", utf8.RuneCountInString(header), r.HeaderMaxLength)
+	}
+
+	parsed, ok := ParseConventionalSubject(subject)
+	if !ok {
+		return nil
+	}
+	description := parsed.Description
+
+	if r.enforced["subject-max-length"] && r.SubjectMaxLength > 0 && utf8.RuneCountInString(description) > r.SubjectMaxLength {
+		return fmt.Errorf("description is %d characters long (commitlint allows %d)", utf8.RuneCountInString(description), r.SubjectMaxLength)
+	}
+	if r.enforced["subject-case"] && len(r.SubjectCase) > 0 {
+		matched := ""
+		for _, name := range r.SubjectCase {
+			if hasCase(description, name) {
+				matched = name
+				break
+			}
+		}
+		if r.SubjectCaseNever && matched != "" {
+			return fmt.Errorf("description %q must not be %s", description, matched)
+		}
+		if !r.SubjectCaseNever && matched == "" {
+			return fmt.Errorf("description %q must be %s", description, strings.Join(r.SubjectCase, " or "))
+		}
+	}
+	return nil
+}
+
+// hasCase reports whether s is written in the named commitlint case
+func hasCase(s, name string) bool {
+	first, _ := utf8.DecodeRuneInString(s)
+	words := strings.Fields(s)
+	switch name {
+	case "lower-case", "lowercase":
+		return s == strings.ToLower(s)
+	case "upper-case", "uppercase":
+		return s == strings.ToUpper(s)
+	case "sentence-case", "sentencecase":
+		return unicode.IsUpper(first)
+	case "start-case", "startcase":
+		for _, word := range words {
+			if r, _ := utf8.DecodeRuneInString(word); !unicode.IsUpper(r) {
+				return false
+			}
+		}
+		return len(words) > 0
+	case "pascal-case", "pascalcase":
+		return len(words) == 1 && unicode.IsUpper(first) && !strings.ContainsAny(s, "-_")
+	case "camel-case", "camelcase":
+		return len(words) == 1 && unicode.IsLower(first) && !strings.ContainsAny(s, "-_")
+	case "kebab-case", "kebabcase":
+		return len(words) == 1 && s == strings.ToLower(s) && !strings.Contains(s, "_")
+	case "snake-case", "snakecase":
+		return len(words) == 1 && s == strings.ToLower(s) && !strings.Contains(s, "-")
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCommitlintConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{".commitlintrc.json", `{"rules": {"type-enum": [2, "always", ["feat", "fix"]], "header-max-length": [2, "always", 72], "subject-case": [2, "always", "lower-case"]}}`},
+		{".commitlintrc.yaml", "rules:\n  type-enum: [2, always, [feat, fix]]\n  header-max-length: [2, always, 72]\n  subject-case: [2, always, lower-case]\n"},
+		{"commitlint.config.js", "// Lint commit messages\nmodule.exports = {\n  rules: {\n    'type-enum': [2, 'always', ['feat', 'fix']],\n    'header-max-length': [2, 'always', 72],\n    'subject-case': [2, 'always', 'lower-case'],\n  },\n};\n"},
+		{"package.json", `{"name": "app", "commitlint": {"rules": {"type-enum": [2, "always", ["feat", "fix"]], "header-max-length": [2, "always", 72], "subject-case": [2, "always", "lower-case"]}}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := initTestRepo(t)
+			if err := os.WriteFile(filepath.Join(repo, test.name), []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			rules, err := LoadCommitlintConfig(repo)
+			if err != nil {
+				t.Fatalf("LoadCommitlintConfig returned error: %v", err)
+			}
+			if rules == nil || strings.Join(rules.Types, ",") != "feat,fix" || rules.HeaderMaxLength != 72 ||
+				strings.Join(rules.SubjectCase, ",") != "lower-case" || rules.SubjectCaseNever {
+				t.Errorf("Unexpected rules %+v", rules)
+			}
+		})
+	}
+
+	// No configuration at all is not an error
+	if rules, err := LoadCommitlintConfig(initTestRepo(t)); rules != nil || err != nil {
+		t.Errorf("Expected no rules, got %+v (error %v)", rules, err)
+	}
+}
+
+func TestCommitlintConventionalDefaults(t *testing.T) {
+	file, err := parseCommitlintFile(".commitlintrc.yml", []byte("extends: ['@commitlint/config-conventional']\nrules:\n  header-max-length: [1, always, 60]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := file.rules()
+	if !containsString(rules.Types, "perf") || rules.HeaderMaxLength != 60 || !rules.SubjectCaseNever {
+		t.Errorf("Expected config-conventional rules with an override, got %+v", rules)
+	}
+
+	tests := []struct {
+		header string
+		valid  bool
+	}{
+		{"feat: add init wizard", true},
+		{"feat: Add init wizard", false},
+		{"feat: ADD INIT WIZARD", false},
+		// header-max-length was lowered to a warning
+		{"feat: " + strings.Repeat("x", 80), true},
+	}
+	for _, test := range tests {
+		if err := rules.validate(test.header, test.header); (err == nil) != test.valid {
+			t.Errorf("validate(%q) = %v, want valid=%v", test.header, err, test.valid)
+		}
+	}
+}
+
+func TestCommitlintWhitelist(t *testing.T) {
+	file, _ := parseCommitlintFile(".commitlintrc", []byte(`{"rules": {"type-enum": [2, "always", ["feat"]], "scope-enum": [1, "always", ["cli"]]}}`))
+	config := DefaultConfig()
+	config.Commitlint = file.rules()
+	gc := New(config)
+	style, _ := LookupStyle("")
+
+	if err := gc.validateSuggestion(style, &CommitSuggestion{Subject: "fix: crash"}); err == nil {
+		t.Error("Expected the commitlint type-enum to be enforced")
+	}
+	// scope-enum is a warning: prompted but not enforced
+	if err := gc.validateSuggestion(style, &CommitSuggestion{Subject: "feat(api): add x"}); err != nil {
+		t.Errorf("Expected warning-level scope-enum to pass, got %v", err)
+	}
+	if prompt := gc.buildPrompt("", nil); !strings.Contains(prompt, "Allowed scopes: cli") {
+		t.Error("Expected the commitlint scopes in the prompt")
+	}
+}
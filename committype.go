@@ -0,0 +1,89 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InferTypes returns the conventional commit types that fit changes by
+// their paths alone, the best first, and why: "test" when every file is a
+// test, "docs" when every file is documentation, and "ci" or "chore" when
+// every file is CI or other configuration. Other changes return nil, as
+// any type may describe them.
+func InferTypes(changes []FileChange) ([]string, string) {
+	if len(changes) == 0 {
+		return nil, ""
+	}
+
+	all := func(match func(string) bool) bool {
+		for _, change := range changes {
+			if !match(change.FilePath) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Tests come first: test fixtures are often text files
+	switch {
+	case all(isTestFile):
+		return []string{"test"}, "every changed file is a test"
+	case all(isDocFile):
+		return []string{"docs"}, "every changed file is documentation"
+	case all(isCIFile):
+		return []string{"ci", "chore"}, "every changed file is CI configuration"
+	case all(func(p string) bool { return (isCIFile(p) || isConfigFile(p)) && !isDependencyFile(p) }):
+		return []string{"chore", "ci"}, "every changed file is configuration"
+	}
+	return nil, ""
+}
+
+// inferTypes returns the types InferTypes finds for the changes that the
+// whitelists allow, when Config.InferTypes is set. A whitelist allowing
+// none of them wins.
+func (gc *GitCommenter) inferTypes(changes []FileChange) ([]string, string) {
+	if !gc.config.InferTypes {
+		return nil, ""
+	}
+	inferred, reason := InferTypes(changes)
+	allowed := gc.allowedTypes(false)
+	var types []string
+	for _, typ := range inferred {
+		if len(allowed) == 0 || containsString(allowed, typ) {
+			types = append(types, typ)
+		}
+	}
+	if len(types) == 0 {
+		return nil, ""
+	}
+	return types, reason
+}
+
+// forceType replaces the type of a conventional subject like forceScope
+func forceType(subject, ticketPrefix, typ string) string {
+	prefix, rest := splitSubjectPrefix(subject, ticketPrefix)
+	parsed, ok := ParseConventionalSubject(rest)
+	if !ok {
+		return subject
+	}
+	scope, breaking := "", ""
+	if len(parsed.Scopes) > 0 {
+		scope = "(" + strings.Join(parsed.Scopes, ",") + ")"
+	}
+	if parsed.Breaking {
+		breaking = "!"
+	}
+	rest = fmt.Sprintf("%s%s%s: %s", typ, scope, breaking, parsed.Description)
+	return strings.Join(append(prefix, rest), " ")
+}
+
+// checkType reports a conventional subject whose type is not among types.
+// Other subjects are left to validateSuggestion.
+func checkType(subject, ticketPrefix string, types []string, reason string) error {
+	_, rest := splitSubjectPrefix(subject, ticketPrefix)
+	parsed, ok := ParseConventionalSubject(rest)
+	if !ok || containsString(types, parsed.Type) {
+		return nil
+	}
+	return fmt.Errorf("type %q does not fit the changes, as %s (use %s)", parsed.Type, reason, strings.Join(types, " or "))
+}
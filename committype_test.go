@@ -0,0 +1,120 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInferTypes(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"client_test.go", "testdata/prompts/basic.golden", "src/app.spec.ts"}, "test"},
+		{[]string{"testdata/input.txt"}, "test"},
+		{[]string{"README.md", "docs/guide/setup.html"}, "docs"},
+		{[]string{".github/workflows/test.yml", ".gitlab-ci.yml"}, "ci,chore"},
+		{[]string{".github/workflows/test.yml", ".editorconfig", "config/app.yaml"}, "chore,ci"},
+		{[]string{".github/workflows/test.yml", "package.json"}, ""},
+		{[]string{"client.go", "client_test.go"}, ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		var changes []FileChange
+		for _, file := range test.files {
+			changes = append(changes, FileChange{FilePath: file, ChangeType: "modified"})
+		}
+		types, reason := InferTypes(changes)
+		if got := strings.Join(types, ","); got != test.want || (got == "") != (reason == "") {
+			t.Errorf("InferTypes(%v) = %q, %q, want %q", test.files, got, reason, test.want)
+		}
+	}
+}
+
+func TestInferTypesRespectsWhitelist(t *testing.T) {
+	changes := []FileChange{{FilePath: ".github/workflows/test.yml", ChangeType: "modified"}}
+	config := DefaultConfig()
+	config.Types = []string{"feat", "fix", "chore"}
+	gc := New(config)
+	if types, _ := gc.inferTypes(changes); strings.Join(types, ",") != "chore" {
+		t.Errorf("Expected only the allowed chore type, got %v", types)
+	}
+
+	config.Types = []string{"feat", "fix"}
+	if types, _ := gc.inferTypes(changes); types != nil {
+		t.Errorf("Expected no type when the whitelist allows none, got %v", types)
+	}
+
+	config.Types, config.InferTypes = nil, false
+	if types, _ := gc.inferTypes(changes); types != nil {
+		t.Errorf("Expected no type when disabled, got %v", types)
+	}
+}
+
+func TestForceType(t *testing.T) {
+	tests := []struct {
+		subject string
+		ticket  string
+		want    string
+	}{
+		{"feat: add retry tests", "", "test: add retry tests"},
+		{"fix(api,cli)!: cover flags", "", "test(api,cli)!: cover flags"},
+		{"PROJ-1 ✨ feat(ui): add tests", "PROJ-1", "PROJ-1 ✨ test(ui): add tests"},
+		{"Add tests", "", "Add tests"},
+	}
+	for _, test := range tests {
+		if got := forceType(test.subject, test.ticket, "test"); got != test.want {
+			t.Errorf("forceType(%q) = %q, want %q", test.subject, got, test.want)
+		}
+	}
+}
+
+func TestGenerateCommitMessageInfersType(t *testing.T) {
+	responses := []string{"feat: run tests on pull requests"}
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Prompt)
+		response := responses[min(len(prompts), len(responses))-1]
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	// Tests only: the type is replaced
+	tests := []FileChange{
+		{FilePath: "client_test.go", ChangeType: "modified", Diff: "+func TestRetry(t *testing.T) {}\n", LinesAdded: 1},
+		{FilePath: "server_test.go", ChangeType: "modified", Diff: "+func TestServe(t *testing.T) {}\n", LinesAdded: 1},
+	}
+	suggestion, err := gc.GenerateCommitMessage(tests)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if suggestion.Subject != "test: run tests on pull requests" || !strings.Contains(prompts[0], "Use the type test, as every changed file is a test") {
+		t.Errorf("Expected the test type to be forced, got %q", suggestion.Subject)
+	}
+
+	// CI only: a type other than ci or chore is requested again
+	responses, prompts = []string{"feat: run tests on pull requests", "ci: run tests on pull requests"}, nil
+	workflows := []FileChange{
+		{FilePath: ".github/workflows/test.yml", ChangeType: "modified", Diff: "+on: [pull_request]\n", LinesAdded: 1},
+		{FilePath: ".github/workflows/lint.yml", ChangeType: "modified", Diff: "+on: [pull_request]\n", LinesAdded: 1},
+	}
+	suggestion, err = gc.GenerateCommitMessage(workflows)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if len(prompts) != 2 || suggestion.Subject != "ci: run tests on pull requests" || len(suggestion.Warnings) != 0 {
+		t.Errorf("Expected a ci message on the second call, got %+v after %d calls", suggestion, len(prompts))
+	}
+	if !strings.Contains(prompts[1], `type "feat" does not fit the changes, as every changed file is CI configuration (use ci or chore)`) {
+		t.Errorf("Expected the retry prompt to explain the type, got:\n%s", prompts[1])
+	}
+}
@@ -0,0 +1,1069 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the repository-level config file
+const ConfigFileName = ".ai-git-commit.yaml"
+
+// FileConfig is the on-disk representation of the user and repository
+// config files. Empty fields are left out when the file is written.
+type FileConfig struct {
+	// Model is the Ollama model to use
+	Model string `yaml:"model,omitempty"`
+	// Endpoint is the Ollama API endpoint
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Temperature controls randomness in the response
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	// MaxTokens is the maximum number of tokens for the response
+	MaxTokens int `yaml:"max_tokens,omitempty"`
+	// Style is the commit message style, one of StyleNames()
+	Style string `yaml:"style,omitempty"`
+	// TicketPrefix is prepended to generated subjects, e.g. "PROJ-123"
+	TicketPrefix string `yaml:"ticket_prefix,omitempty"`
+	// Anonymize hides identifiers and string literals from the model host
+	Anonymize *bool `yaml:"anonymize,omitempty"`
+	// Language is the language of generated messages, e.g. "de", or "auto"
+	// to follow the recent commits
+	Language string `yaml:"language,omitempty"`
+	// Sign makes commits GPG/SSH signed (git commit -S)
+	Sign *bool `yaml:"sign,omitempty"`
+	// Provenance adds Generated-by and Prompt-hash trailers to messages
+	Provenance *bool `yaml:"provenance,omitempty"`
+	// GitHubAPI is the GitHub REST API used for forge features, e.g. a
+	// GitHub Enterprise server's https://github.example.com/api/v3
+	GitHubAPI string `yaml:"github_api,omitempty"`
+	// GitLabAPI is the GitLab REST API used for merge requests, e.g.
+	// https://gitlab.example.com/api/v4
+	GitLabAPI string `yaml:"gitlab_api,omitempty"`
+	// EmbeddingModel is the Ollama model used to find past commits similar
+	// to the changes, e.g. nomic-embed-text
+	EmbeddingModel string `yaml:"embedding_model,omitempty"`
+	// DependencyRisk adds a release-notes-based risk summary to messages of
+	// dependency updates
+	DependencyRisk *bool `yaml:"dependency_risk,omitempty"`
+	// AI is "off" to turn generation off for the repository
+	AI string `yaml:"ai,omitempty"`
+	// NoEmoji replaces emoji in the CLI output with plain text
+	NoEmoji *bool `yaml:"no_emoji,omitempty"`
+	// Push is the push behavior after committing: "always", "ask" or "never"
+	Push string `yaml:"push,omitempty"`
+	// PushRemotes are the remotes pushed to in parallel, e.g. origin and a
+	// mirror; empty pushes to origin (or the only remote)
+	PushRemotes []string `yaml:"push_remotes,omitempty"`
+	// Journal is where events are written for dashboards and editors: a
+	// file, "unix:<path>" or "tcp:<host:port>"
+	Journal string `yaml:"journal,omitempty"`
+	// Workflow is the name of a workflow preset ("trunk", "pr-flow", "gerrit")
+	Workflow string `yaml:"workflow,omitempty"`
+	// Cache selects the response cache: "fs[:dir]", "sqlite[:path]" or a
+	// redis:// URL. Empty disables caching.
+	Cache string `yaml:"cache,omitempty"`
+	// PromptTemplate is the path of a text/template file for the prompt
+	PromptTemplate string `yaml:"prompt_template,omitempty"`
+	// Exclude lists glob patterns of files left out of the prompt
+	Exclude []string `yaml:"exclude,omitempty"`
+	// Types and Scopes restrict conventional commit types and scopes
+	Types  []string `yaml:"types,omitempty"`
+	Scopes []string `yaml:"scopes,omitempty"`
+	// ScopeMap derives the scope from the changed paths, e.g. "cmd/** -> cli"
+	ScopeMap []string `yaml:"scope_map,omitempty"`
+	// ManualSections are body sections the author fills in, e.g. "Testing done:"
+	ManualSections []string `yaml:"manual_sections,omitempty"`
+	// MaxSubjectLength is the longest accepted subject (e.g. 50 or 72)
+	MaxSubjectLength int `yaml:"max_subject_length,omitempty"`
+	// MaxPromptBytes, MaxPromptFiles and MaxFileBytes bound the diffs shown
+	// to the model in total, in files and per file
+	MaxPromptBytes int `yaml:"max_prompt_bytes,omitempty"`
+	MaxPromptFiles int `yaml:"max_prompt_files,omitempty"`
+	MaxFileBytes   int `yaml:"max_file_bytes,omitempty"`
+	// ContextWindow is the model's context window in tokens the prompt is
+	// fitted to, or ContextWindowAuto (-1) to read it from the model
+	ContextWindow int `yaml:"context_window,omitempty"`
+	// AdaptiveTemperature scales the temperature by the kind of change
+	AdaptiveTemperature *bool `yaml:"adaptive_temperature,omitempty"`
+	// InferTypes constrains the commit type of test, docs and configuration
+	// changes
+	InferTypes *bool `yaml:"infer_types,omitempty"`
+	// TrivialMaxLines is the most changed lines a trivial change may have
+	// to get a template message without the model; 0 always uses the model
+	TrivialMaxLines *int `yaml:"trivial_max_lines,omitempty"`
+	// HistoryExamples is how many recent commits are searched for example
+	// messages; 0 leaves them out
+	HistoryExamples *int `yaml:"history_examples,omitempty"`
+	// HistoryContext shows the subjects of the last commits touching the
+	// changed files (Config.IncludeHistoryContext)
+	HistoryContext *bool `yaml:"history_context,omitempty"`
+	// CountExcluded tells the model how many files and lines were left out
+	// of the prompt (Config.CountExcluded)
+	CountExcluded *bool `yaml:"count_excluded,omitempty"`
+	// SummarizeFiles summarizes the files whose diffs do not fit in the
+	// prompt (Config.SummarizeFiles)
+	SummarizeFiles *bool `yaml:"summarize_files,omitempty"`
+	// TreeSitter lists the changed symbols of non-Go files in the prompt
+	// (see SymbolAnalyzer)
+	TreeSitter *bool `yaml:"tree_sitter,omitempty"`
+	// SecretScan is block, warn or off (Config.SecretScan)
+	SecretScan string `yaml:"secret_scan,omitempty"`
+	// Redact masks parts of the diffs before they reach the model
+	// (Config.Redact). Rules from all config files apply.
+	Redact []RedactionRule `yaml:"redact,omitempty"`
+	// Hooks are shell commands run around the commit
+	Hooks HookConfig `yaml:"hooks,omitempty"`
+	// Gitmoji prefixes subjects with a gitmoji on top of the style
+	Gitmoji *bool `yaml:"gitmoji,omitempty"`
+	// SigningKey selects the key used when Sign is set (git commit -S<key>)
+	SigningKey string `yaml:"signing_key,omitempty"`
+	// Profile selects one of Profiles by default
+	Profile string `yaml:"profile,omitempty"`
+	// Profiles are named sets of settings (e.g. "work", "personal") applied
+	// on top of the rest of the file when selected
+	Profiles map[string]*FileConfig `yaml:"profiles,omitempty"`
+}
+
+// HookConfig lists shell commands run by the CLI before and after
+// committing. Hooks from the repository config file only run in
+// repositories the user trusts (see TrustHooksKey).
+type HookConfig struct {
+	// PreCommit commands run before committing; a failure aborts the commit
+	PreCommit []string `yaml:"pre_commit,omitempty"`
+	// PostCommit commands run after a successful commit
+	PostCommit []string `yaml:"post_commit,omitempty"`
+}
+
+// TrustHooksKey is the git config key that lets the hooks of a
+// repository's config file run, e.g. "git config ai-commit.trust-hooks
+// true". The file comes with a clone; git config does not.
+const TrustHooksKey = GitConfigSection + "." + trustHooksName
+
+// trustHooksName is the name of TrustHooksKey in the ai-commit section
+const trustHooksName = "trust-hooks"
+
+// RepoHooksTrusted reports whether the git config visible from repoPath
+// lets the hooks of the repository config file run
+func RepoHooksTrusted(repoPath string) bool {
+	cmd := exec.Command("git", "config", "--bool", "--get", TrustHooksKey)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// RepoHooksIgnored reports whether the repository config file has hooks
+// that do not run because the repository is not trusted
+func RepoHooksIgnored(repoPath string) bool {
+	path, err := RepoConfigPath(repoPath)
+	if err != nil {
+		return false
+	}
+	fc, err := LoadConfigFile(path)
+	return err == nil && fc.dropHooks() && !RepoHooksTrusted(repoPath)
+}
+
+// dropHooks removes the hooks of fc and its profiles, reporting whether
+// there were any
+func (fc *FileConfig) dropHooks() bool {
+	dropped := len(fc.Hooks.PreCommit) > 0 || len(fc.Hooks.PostCommit) > 0
+	fc.Hooks = HookConfig{}
+	for _, profile := range fc.Profiles {
+		if profile != nil && profile.dropHooks() {
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// UserConfigPath returns the path of the per-user config file
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "ai-git-commit", "config.yaml"), nil
+}
+
+// RepoConfigPath returns the path of the config file at the root of the
+// repository containing repoPath
+func RepoConfigPath(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(output)), ConfigFileName), nil
+}
+
+// LoadConfigFile reads a config file. A missing file yields an empty config.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	fc := &FileConfig{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return fc, nil
+}
+
+// ConfigLayer is one source of settings, such as a config file
+type ConfigLayer struct {
+	// Source describes where the settings came from, e.g. a file path
+	Source string
+	// Config holds the settings defined by this source
+	Config *FileConfig
+}
+
+// LoadConfigLayers loads every non-flag source of settings in increasing
+// order of precedence: the user config file, the repository config file,
+// the ai-commit.* git config, the selected profile and AI_COMMIT_*
+// environment variables. An empty profile uses the "profile" setting.
+func LoadConfigLayers(repoPath, profile string) ([]ConfigLayer, error) {
+	return loadConfigLayers(repoPath, profile, true)
+}
+
+// LoadUntrustedConfigLayers loads the layers of LoadConfigLayers except
+// the repository config file, for analyzing repositories the user does
+// not trust: whoever wrote the file could otherwise choose the endpoint
+// the diffs are sent to, turn off the secret scan or read any file as the
+// prompt template.
+func LoadUntrustedConfigLayers(repoPath, profile string) ([]ConfigLayer, error) {
+	return loadConfigLayers(repoPath, profile, false)
+}
+
+// loadConfigLayers implements LoadConfigLayers, reading the repository
+// config file if trusted
+func loadConfigLayers(repoPath, profile string, trusted bool) ([]ConfigLayer, error) {
+	var layers []ConfigLayer
+
+	if userPath, err := UserConfigPath(); err == nil {
+		userConfig, err := LoadConfigFile(userPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, ConfigLayer{Source: userPath, Config: userConfig})
+	}
+
+	if repoConfigPath, err := RepoConfigPath(repoPath); err == nil && trusted {
+		repoConfig, err := LoadConfigFile(repoConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		// The file is committed with the repository, so whoever wrote it
+		// must not run commands on every machine that clones it
+		if !RepoHooksTrusted(repoPath) {
+			repoConfig.dropHooks()
+		}
+		layers = append(layers, ConfigLayer{Source: repoConfigPath, Config: repoConfig})
+	}
+
+	gitConfig, err := LoadGitConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, ConfigLayer{Source: "git config", Config: gitConfig})
+
+	envConfig, err := LoadEnvConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile can be chosen by any layer, but is applied below the
+	// environment so AI_COMMIT_* still overrides individual settings
+	merged := &FileConfig{}
+	for _, layer := range append(layers, ConfigLayer{Config: envConfig}) {
+		merged.Merge(layer.Config)
+	}
+	if profile == "" {
+		profile = merged.Profile
+	}
+	if profile != "" {
+		profileConfig, ok := merged.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(merged.ProfileNames(), ", "))
+		}
+		profileConfig.Profile = profile
+		layers = append(layers, ConfigLayer{Source: "profile " + profile, Config: profileConfig})
+	}
+
+	layers = append(layers, ConfigLayer{Source: "environment", Config: envConfig})
+
+	return layers, nil
+}
+
+// ProfileNames returns the names of the defined profiles in sorted order
+func (fc *FileConfig) ProfileNames() []string {
+	names := make([]string, 0, len(fc.Profiles))
+	for name := range fc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadFileConfigs merges all layers returned by LoadConfigLayers
+func LoadFileConfigs(repoPath, profile string) (*FileConfig, error) {
+	return mergeLayers(LoadConfigLayers(repoPath, profile))
+}
+
+// LoadUntrustedFileConfigs merges all layers returned by
+// LoadUntrustedConfigLayers
+func LoadUntrustedFileConfigs(repoPath, profile string) (*FileConfig, error) {
+	return mergeLayers(LoadUntrustedConfigLayers(repoPath, profile))
+}
+
+// mergeLayers merges the loaded layers in order
+func mergeLayers(layers []ConfigLayer, err error) (*FileConfig, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &FileConfig{}
+	for _, layer := range layers {
+		merged.Merge(layer.Config)
+	}
+	return merged, nil
+}
+
+// EnvVarName returns the environment variable for a setting, e.g.
+// "max-tokens" becomes AI_COMMIT_MAX_TOKENS
+func EnvVarName(key string) string {
+	return "AI_COMMIT_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// LoadEnvConfig reads settings from AI_COMMIT_* environment variables
+func LoadEnvConfig() (*FileConfig, error) {
+	fc := &FileConfig{}
+	for _, key := range ConfigKeys() {
+		if value, ok := os.LookupEnv(EnvVarName(key)); ok && value != "" {
+			if err := fc.Set(key, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", EnvVarName(key), err)
+			}
+		}
+	}
+	return fc, nil
+}
+
+// DefaultValues returns the built-in default of every setting by key
+func DefaultValues() map[string]string {
+	config := DefaultConfig()
+	return map[string]string{
+		"model":                config.Model,
+		"endpoint":             config.OllamaEndpoint,
+		"temperature":          strconv.FormatFloat(config.Temperature, 'f', -1, 64),
+		"max-tokens":           strconv.Itoa(config.MaxTokens),
+		"style":                config.Style,
+		"ticket-prefix":        "",
+		"language":             "en",
+		"anonymize":            "false",
+		"sign":                 "false",
+		"provenance":           "false",
+		"no-emoji":             "false",
+		"ai":                   "on",
+		"github-api":           config.GitHubAPI,
+		"gitlab-api":           "",
+		"embedding-model":      "",
+		"dependency-risk":      "false",
+		"gitmoji":              "false",
+		"push":                 "ask",
+		"push-remotes":         "",
+		"journal":              "",
+		"workflow":             "",
+		"cache":                "",
+		"prompt-template":      "",
+		"exclude":              "",
+		"types":                "",
+		"scopes":               "",
+		"signing-key":          "",
+		"profile":              "",
+		"scope-map":            "",
+		"manual-sections":      "",
+		"max-subject-length":   strconv.Itoa(config.MaxSubjectLength),
+		"max-prompt-bytes":     strconv.Itoa(config.MaxPromptBytes),
+		"max-prompt-files":     strconv.Itoa(config.MaxPromptFiles),
+		"max-file-bytes":       strconv.Itoa(config.MaxFileBytes),
+		"context-window":       strconv.Itoa(config.ContextWindow),
+		"adaptive-temperature": strconv.FormatBool(config.AdaptiveTemperature),
+		"infer-types":          strconv.FormatBool(config.InferTypes),
+		"trivial-max-lines":    strconv.Itoa(config.TrivialMaxLines),
+		"history-examples":     strconv.Itoa(config.HistoryExamples),
+		"history-context":      strconv.FormatBool(config.IncludeHistoryContext),
+		"count-excluded":       strconv.FormatBool(config.CountExcluded),
+		"summarize-files":      strconv.FormatBool(config.SummarizeFiles),
+		"tree-sitter":          "false",
+		"secret-scan":          SecretScanBlock,
+	}
+}
+
+// Values returns the settings defined in fc as strings keyed like ConfigKeys
+func (fc *FileConfig) Values() map[string]string {
+	values := make(map[string]string)
+	if fc.Model != "" {
+		values["model"] = fc.Model
+	}
+	if fc.Endpoint != "" {
+		values["endpoint"] = fc.Endpoint
+	}
+	if fc.Temperature != nil {
+		values["temperature"] = strconv.FormatFloat(*fc.Temperature, 'f', -1, 64)
+	}
+	if fc.MaxTokens != 0 {
+		values["max-tokens"] = strconv.Itoa(fc.MaxTokens)
+	}
+	if fc.Style != "" {
+		values["style"] = fc.Style
+	}
+	if fc.Language != "" {
+		values["language"] = fc.Language
+	}
+	if fc.Anonymize != nil {
+		values["anonymize"] = strconv.FormatBool(*fc.Anonymize)
+	}
+	if fc.TicketPrefix != "" {
+		values["ticket-prefix"] = fc.TicketPrefix
+	}
+	if fc.Sign != nil {
+		values["sign"] = strconv.FormatBool(*fc.Sign)
+	}
+	if fc.Provenance != nil {
+		values["provenance"] = strconv.FormatBool(*fc.Provenance)
+	}
+	if fc.NoEmoji != nil {
+		values["no-emoji"] = strconv.FormatBool(*fc.NoEmoji)
+	}
+	if fc.AI != "" {
+		values["ai"] = fc.AI
+	}
+	if fc.GitHubAPI != "" {
+		values["github-api"] = fc.GitHubAPI
+	}
+	if fc.GitLabAPI != "" {
+		values["gitlab-api"] = fc.GitLabAPI
+	}
+	if fc.EmbeddingModel != "" {
+		values["embedding-model"] = fc.EmbeddingModel
+	}
+	if fc.DependencyRisk != nil {
+		values["dependency-risk"] = strconv.FormatBool(*fc.DependencyRisk)
+	}
+	if fc.Push != "" {
+		values["push"] = fc.Push
+	}
+	if len(fc.PushRemotes) > 0 {
+		values["push-remotes"] = strings.Join(fc.PushRemotes, ",")
+	}
+	if fc.Journal != "" {
+		values["journal"] = fc.Journal
+	}
+	if fc.Workflow != "" {
+		values["workflow"] = fc.Workflow
+	}
+	if fc.Cache != "" {
+		values["cache"] = fc.Cache
+	}
+	if fc.PromptTemplate != "" {
+		values["prompt-template"] = fc.PromptTemplate
+	}
+	if len(fc.Exclude) > 0 {
+		values["exclude"] = strings.Join(fc.Exclude, ",")
+	}
+	if len(fc.Types) > 0 {
+		values["types"] = strings.Join(fc.Types, ",")
+	}
+	if len(fc.Scopes) > 0 {
+		values["scopes"] = strings.Join(fc.Scopes, ",")
+	}
+	if len(fc.ScopeMap) > 0 {
+		values["scope-map"] = strings.Join(fc.ScopeMap, ",")
+	}
+	if len(fc.ManualSections) > 0 {
+		values["manual-sections"] = strings.Join(fc.ManualSections, ",")
+	}
+	if fc.MaxSubjectLength != 0 {
+		values["max-subject-length"] = strconv.Itoa(fc.MaxSubjectLength)
+	}
+	if fc.MaxPromptBytes != 0 {
+		values["max-prompt-bytes"] = strconv.Itoa(fc.MaxPromptBytes)
+	}
+	if fc.MaxPromptFiles != 0 {
+		values["max-prompt-files"] = strconv.Itoa(fc.MaxPromptFiles)
+	}
+	if fc.MaxFileBytes != 0 {
+		values["max-file-bytes"] = strconv.Itoa(fc.MaxFileBytes)
+	}
+	if fc.ContextWindow != 0 {
+		values["context-window"] = strconv.Itoa(fc.ContextWindow)
+	}
+	if fc.AdaptiveTemperature != nil {
+		values["adaptive-temperature"] = strconv.FormatBool(*fc.AdaptiveTemperature)
+	}
+	if fc.InferTypes != nil {
+		values["infer-types"] = strconv.FormatBool(*fc.InferTypes)
+	}
+	if fc.TrivialMaxLines != nil {
+		values["trivial-max-lines"] = strconv.Itoa(*fc.TrivialMaxLines)
+	}
+	if fc.HistoryExamples != nil {
+		values["history-examples"] = strconv.Itoa(*fc.HistoryExamples)
+	}
+	if fc.HistoryContext != nil {
+		values["history-context"] = strconv.FormatBool(*fc.HistoryContext)
+	}
+	if fc.CountExcluded != nil {
+		values["count-excluded"] = strconv.FormatBool(*fc.CountExcluded)
+	}
+	if fc.SummarizeFiles != nil {
+		values["summarize-files"] = strconv.FormatBool(*fc.SummarizeFiles)
+	}
+	if fc.TreeSitter != nil {
+		values["tree-sitter"] = strconv.FormatBool(*fc.TreeSitter)
+	}
+	if fc.SecretScan != "" {
+		values["secret-scan"] = fc.SecretScan
+	}
+	if fc.Gitmoji != nil {
+		values["gitmoji"] = strconv.FormatBool(*fc.Gitmoji)
+	}
+	if fc.SigningKey != "" {
+		values["signing-key"] = fc.SigningKey
+	}
+	if fc.Profile != "" {
+		values["profile"] = fc.Profile
+	}
+	return values
+}
+
+// EffectiveSetting is a resolved setting and the source it came from
+type EffectiveSetting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// ResolveSettings resolves every setting across the layers (in increasing
+// precedence) on top of the defaults, recording where each value came from
+func ResolveSettings(layers []ConfigLayer) []EffectiveSetting {
+	defaults := DefaultValues()
+
+	var settings []EffectiveSetting
+	for _, key := range ConfigKeys() {
+		setting := EffectiveSetting{Key: key, Value: defaults[key], Source: "default"}
+		for _, layer := range layers {
+			if value, ok := layer.Config.Values()[key]; ok {
+				setting.Value = value
+				setting.Source = layer.Source
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// splitCommaList splits a comma-separated setting, dropping empty entries
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ConfigKeys returns the setting names accepted by FileConfig.Set. They match
+// the CLI flag names and the ai-commit.* git config keys.
+func ConfigKeys() []string {
+	keys := []string{"model", "endpoint", "temperature", "max-tokens", "style",
+		"ticket-prefix", "language", "anonymize", "sign", "signing-key", "push", "push-remotes", "journal", "workflow", "exclude", "profile", "cache", "prompt-template", "gitmoji", "types", "scopes", "scope-map", "manual-sections", "max-subject-length", "max-prompt-bytes", "max-prompt-files", "max-file-bytes", "context-window", "adaptive-temperature", "infer-types", "provenance", "no-emoji", "ai", "github-api", "gitlab-api", "dependency-risk", "trivial-max-lines", "history-examples", "history-context", "count-excluded", "summarize-files", "tree-sitter", "secret-scan", "embedding-model"}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set assigns a single setting by key, parsing the value for its type.
+// Lists such as exclude are comma-separated.
+func (fc *FileConfig) Set(key, value string) error {
+	switch strings.ToLower(key) {
+	case "model":
+		fc.Model = value
+	case "endpoint":
+		fc.Endpoint = value
+	case "temperature":
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid temperature %q: %w", value, err)
+		}
+		fc.Temperature = &temperature
+	case "max-tokens":
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid max-tokens %q: %w", value, err)
+		}
+		fc.MaxTokens = maxTokens
+	case "style":
+		fc.Style = value
+	case "ticket-prefix":
+		fc.TicketPrefix = value
+	case "anonymize":
+		anonymize, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid anonymize %q: %w", value, err)
+		}
+		fc.Anonymize = &anonymize
+	case "language":
+		if err := ValidateLanguage(value); err != nil {
+			return err
+		}
+		fc.Language = value
+	case "sign":
+		sign, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid sign %q: %w", value, err)
+		}
+		fc.Sign = &sign
+	case "provenance":
+		provenance, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid provenance %q: %w", value, err)
+		}
+		fc.Provenance = &provenance
+	case "github-api":
+		fc.GitHubAPI = strings.TrimSuffix(value, "/")
+	case "gitlab-api":
+		fc.GitLabAPI = strings.TrimSuffix(value, "/")
+	case "embedding-model":
+		fc.EmbeddingModel = value
+	case "dependency-risk":
+		dependencyRisk, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid dependency-risk %q: %w", value, err)
+		}
+		fc.DependencyRisk = &dependencyRisk
+	case "ai":
+		if value != "on" && value != "off" {
+			return fmt.Errorf("invalid ai %q (use on or off)", value)
+		}
+		fc.AI = value
+	case "no-emoji":
+		noEmoji, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid no-emoji %q: %w", value, err)
+		}
+		fc.NoEmoji = &noEmoji
+	case "gitmoji":
+		gitmoji, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid gitmoji %q: %w", value, err)
+		}
+		fc.Gitmoji = &gitmoji
+	case "push":
+		switch value {
+		case "ask", "always", "never":
+			fc.Push = value
+		default:
+			return fmt.Errorf("invalid push %q (expected ask, always or never)", value)
+		}
+	case "push-remotes":
+		fc.PushRemotes = splitCommaList(value)
+	case "journal":
+		fc.Journal = value
+	case "workflow":
+		fc.Workflow = value
+	case "cache":
+		fc.Cache = value
+	case "prompt-template":
+		fc.PromptTemplate = value
+	case "signing-key":
+		fc.SigningKey = value
+	case "profile":
+		fc.Profile = value
+	case "exclude":
+		fc.Exclude = splitCommaList(value)
+	case "types":
+		fc.Types = splitCommaList(value)
+	case "scopes":
+		fc.Scopes = splitCommaList(value)
+	case "adaptive-temperature":
+		adaptive, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid adaptive-temperature %q: %w", value, err)
+		}
+		fc.AdaptiveTemperature = &adaptive
+	case "infer-types":
+		infer, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid infer-types %q: %w", value, err)
+		}
+		fc.InferTypes = &infer
+	case "max-subject-length":
+		length, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid max-subject-length %q: %w", value, err)
+		}
+		fc.MaxSubjectLength = length
+	case "max-prompt-bytes", "max-prompt-files", "max-file-bytes":
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid %s %q: expected a positive number", key, value)
+		}
+		switch key {
+		case "max-prompt-bytes":
+			fc.MaxPromptBytes = limit
+		case "max-prompt-files":
+			fc.MaxPromptFiles = limit
+		default:
+			fc.MaxFileBytes = limit
+		}
+	case "context-window":
+		if value == "auto" {
+			fc.ContextWindow = ContextWindowAuto
+			break
+		}
+		window, err := strconv.Atoi(value)
+		if err != nil || window <= 0 && window != ContextWindowAuto {
+			return fmt.Errorf("invalid context-window %q: expected a number of tokens or auto", value)
+		}
+		fc.ContextWindow = window
+	case "trivial-max-lines":
+		lines, err := strconv.Atoi(value)
+		if err != nil || lines < 0 {
+			return fmt.Errorf("invalid trivial-max-lines %q: expected a number of lines, 0 to disable", value)
+		}
+		fc.TrivialMaxLines = &lines
+	case "history-examples":
+		commits, err := strconv.Atoi(value)
+		if err != nil || commits < 0 {
+			return fmt.Errorf("invalid history-examples %q: expected a number of commits, 0 to disable", value)
+		}
+		fc.HistoryExamples = &commits
+	case "history-context":
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid history-context %q: %w", value, err)
+		}
+		fc.HistoryContext = &include
+	case "count-excluded":
+		count, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid count-excluded %q: %w", value, err)
+		}
+		fc.CountExcluded = &count
+	case "summarize-files":
+		summarize, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid summarize-files %q: %w", value, err)
+		}
+		fc.SummarizeFiles = &summarize
+	case "tree-sitter":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid tree-sitter %q: %w", value, err)
+		}
+		fc.TreeSitter = &enabled
+	case "secret-scan":
+		switch value {
+		case SecretScanBlock, SecretScanWarn, SecretScanOff:
+			fc.SecretScan = value
+		default:
+			return fmt.Errorf("invalid secret-scan %q (expected block, warn or off)", value)
+		}
+	case "scope-map":
+		mappings := splitCommaList(value)
+		for _, mapping := range mappings {
+			if _, err := ParseScopeMapping(mapping); err != nil {
+				return err
+			}
+		}
+		fc.ScopeMap = mappings
+	case "manual-sections":
+		fc.ManualSections = splitCommaList(value)
+	default:
+		return fmt.Errorf("unknown setting %q (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+	}
+	return nil
+}
+
+// Merge overlays the non-empty settings of other onto fc
+func (fc *FileConfig) Merge(other *FileConfig) {
+	if other.Model != "" {
+		fc.Model = other.Model
+	}
+	if other.Endpoint != "" {
+		fc.Endpoint = other.Endpoint
+	}
+	if other.Temperature != nil {
+		fc.Temperature = other.Temperature
+	}
+	if other.MaxTokens != 0 {
+		fc.MaxTokens = other.MaxTokens
+	}
+	if other.Style != "" {
+		fc.Style = other.Style
+	}
+	if other.TicketPrefix != "" {
+		fc.TicketPrefix = other.TicketPrefix
+	}
+	if other.Sign != nil {
+		fc.Sign = other.Sign
+	}
+	if other.Provenance != nil {
+		fc.Provenance = other.Provenance
+	}
+	if other.NoEmoji != nil {
+		fc.NoEmoji = other.NoEmoji
+	}
+	if other.AI != "" {
+		fc.AI = other.AI
+	}
+	if other.GitHubAPI != "" {
+		fc.GitHubAPI = other.GitHubAPI
+	}
+	if other.GitLabAPI != "" {
+		fc.GitLabAPI = other.GitLabAPI
+	}
+	if other.EmbeddingModel != "" {
+		fc.EmbeddingModel = other.EmbeddingModel
+	}
+	if other.DependencyRisk != nil {
+		fc.DependencyRisk = other.DependencyRisk
+	}
+	if other.Push != "" {
+		fc.Push = other.Push
+	}
+	if len(other.PushRemotes) > 0 {
+		fc.PushRemotes = other.PushRemotes
+	}
+	if other.Journal != "" {
+		fc.Journal = other.Journal
+	}
+	if other.Language != "" {
+		fc.Language = other.Language
+	}
+	if other.Anonymize != nil {
+		fc.Anonymize = other.Anonymize
+	}
+	if other.Workflow != "" {
+		fc.Workflow = other.Workflow
+	}
+	if other.Cache != "" {
+		fc.Cache = other.Cache
+	}
+	if other.PromptTemplate != "" {
+		fc.PromptTemplate = other.PromptTemplate
+	}
+	if len(other.Exclude) > 0 {
+		fc.Exclude = other.Exclude
+	}
+	if len(other.Types) > 0 {
+		fc.Types = other.Types
+	}
+	if len(other.Scopes) > 0 {
+		fc.Scopes = other.Scopes
+	}
+	if len(other.ScopeMap) > 0 {
+		fc.ScopeMap = other.ScopeMap
+	}
+	if len(other.ManualSections) > 0 {
+		fc.ManualSections = other.ManualSections
+	}
+	if other.MaxSubjectLength != 0 {
+		fc.MaxSubjectLength = other.MaxSubjectLength
+	}
+	if other.MaxPromptBytes != 0 {
+		fc.MaxPromptBytes = other.MaxPromptBytes
+	}
+	if other.MaxPromptFiles != 0 {
+		fc.MaxPromptFiles = other.MaxPromptFiles
+	}
+	if other.MaxFileBytes != 0 {
+		fc.MaxFileBytes = other.MaxFileBytes
+	}
+	if other.ContextWindow != 0 {
+		fc.ContextWindow = other.ContextWindow
+	}
+	if other.AdaptiveTemperature != nil {
+		fc.AdaptiveTemperature = other.AdaptiveTemperature
+	}
+	if other.InferTypes != nil {
+		fc.InferTypes = other.InferTypes
+	}
+	if other.TrivialMaxLines != nil {
+		fc.TrivialMaxLines = other.TrivialMaxLines
+	}
+	if other.HistoryExamples != nil {
+		fc.HistoryExamples = other.HistoryExamples
+	}
+	if other.HistoryContext != nil {
+		fc.HistoryContext = other.HistoryContext
+	}
+	if other.CountExcluded != nil {
+		fc.CountExcluded = other.CountExcluded
+	}
+	if other.SummarizeFiles != nil {
+		fc.SummarizeFiles = other.SummarizeFiles
+	}
+	if other.TreeSitter != nil {
+		fc.TreeSitter = other.TreeSitter
+	}
+	if other.SecretScan != "" {
+		fc.SecretScan = other.SecretScan
+	}
+	fc.Redact = append(fc.Redact, other.Redact...)
+	if len(other.Hooks.PreCommit) > 0 {
+		fc.Hooks.PreCommit = other.Hooks.PreCommit
+	}
+	if len(other.Hooks.PostCommit) > 0 {
+		fc.Hooks.PostCommit = other.Hooks.PostCommit
+	}
+	if other.Gitmoji != nil {
+		fc.Gitmoji = other.Gitmoji
+	}
+	if other.SigningKey != "" {
+		fc.SigningKey = other.SigningKey
+	}
+	if other.Profile != "" {
+		fc.Profile = other.Profile
+	}
+	for name, profile := range other.Profiles {
+		if fc.Profiles == nil {
+			fc.Profiles = make(map[string]*FileConfig)
+		}
+		if fc.Profiles[name] == nil {
+			fc.Profiles[name] = &FileConfig{}
+		}
+		fc.Profiles[name].Merge(profile)
+	}
+}
+
+// Apply copies the library settings of fc onto config
+func (fc *FileConfig) Apply(config *Config) {
+	if fc.Model != "" {
+		config.Model = fc.Model
+	}
+	if fc.Endpoint != "" {
+		config.OllamaEndpoint = fc.Endpoint
+	}
+	if fc.Temperature != nil {
+		config.Temperature = *fc.Temperature
+	}
+	if fc.MaxTokens != 0 {
+		config.MaxTokens = fc.MaxTokens
+	}
+	if fc.Style != "" {
+		config.Style = fc.Style
+	}
+	if fc.TicketPrefix != "" {
+		config.TicketPrefix = fc.TicketPrefix
+	}
+	if fc.Language != "" {
+		config.Language = fc.Language
+	}
+	if fc.Anonymize != nil {
+		config.Anonymize = *fc.Anonymize
+	}
+	if len(fc.Exclude) > 0 {
+		config.Exclude = fc.Exclude
+	}
+	if len(fc.Types) > 0 {
+		config.Types = fc.Types
+	}
+	if len(fc.Scopes) > 0 {
+		config.Scopes = fc.Scopes
+	}
+	if len(fc.ScopeMap) > 0 {
+		config.ScopeMap = fc.ScopeMap
+	}
+	if len(fc.ManualSections) > 0 {
+		config.ManualSections = fc.ManualSections
+	}
+	if fc.MaxSubjectLength != 0 {
+		config.MaxSubjectLength = fc.MaxSubjectLength
+	}
+	if fc.MaxPromptBytes != 0 {
+		config.MaxPromptBytes = fc.MaxPromptBytes
+	}
+	if fc.MaxPromptFiles != 0 {
+		config.MaxPromptFiles = fc.MaxPromptFiles
+	}
+	if fc.MaxFileBytes != 0 {
+		config.MaxFileBytes = fc.MaxFileBytes
+	}
+	if fc.ContextWindow != 0 {
+		config.ContextWindow = fc.ContextWindow
+	}
+	if fc.AdaptiveTemperature != nil {
+		config.AdaptiveTemperature = *fc.AdaptiveTemperature
+	}
+	if fc.InferTypes != nil {
+		config.InferTypes = *fc.InferTypes
+	}
+	if fc.TrivialMaxLines != nil {
+		config.TrivialMaxLines = *fc.TrivialMaxLines
+	}
+	if fc.HistoryExamples != nil {
+		config.HistoryExamples = *fc.HistoryExamples
+	}
+	if fc.HistoryContext != nil {
+		config.IncludeHistoryContext = *fc.HistoryContext
+	}
+	if fc.CountExcluded != nil {
+		config.CountExcluded = *fc.CountExcluded
+	}
+	if fc.SummarizeFiles != nil {
+		config.SummarizeFiles = *fc.SummarizeFiles
+	}
+	if fc.SecretScan != "" {
+		config.SecretScan = fc.SecretScan
+	}
+	if len(fc.Redact) > 0 {
+		config.Redact = fc.Redact
+	}
+	if fc.AI != "" {
+		config.Disabled = fc.AI == "off"
+	}
+	if fc.GitHubAPI != "" {
+		config.GitHubAPI = fc.GitHubAPI
+	}
+	if fc.GitLabAPI != "" {
+		config.GitLabAPI = fc.GitLabAPI
+	}
+	if fc.EmbeddingModel != "" {
+		config.EmbeddingModel = fc.EmbeddingModel
+	}
+	if fc.PromptTemplate != "" {
+		config.PromptTemplate = fc.PromptTemplate
+	}
+	if fc.Gitmoji != nil {
+		config.Gitmoji = *fc.Gitmoji
+	}
+}
+
+// WriteConfigFile writes fc as YAML to path, creating parent directories
+func WriteConfigFile(path string, fc *FileConfig) error {
+	data, err := yaml.Marshal(fc)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
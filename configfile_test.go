@@ -0,0 +1,220 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+
+	sign := true
+	fc := &FileConfig{Model: "llama3.2:3b", Sign: &sign, Push: "ask"}
+	if err := WriteConfigFile(path, fc); err != nil {
+		t.Fatalf("WriteConfigFile returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read written config: %v", err)
+	}
+
+	content := string(data)
+	for _, want := range []string{"model: llama3.2:3b", "sign: true", "push: ask"} {
+		if !contains(content, want) {
+			t.Errorf("Expected config to contain %q, got:\n%s", want, content)
+		}
+	}
+
+	if contains(content, "endpoint") {
+		t.Errorf("Expected empty fields to be omitted, got:\n%s", content)
+	}
+}
+
+func TestLoadConfigFileAndMerge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFileName)
+
+	content := "model: qwen2.5-coder:7b\ntemperature: 0.3\nexclude:\n  - dist/\n  - '*.min.js'\nhooks:\n  pre_commit:\n    - make lint\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile returned error: %v", err)
+	}
+
+	if fc.Model != "qwen2.5-coder:7b" || fc.Temperature == nil || *fc.Temperature != 0.3 {
+		t.Errorf("Unexpected config: %+v", fc)
+	}
+	if len(fc.Exclude) != 2 || len(fc.Hooks.PreCommit) != 1 {
+		t.Errorf("Expected excludes and hooks to be parsed, got %+v", fc)
+	}
+
+	// Repository settings override user settings, empty values do not
+	user := &FileConfig{Model: "llama2", Endpoint: "http://gpu:11434", Style: "plain"}
+	user.Merge(fc)
+	if user.Model != "qwen2.5-coder:7b" || user.Endpoint != "http://gpu:11434" || user.Style != "plain" {
+		t.Errorf("Unexpected merge result: %+v", user)
+	}
+
+	config := DefaultConfig()
+	user.Apply(config)
+	if config.Model != "qwen2.5-coder:7b" || config.Temperature != 0.3 || config.Style != "plain" || len(config.Exclude) != 2 {
+		t.Errorf("Unexpected applied config: %+v", config)
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	fc, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error for a missing file, got %v", err)
+	}
+	if fc.Model != "" {
+		t.Errorf("Expected an empty config, got %+v", fc)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	temperature := 0.2
+	layers := []ConfigLayer{
+		{Source: "user.yaml", Config: &FileConfig{Model: "llama2", Temperature: &temperature}},
+		{Source: "repo.yaml", Config: &FileConfig{Model: "codellama"}},
+		{Source: "environment", Config: &FileConfig{Style: "plain"}},
+	}
+
+	sources := make(map[string]EffectiveSetting)
+	for _, setting := range ResolveSettings(layers) {
+		sources[setting.Key] = setting
+	}
+
+	tests := []struct {
+		key, value, source string
+	}{
+		{"model", "codellama", "repo.yaml"},
+		{"temperature", "0.2", "user.yaml"},
+		{"style", "plain", "environment"},
+		{"max-tokens", "150", "default"},
+	}
+
+	for _, test := range tests {
+		got := sources[test.key]
+		if got.Value != test.value || got.Source != test.source {
+			t.Errorf("%s = %q from %q, want %q from %q", test.key, got.Value, got.Source, test.value, test.source)
+		}
+	}
+}
+
+func TestLoadEnvConfig(t *testing.T) {
+	t.Setenv("AI_COMMIT_MODEL", "mistral")
+	t.Setenv("AI_COMMIT_MAX_TOKENS", "300")
+
+	fc, err := LoadEnvConfig()
+	if err != nil {
+		t.Fatalf("LoadEnvConfig returned error: %v", err)
+	}
+	if fc.Model != "mistral" || fc.MaxTokens != 300 {
+		t.Errorf("Unexpected env config: %+v", fc)
+	}
+
+	t.Setenv("AI_COMMIT_TEMPERATURE", "warm")
+	if _, err := LoadEnvConfig(); err == nil {
+		t.Error("Expected an error for an invalid temperature")
+	}
+}
+
+func TestLoadFileConfigsProfile(t *testing.T) {
+	dir := initTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	content := "model: llama2\nstyle: plain\nprofile: personal\nprofiles:\n  work:\n    endpoint: http://gpu.corp:11434\n    model: qwen2.5-coder:7b\n    sign: true\n    signing_key: ABC123\n  personal:\n    model: llama3.2:3b\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default profile comes from the "profile" setting
+	fc, err := LoadFileConfigs(dir, "")
+	if err != nil {
+		t.Fatalf("LoadFileConfigs returned error: %v", err)
+	}
+	if fc.Model != "llama3.2:3b" || fc.Style != "plain" {
+		t.Errorf("Expected personal profile on top of the file, got %+v", fc)
+	}
+
+	// An explicit profile wins, environment variables still override it
+	t.Setenv("AI_COMMIT_MODEL", "mistral")
+	fc, err = LoadFileConfigs(dir, "work")
+	if err != nil {
+		t.Fatalf("LoadFileConfigs returned error: %v", err)
+	}
+	if fc.Endpoint != "http://gpu.corp:11434" || fc.SigningKey != "ABC123" || fc.Sign == nil || !*fc.Sign {
+		t.Errorf("Expected work profile settings, got %+v", fc)
+	}
+	if fc.Model != "mistral" || fc.Profile != "work" {
+		t.Errorf("Expected env model and work profile, got model %q profile %q", fc.Model, fc.Profile)
+	}
+
+	if _, err := LoadFileConfigs(dir, "holiday"); err == nil || !contains(err.Error(), "work") {
+		t.Errorf("Expected unknown profile error listing profiles, got %v", err)
+	}
+}
+
+func TestRepoHooksNeedTrust(t *testing.T) {
+	dir := initTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	content := "hooks:\n  pre_commit:\n    - make lint\nprofiles:\n  ci:\n    hooks:\n      post_commit:\n        - curl example.com\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := LoadFileConfigs(dir, "ci")
+	if err != nil {
+		t.Fatalf("LoadFileConfigs returned error: %v", err)
+	}
+	if len(fc.Hooks.PreCommit) != 0 || len(fc.Hooks.PostCommit) != 0 {
+		t.Errorf("Expected the hooks of an untrusted repository to be dropped, got %+v", fc.Hooks)
+	}
+	if !RepoHooksIgnored(dir) {
+		t.Error("Expected RepoHooksIgnored to report the dropped hooks")
+	}
+
+	gitRun(t, dir, "config", TrustHooksKey, "true")
+	fc, err = LoadFileConfigs(dir, "ci")
+	if err != nil {
+		t.Fatalf("LoadFileConfigs returned error: %v", err)
+	}
+	if len(fc.Hooks.PreCommit) != 1 || len(fc.Hooks.PostCommit) != 1 {
+		t.Errorf("Expected the hooks of a trusted repository, got %+v", fc.Hooks)
+	}
+	if RepoHooksIgnored(dir) {
+		t.Error("Expected no ignored hooks once the repository is trusted")
+	}
+}
+
+func TestLoadUntrustedFileConfigs(t *testing.T) {
+	dir := initTestRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	content := "endpoint: http://attacker.example:11434\nsecret_scan: off\nprompt_template: /etc/passwd\nmodel: llama3.2:3b\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := LoadFileConfigs(dir, "")
+	if err != nil || fc.Endpoint == "" {
+		t.Fatalf("Expected the repository file to apply, got %+v: %v", fc, err)
+	}
+	fc, err = LoadUntrustedFileConfigs(dir, "")
+	if err != nil {
+		t.Fatalf("LoadUntrustedFileConfigs returned error: %v", err)
+	}
+	if fc.Endpoint != "" || fc.SecretScan != "" || fc.PromptTemplate != "" || fc.Model != "" {
+		t.Errorf("Expected the repository file to be ignored, got %+v", fc)
+	}
+}
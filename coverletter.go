@@ -0,0 +1,129 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CoverLetter is a git send-email style cover letter for a patch series
+type CoverLetter struct {
+	// Title summarizes the whole series
+	Title string
+	// Summary explains what the series does and why
+	Summary string
+	// Commits are the patches of the series, oldest first
+	Commits []Commit
+	// Diffstat is the output of git diff --stat for the series
+	Diffstat string
+}
+
+// GenerateCoverLetter generates a cover letter for the commits in revRange
+func (gc *GitCommenter) GenerateCoverLetter(revRange string) (*CoverLetter, error) {
+	commits, err := gc.Commits(revRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in %s", revRange)
+	}
+
+	diffstat, err := gc.gitOutput("diff", "--stat", commits[0].Hash+"^", commits[len(commits)-1].Hash)
+	if err != nil {
+		// The first commit may be the root commit; fall back to per-commit stats
+		diffstat, err = gc.gitOutput("log", "--format=", "--stat", revRange)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute diffstat: %w", err)
+		}
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing the cover letter (patch 0/N) for a patch series sent to a mailing list.\n\n")
+	prompt.WriteString("PATCHES IN THE SERIES:\n")
+	for i, commit := range commits {
+		prompt.WriteString(fmt.Sprintf("[%d/%d] %s\n", i+1, len(commits), commit.Subject))
+		if commit.Body != "" {
+			prompt.WriteString(indent(commit.Body, "      "))
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\nDIFFSTAT:\n")
+	prompt.WriteString(diffstat)
+	prompt.WriteString("\nWrite a cover letter that:\n")
+	prompt.WriteString("1. Starts with a one-line title for the whole series (no 'Subject:' prefix, no [PATCH] tag)\n")
+	prompt.WriteString("2. Follows with a blank line and 1-3 short paragraphs explaining what the series does and why\n")
+	prompt.WriteString("3. Mentions how the patches build on each other when relevant\n\n")
+	prompt.WriteString("Respond with only the title and the paragraphs, wrapped at 72 columns, no additional text.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate cover letter: %w", err)
+	}
+
+	title, summary := splitTitle(response)
+	return &CoverLetter{
+		Title:    title,
+		Summary:  summary,
+		Commits:  commits,
+		Diffstat: strings.TrimRight(diffstat, "\n"),
+	}, nil
+}
+
+// String renders the cover letter in the layout of git format-patch
+// --cover-letter: subject, summary, shortlog and diffstat
+func (cl *CoverLetter) String() string {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("Subject: [PATCH 0/%d] %s\n\n", len(cl.Commits), cl.Title))
+	if cl.Summary != "" {
+		out.WriteString(cl.Summary)
+		out.WriteString("\n\n")
+	}
+
+	out.WriteString(cl.Shortlog())
+	out.WriteString(cl.Diffstat)
+	out.WriteString("\n")
+
+	return out.String()
+}
+
+// Shortlog groups patch subjects by author like git shortlog
+func (cl *CoverLetter) Shortlog() string {
+	byAuthor := make(map[string][]string)
+	var authors []string
+	for _, commit := range cl.Commits {
+		if _, seen := byAuthor[commit.Author]; !seen {
+			authors = append(authors, commit.Author)
+		}
+		byAuthor[commit.Author] = append(byAuthor[commit.Author], commit.Subject)
+	}
+	sort.Strings(authors)
+
+	var out strings.Builder
+	for _, author := range authors {
+		subjects := byAuthor[author]
+		out.WriteString(fmt.Sprintf("%s (%d):\n", author, len(subjects)))
+		for _, subject := range subjects {
+			out.WriteString("  " + subject + "\n")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// splitTitle splits a model response into its first line and the rest
+func splitTitle(response string) (string, string) {
+	response = strings.TrimSpace(response)
+	title, rest, _ := strings.Cut(response, "\n")
+	title = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(title), "Subject:"))
+	return strings.Trim(title, "\"'` "), strings.TrimSpace(rest)
+}
+
+// indent prefixes every line of text
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,39 @@
+package gitcommenter
+
+import "testing"
+
+func TestCoverLetterString(t *testing.T) {
+	cl := &CoverLetter{
+		Title:   "gitcommenter: add pluggable git backends",
+		Summary: "This series abstracts git access.",
+		Commits: []Commit{
+			{Author: "Bob", Subject: "add GitBackend interface"},
+			{Author: "Alice", Subject: "add go-git backend"},
+			{Author: "Bob", Subject: "use backend in CLI"},
+		},
+		Diffstat: " git.go | 10 ++++++++++\n 1 file changed, 10 insertions(+)",
+	}
+
+	letter := cl.String()
+
+	for _, want := range []string{
+		"Subject: [PATCH 0/3] gitcommenter: add pluggable git backends\n",
+		"Alice (1):\n  add go-git backend\n",
+		"Bob (2):\n  add GitBackend interface\n  use backend in CLI\n",
+		"1 file changed",
+	} {
+		if !contains(letter, want) {
+			t.Errorf("Expected cover letter to contain %q, got:\n%s", want, letter)
+		}
+	}
+}
+
+func TestSplitTitle(t *testing.T) {
+	title, summary := splitTitle("Subject: \"Add retries\"\n\nFirst paragraph.\n\nSecond.")
+	if title != "Add retries" {
+		t.Errorf("Unexpected title %q", title)
+	}
+	if summary != "First paragraph.\n\nSecond." {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
@@ -0,0 +1,316 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DependencyBump is a dependency whose version changed
+type DependencyBump struct {
+	// Ecosystem is "go", "npm", "pip", "cargo", "ruby" or "composer"
+	Ecosystem string
+	Name      string
+	// From is empty when only the new version is known
+	From string
+	To   string
+}
+
+// String formats the bump as "name from -> to"
+func (b DependencyBump) String() string {
+	if b.From == "" {
+		return fmt.Sprintf("%s to %s", b.Name, b.To)
+	}
+	return fmt.Sprintf("%s %s -> %s", b.Name, b.From, b.To)
+}
+
+// Major reports whether the major version changes
+func (b DependencyBump) Major() bool {
+	from, to := versionNumbers(b.From), versionNumbers(b.To)
+	return len(from) > 0 && len(to) > 0 && from[0] != to[0]
+}
+
+// dependencyBotBranches are the branch prefixes used by Dependabot and
+// Renovate
+var dependencyBotBranches = []string{"dependabot/", "renovate/"}
+
+// IsDependencyBotBranch reports whether branch was created by Dependabot or
+// Renovate
+func IsDependencyBotBranch(branch string) bool {
+	for _, prefix := range dependencyBotBranches {
+		if strings.HasPrefix(branch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDependencyUpdate reports whether changes are a dependency update: made
+// on a Dependabot or Renovate branch, or touching only manifests and lock
+// files
+func (gc *GitCommenter) IsDependencyUpdate(changes []FileChange) bool {
+	return IsDependencyBotBranch(gc.currentBranch()) || len(changes) > 0 && ClassifyChanges(changes) == KindDependencies
+}
+
+// Version lines of the manifests ParseDependencyBumps understands
+var (
+	goModRequire     = regexp.MustCompile(`^(?:require\s+)?([^\s()]+)\s+(v\d\S*)(?:\s*//.*)?$`)
+	packageJSONEntry = regexp.MustCompile(`^"([^"]+)":\s*"[~^=v]*(\d[^"]*)",?$`)
+	requirementsPin  = regexp.MustCompile(`^([A-Za-z0-9_.\-]+)(?:\[[^\]]*\])?\s*==\s*([^\s;#]+)`)
+	cargoDependency  = regexp.MustCompile(`^([A-Za-z0-9_\-]+)\s*=\s*(?:\{.*version\s*=\s*)?"[~^=]*(\d[^"]*)"`)
+)
+
+// manifestFields are version-like manifest settings that are not
+// dependencies
+var manifestFields = map[string]bool{"version": true, "go": true, "edition": true, "rust-version": true}
+
+// ParseDependencyBumps finds version changes in the diffs of go.mod,
+// package.json, requirements*.txt and Cargo.toml
+func ParseDependencyBumps(changes []FileChange) []DependencyBump {
+	var bumps []DependencyBump
+	for _, change := range changes {
+		format := dependencyFormatOf(change.FilePath)
+		if format == nil || format.lock {
+			continue
+		}
+		versions := parseVersionDiff(format, change.Diff)
+		for _, name := range versions.order {
+			from, removed := versions.removed[name]
+			to, added := versions.added[name]
+			if removed && added && from != to {
+				bumps = append(bumps, DependencyBump{Ecosystem: format.ecosystem, Name: name, From: from, To: to})
+			}
+		}
+	}
+	return bumps
+}
+
+// dependabotEcosystems maps Dependabot's package manager directory names
+var dependabotEcosystems = map[string]string{
+	"go_modules": "go", "npm_and_yarn": "npm", "pip": "pip", "cargo": "cargo",
+}
+
+// dependabotBranch matches e.g. dependabot/npm_and_yarn/lodash-4.17.21
+var dependabotBranch = regexp.MustCompile(`^dependabot/([^/]+)/(.+)-v?(\d[\w.\-+]*)$`)
+
+// ParseDependabotBranch returns the dependency and new version named by a
+// Dependabot branch; From is unknown
+func ParseDependabotBranch(branch string) (DependencyBump, bool) {
+	match := dependabotBranch.FindStringSubmatch(branch)
+	if match == nil {
+		return DependencyBump{}, false
+	}
+	return DependencyBump{Ecosystem: dependabotEcosystems[match[1]], Name: match[2], To: match[3]}, true
+}
+
+// DependencyBumps returns the version changes of a dependency update, from
+// the manifests or, when only lock files changed, the Dependabot branch
+func (gc *GitCommenter) DependencyBumps(changes []FileChange) []DependencyBump {
+	bumps := ParseDependencyBumps(changes)
+	if len(bumps) == 0 {
+		if bump, ok := ParseDependabotBranch(gc.currentBranch()); ok {
+			bumps = append(bumps, bump)
+		}
+	}
+	return bumps
+}
+
+// Package registries used to find the repository of a dependency
+var (
+	npmRegistryURL = "https://registry.npmjs.org"
+	pypiURL        = "https://pypi.org"
+	cratesURL      = "https://crates.io"
+)
+
+// dependencyRepository returns the GitHub "owner/repo" of a dependency, or
+// "" when it is not hosted on GitHub
+func (gc *GitCommenter) dependencyRepository(bump DependencyBump) (string, error) {
+	switch bump.Ecosystem {
+	case "go":
+		if name, ok := strings.CutPrefix(bump.Name, "golang.org/x/"); ok {
+			return "golang/" + name, nil
+		}
+		parts := strings.Split(bump.Name, "/")
+		if parts[0] == "github.com" && len(parts) >= 3 {
+			return parts[1] + "/" + parts[2], nil
+		}
+		return "", nil
+	case "npm":
+		var pkg struct {
+			Repository any `json:"repository"`
+		}
+		if err := gc.getJSON(npmRegistryURL+"/"+url.PathEscape(bump.Name), nil, &pkg); err != nil {
+			return "", err
+		}
+		// The field is either a URL or {"type": "git", "url": ...}
+		switch repository := pkg.Repository.(type) {
+		case string:
+			return GitHubRepoFromURL(repository), nil
+		case map[string]any:
+			repoURL, _ := repository["url"].(string)
+			return GitHubRepoFromURL(repoURL), nil
+		}
+		return "", nil
+	case "pip":
+		var pkg struct {
+			Info struct {
+				HomePage    string            `json:"home_page"`
+				ProjectURLs map[string]string `json:"project_urls"`
+			} `json:"info"`
+		}
+		if err := gc.getJSON(pypiURL+"/pypi/"+url.PathEscape(bump.Name)+"/json", nil, &pkg); err != nil {
+			return "", err
+		}
+		urls := []string{pkg.Info.HomePage}
+		for _, projectURL := range pkg.Info.ProjectURLs {
+			urls = append(urls, projectURL)
+		}
+		sort.Strings(urls[1:])
+		for _, projectURL := range urls {
+			if repo := GitHubRepoFromURL(projectURL); repo != "" {
+				return repo, nil
+			}
+		}
+		return "", nil
+	case "cargo":
+		var pkg struct {
+			Crate struct {
+				Repository string `json:"repository"`
+			} `json:"crate"`
+		}
+		if err := gc.getJSON(cratesURL+"/api/v1/crates/"+url.PathEscape(bump.Name), nil, &pkg); err != nil {
+			return "", err
+		}
+		return GitHubRepoFromURL(pkg.Crate.Repository), nil
+	}
+	return "", nil
+}
+
+// ReleaseNote is a GitHub release of a dependency
+type ReleaseNote struct {
+	Version string
+	URL     string
+	Body    string
+}
+
+// DependencyReleaseNotes fetches the GitHub releases of a dependency newer
+// than bump.From up to bump.To, newest first. Dependencies not hosted on
+// GitHub have none.
+func (gc *GitCommenter) DependencyReleaseNotes(bump DependencyBump) ([]ReleaseNote, error) {
+	repo, err := gc.dependencyRepository(bump)
+	if err != nil || repo == "" {
+		return nil, err
+	}
+
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+		Body    string `json:"body"`
+		Draft   bool   `json:"draft"`
+	}
+	if err := gc.githubGet("/repos/"+repo+"/releases?per_page=100", &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s: %w", repo, err)
+	}
+
+	var notes []ReleaseNote
+	for _, release := range releases {
+		// Monorepos tag releases as name@1.2.3 or name/v1.2.3
+		version := release.TagName[strings.LastIndexAny(release.TagName, "@/")+1:]
+		if release.Draft || compareVersions(version, bump.To) > 0 {
+			continue
+		}
+		if bump.From == "" && compareVersions(version, bump.To) != 0 || bump.From != "" && compareVersions(version, bump.From) <= 0 {
+			continue
+		}
+		notes = append(notes, ReleaseNote{Version: version, URL: release.HTMLURL, Body: strings.TrimSpace(release.Body)})
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return compareVersions(notes[i].Version, notes[j].Version) > 0 })
+	return notes, nil
+}
+
+// Limits keeping the risk prompt within a small model's context
+const (
+	maxReleaseNotes    = 8
+	maxReleaseNoteSize = 1500
+)
+
+// DependencyRiskSummary asks the model for a short risk assessment of the
+// bumps based on their release notes. Dependencies whose notes cannot be
+// fetched are judged by their version change alone.
+func (gc *GitCommenter) DependencyRiskSummary(bumps []DependencyBump) (string, error) {
+	var prompt strings.Builder
+	prompt.WriteString("You review automated dependency updates. Judge the upgrade risk from the version changes and the release notes below.\n\n")
+	for _, bump := range bumps {
+		fmt.Fprintf(&prompt, "=== %s (%s)", bump, bump.Ecosystem)
+		if bump.Major() {
+			prompt.WriteString(", major version change")
+		}
+		prompt.WriteString(" ===\n")
+
+		notes, err := gc.DependencyReleaseNotes(bump)
+		if err != nil || len(notes) == 0 {
+			prompt.WriteString("No release notes available.\n\n")
+			continue
+		}
+		for i, note := range notes {
+			if i == maxReleaseNotes {
+				fmt.Fprintf(&prompt, "(%d older releases left out)\n", len(notes)-i)
+				break
+			}
+			body := note.Body
+			if len(body) > maxReleaseNoteSize {
+				body = body[:maxReleaseNoteSize] + "\n[...]"
+			}
+			fmt.Fprintf(&prompt, "Release %s:\n%s\n\n", note.Version, body)
+		}
+	}
+	prompt.WriteString(`Reply with a first line "Risk: low", "Risk: medium" or "Risk: high", followed by at most five lines starting with "- " that name breaking changes, deprecations, security fixes or notable features. Only mention what the release notes say; do not invent changes.`)
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize dependency risk: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// versionNumbers returns the leading numeric parts of a version such as
+// v1.2.3-rc.1 ([1 2 3])
+func versionNumbers(version string) []int {
+	version = strings.TrimLeft(version, "v^~=")
+	version, _, _ = strings.Cut(version, "-")
+	version, _, _ = strings.Cut(version, "+")
+	var numbers []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// compareVersions compares the numeric parts of two versions; missing
+// parts count as zero
+func compareVersions(a, b string) int {
+	x, y := versionNumbers(a), versionNumbers(b)
+	for i := 0; i < max(len(x), len(y)); i++ {
+		var m, n int
+		if i < len(x) {
+			m = x[i]
+		}
+		if i < len(y) {
+			n = y[i]
+		}
+		if m != n {
+			if m < n {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
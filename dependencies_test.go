@@ -0,0 +1,121 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseDependencyBumps(t *testing.T) {
+	changes := []FileChange{
+		{FilePath: "go.mod", Diff: "--- a/go.mod\n+++ b/go.mod\n-go 1.21\n+go 1.22\n-\tgithub.com/spf13/cobra v1.7.0\n+\tgithub.com/spf13/cobra v1.8.0\n \tgolang.org/x/term v0.31.0 // indirect\n"},
+		{FilePath: "web/package.json", Diff: "-  \"version\": \"1.0.0\",\n+  \"version\": \"1.1.0\",\n-    \"lodash\": \"^4.17.20\",\n+    \"lodash\": \"^4.17.21\",\n"},
+		{FilePath: "requirements-dev.txt", Diff: "-requests[socks]==2.31.0\n+requests[socks]==2.32.3\n+pytest==8.0.0\n"},
+		{FilePath: "Cargo.toml", Diff: "-edition = \"2018\"\n+edition = \"2021\"\n-serde = { version = \"1.0.190\", features = [\"derive\"] }\n+serde = { version = \"1.0.200\", features = [\"derive\"] }\n"},
+		{FilePath: "yarn.lock", Diff: "-lodash@^4.17.20:\n+lodash@^4.17.21:\n"},
+	}
+
+	var got []string
+	for _, bump := range ParseDependencyBumps(changes) {
+		got = append(got, bump.Ecosystem+": "+bump.String())
+	}
+	want := []string{
+		"go: github.com/spf13/cobra v1.7.0 -> v1.8.0",
+		"npm: lodash 4.17.20 -> 4.17.21",
+		"pip: requests 2.31.0 -> 2.32.3",
+		"cargo: serde 1.0.190 -> 1.0.200",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected bumps:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestParseDependabotBranch(t *testing.T) {
+	bump, ok := ParseDependabotBranch("dependabot/npm_and_yarn/web/lodash-4.17.21")
+	if !ok || bump.Ecosystem != "npm" || bump.Name != "web/lodash" || bump.To != "4.17.21" {
+		t.Errorf("Unexpected bump %+v (ok %v)", bump, ok)
+	}
+	if bump, ok := ParseDependabotBranch("dependabot/go_modules/github.com/spf13/cobra-1.8.0"); !ok || bump.Name != "github.com/spf13/cobra" {
+		t.Errorf("Unexpected bump %+v (ok %v)", bump, ok)
+	}
+	if _, ok := ParseDependabotBranch("renovate/lodash-4.x"); ok {
+		t.Error("Expected Renovate branches not to parse")
+	}
+	if !IsDependencyBotBranch("renovate/lodash-4.x") || IsDependencyBotBranch("feature/renovate") {
+		t.Error("Unexpected bot branch detection")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"v1.8.0", "1.8", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0.0-rc.1", "2.0.0", 0},
+		{"^4.17.20", "4.17.21", -1},
+	} {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+	if !(DependencyBump{From: "v1.9.0", To: "v2.0.0"}).Major() || (DependencyBump{From: "1.2", To: "1.3"}).Major() {
+		t.Error("Unexpected major version detection")
+	}
+}
+
+func TestDependencyRiskSummary(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/npm/lodash":
+			json.NewEncoder(w).Encode(map[string]any{"repository": map[string]string{"type": "git", "url": "git+https://github.com/lodash/lodash.git"}})
+		case "/github/repos/lodash/lodash/releases":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"tag_name": "4.18.0", "body": "Unreleased"},
+				{"tag_name": "4.17.21", "body": "Fix prototype pollution in zipObjectDeep"},
+				{"tag_name": "4.17.20", "body": "Already installed"},
+			})
+		case "/api/generate":
+			var req OllamaRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			prompt = req.Prompt
+			json.NewEncoder(w).Encode(OllamaResponse{Response: "Risk: low\n- Security fix for prototype pollution", Done: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(registry string) { npmRegistryURL = registry }(npmRegistryURL)
+	npmRegistryURL = server.URL + "/npm"
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.GitHubAPI = server.URL + "/github"
+	gc := New(config)
+
+	bump := DependencyBump{Ecosystem: "npm", Name: "lodash", From: "4.17.20", To: "4.17.21"}
+	notes, err := gc.DependencyReleaseNotes(bump)
+	if err != nil {
+		t.Fatalf("DependencyReleaseNotes returned error: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Version != "4.17.21" {
+		t.Fatalf("Expected only the 4.17.21 release, got %+v", notes)
+	}
+
+	summary, err := gc.DependencyRiskSummary([]DependencyBump{bump, {Ecosystem: "go", Name: "example.com/private", From: "v1.0.0", To: "v2.0.0"}})
+	if err != nil {
+		t.Fatalf("DependencyRiskSummary returned error: %v", err)
+	}
+	if !strings.HasPrefix(summary, "Risk: low") {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	for _, want := range []string{"Fix prototype pollution", "example.com/private v1.0.0 -> v2.0.0 (go), major version change", "No release notes available"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in the prompt:\n%s", want, prompt)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Actions of a DependencyChange
+const (
+	DependencyAdded      = "added"
+	DependencyRemoved    = "removed"
+	DependencyUpgraded   = "upgraded"
+	DependencyDowngraded = "downgraded"
+)
+
+// DependencyChange is a dependency added, removed or moved to another
+// version by a changeset. From is empty for added dependencies and To for
+// removed ones.
+type DependencyChange struct {
+	DependencyBump
+	// Action is one of DependencyAdded, DependencyRemoved,
+	// DependencyUpgraded and DependencyDowngraded
+	Action string
+}
+
+// String describes the change the way a commit subject would, e.g. "bump
+// lodash from 4.17.20 to 4.17.21"
+func (c DependencyChange) String() string {
+	switch c.Action {
+	case DependencyAdded:
+		return fmt.Sprintf("add %s %s", c.Name, c.To)
+	case DependencyRemoved:
+		return fmt.Sprintf("remove %s %s", c.Name, c.From)
+	case DependencyDowngraded:
+		return fmt.Sprintf("downgrade %s from %s to %s", c.Name, c.From, c.To)
+	}
+	return fmt.Sprintf("bump %s from %s to %s", c.Name, c.From, c.To)
+}
+
+// dependencyFormat describes how a manifest or lock file lists versions
+type dependencyFormat struct {
+	ecosystem string
+	// line matches a dependency and its version on one line
+	line *regexp.Regexp
+	// key matches a line naming a dependency whose version is on the
+	// following line, matched by version
+	key, version *regexp.Regexp
+	// lock marks lock files, which list indirect dependencies too
+	lock bool
+}
+
+// Lines of the lock files ParseDependencyChanges understands
+var (
+	goSumLine        = regexp.MustCompile(`^(\S+) (v[^/\s]+) h1:\S+$`)
+	packageLockKey   = regexp.MustCompile(`^"(?:[^"]*node_modules/)?((?:@[^/"]+/)?[^/"]+)":\s*\{$`)
+	jsonVersion      = regexp.MustCompile(`^"version":\s*"v?([^"]+)",?$`)
+	yarnLockKey      = regexp.MustCompile(`^"?((?:@[^@/\s"]+/)?[^@\s"]+)@.*:$`)
+	yarnLockVersion  = regexp.MustCompile(`^version:?\s+"?([^"\s]+)"?$`)
+	tomlLockName     = regexp.MustCompile(`^name = "([^"]+)"$`)
+	tomlLockVersion  = regexp.MustCompile(`^version = "([^"]+)"$`)
+	gemfileLockSpec  = regexp.MustCompile(`^(\S+) \((\d[^)\s]*)\)$`)
+	composerLockName = regexp.MustCompile(`^"name":\s*"([^"]+)",?$`)
+)
+
+// dependencyFormats maps the base names of manifests and lock files to
+// their format
+var dependencyFormats = map[string]*dependencyFormat{
+	"go.mod":              {ecosystem: "go", line: goModRequire},
+	"package.json":        {ecosystem: "npm", line: packageJSONEntry},
+	"Cargo.toml":          {ecosystem: "cargo", line: cargoDependency},
+	"go.sum":              {ecosystem: "go", line: goSumLine, lock: true},
+	"package-lock.json":   {ecosystem: "npm", key: packageLockKey, version: jsonVersion, lock: true},
+	"npm-shrinkwrap.json": {ecosystem: "npm", key: packageLockKey, version: jsonVersion, lock: true},
+	"yarn.lock":           {ecosystem: "npm", key: yarnLockKey, version: yarnLockVersion, lock: true},
+	"Cargo.lock":          {ecosystem: "cargo", key: tomlLockName, version: tomlLockVersion, lock: true},
+	"poetry.lock":         {ecosystem: "pip", key: tomlLockName, version: tomlLockVersion, lock: true},
+	"Gemfile.lock":        {ecosystem: "ruby", line: gemfileLockSpec, lock: true},
+	"composer.lock":       {ecosystem: "composer", key: composerLockName, version: jsonVersion, lock: true},
+}
+
+// requirementsFormat covers requirements.txt, requirements-dev.txt and the
+// like
+var requirementsFormat = &dependencyFormat{ecosystem: "pip", line: requirementsPin}
+
+// dependencyFormatOf returns the format of a manifest or lock file, or nil
+// for other files
+func dependencyFormatOf(p string) *dependencyFormat {
+	base := path.Base(p)
+	if strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt") {
+		return requirementsFormat
+	}
+	return dependencyFormats[base]
+}
+
+// versionDiff holds the dependency versions on the removed and added lines
+// of a diff, the highest one where a dependency has several
+type versionDiff struct {
+	removed, added map[string]string
+	// order lists the dependencies as they first appear in the diff
+	order []string
+}
+
+// parseVersionDiff collects the dependency versions of a manifest or lock
+// file diff
+func parseVersionDiff(format *dependencyFormat, diff string) versionDiff {
+	versions := versionDiff{removed: make(map[string]string), added: make(map[string]string)}
+	record := func(sign byte, name, version string) {
+		if manifestFields[name] {
+			return
+		}
+		side := versions.added
+		if sign == '-' {
+			side = versions.removed
+		}
+		_, seenRemoved := versions.removed[name]
+		_, seenAdded := versions.added[name]
+		if !seenRemoved && !seenAdded {
+			versions.order = append(versions.order, name)
+		}
+		if known, ok := side[name]; !ok || compareVersions(version, known) > 0 {
+			side[name] = version
+		}
+	}
+
+	// With key and version patterns, the key may be an unchanged line
+	// above the changed version lines. Removed and added lines are
+	// grouped in a hunk, so each side remembers its own key.
+	current := map[byte]string{}
+	for _, line := range strings.Split(diff, "\n") {
+		if len(line) == 0 || strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || line[0] != '+' && line[0] != '-' && line[0] != ' ' {
+			current = map[byte]string{}
+			continue
+		}
+		sign, content := line[0], strings.TrimSpace(line[1:])
+
+		if format.line != nil {
+			if match := format.line.FindStringSubmatch(content); match != nil && sign != ' ' {
+				record(sign, match[1], match[2])
+			}
+			continue
+		}
+		if match := format.version.FindStringSubmatch(content); match != nil {
+			if sign != ' ' && current[sign] != "" {
+				record(sign, current[sign], match[1])
+			}
+			continue
+		}
+		key := ""
+		if match := format.key.FindStringSubmatch(content); match != nil {
+			key = match[1]
+		}
+		if sign == ' ' {
+			current['-'], current['+'] = key, key
+		} else {
+			current[sign] = key
+		}
+	}
+	return versions
+}
+
+// ParseDependencyChanges finds the dependencies added, removed, upgraded or
+// downgraded in the diffs of manifests (go.mod, package.json, Cargo.toml,
+// requirements*.txt) and lock files (go.sum, package-lock.json, yarn.lock,
+// Cargo.lock, poetry.lock, Gemfile.lock, composer.lock). Manifests are read
+// first; a dependency is reported once per ecosystem.
+func ParseDependencyChanges(changes []FileChange) []DependencyChange {
+	var manifests, locks []FileChange
+	for _, change := range changes {
+		if format := dependencyFormatOf(change.FilePath); format == nil {
+			continue
+		} else if format.lock {
+			locks = append(locks, change)
+		} else {
+			manifests = append(manifests, change)
+		}
+	}
+
+	var result []DependencyChange
+	seen := make(map[string]bool)
+	for _, change := range append(manifests, locks...) {
+		format := dependencyFormatOf(change.FilePath)
+		versions := parseVersionDiff(format, change.Diff)
+		for _, name := range versions.order {
+			from, removed := versions.removed[name]
+			to, added := versions.added[name]
+			key := format.ecosystem + " " + name
+			if seen[key] || removed && added && from == to {
+				continue
+			}
+			seen[key] = true
+
+			dependency := DependencyChange{DependencyBump: DependencyBump{Ecosystem: format.ecosystem, Name: name, From: from, To: to}}
+			switch {
+			case !removed:
+				dependency.Action = DependencyAdded
+			case !added:
+				dependency.Action = DependencyRemoved
+			case compareVersions(from, to) > 0:
+				dependency.Action = DependencyDowngraded
+			default:
+				dependency.Action = DependencyUpgraded
+			}
+			result = append(result, dependency)
+		}
+	}
+	return result
+}
+
+// maxDependencyLines bounds the dependency changes listed in the prompt,
+// e.g. for a go.sum after go mod tidy
+const maxDependencyLines = 15
+
+// dependencyContext lists the dependency changes for the prompt, so the
+// message can name the dependencies and versions
+func (gc *GitCommenter) dependencyContext(changes []FileChange) string {
+	dependencies := ParseDependencyChanges(changes)
+	if len(dependencies) == 0 {
+		return ""
+	}
+
+	var context strings.Builder
+	context.WriteString("DEPENDENCY CHANGES (from manifests and lock files):\n")
+	for i, dependency := range dependencies {
+		if i == maxDependencyLines {
+			context.WriteString(fmt.Sprintf("- ... and %d more\n", len(dependencies)-i))
+			break
+		}
+		context.WriteString(fmt.Sprintf("- %s (%s)\n", dependency, dependency.Ecosystem))
+	}
+	example := dependencies[0].String()
+	if style, err := LookupStyle(gc.config.Style); err == nil && style.Conventional {
+		example = "chore(deps): " + example
+	}
+	context.WriteString(fmt.Sprintf("Name the dependencies and versions in the message, e.g. %q\n\n", example))
+	return context.String()
+}
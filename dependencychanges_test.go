@@ -0,0 +1,56 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDependencyChanges(t *testing.T) {
+	changes := []FileChange{
+		{FilePath: "go.sum", Diff: "-github.com/spf13/cobra v1.7.0 h1:old=\n-github.com/spf13/cobra v1.7.0/go.mod h1:old=\n+github.com/spf13/cobra v1.8.0 h1:new=\n+github.com/spf13/cobra v1.8.0/go.mod h1:new=\n+github.com/google/uuid v1.6.0 h1:new=\n+golang.org/x/text v0.14.0/go.mod h1:new=\n"},
+		{FilePath: "go.mod", Diff: "-\tgithub.com/spf13/cobra v1.7.0\n+\tgithub.com/spf13/cobra v1.8.0\n"},
+		{FilePath: "web/package-lock.json", Diff: "@@ -10,7 +10,7 @@\n     \"node_modules/lodash\": {\n-      \"version\": \"4.17.20\",\n-      \"resolved\": \"https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz\",\n+      \"version\": \"4.17.21\",\n+      \"resolved\": \"https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz\",\n-    \"node_modules/@types/node\": {\n-      \"version\": \"20.1.0\",\n-      \"dev\": true\n-    },\n"},
+		{FilePath: "yarn.lock", Diff: "-react@^18.2.0:\n-  version \"18.3.0\"\n+react@^18.2.0:\n+  version \"18.2.0\"\n"},
+		{FilePath: "Cargo.lock", Diff: " [[package]]\n name = \"serde\"\n-version = \"1.0.190\"\n+version = \"1.0.200\"\n source = \"registry+https://github.com/rust-lang/crates.io-index\"\n"},
+		{FilePath: "Gemfile.lock", Diff: "-    rails (7.0.8)\n+    rails (7.1.2)\n"},
+		{FilePath: "README.md", Diff: "+name = \"serde\"\n"},
+	}
+
+	var got []string
+	for _, change := range ParseDependencyChanges(changes) {
+		got = append(got, change.Ecosystem+": "+change.String())
+	}
+	want := []string{
+		"go: bump github.com/spf13/cobra from v1.7.0 to v1.8.0",
+		"go: add github.com/google/uuid v1.6.0",
+		"npm: bump lodash from 4.17.20 to 4.17.21",
+		"npm: remove @types/node 20.1.0",
+		"npm: downgrade react from 18.3.0 to 18.2.0",
+		"cargo: bump serde from 1.0.190 to 1.0.200",
+		"ruby: bump rails from 7.0.8 to 7.1.2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected dependency changes:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestDependencyContext(t *testing.T) {
+	config := DefaultConfig()
+	config.HistoryExamples = 0
+	gc := New(config)
+
+	changes := []FileChange{{FilePath: "go.mod", ChangeType: "modified", Diff: "-\tgithub.com/spf13/cobra v1.7.0\n+\tgithub.com/spf13/cobra v1.8.0\n"}}
+	plan, err := gc.plan(changes)
+	if err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	for _, line := range []string{"- bump github.com/spf13/cobra from v1.7.0 to v1.8.0 (go)", `"chore(deps): bump github.com/spf13/cobra from v1.7.0 to v1.8.0"`} {
+		if !strings.Contains(plan.prompt, line) {
+			t.Errorf("Expected %q in the prompt:\n%s", line, plan.prompt)
+		}
+	}
+
+	if context := gc.dependencyContext([]FileChange{{FilePath: "main.go", Diff: "+package main\n"}}); context != "" {
+		t.Errorf("Expected no dependency context without manifests, got %q", context)
+	}
+}
@@ -0,0 +1,190 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// OllamaEmbedRequest represents a request to the Ollama embeddings API
+type OllamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// OllamaEmbedResponse represents a response from the Ollama embeddings API
+type OllamaEmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// Embed returns the embedding of each text from Config.EmbeddingModel.
+// Embeddings are cached like responses; the missing ones are requested in
+// one batch.
+func (gc *GitCommenter) Embed(texts []string) ([][]float64, error) {
+	if gc.config.EmbeddingModel == "" {
+		return nil, fmt.Errorf("no embedding model configured")
+	}
+
+	vectors := make([][]float64, len(texts))
+	keys := make([]string, len(texts))
+	var missing []int
+	for i, text := range texts {
+		keys[i] = CacheKey("embed", gc.config.EmbeddingModel, text)
+		if cached, ok := gc.cachedResponse(keys[i]); ok && json.Unmarshal([]byte(cached), &vectors[i]) == nil {
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	req := OllamaEmbedRequest{Model: gc.config.EmbeddingModel}
+	for _, i := range missing {
+		text, err := gc.guardPrompt(texts[i])
+		if err != nil {
+			return nil, err
+		}
+		if gc.anonymizer != nil {
+			text = gc.anonymizer.Anonymize(text)
+		}
+		req.Input = append(req.Input, text)
+	}
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := gc.client.Post(gc.config.OllamaEndpoint+"/api/embed", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Ollama API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	var embedResp OllamaEmbedResponse
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(embedResp.Embeddings) != len(missing) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d texts", len(embedResp.Embeddings), len(missing))
+	}
+
+	for j, i := range missing {
+		vectors[i] = embedResp.Embeddings[j]
+		if data, err := json.Marshal(vectors[i]); err == nil {
+			gc.storeResponse(keys[i], string(data))
+		}
+	}
+	return vectors, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0
+// when either is empty or their lengths differ
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// maxEmbeddingDiffLength bounds how much of a diff is embedded
+const maxEmbeddingDiffLength = 2000
+
+// similarSearchCommits is how many recent commits are indexed before
+// looking for similar ones
+const similarSearchCommits = 100
+
+// similarExampleCount is how many similar commits go into the prompt
+const similarExampleCount = 3
+
+// minSimilarity is the lowest similarity of a commit shown as an example
+const minSimilarity = 0.5
+
+// embeddingText is the text embedded for a diff: the diff, truncated
+func embeddingText(diff string) string {
+	return truncateUTF8(strings.TrimSpace(diff), maxEmbeddingDiffLength)
+}
+
+// changesDiff joins the diffs of changes
+func changesDiff(changes []FileChange) string {
+	var diff strings.Builder
+	for _, change := range changes {
+		diff.WriteString(change.Diff)
+		diff.WriteString("\n")
+	}
+	return diff.String()
+}
+
+// similarCommits returns the indexed commits whose diffs are most similar
+// to the changes, most similar first, when Config.EmbeddingModel is set.
+// The last similarSearchCommits commits are indexed first unless the
+// repository is read-only. It is best effort: without history or
+// embeddings there are none.
+func (gc *GitCommenter) similarCommits(changes []FileChange) []Commit {
+	if gc.config.EmbeddingModel == "" || len(changes) == 0 {
+		return nil
+	}
+	if !gc.config.ReadOnly {
+		if _, err := gc.indexCommits(similarSearchCommits); err != nil {
+			return nil
+		}
+	}
+	matches, err := gc.FindSimilar(changesDiff(changes))
+	if err != nil {
+		return nil
+	}
+
+	var similar []Commit
+	for _, match := range matches {
+		if match.Similarity >= minSimilarity && !isExemptMessage(match.Commit.Message()) && len(similar) < similarExampleCount {
+			similar = append(similar, match.Commit)
+		}
+	}
+	return similar
+}
+
+// similarCommitsPrompt lists similar commits for the prompt, leaving out
+// those already shown as examples
+func similarCommitsPrompt(similar, shown []Commit) string {
+	var prompt strings.Builder
+	for _, commit := range similar {
+		duplicate := false
+		for _, example := range shown {
+			duplicate = duplicate || example.Hash == commit.Hash
+		}
+		if duplicate {
+			continue
+		}
+		if prompt.Len() == 0 {
+			prompt.WriteString("Past commits in this repository with similar changes (describe this change the same way where it fits):\n")
+		}
+		prompt.WriteString("- " + commit.Subject + "\n")
+		if commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(commit.Body, 200), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+	if prompt.Len() == 0 {
+		return ""
+	}
+	return prompt.String() + "\n"
+}
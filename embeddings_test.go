@@ -0,0 +1,128 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// embedServer answers embedding requests with [1, 0] for texts mentioning
+// "limit" and [0, 1] otherwise, counting the texts it embedded
+func embedServer(t *testing.T, embedded *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req OllamaEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("Expected the embedding model, got %q", req.Model)
+		}
+		var resp OllamaEmbedResponse
+		for _, text := range req.Input {
+			*embedded++
+			if strings.Contains(text, "limit") {
+				resp.Embeddings = append(resp.Embeddings, []float64{1, 0})
+			} else {
+				resp.Embeddings = append(resp.Embeddings, []float64{0, 1})
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestEmbedCachesEmbeddings(t *testing.T) {
+	var embedded int
+	server := embedServer(t, &embedded)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	if _, err := gc.Embed([]string{"x"}); err == nil {
+		t.Error("Expected an error without an embedding model")
+	}
+
+	config.EmbeddingModel = "nomic-embed-text"
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc.SetCache(cache)
+	vectors, err := gc.Embed([]string{"rate limit", "docs"})
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors %v", vectors)
+	}
+	if _, err := gc.Embed([]string{"docs", "rate limit", "new limit"}); err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if embedded != 3 {
+		t.Errorf("Expected cached texts not to be embedded again, embedded %d", embedded)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	for _, test := range []struct {
+		a, b []float64
+		want float64
+	}{
+		{[]float64{1, 0}, []float64{2, 0}, 1},
+		{[]float64{1, 0}, []float64{0, 1}, 0},
+		{[]float64{1, 1}, []float64{-1, -1}, -1},
+		{[]float64{1}, []float64{1, 0}, 0},
+		{nil, nil, 0},
+	} {
+		if got := cosineSimilarity(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSimilarCommitsInPrompt(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, commit := range []struct{ file, content, message string }{
+		{"limiter.go", "package app\n\nfunc limit() {}\n", "feat(limit): add the token bucket"},
+		{"README.md", "# app\n", "docs: describe the app"},
+	} {
+		writeFile(t, dir, commit.file, commit.content)
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", commit.message)
+	}
+
+	var embedded int
+	server := embedServer(t, &embedded)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.HistoryExamples = 0
+	gc := New(config)
+	changes := []FileChange{{FilePath: "limiter.go", ChangeType: "modified", Diff: "+func limitBurst() {}\n", LinesAdded: 1}}
+	if similar := gc.similarCommits(changes); similar != nil {
+		t.Errorf("Expected no retrieval without an embedding model, got %v", similar)
+	}
+
+	config.EmbeddingModel = "nomic-embed-text"
+	similar := gc.similarCommits(changes)
+	if len(similar) != 1 || similar[0].Subject != "feat(limit): add the token bucket" {
+		t.Fatalf("Expected the limiter commit only, got %+v", similar)
+	}
+	prompt := gc.buildPrompt(gc.buildChangeContext(changes), changes)
+	if !strings.Contains(prompt, "Past commits in this repository with similar changes") || !strings.Contains(prompt, "- feat(limit): add the token bucket\n") {
+		t.Errorf("Expected the similar commit in the prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "describe the app") {
+		t.Errorf("Expected the dissimilar commit left out:\n%s", prompt)
+	}
+	if prompt := similarCommitsPrompt(similar, similar); prompt != "" {
+		t.Errorf("Expected commits already shown as examples left out, got %q", prompt)
+	}
+}
@@ -0,0 +1,222 @@
+package gitcommenter
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed evaldata/default.yaml
+var defaultEvalDataset []byte
+
+// EvalCase is one labeled example: staged changes and the message a human
+// wrote for them
+type EvalCase struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description,omitempty"`
+	Changes     []EvalChange `yaml:"changes"`
+	// Reference is the expected commit message
+	Reference string `yaml:"reference"`
+	// Keywords should appear in a good message. Without them the
+	// significant words of the reference subject are used.
+	Keywords []string `yaml:"keywords,omitempty"`
+}
+
+// EvalChange is a staged file of an EvalCase
+type EvalChange struct {
+	Path       string `yaml:"path"`
+	ChangeType string `yaml:"change_type"`
+	Diff       string `yaml:"diff"`
+}
+
+// EvalResult is the score of one generated message
+type EvalResult struct {
+	Case    string
+	Message string
+	// Error is set when no message could be generated
+	Error string
+	// Valid reports whether the message follows the style and whitelists;
+	// Problem says why not
+	Valid   bool
+	Problem string
+	// KeywordRecall is the fraction of keywords found in the message
+	KeywordRecall float64
+	// LengthScore is 1 for subjects up to 50 characters, 0.5 up to 72 and 0
+	// beyond
+	LengthScore float64
+	// Score averages validity, keyword recall and length
+	Score float64
+}
+
+// EvalReport holds the results of one evaluation run
+type EvalReport struct {
+	Model   string
+	Style   string
+	Results []EvalResult
+}
+
+// LoadEvalDataset reads a dataset file, or the built-in dataset when path is
+// empty
+func LoadEvalDataset(path string) ([]EvalCase, error) {
+	data := defaultEvalDataset
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read eval dataset: %w", err)
+		}
+	}
+
+	var dataset struct {
+		Cases []EvalCase `yaml:"cases"`
+	}
+	if err := yaml.Unmarshal(data, &dataset); err != nil {
+		return nil, fmt.Errorf("failed to parse eval dataset: %w", err)
+	}
+	if len(dataset.Cases) == 0 {
+		return nil, fmt.Errorf("eval dataset has no cases")
+	}
+	return dataset.Cases, nil
+}
+
+// Evaluate generates a message for every case with the current model,
+// prompt and style, and scores it against the reference. Failed
+// generations are recorded in the results rather than stopping the run.
+func (gc *GitCommenter) Evaluate(cases []EvalCase) (*EvalReport, error) {
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EvalReport{Model: gc.config.Model, Style: style.Name}
+	for _, evalCase := range cases {
+		result := EvalResult{Case: evalCase.Name}
+
+		suggestion, err := gc.GenerateCommitMessage(gc.evalChanges(evalCase))
+		if err != nil {
+			result.Error = err.Error()
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		result.Message = suggestion.Message()
+		if problem := gc.validateSuggestion(style, suggestion); problem != nil {
+			result.Problem = problem.Error()
+		} else {
+			result.Valid = true
+		}
+		result.KeywordRecall = keywordRecall(evalCase.keywords(), result.Message)
+		result.LengthScore = subjectLengthScore(suggestion.Subject)
+
+		validity := 0.0
+		if result.Valid {
+			validity = 1
+		}
+		result.Score = (validity + result.KeywordRecall + result.LengthScore) / 3
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// Averages returns the mean validity, keyword recall, length score and
+// overall score. Failed generations count as zero.
+func (r *EvalReport) Averages() (valid, recall, length, score float64) {
+	if len(r.Results) == 0 {
+		return 0, 0, 0, 0
+	}
+	for _, result := range r.Results {
+		if result.Valid {
+			valid++
+		}
+		recall += result.KeywordRecall
+		length += result.LengthScore
+		score += result.Score
+	}
+	n := float64(len(r.Results))
+	return valid / n, recall / n, length / n, score / n
+}
+
+// evalChanges converts the changes of a case
+func (gc *GitCommenter) evalChanges(evalCase EvalCase) []FileChange {
+	var changes []FileChange
+	for _, change := range evalCase.Changes {
+		added, removed := gc.countDiffLines(change.Diff)
+		changes = append(changes, FileChange{
+			FilePath:     change.Path,
+			ChangeType:   change.ChangeType,
+			Diff:         change.Diff,
+			LinesAdded:   added,
+			LinesRemoved: removed,
+		})
+	}
+	return changes
+}
+
+// evalStopWords are reference words that say nothing about the change
+var evalStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true,
+	"to": true, "in": true, "on": true, "for": true, "with": true, "when": true,
+	"is": true, "it": true, "by": true, "from": true, "into": true,
+}
+
+// keywords returns the explicit keywords or the significant words of the
+// reference subject
+func (c EvalCase) keywords() []string {
+	if len(c.Keywords) > 0 {
+		return c.Keywords
+	}
+
+	subject, _, _ := strings.Cut(c.Reference, "\n")
+	if parsed, ok := ParseConventionalSubject(subject); ok {
+		subject = parsed.Description
+	}
+	var keywords []string
+	for _, word := range strings.Fields(strings.ToLower(subject)) {
+		word = strings.Trim(word, ".,:;'\"()")
+		if len(word) > 1 && !evalStopWords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+	return keywords
+}
+
+// keywordRecall returns the fraction of keywords found in message. Common
+// suffixes are ignored so "retries" matches "retry".
+func keywordRecall(keywords []string, message string) float64 {
+	if len(keywords) == 0 {
+		return 1
+	}
+	message = strings.ToLower(message)
+	found := 0
+	for _, keyword := range keywords {
+		if strings.Contains(message, keywordStem(strings.ToLower(keyword))) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(keywords))
+}
+
+// keywordStem drops a common English suffix
+func keywordStem(word string) string {
+	for _, suffix := range []string{"ing", "ies", "ied", "es", "ed", "s", "y"} {
+		if stem := strings.TrimSuffix(word, suffix); stem != word && len(stem) >= 3 {
+			return stem
+		}
+	}
+	return word
+}
+
+// subjectLengthScore rewards subjects that fit the usual 50/72 limits
+func subjectLengthScore(subject string) float64 {
+	switch length := utf8.RuneCountInString(subject); {
+	case length <= 50:
+		return 1
+	case length <= 72:
+		return 0.5
+	default:
+		return 0
+	}
+}
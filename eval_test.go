@@ -0,0 +1,62 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadEvalDatasetBuiltIn(t *testing.T) {
+	cases, err := LoadEvalDataset("")
+	if err != nil {
+		t.Fatalf("LoadEvalDataset returned error: %v", err)
+	}
+	for _, evalCase := range cases {
+		if evalCase.Name == "" || evalCase.Reference == "" || len(evalCase.Changes) == 0 {
+			t.Errorf("Incomplete eval case %+v", evalCase)
+		}
+	}
+}
+
+func TestEvalKeywords(t *testing.T) {
+	evalCase := EvalCase{Reference: "fix(config): fall back to the defaults when missing"}
+	keywords := evalCase.keywords()
+	if len(keywords) != 4 || keywords[0] != "fall" || keywords[3] != "missing" {
+		t.Errorf("Unexpected keywords %v", keywords)
+	}
+
+	if recall := keywordRecall([]string{"retry", "backoff"}, "feat: retries requests"); recall != 0.5 {
+		t.Errorf("keywordRecall = %v, want 0.5", recall)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat(client): retry requests with backoff", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	cases := []EvalCase{{
+		Name:      "retry",
+		Changes:   []EvalChange{{Path: "client.go", ChangeType: "modified", Diff: "+retry"}},
+		Reference: "feat(client): retry failed requests with backoff",
+		Keywords:  []string{"retry", "backoff", "jitter"},
+	}}
+	report, err := gc.Evaluate(cases)
+	if err != nil {
+		t.Fatalf("Evaluate returned error: %v", err)
+	}
+
+	result := report.Results[0]
+	if !result.Valid || result.LengthScore != 1 || result.KeywordRecall < 0.66 || result.KeywordRecall > 0.67 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if _, _, _, score := report.Averages(); score != result.Score {
+		t.Errorf("Averages score = %v, want %v", score, result.Score)
+	}
+}
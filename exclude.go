@@ -0,0 +1,79 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// filterExcluded splits the changes into those whose paths match neither
+// Config.Exclude nor the .aiignore file and those left out of the prompt
+func (gc *GitCommenter) filterExcluded(changes []FileChange) (included, excluded []FileChange, err error) {
+	ignore, err := gc.LoadAIIgnore()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(gc.config.Exclude) == 0 && len(ignore) == 0 {
+		return changes, nil, nil
+	}
+
+	for _, change := range changes {
+		p := NormalizePath(change.FilePath)
+		if MatchesAny(p, gc.config.Exclude) || MatchesIgnore(p, ignore) {
+			excluded = append(excluded, change)
+		} else {
+			included = append(included, change)
+		}
+	}
+	return included, excluded, nil
+}
+
+// excludedSummary counts the files left out of the prompt for
+// Config.CountExcluded, so the model still sees the size of the change
+func excludedSummary(excluded []FileChange) string {
+	if len(excluded) == 0 {
+		return ""
+	}
+	added, removed := 0, 0
+	for _, change := range excluded {
+		added += change.LinesAdded
+		removed += change.LinesRemoved
+	}
+	return fmt.Sprintf("EXCLUDED FILES (committed, diffs not shown): %d file(s), +%d -%d lines\n\n", len(excluded), added, removed)
+}
+
+// MatchesAny reports whether filePath matches one of the glob patterns.
+// A pattern matches the full path, the base name, or, when it ends with
+// "/", any path below that directory. A pattern ending with "/**" matches
+// any path below that directory relative to the repository root.
+func MatchesAny(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+		if pattern == "" {
+			continue
+		}
+
+		if strings.HasSuffix(pattern, "/**") {
+			if strings.HasPrefix(filePath, strings.TrimSuffix(pattern, "**")) {
+				return true
+			}
+			continue
+		}
+
+		if strings.HasSuffix(pattern, "/") {
+			dir := strings.TrimSuffix(pattern, "/")
+			if strings.HasPrefix(filePath, dir+"/") || strings.Contains(filePath, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(filePath)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesAny(t *testing.T) {
+	patterns := []string{"dist/", "*.min.js", "docs/*.md", "cmd/**"}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"dist/app.js", true},
+		{"web/dist/app.js", true},
+		{"assets/vendor.min.js", true},
+		{"docs/guide.md", true},
+		{"docs/api/guide.md", false},
+		{"main.go", false},
+		{"distribution/app.js", false},
+		{"cmd/ai-git-auto/main.go", true},
+		{"tools/cmd/main.go", false},
+	}
+
+	for _, test := range tests {
+		if got := MatchesAny(test.path, patterns); got != test.expected {
+			t.Errorf("MatchesAny(%s) = %v, want %v", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestFilterExcluded(t *testing.T) {
+	config := DefaultConfig()
+	config.Exclude = []string{"*.lock"}
+	commenter := New(config)
+
+	changes := []FileChange{{FilePath: "main.go"}, {FilePath: "Cargo.lock", LinesAdded: 40, LinesRemoved: 2}}
+	included, excluded, err := commenter.filterExcluded(changes)
+	if err != nil {
+		t.Fatalf("filterExcluded returned error: %v", err)
+	}
+	if len(included) != 1 || included[0].FilePath != "main.go" {
+		t.Errorf("Unexpected filtered changes: %+v", included)
+	}
+	if got := excludedSummary(excluded); !strings.Contains(got, "1 file(s), +40 -2 lines") {
+		t.Errorf("Unexpected excluded summary %q", got)
+	}
+}
+
+func TestTicketPrefix(t *testing.T) {
+	config := DefaultConfig()
+	config.TicketPrefix = "PROJ-42"
+	commenter := New(config)
+
+	suggestion := commenter.parseCommitSuggestion("feat: add export", nil)
+	if suggestion.Subject != "PROJ-42 feat: add export" {
+		t.Errorf("Unexpected subject %q", suggestion.Subject)
+	}
+
+	suggestion = commenter.parseCommitSuggestion("PROJ-42 fix: crash", nil)
+	if suggestion.Subject != "PROJ-42 fix: crash" {
+		t.Errorf("Expected the prefix not to be duplicated, got %q", suggestion.Subject)
+	}
+}
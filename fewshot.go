@@ -0,0 +1,90 @@
+package gitcommenter
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultHistoryExamples is the default Config.HistoryExamples
+const DefaultHistoryExamples = 50
+
+// historyExampleCount is how many messages from the history go into the
+// prompt
+const historyExampleCount = 5
+
+// historyExamples returns up to historyExampleCount representative
+// messages among the last Config.HistoryExamples commits, newest first.
+// A repository without commits has none.
+func (gc *GitCommenter) historyExamples() []Commit {
+	if gc.config.HistoryExamples <= 0 {
+		return nil
+	}
+	commits, err := gc.recentCommits(gc.config.HistoryExamples)
+	if err != nil {
+		return nil
+	}
+	return representativeCommits(commits, historyExampleCount)
+}
+
+// representativeCommits picks up to n informative messages from commits
+// (newest first), alternating between conventional types so that the
+// common ones come first but rarer ones are shown too. Messages written by
+// git and subjects too short or too long to be examples are skipped.
+func representativeCommits(commits []Commit, n int) []Commit {
+	groups := make(map[string][]int)
+	var order []string
+	for i, commit := range commits {
+		length := utf8.RuneCountInString(commit.Subject)
+		if isExemptMessage(commit.Message()) || len(strings.Fields(commit.Subject)) < 3 || length > 100 {
+			continue
+		}
+		_, subject, _ := SplitGitmoji(commit.Subject)
+		parsed, _ := ParseConventionalSubject(subject)
+		if _, ok := groups[parsed.Type]; !ok {
+			order = append(order, parsed.Type)
+		}
+		groups[parsed.Type] = append(groups[parsed.Type], i)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(groups[order[i]]) > len(groups[order[j]]) })
+
+	var picked []int
+	for round := 0; len(picked) < n; round++ {
+		added := false
+		for _, key := range order {
+			if round < len(groups[key]) && len(picked) < n {
+				picked = append(picked, groups[key][round])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	sort.Ints(picked)
+
+	examples := make([]Commit, 0, len(picked))
+	for _, i := range picked {
+		examples = append(examples, commits[i])
+	}
+	return examples
+}
+
+// historyExamplesPrompt lists the examples for the prompt, or returns ""
+// without any
+func historyExamplesPrompt(examples []Commit) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var prompt strings.Builder
+	prompt.WriteString("Recent commit messages from this repository (match their conventions: scopes, tense, capitalization, emoji, body layout):\n")
+	for _, commit := range examples {
+		prompt.WriteString("- " + commit.Subject + "\n")
+		if commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(commit.Body, 200), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\n")
+	return prompt.String()
+}
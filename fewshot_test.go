@@ -0,0 +1,68 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepresentativeCommits(t *testing.T) {
+	commits := []Commit{
+		{Subject: "feat(api): add request paging"},
+		{Subject: "Merge branch 'main' into feature"},
+		{Subject: "fix(cli): keep flags after the subcommand"},
+		{Subject: "feat(api): return the total count"},
+		{Subject: "wip"},
+		{Subject: "docs: describe the paging parameters"},
+		{Subject: "feat(ui): show the page size selector"},
+		{Subject: "fix(api): reject negative page numbers"},
+		{Subject: "feat: " + strings.Repeat("very long ", 12)},
+	}
+
+	got := representativeCommits(commits, 4)
+	var subjects []string
+	for _, commit := range got {
+		subjects = append(subjects, commit.Subject)
+	}
+	want := []string{
+		"feat(api): add request paging",
+		"fix(cli): keep flags after the subcommand",
+		"feat(api): return the total count",
+		"docs: describe the paging parameters",
+	}
+	if strings.Join(subjects, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected the common types first and each type once, newest first, got:\n%s", strings.Join(subjects, "\n"))
+	}
+
+	if got := representativeCommits(commits[1:2], 4); len(got) != 0 {
+		t.Errorf("Expected no examples from a merge, got %v", got)
+	}
+}
+
+func TestHistoryExamplesInPrompt(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, message := range []string{"✨ feat(core): add the plugin loader", "🐛 fix(core): close plugin files on error"} {
+		writeFile(t, dir, "core.go", strings.Repeat("// x\n", i+1))
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", message, "-m", "Refs #4")
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	changes := []FileChange{{FilePath: "core.go", ChangeType: "modified", Diff: "+// y\n", LinesAdded: 1}}
+
+	prompt := gc.buildPrompt(gc.buildChangeContext(changes), changes)
+	for _, part := range []string{"Recent commit messages from this repository", "- 🐛 fix(core): close plugin files on error\n  Refs #4", "- ✨ feat(core): add the plugin loader"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected %q in the prompt:\n%s", part, prompt)
+		}
+	}
+	if data := gc.promptData("", changes, gc.PromptBudget()); len(data.HistoryExamples) != 2 || !strings.HasPrefix(data.HistoryExamples[0], "🐛 fix(core)") {
+		t.Errorf("Expected the examples in the template data, got %q", data.HistoryExamples)
+	}
+
+	config.HistoryExamples = 0
+	if prompt := gc.buildPrompt(gc.buildChangeContext(changes), changes); strings.Contains(prompt, "Recent commit messages") {
+		t.Errorf("Expected no examples when disabled:\n%s", prompt)
+	}
+}
@@ -0,0 +1,156 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fixupSearchCommits is how many recent commits FindFixupTarget considers
+const fixupSearchCommits = 10
+
+// TemplateFixup is the CommitSuggestion.Template of FixupSuggestion
+const TemplateFixup = "fixup"
+
+// diffHunk is the position of a hunk in the old and new version of a file,
+// with the function git names in its header
+type diffHunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	section            string
+}
+
+// hunkHeader matches "@@ -12,3 +12,4 @@ func name()"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// parseHunks returns the hunks of a diff by the new path of each file; a
+// deleted file is listed under its old path
+func parseHunks(diff string) map[string][]diffHunk {
+	hunks := make(map[string][]diffHunk)
+	var oldPath, path string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			oldPath, path = "", ""
+		case strings.HasPrefix(line, "--- ") && path == "":
+			oldPath = strings.TrimPrefix(strings.TrimRight(line[4:], "\t"), "a/")
+		case strings.HasPrefix(line, "+++ ") && path == "":
+			path = strings.TrimPrefix(strings.TrimRight(line[4:], "\t"), "b/")
+			if path == "/dev/null" {
+				path = oldPath
+			}
+		case path != "":
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			hunk := diffHunk{section: strings.TrimSpace(match[5])}
+			hunk.oldStart, hunk.oldLines = hunkRange(match[1], match[2])
+			hunk.newStart, hunk.newLines = hunkRange(match[3], match[4])
+			hunks[path] = append(hunks[path], hunk)
+		}
+	}
+	return hunks
+}
+
+// hunkRange parses the start and optional line count of a hunk header
+func hunkRange(start, lines string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	n := 1
+	if lines != "" {
+		n, _ = strconv.Atoi(lines)
+	}
+	return s, n
+}
+
+// editsSameCode reports whether the staged hunks of a file change the
+// functions or lines that the hunks of a commit changed
+func editsSameCode(staged, committed []diffHunk) bool {
+	for _, s := range staged {
+		for _, c := range committed {
+			if s.section != "" && s.section == c.section {
+				return true
+			}
+			// The staged hunk's old lines are the commit's new lines when
+			// the file was not changed since
+			if s.oldStart <= c.newStart+c.newLines && c.newStart <= s.oldStart+s.oldLines {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isFixupSubject reports whether a subject is one of git's autosquash
+// markers
+func isFixupSubject(subject string) bool {
+	return strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") || strings.HasPrefix(subject, "amend! ")
+}
+
+// FindFixupTarget returns the recent commit the changes clearly belong
+// to, or nil: the newest of the last fixupSearchCommits commits not yet on
+// the upstream branch that touched every changed file and whose changes
+// are in the same functions or lines. Only modifications qualify.
+func (gc *GitCommenter) FindFixupTarget(changes []FileChange) (*Commit, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	staged := make(map[string][]diffHunk)
+	for _, change := range changes {
+		if change.ChangeType != "modified" {
+			return nil, nil
+		}
+		staged[change.FilePath] = parseHunks(change.Diff)[change.FilePath]
+	}
+
+	// Pushed commits should not be rewritten
+	revRange := "HEAD"
+	if _, err := gc.gitOutput("rev-parse", "--verify", "--quiet", "@{upstream}"); err == nil {
+		revRange = "@{upstream}..HEAD"
+	}
+	output, err := gc.gitOutput("log", "--no-merges", "-n", fmt.Sprint(fixupSearchCommits), logFormat, revRange)
+	if err != nil {
+		if _, headErr := gc.HeadCommit(); headErr != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list recent commits: %w", err)
+	}
+
+	for _, commit := range parseLog(output) {
+		if isFixupSubject(commit.Subject) {
+			continue
+		}
+		diff, err := gc.CommitDiff(commit.Hash)
+		if err != nil {
+			return nil, err
+		}
+		committed := parseHunks(diff)
+		matches := true
+		for path, hunks := range staged {
+			if !editsSameCode(hunks, committed[path]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return &commit, nil
+		}
+	}
+	return nil, nil
+}
+
+// FixupSuggestion returns the "fixup! <subject>" message of a commit
+// amending target with the changes, which git rebase --autosquash moves
+// after it
+func FixupSuggestion(target Commit, changes []FileChange) *CommitSuggestion {
+	suggestion := &CommitSuggestion{
+		Subject:    "fixup! " + target.Subject,
+		Confidence: 1,
+		Template:   TemplateFixup,
+	}
+	for _, change := range changes {
+		suggestion.FilesAffected = append(suggestion.FilesAffected, change.FilePath)
+	}
+	return suggestion
+}
@@ -0,0 +1,75 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHunks(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\nindex 1..2 100644\n--- a/a.go\n+++ b/a.go\n" +
+		"@@ -10,3 +10,4 @@ func Allow() bool {\n x\n+y\n@@ -40 +41 @@\n-a\n+b\n" +
+		"diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n--- a/gone.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n"
+
+	hunks := parseHunks(diff)
+	if got := hunks["a.go"]; len(got) != 2 || got[0] != (diffHunk{10, 3, 10, 4, "func Allow() bool {"}) || got[1] != (diffHunk{40, 1, 41, 1, ""}) {
+		t.Errorf("Unexpected hunks of a.go: %+v", got)
+	}
+	if got := hunks["gone.go"]; len(got) != 1 || got[0].newLines != 0 {
+		t.Errorf("Expected the deleted file under its old path, got %+v", hunks)
+	}
+}
+
+func TestFindFixupTarget(t *testing.T) {
+	dir := initTestRepo(t)
+	body := func(name string) string {
+		return "func " + name + "() {\n" + strings.Repeat("\tstep()\n", 10) + "}\n\n"
+	}
+	write := func(content string) {
+		writeFile(t, dir, "limiter.go", "package app\n\n"+content)
+	}
+	write(body("Allow") + body("Reset"))
+	writeFile(t, dir, "other.go", "package app\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "feat: add the limiter")
+	write(strings.Replace(body("Allow"), "step()", "check()", 1) + body("Reset"))
+	gitRun(t, dir, "commit", "-q", "-am", "fix(limit): check before stepping")
+	writeFile(t, dir, "other.go", "package app\n\nvar x = 1\n")
+	gitRun(t, dir, "commit", "-q", "-am", "chore: add x")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	scan := func() []FileChange {
+		t.Helper()
+		gitRun(t, dir, "add", ".")
+		changes, err := gc.ScanStagedChanges()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes
+	}
+
+	// A further edit of Allow belongs to the commit that last changed it
+	write(strings.Replace(body("Allow"), "step()", "check()", 2) + body("Reset"))
+	target, err := gc.FindFixupTarget(scan())
+	if err != nil || target == nil || target.Subject != "fix(limit): check before stepping" {
+		t.Fatalf("Expected the fix commit as the target, got %+v, %v", target, err)
+	}
+	if fixup := FixupSuggestion(*target, scan()); fixup.Subject != "fixup! fix(limit): check before stepping" || fixup.Template != TemplateFixup || len(fixup.FilesAffected) != 1 {
+		t.Errorf("Unexpected fixup suggestion %+v", fixup)
+	}
+
+	// Code no recent commit touched is a change of its own
+	write(strings.Replace(body("Allow"), "step()", "check()", 1) + strings.Replace(body("Reset"), "step()", "clear()", 10))
+	if target, err := gc.FindFixupTarget(scan()); err != nil || target == nil || target.Subject != "feat: add the limiter" {
+		t.Errorf("Expected the commit adding Reset as the target, got %+v, %v", target, err)
+	}
+	gitRun(t, dir, "branch", "pushed")
+	gitRun(t, dir, "branch", "--set-upstream-to=pushed")
+	if target, err := gc.FindFixupTarget(scan()); err != nil || target != nil {
+		t.Errorf("Expected pushed commits to be left alone, got %+v, %v", target, err)
+	}
+	if target, err := gc.FindFixupTarget([]FileChange{{FilePath: "new.go", ChangeType: "added", Diff: "+package app\n"}}); err != nil || target != nil {
+		t.Errorf("Expected no target for a new file, got %+v, %v", target, err)
+	}
+}
@@ -0,0 +1,170 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// DefaultGitHubAPI is the public GitHub REST API
+const DefaultGitHubAPI = "https://api.github.com"
+
+// githubRepoPattern finds owner/repo in GitHub clone and web URLs such as
+// git+https://github.com/owner/repo.git or git@github.com:owner/repo
+var githubRepoPattern = regexp.MustCompile(`github\.com[/:]([\w.-]+)/([\w.-]+?)(?:\.git)?(?:[/#?].*)?$`)
+
+// GitHubRepoFromURL returns "owner/repo" for a GitHub URL, or "" for other
+// hosts
+func GitHubRepoFromURL(url string) string {
+	match := githubRepoPattern.FindStringSubmatch(strings.TrimSpace(url))
+	if match == nil {
+		return ""
+	}
+	return match[1] + "/" + match[2]
+}
+
+// GitHubRepository returns "owner/repo" for the repository's origin remote
+func (gc *GitCommenter) GitHubRepository() (string, error) {
+	url, err := gc.gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	repo := GitHubRepoFromURL(url)
+	if repo == "" {
+		return "", fmt.Errorf("origin %s is not a GitHub repository", strings.TrimSpace(url))
+	}
+	return repo, nil
+}
+
+// githubToken returns the token used for GitHub API requests, if any.
+// Anonymous requests work but are rate limited.
+func githubToken() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// githubGet decodes the response of a GitHub REST API path such as
+// /repos/owner/repo/releases into v
+func (gc *GitCommenter) githubGet(path string, v any) error {
+	header := http.Header{"Accept": {"application/vnd.github+json"}}
+	if token := githubToken(); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return gc.getJSON(gc.githubAPI()+path, header, v)
+}
+
+// githubPost sends body as JSON to a GitHub REST API path and decodes the
+// response into v. Unlike reads, writes need a token (see
+// githubCredential).
+func (gc *GitCommenter) githubPost(path string, body, v any) error {
+	token, err := gc.githubCredential()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	header := http.Header{
+		"Accept":        {"application/vnd.github+json"},
+		"Authorization": {"Bearer " + token},
+		"Content-Type":  {"application/json"},
+	}
+	return gc.sendJSON(http.MethodPost, gc.githubAPI()+path, header, bytes.NewReader(data), v)
+}
+
+// githubAPI returns the configured GitHub REST API without a trailing slash
+func (gc *GitCommenter) githubAPI() string {
+	if gc.config.GitHubAPI == "" {
+		return DefaultGitHubAPI
+	}
+	return strings.TrimSuffix(gc.config.GitHubAPI, "/")
+}
+
+// githubHost returns the web host of the configured GitHub API, e.g.
+// github.com for https://api.github.com
+func (gc *GitCommenter) githubHost() string {
+	api := gc.githubAPI()
+	if api == DefaultGitHubAPI {
+		return "github.com"
+	}
+	if u, err := url.Parse(api); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "github.com"
+}
+
+// githubCredential returns a token for GitHub API writes: GITHUB_TOKEN or
+// GH_TOKEN, or else the password stored for the host by git's credential
+// helper, e.g. the macOS keychain or Git Credential Manager
+func (gc *GitCommenter) githubCredential() (string, error) {
+	if token := githubToken(); token != "" {
+		return token, nil
+	}
+	if password := gc.storedCredential(gc.githubHost()); password != "" {
+		return password, nil
+	}
+	return "", fmt.Errorf("no GitHub token for %s: set GITHUB_TOKEN or store one with git's credential helper", gc.githubHost())
+}
+
+// storedCredential returns the password git's credential helper stores for
+// https://host, or "" when there is none
+func (gc *GitCommenter) storedCredential(host string) string {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Dir = gc.config.RepositoryPath
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	// Never ask on the terminal; only stored credentials are used
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if password, ok := strings.CutPrefix(line, "password="); ok && password != "" {
+			return password
+		}
+	}
+	return ""
+}
+
+// getJSON decodes the JSON response of a GET request into v
+func (gc *GitCommenter) getJSON(url string, header http.Header, v any) error {
+	return gc.sendJSON(http.MethodGet, url, header, nil, v)
+}
+
+// sendJSON makes a request and decodes its JSON response into v
+func (gc *GitCommenter) sendJSON(method, url string, header http.Header, body io.Reader, v any) error {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	// Some registries, e.g. crates.io, reject requests without one
+	req.Header.Set("User-Agent", "ai-git-commit")
+
+	resp, err := gc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
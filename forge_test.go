@@ -0,0 +1,18 @@
+package gitcommenter
+
+import "testing"
+
+func TestGitHubRepoFromURL(t *testing.T) {
+	for url, want := range map[string]string{
+		"git+https://github.com/lodash/lodash.git":     "lodash/lodash",
+		"git@github.com:spf13/cobra.git":               "spf13/cobra",
+		"https://github.com/psf/requests/tree/main":    "psf/requests",
+		"https://github.com/serde-rs/serde#readme":     "serde-rs/serde",
+		"https://gitlab.com/gitlab-org/gitlab":         "",
+		"https://github.com/owner/repo.js/releases/v2": "owner/repo.js",
+	} {
+		if got := GitHubRepoFromURL(url); got != want {
+			t.Errorf("GitHubRepoFromURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
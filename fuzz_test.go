@@ -0,0 +1,127 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// The parsers below take untrusted input from models and git. The fuzz
+// targets run their seed corpus as part of go test; run them with e.g.
+// go test -fuzz FuzzParseCommitSuggestion to search for new failures.
+
+func FuzzParseCommitSuggestion(f *testing.F) {
+	for _, seed := range []string{
+		"feat: add cache\n\nStores responses on disk.",
+		"",
+		"\n\n\n",
+		"\r\nfix: handle CRLF\r\n\r\nBody\r\n",
+		"subject\rwith\rcarriage returns",
+		"```\nfeat: fenced\n```",
+		"✨ Add init wizard",
+		":sparkles: add",
+		"PROJ-1 feat(api)!: breaking",
+		"   \t  ",
+	} {
+		f.Add(seed, false, "")
+		f.Add(seed, true, "PROJ-1")
+	}
+
+	f.Fuzz(func(t *testing.T, response string, gitmoji bool, ticket string) {
+		config := DefaultConfig()
+		config.Gitmoji = gitmoji
+		config.TicketPrefix = strings.Join(strings.Fields(ticket), " ") // Config is trusted input
+		gc := New(config)
+
+		suggestion := gc.parseCommitSuggestion(response, []FileChange{{FilePath: "main.go"}})
+		checkSuggestion(t, response, suggestion)
+	})
+}
+
+func FuzzParseNameStatus(f *testing.F) {
+	for _, seed := range []string{
+		"M\x00gitcommenter.go\x00A\x00git.go\x00",
+		"R100\x00old name.go\x00new name.go\x00",
+		"C75\x00src.go\x00copy.go",
+		"M\x00\x00\x00tab\tin name\x00",
+		"M file\x00",
+		"\x00\x00\x00",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, output string) {
+		for _, file := range parseNameStatus(output) {
+			if file.Status == "" || file.Path == "" {
+				t.Fatalf("Empty status or path in %+v from %q", file, output)
+			}
+			if strings.Contains(file.Path, "\x00") || strings.ContainsAny(file.Status, "\x00\t\n ") {
+				t.Fatalf("Separator left in %+v from %q", file, output)
+			}
+		}
+	})
+}
+
+func FuzzParseConventionalSubject(f *testing.F) {
+	for _, seed := range []string{
+		"feat: add cache",
+		"fix(api,cli)!: rename flags",
+		"feat(): empty scope",
+		"feat(a)(b): double scope",
+		"Feat: capitalized",
+		"feat:missing space",
+		"chore(deps): bump x from 1 to 2\nsecond line",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, subject string) {
+		parsed, ok := ParseConventionalSubject(subject)
+		if !ok {
+			return
+		}
+		if parsed.Type == "" || strings.ToLower(parsed.Type) != parsed.Type {
+			t.Fatalf("Invalid type %q from %q", parsed.Type, subject)
+		}
+		if strings.TrimSpace(parsed.Description) == "" || strings.Contains(parsed.Description, "\n") {
+			t.Fatalf("Invalid description %q from %q", parsed.Description, subject)
+		}
+		for _, scope := range parsed.Scopes {
+			if scope == "" || strings.ContainsAny(scope, ",()") {
+				t.Fatalf("Invalid scope %q from %q", scope, subject)
+			}
+		}
+	})
+}
+
+// TestParseCommitSuggestionProperties checks the suggestion invariants on
+// random model output
+func TestParseCommitSuggestionProperties(t *testing.T) {
+	gc := New(DefaultConfig())
+	property := func(response string) bool {
+		suggestion := gc.parseCommitSuggestion(response, nil)
+		return suggestion.Subject != "" &&
+			!strings.ContainsAny(suggestion.Subject, "\r\n") &&
+			strings.TrimSpace(suggestion.Body) == suggestion.Body
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// checkSuggestion asserts the invariants every parsed suggestion must hold
+func checkSuggestion(t *testing.T, response string, suggestion *CommitSuggestion) {
+	t.Helper()
+	if strings.TrimSpace(suggestion.Subject) == "" {
+		t.Fatalf("Empty subject from %q", response)
+	}
+	if strings.ContainsAny(suggestion.Subject, "\r\n") {
+		t.Fatalf("Subject %q from %q contains a line break", suggestion.Subject, response)
+	}
+	if strings.Contains(suggestion.Body, "\r") {
+		t.Fatalf("Body from %q contains a carriage return", response)
+	}
+	if len(suggestion.FilesAffected) != 1 {
+		t.Fatalf("Expected the affected files to be kept, got %v", suggestion.FilesAffected)
+	}
+}
@@ -0,0 +1,151 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// linguistAttributes are the .gitattributes entries GitHub's linguist uses
+// to mark generated and third-party files
+var linguistAttributes = []string{"linguist-generated", "linguist-vendored"}
+
+// ClassifyFiles classifies the files of the changes like ClassifyChange,
+// then applies the repository's linguist-generated and linguist-vendored
+// attributes. An attribute set to false overrides the path and marker
+// heuristics, e.g. for a hand-edited file under vendor/.
+func (gc *GitCommenter) ClassifyFiles(changes []FileChange) []PathInfo {
+	infos := make([]PathInfo, len(changes))
+	for i, change := range changes {
+		infos[i] = ClassifyChange(change)
+	}
+
+	// Without the attributes, e.g. outside a repository, only the heuristics
+	// apply
+	attributes, err := gc.checkAttributes(infos)
+	if err != nil {
+		return infos
+	}
+	for i := range infos {
+		if set, ok := attributes[infos[i].Path]["linguist-generated"]; ok {
+			infos[i].Generated = set
+		}
+		if set, ok := attributes[infos[i].Path]["linguist-vendored"]; ok {
+			infos[i].Vendored = set
+		}
+	}
+	return infos
+}
+
+// checkAttributes looks up the linguist attributes of the files with git
+// check-attr, or with the backend when it implements RepositoryBackend.
+// Attributes that are not specified are left out of the map.
+func (gc *GitCommenter) checkAttributes(infos []PathInfo) (map[string]map[string]bool, error) {
+	if len(infos) == 0 {
+		return nil, nil
+	}
+	values, err := gc.attributeValues(infos)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]map[string]bool)
+	for file, names := range values {
+		for name, value := range names {
+			var set bool
+			switch value {
+			case "set", "true":
+				set = true
+			case "unset", "false":
+			default:
+				continue
+			}
+			if attributes[file] == nil {
+				attributes[file] = make(map[string]bool)
+			}
+			attributes[file][name] = set
+		}
+	}
+	return attributes, nil
+}
+
+// attributeValues returns the values of the linguist attributes of the
+// files as check-attr prints them, by repository-relative path
+func (gc *GitCommenter) attributeValues(infos []PathInfo) (map[string]map[string]string, error) {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		paths := make([]string, len(infos))
+		for i, info := range infos {
+			paths[i] = info.Path
+		}
+		return repository.Attr(paths, linguistAttributes)
+	}
+
+	// check-attr takes paths relative to the working directory, which may
+	// be below the root; absolute paths work from anywhere
+	args := append(append([]string{"check-attr", "-z"}, linguistAttributes...), "--")
+	byAbsolute := make(map[string]string)
+	for _, info := range infos {
+		abs, err := gc.AbsolutePath(info.Path)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, abs)
+		byAbsolute[abs] = info.Path
+	}
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The output is a sequence of path, attribute and value
+	values := make(map[string]map[string]string)
+	fields := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "unspecified" {
+			continue
+		}
+		file := byAbsolute[fields[i]]
+		if values[file] == nil {
+			values[file] = make(map[string]string)
+		}
+		values[file][fields[i+1]] = fields[i+2]
+	}
+	return values, nil
+}
+
+// splitGenerated separates the changes to generated and vendored files,
+// which are summarized in one line each instead of showing their diffs.
+// When every file is generated or vendored, all diffs are kept so the
+// model has something to describe.
+func (gc *GitCommenter) splitGenerated(changes []FileChange) (detailed, summarized []FileChange, infos []PathInfo) {
+	for i, info := range gc.ClassifyFiles(changes) {
+		if info.Generated || info.Vendored {
+			summarized = append(summarized, changes[i])
+			infos = append(infos, info)
+		} else {
+			detailed = append(detailed, changes[i])
+		}
+	}
+	if len(detailed) == 0 {
+		return changes, nil, nil
+	}
+	return detailed, summarized, infos
+}
+
+// generatedSummary lists the summarized generated and vendored files for
+// the prompt
+func generatedSummary(summarized []FileChange, infos []PathInfo) string {
+	if len(summarized) == 0 {
+		return ""
+	}
+	var out strings.Builder
+	out.WriteString("GENERATED AND VENDORED FILES (diffs not shown, do not describe them as hand-written):\n")
+	for i, change := range summarized {
+		kind := "generated"
+		if infos[i].Vendored {
+			kind = "vendored"
+		}
+		out.WriteString(fmt.Sprintf("- %s (%s, %s, +%d -%d)\n", change.FilePath, kind, change.ChangeType, change.LinesAdded, change.LinesRemoved))
+	}
+	out.WriteString("\n")
+	return out.String()
+}
@@ -0,0 +1,63 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassifyFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	attributes := "api/*.pb.go linguist-generated\nthird_party/patched.c linguist-vendored=false\n"
+	writeFile(t, dir, ".gitattributes", attributes)
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	changes := []FileChange{
+		{FilePath: "api/service.pb.go"},
+		{FilePath: "third_party/patched.c"},
+		{FilePath: "node_modules/left-pad/index.js"},
+		{FilePath: "mocks/store.go", Diff: "+// Code generated by mockgen. DO NOT EDIT.\n"},
+		{FilePath: "main.go"},
+	}
+	var got []string
+	for _, info := range gc.ClassifyFiles(changes) {
+		got = append(got, fmt.Sprintf("%s:%t:%t", filepath.Base(info.Path), info.Generated, info.Vendored))
+	}
+	want := "service.pb.go:true:false,patched.c:false:false,index.js:false:true,store.go:true:false,main.go:false:false"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Unexpected classification %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestGeneratedFilesSummarizedInPrompt(t *testing.T) {
+	config := DefaultConfig()
+	config.HistoryExamples = 0
+	gc := New(config)
+
+	changes := []FileChange{
+		{FilePath: "store.go", ChangeType: "modified", Diff: "+func Open() {}\n", LinesAdded: 1},
+		{FilePath: "vendor/lib/lib.go", ChangeType: "modified", Diff: "+VENDORED_DIFF\n", LinesAdded: 1},
+		{FilePath: "store_mock.go", ChangeType: "added", Diff: "+// Code generated by mockgen. DO NOT EDIT.\n+GENERATED_DIFF\n", LinesAdded: 2},
+	}
+	plan, err := gc.plan(changes)
+	if err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	if strings.Contains(plan.prompt, "VENDORED_DIFF") || strings.Contains(plan.prompt, "GENERATED_DIFF") {
+		t.Errorf("Expected no diffs of generated or vendored files:\n%s", plan.prompt)
+	}
+	for _, line := range []string{"- vendor/lib/lib.go (vendored, modified, +1 -0)", "- store_mock.go (generated, added, +2 -0)", "+func Open() {}"} {
+		if !strings.Contains(plan.prompt, line) {
+			t.Errorf("Expected %q in the prompt:\n%s", line, plan.prompt)
+		}
+	}
+
+	// With nothing else to describe, the diffs are kept
+	if plan, _ = gc.plan(changes[1:]); !strings.Contains(plan.prompt, "VENDORED_DIFF") {
+		t.Errorf("Expected the diffs when every file is vendored or generated:\n%s", plan.prompt)
+	}
+}
@@ -0,0 +1,80 @@
+package gitcommenter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Generation is a commit message being generated in the background
+type Generation struct {
+	gc      *GitCommenter
+	changes []FileChange
+	done    chan struct{}
+
+	mu         sync.Mutex
+	partial    string
+	suggestion *CommitSuggestion
+	err        error
+}
+
+// StartCommitMessage starts generating a commit message in the background,
+// e.g. to prefetch it while the user reviews the changes. The response is
+// streamed so Wait can return what has arrived so far.
+func (gc *GitCommenter) StartCommitMessage(changes []FileChange) *Generation {
+	g := &Generation{gc: gc, changes: changes, done: make(chan struct{})}
+	go func() {
+		suggestion, err := gc.generate(context.Background(), changes, 0, func(partial string) {
+			g.mu.Lock()
+			g.partial = partial
+			g.mu.Unlock()
+		})
+
+		g.mu.Lock()
+		g.suggestion, g.err = suggestion, err
+		g.mu.Unlock()
+		close(g.done)
+	}()
+	return g
+}
+
+// Ready reports whether the generation has finished, so that Wait(0)
+// returns without blocking
+func (g *Generation) Ready() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits up to maxWait for the message; zero waits until it is done.
+// If the model is still running, it returns a suggestion parsed from the
+// text streamed so far (usually a complete subject) and done is false.
+// Wait may be called again to keep waiting.
+func (g *Generation) Wait(maxWait time.Duration) (suggestion *CommitSuggestion, done bool, err error) {
+	if maxWait <= 0 {
+		<-g.done
+	} else {
+		timer := time.NewTimer(maxWait)
+		defer timer.Stop()
+		select {
+		case <-g.done:
+		case <-timer.C:
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.done:
+		return g.suggestion, true, g.err
+	default:
+	}
+
+	suggestion = g.gc.parseCommitSuggestion(g.partial, g.changes)
+	suggestion.Confidence = 0.3
+	suggestion.Warnings = append(suggestion.Warnings, "the model had not finished; the message may be incomplete")
+	return suggestion, false, nil
+}
@@ -0,0 +1,64 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGenerationWaitReturnsPartialMessage(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("Expected a streaming request")
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.Encode(OllamaResponse{Response: "feat: add init wizard\n\n"})
+		w.(http.Flusher).Flush()
+		<-release
+		encoder.Encode(OllamaResponse{Response: "Asks for a model.", Done: true})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	generation := gc.StartCommitMessage([]FileChange{{FilePath: "init.go", ChangeType: "added", Diff: "+package main"}})
+
+	var suggestion *CommitSuggestion
+	var done bool
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		suggestion, done, err = generation.Wait(20 * time.Millisecond)
+		if err != nil || done {
+			t.Fatalf("Expected an unfinished generation, got done=%v error=%v", done, err)
+		}
+		if suggestion.Subject == "feat: add init wizard" {
+			break
+		}
+	}
+	if suggestion.Subject != "feat: add init wizard" || len(suggestion.Warnings) == 0 {
+		t.Fatalf("Expected the streamed subject with a warning, got %+v", suggestion)
+	}
+
+	if generation.Ready() {
+		t.Error("Expected the generation not to be ready while the model runs")
+	}
+	release <- struct{}{}
+	suggestion, done, err = generation.Wait(0)
+	if err != nil || !done {
+		t.Fatalf("Expected the finished message, got done=%v error=%v", done, err)
+	}
+	if !generation.Ready() {
+		t.Error("Expected the generation to be ready after Wait returned")
+	}
+	if suggestion.Body != "Asks for a model." || len(suggestion.Warnings) != 0 {
+		t.Errorf("Unexpected final suggestion %+v", suggestion)
+	}
+}
@@ -0,0 +1,363 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// StagedFile is a single entry of the staged file listing
+type StagedFile struct {
+	// Status is the raw git status letter(s), e.g. "M", "A", "R100"
+	Status string
+	// Path is the path of the file relative to the repository root
+	Path string
+	// OldPath is the path a renamed or copied file came from
+	OldPath string
+}
+
+// paths returns the path of the file and, for a rename or copy, the path
+// it came from, so a diff limited to them shows the rename
+func (f StagedFile) paths() []string {
+	if f.OldPath == "" {
+		return []string{f.Path}
+	}
+	return []string{f.OldPath, f.Path}
+}
+
+// GitBackend abstracts the Git operations used by the commenter so that
+// library consumers can inject a fake in unit tests or plug in an
+// alternative implementation.
+type GitBackend interface {
+	// IsRepository returns an error if the backend is not pointed at a Git repository
+	IsRepository() error
+	// StagedFiles lists the files staged for the next commit
+	StagedFiles() ([]StagedFile, error)
+	// StagedDiff returns the staged diff for a single file; it is called
+	// from several goroutines at once
+	StagedDiff(path string) (string, error)
+	// Commit records the staged changes with the given message
+	Commit(message string) error
+	// Push pushes the current branch to its upstream
+	Push() error
+}
+
+// FileDiff is the staged diff of one file with its line counts
+type FileDiff struct {
+	Path         string
+	Diff         string
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// BatchDiffBackend is implemented by backends that return every staged diff
+// at once, which in large repositories is much faster than calling
+// StagedDiff for each file
+type BatchDiffBackend interface {
+	StagedDiffs() ([]FileDiff, error)
+}
+
+// RepositoryBackend is implemented by backends that read the history,
+// branch, config and attributes of the repository themselves, such as the
+// go-git backend in environments without a git binary. The few-shot
+// examples, the language of the history, the RecentCommits of templates,
+// HeadCommit, the branch opt-out, RepoRoot and the linguist attributes
+// use it; with other backends they run git.
+type RepositoryBackend interface {
+	// Log returns the last n commits on HEAD without merges, newest first,
+	// and none before the first commit
+	Log(n int) ([]Commit, error)
+	// Head returns the checked out commit, "" before the first commit, and
+	// branch, "" on a detached HEAD
+	Head() (hash, branch string, err error)
+	// Root returns the top-level directory of the working tree
+	Root() (string, error)
+	// Config returns the value of a git config key such as
+	// "branch.main.description", or "" when it is not set
+	Config(key string) (string, error)
+	// Attr returns the gitattributes names of the repository-relative
+	// paths as git check-attr does: "set", "unset" or the value, leaving
+	// out the attributes that are not specified
+	Attr(paths, names []string) (map[string]map[string]string, error)
+}
+
+// ExecBackend is the default GitBackend which shells out to the git binary
+type ExecBackend struct {
+	// Dir is the working directory in which git is run
+	Dir string
+	// Stdout and Stderr receive the output of commit and push (optional)
+	Stdout io.Writer
+	Stderr io.Writer
+	// Sign creates GPG/SSH signed commits (git commit -S)
+	Sign bool
+	// SigningKey overrides user.signingkey when Sign is set (optional)
+	SigningKey string
+	// ReadOnly sandboxes every git command (see ReadOnlyGitArgs); staging,
+	// committing and pushing return ErrReadOnly
+	ReadOnly bool
+}
+
+// NewExecBackend creates a GitBackend that runs git in the given directory
+func NewExecBackend(dir string) *ExecBackend {
+	return &ExecBackend{Dir: dir}
+}
+
+// IsRepository checks that Dir is inside a Git repository
+func (b *ExecBackend) IsRepository() error {
+	_, err := b.output("rev-parse", "--git-dir")
+	return err
+}
+
+// StagedFiles lists staged files using git diff --cached --name-status -z,
+// detecting renames and copies
+func (b *ExecBackend) StagedFiles() ([]StagedFile, error) {
+	output, err := b.output("diff", "--cached", "--find-copies", "--name-status", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNameStatus(output), nil
+}
+
+// StagedDiff returns the staged diff for a single file
+func (b *ExecBackend) StagedDiff(path string) (string, error) {
+	return b.output("diff", "--cached", "--", TopPathspec(path))
+}
+
+// StagedDiffs returns the diffs of all staged files from a single
+// git diff --cached --patch --numstat -z. Renames and copies are detected
+// as StagedFiles does and keyed by the new path.
+func (b *ExecBackend) StagedDiffs() ([]FileDiff, error) {
+	output, err := b.output("diff", "--cached", "--find-copies", "--patch", "--numstat", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstatPatch(output)
+}
+
+// UnstagedFiles runs git diff --name-only
+func (b *ExecBackend) UnstagedFiles() ([]string, error) {
+	output, err := b.output("diff", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(output), nil
+}
+
+// Stage runs git add for repository-relative paths
+func (b *ExecBackend) Stage(paths ...string) error {
+	args := []string{"add", "--"}
+	for _, path := range paths {
+		args = append(args, TopPathspec(path))
+	}
+	return b.run(args...)
+}
+
+// Unstage runs git reset for repository-relative paths
+func (b *ExecBackend) Unstage(paths ...string) error {
+	args := []string{"reset", "-q", "--"}
+	for _, path := range paths {
+		args = append(args, TopPathspec(path))
+	}
+	return b.run(args...)
+}
+
+// Commit runs git commit with the given message
+func (b *ExecBackend) Commit(message string) error {
+	if b.Sign {
+		return b.run("commit", "-S"+b.SigningKey, "-m", message)
+	}
+	return b.run("commit", "-m", message)
+}
+
+// Push runs git push
+func (b *ExecBackend) Push() error {
+	return b.run("push")
+}
+
+// output runs a git command and returns its stdout
+func (b *ExecBackend) output(args ...string) (string, error) {
+	if b.ReadOnly {
+		var err error
+		if args, err = ReadOnlyGitArgs(args); err != nil {
+			return "", err
+		}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = b.Dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// run runs a git command, streaming its output to Stdout and Stderr
+func (b *ExecBackend) run(args ...string) error {
+	if b.ReadOnly {
+		return fmt.Errorf("git %s: %w", args[0], ErrReadOnly)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = b.Dir
+	cmd.Stdout = b.Stdout
+	cmd.Stderr = b.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// parseNumstatPatch splits the output of git diff --patch --numstat -z into
+// per-file diffs. The NUL-terminated numstat records come first, ended by an
+// empty record, followed by the patch; each file's patch starts with a
+// "diff --git" line, in numstat order.
+func parseNumstatPatch(output string) ([]FileDiff, error) {
+	diffs, rest, err := parseNumstat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var starts []int
+	for offset := 0; offset < len(rest); {
+		if strings.HasPrefix(rest[offset:], "diff --git ") {
+			starts = append(starts, offset)
+		}
+		next := strings.IndexByte(rest[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	if len(starts) != len(diffs) {
+		return nil, fmt.Errorf("found %d patches for %d files", len(starts), len(diffs))
+	}
+	for i := range diffs {
+		end := len(rest)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		diffs[i].Diff = rest[starts[i]:end]
+	}
+	return diffs, nil
+}
+
+// parseNumstat parses the records of git diff --numstat -z into line counts
+// without diffs, returning what follows the terminating empty record, if
+// any. Binary files count no lines; renames and copies use the new path.
+func parseNumstat(output string) ([]FileDiff, string, error) {
+	var diffs []FileDiff
+	rest := output
+	for rest != "" {
+		record, after, found := strings.Cut(rest, "\x00")
+		if !found {
+			return nil, "", fmt.Errorf("unterminated numstat record %q", record)
+		}
+		rest = after
+		if record == "" {
+			break
+		}
+
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) != 3 {
+			return nil, "", fmt.Errorf("invalid numstat record %q", record)
+		}
+		path := fields[2]
+		if path == "" {
+			// A rename or copy: the old and new paths follow
+			var old, renamed string
+			old, rest, _ = strings.Cut(rest, "\x00")
+			renamed, rest, _ = strings.Cut(rest, "\x00")
+			path = renamed
+			if path == "" {
+				path = old
+			}
+		}
+		// Binary files count "-" lines
+		added, _ := strconv.Atoi(fields[0])
+		removed, _ := strconv.Atoi(fields[1])
+		diffs = append(diffs, FileDiff{Path: path, LinesAdded: added, LinesRemoved: removed})
+	}
+	return diffs, rest, nil
+}
+
+// parseNameStatus parses the output of git diff --name-status -z: a status
+// and a path per file, NUL-terminated, so paths may contain any character.
+// Renames and copies list the old and the new path.
+func parseNameStatus(output string) []StagedFile {
+	var files []StagedFile
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		if status == "" || strings.ContainsAny(status, " \t\r\n") {
+			// Not a status: resynchronize on the next field
+			i--
+			continue
+		}
+		file := StagedFile{Status: status, Path: path}
+		if (status[0] == 'R' || status[0] == 'C') && i+2 < len(fields) {
+			i++
+			if fields[i+1] != "" {
+				file.Path, file.OldPath = fields[i+1], path
+			}
+		}
+		if file.Path != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// numstat runs a git command with --numstat -z appended and returns the
+// line counts of each file keyed by path
+func (gc *GitCommenter) numstat(args ...string) (map[string]FileDiff, error) {
+	output, err := gc.gitOutput(append(args, "--numstat", "-z")...)
+	if err != nil {
+		return nil, err
+	}
+	diffs, _, err := parseNumstat(output)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]FileDiff, len(diffs))
+	for _, diff := range diffs {
+		counts[diff.Path] = diff
+	}
+	return counts, nil
+}
+
+// gitOutput runs an arbitrary read-only git command in the repository and
+// returns its stdout. It is used by features that go beyond GitBackend.
+func (gc *GitCommenter) gitOutput(args ...string) (string, error) {
+	command := args[0]
+	if gc.config.ReadOnly {
+		var err error
+		if args, err = ReadOnlyGitArgs(args); err != nil {
+			return "", err
+		}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gc.config.RepositoryPath
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", command, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", command, err)
+	}
+	return string(output), nil
+}
+
+// currentBranch returns the checked out branch, or "" on a detached HEAD
+func (gc *GitCommenter) currentBranch() string {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		_, branch, _ := repository.Head()
+		return branch
+	}
+	branch, err := gc.gitOutput("symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(branch)
+}
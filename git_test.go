@@ -0,0 +1,270 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend is an in-memory GitBackend used by the tests
+type fakeBackend struct {
+	files     []StagedFile
+	diffs     map[string]string
+	committed []string
+	pushed    int
+}
+
+func (f *fakeBackend) IsRepository() error { return nil }
+
+func (f *fakeBackend) StagedFiles() ([]StagedFile, error) { return f.files, nil }
+
+func (f *fakeBackend) StagedDiff(path string) (string, error) {
+	diff, ok := f.diffs[path]
+	if !ok {
+		return "", fmt.Errorf("no diff for %s", path)
+	}
+	return diff, nil
+}
+
+func (f *fakeBackend) Commit(message string) error {
+	f.committed = append(f.committed, message)
+	return nil
+}
+
+func (f *fakeBackend) Push() error {
+	f.pushed++
+	return nil
+}
+
+func TestParseNameStatus(t *testing.T) {
+	output := "M\x00gitcommenter.go\x00A\x00docs dir/read me.md\x00R087\x00old.go\x00new.go\x00D\x00old.txt\x00"
+
+	files := parseNameStatus(output)
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 files, got %+v", files)
+	}
+
+	if files[1].Status != "A" || files[1].Path != "docs dir/read me.md" {
+		t.Errorf("Unexpected second entry: %+v", files[1])
+	}
+	if files[2].Status != "R087" || files[2].Path != "new.go" || files[2].OldPath != "old.go" {
+		t.Errorf("Expected the new path of a rename, got %+v", files[2])
+	}
+	if files[3].Status != "D" || files[3].Path != "old.txt" {
+		t.Errorf("Unexpected last entry: %+v", files[3])
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	diffs, rest, err := parseNumstat("3\t1\tname with spaces.go\x00-\t-\tlogo.png\x000\t0\t\x00old.go\x00new.go\x00")
+	if err != nil {
+		t.Fatalf("parseNumstat returned error: %v", err)
+	}
+	if rest != "" || len(diffs) != 3 {
+		t.Fatalf("Expected 3 records and nothing left, got %+v and %q", diffs, rest)
+	}
+	if diffs[0].Path != "name with spaces.go" || diffs[0].LinesAdded != 3 || diffs[0].LinesRemoved != 1 {
+		t.Errorf("Unexpected first record %+v", diffs[0])
+	}
+	if diffs[1].LinesAdded != 0 || diffs[1].LinesRemoved != 0 || diffs[2].Path != "new.go" {
+		t.Errorf("Unexpected binary or rename records %+v", diffs[1:])
+	}
+	if _, _, err := parseNumstat("1\t1\tmain.go"); err == nil {
+		t.Error("Expected an error for an unterminated record")
+	}
+}
+
+func TestScanStagedChangesWithBackend(t *testing.T) {
+	backend := &fakeBackend{
+		files: []StagedFile{
+			{Status: "M", Path: "main.go"},
+			{Status: "A", Path: "missing.go"},
+		},
+		diffs: map[string]string{
+			"main.go": "--- a/main.go\n+++ b/main.go\n+new line\n-old line\n+another",
+		},
+	}
+
+	commenter := New(nil)
+	commenter.SetGitBackend(backend)
+
+	changes, err := commenter.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+
+	// missing.go has no diff and should be skipped
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(changes))
+	}
+
+	if changes[0].LinesAdded != 2 || changes[0].LinesRemoved != 1 {
+		t.Errorf("Expected +2 -1, got +%d -%d", changes[0].LinesAdded, changes[0].LinesRemoved)
+	}
+}
+
+// slowBackend delays each StagedDiff, recording how many ran at once
+type slowBackend struct {
+	fakeBackend
+	mu               sync.Mutex
+	running, maxSeen int
+}
+
+func (s *slowBackend) StagedDiff(path string) (string, error) {
+	s.mu.Lock()
+	s.running++
+	s.maxSeen = max(s.maxSeen, s.running)
+	s.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return s.fakeBackend.StagedDiff(path)
+}
+
+func TestScanStagedChangesDiffsConcurrently(t *testing.T) {
+	backend := &slowBackend{fakeBackend: fakeBackend{diffs: make(map[string]string)}}
+	for i := 0; i < 40; i++ {
+		path := fmt.Sprintf("file%02d.go", i)
+		backend.files = append(backend.files, StagedFile{Status: "M", Path: path})
+		if i != 7 {
+			backend.diffs[path] = strings.Repeat("+line\n", i)
+		}
+	}
+
+	commenter := New(nil)
+	commenter.SetGitBackend(backend)
+	changes, err := commenter.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+
+	if len(changes) != 39 {
+		t.Fatalf("Expected 39 changes without the failed file, got %d", len(changes))
+	}
+	for i, change := range changes {
+		n := i
+		if i >= 7 {
+			n++
+		}
+		if change.FilePath != fmt.Sprintf("file%02d.go", n) || change.LinesAdded != n {
+			t.Errorf("Expected file%02d.go with +%d at %d, got %s with +%d", n, n, i, change.FilePath, change.LinesAdded)
+		}
+	}
+	if backend.maxSeen < 2 || backend.maxSeen > diffWorkers {
+		t.Errorf("Expected between 2 and %d diffs at once, got %d", diffWorkers, backend.maxSeen)
+	}
+}
+
+func TestCommitAndPushWithBackend(t *testing.T) {
+	backend := &fakeBackend{}
+
+	commenter := New(nil)
+	commenter.SetGitBackend(backend)
+
+	suggestion := &CommitSuggestion{Subject: "feat: add backend", Body: "Details here."}
+	if err := commenter.Commit(suggestion); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+
+	if len(backend.committed) != 1 || backend.committed[0] != "feat: add backend\n\nDetails here." {
+		t.Errorf("Unexpected commit messages: %q", backend.committed)
+	}
+
+	if err := commenter.Commit(&CommitSuggestion{}); err == nil {
+		t.Error("Expected an error when committing an empty message")
+	}
+
+	if err := commenter.Push(); err != nil {
+		t.Fatalf("Push returned error: %v", err)
+	}
+
+	if backend.pushed != 1 {
+		t.Errorf("Expected 1 push, got %d", backend.pushed)
+	}
+}
+
+func TestParseNumstatPatch(t *testing.T) {
+	output := "1\t1\tmain.go\x00-\t-\tlogo.png\x002\t0\t\x00old.go\x00new.go\x00\x00" +
+		"diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n" +
+		"diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/old.go b/new.go\n--- a/old.go\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+diff --git a/x b/x\n+b\n"
+
+	diffs, err := parseNumstatPatch(output)
+	if err != nil {
+		t.Fatalf("parseNumstatPatch returned error: %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("Expected 3 diffs, got %+v", diffs)
+	}
+	if diffs[0].Path != "main.go" || diffs[0].LinesAdded != 1 || diffs[0].LinesRemoved != 1 || !strings.HasSuffix(diffs[0].Diff, "+new\n") {
+		t.Errorf("Unexpected first diff %+v", diffs[0])
+	}
+	if diffs[1].Path != "logo.png" || diffs[1].LinesAdded != 0 || !strings.Contains(diffs[1].Diff, "Binary files") {
+		t.Errorf("Unexpected binary diff %+v", diffs[1])
+	}
+	// Added lines that look like a diff header belong to the file
+	if diffs[2].Path != "new.go" || !strings.HasSuffix(diffs[2].Diff, "+diff --git a/x b/x\n+b\n") {
+		t.Errorf("Unexpected renamed diff %+v", diffs[2])
+	}
+
+	if _, err := parseNumstatPatch("1\t1\tmain.go\x00\x00"); err == nil {
+		t.Error("Expected an error when patches are missing")
+	}
+}
+
+func TestStagedDiffsMatchStagedDiff(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "old.txt", "moved\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() { run() }\n")
+	writeFile(t, dir, "docs dir/read me.md", "# Title\n")
+	writeFile(t, dir, "logo.png", "\x89PNG\x00\x01")
+	gitRun(t, dir, "mv", "old.txt", "new.txt")
+	gitRun(t, dir, "add", "-A")
+
+	backend := NewExecBackend(dir)
+	files, err := backend.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles returned error: %v", err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if got := strings.Join(paths, ","); got != "docs dir/read me.md,logo.png,main.go,new.txt" {
+		t.Errorf("Unexpected staged files %q", got)
+	}
+	if last := files[len(files)-1]; last.Status != "R100" || last.OldPath != "old.txt" {
+		t.Errorf("Expected the rename of old.txt, got %+v", last)
+	}
+
+	diffs, err := backend.StagedDiffs()
+	if err != nil {
+		t.Fatalf("StagedDiffs returned error: %v", err)
+	}
+	if len(diffs) != 4 {
+		t.Fatalf("Expected 4 diffs, got %d", len(diffs))
+	}
+	for _, diff := range diffs {
+		if diff.Path == "new.txt" {
+			// StagedDiff sees only the new path of a rename
+			if !strings.Contains(diff.Diff, "rename from old.txt\nrename to new.txt\n") || diff.LinesAdded != 0 {
+				t.Errorf("Expected the rename in the batch, got %+v", diff)
+			}
+			continue
+		}
+		single, err := backend.StagedDiff(diff.Path)
+		if err != nil {
+			t.Fatalf("StagedDiff(%s) returned error: %v", diff.Path, err)
+		}
+		if diff.Diff != single {
+			t.Errorf("Diff of %s differs:\n%s\nwant:\n%s", diff.Path, diff.Diff, single)
+		}
+	}
+}
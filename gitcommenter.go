@@ -4,13 +4,17 @@ package gitcommenter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // Config holds the configuration for the Git commenter
@@ -27,24 +31,151 @@ type Config struct {
 	RepositoryPath string
 	// Timeout is the HTTP request timeout
 	Timeout time.Duration
+	// Style is the commit message style, one of StyleNames()
+	Style string
+	// TicketPrefix is prepended to generated subjects, e.g. "PROJ-123"
+	TicketPrefix string
+	// Language is the code of the language messages are written in (see
+	// Languages), or LanguageAuto to follow the recent commits; empty
+	// means English
+	Language string
+	// Exclude lists glob patterns of files left out of the prompt, on top
+	// of the patterns in the repository's .aiignore file (see AIIgnoreFile)
+	Exclude []string
+	// SecretScan decides what happens to changes whose added lines look
+	// like API keys, tokens or private keys: SecretScanBlock (the default
+	// when empty) keeps them from the model, SecretScanWarn adds a warning
+	// to the suggestion and SecretScanOff skips the scan (see ScanSecrets)
+	SecretScan string
+	// Redact masks parts of the diffs, such as email addresses or files
+	// under secrets/, before they are put into the prompt (see
+	// RedactChanges); the commit keeps the real content
+	Redact []RedactionRule
+	// MaxPromptBytes, MaxPromptFiles and MaxFileBytes bound the diffs
+	// shown to the model: in total, in number of files and per file of
+	// average weight. The files with the largest changes are shown first
+	// and get a share of the total proportional to their weight (see
+	// PrioritizeChanges). Zero uses DefaultMaxPromptBytes,
+	// DefaultMaxPromptFiles and DefaultMaxFileBytes.
+	MaxPromptBytes int
+	MaxPromptFiles int
+	MaxFileBytes   int
+	// ContextWindow is the model's context window in tokens. When set, the
+	// diff budget is scaled so the prompt, counted with the model's
+	// tokenizer, fills the window without overflowing it, and Ollama is
+	// asked to run with that window. ContextWindowAuto reads it from the
+	// model; zero keeps the byte limits only.
+	ContextWindow int
+	// SummarizeFiles lists a one-line summary from the model of each file
+	// whose diff the prompt cuts short or leaves out. Summaries are cached
+	// by the file's blobs, so they are not asked for again on the next run
+	// (see SummarizeFile).
+	SummarizeFiles bool
+	// CountExcluded still tells the model how many files and lines were
+	// left out of the prompt, without their paths or diffs
+	CountExcluded bool
+	// Types and Scopes restrict the conventional commit types and scopes the
+	// model may use (empty allows any)
+	Types  []string
+	Scopes []string
+	// Gitmoji prefixes subjects with a gitmoji chosen by the model, on top
+	// of the style
+	Gitmoji bool
+	// PromptTemplate is the path of a text/template file replacing the
+	// built-in prompt; relative paths are resolved from RepositoryPath
+	PromptTemplate string
+	// ScopeMap derives the conventional commit scope from the changed paths,
+	// with entries like "cmd/** -> cli" (see ParseScopeMapping)
+	ScopeMap []string
+	// ManualSections are body sections such as "Testing done:" that the
+	// model never writes; the author fills them in (see AddSection)
+	ManualSections []string
+	// Commitlint holds rules read from the repository's commitlint
+	// configuration (see LoadCommitlintConfig); nil ignores commitlint
+	Commitlint *CommitlintRules
+	// MaxSubjectLength is the longest accepted subject. Longer subjects are
+	// sent back to the model to be shortened, then truncated. Zero disables
+	// the limit.
+	MaxSubjectLength int
+	// AdaptiveTemperature scales Temperature by the kind of change: lower
+	// for mechanical changes such as dependency bumps, higher for features
+	AdaptiveTemperature bool
+	// InferTypes constrains the commit type of changes to only tests, docs
+	// or configuration: "test", "docs", or "ci" or "chore" (see InferTypes)
+	InferTypes bool
+	// TrivialMaxLines is the most changed lines a single-file trivial change
+	// (a typo fix, a reformat, a version bump) may have to get a template
+	// message instead of asking the model. Zero always asks the model.
+	TrivialMaxLines int
+	// HistoryExamples is how many recent commits are searched for example
+	// messages shown to the model, so generated messages follow the
+	// repository's conventions. Zero leaves the examples out.
+	HistoryExamples int
+	// IncludeHistoryContext shows the model the subjects of the last
+	// commits touching the changed files
+	IncludeHistoryContext bool
+	// EmbeddingModel is the Ollama model computing embeddings, e.g.
+	// "nomic-embed-text". When set, the past commits with the diffs most
+	// similar to the changes are shown to the model as examples.
+	EmbeddingModel string
+	// GitHubAPI is the GitHub REST API used by forge features (default:
+	// https://api.github.com)
+	GitHubAPI string
+	// GitLabAPI is the GitLab REST API used for merge requests (default:
+	// https://<host>/api/v4 for the host of the origin remote)
+	GitLabAPI string
+	// Disabled turns generation off for the repository ("ai: off" in a
+	// config file); see OptOutReason
+	Disabled bool
+	// ReadOnly never modifies the repository: committing, pushing and
+	// staging return ErrReadOnly, and git runs sandboxed (see
+	// ReadOnlyGitArgs)
+	ReadOnly bool
+	// Anonymize replaces code identifiers and string literals in prompts
+	// with placeholders before they reach the model, e.g. on a shared team
+	// server, and restores them in the answers (see CodeAnonymizer)
+	Anonymize bool
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		OllamaEndpoint: "http://localhost:11434",
-		Model:         "llama2",
-		MaxTokens:     150,
-		Temperature:   0.7,
+		Model:          "llama2",
+		MaxTokens:      150,
+		Temperature:    0.7,
 		RepositoryPath: ".",
-		Timeout:       30 * time.Second,
+		Timeout:        30 * time.Second,
+		Style:          "conventional",
+		// git log --oneline and most forges cut subjects beyond 72 columns
+		MaxSubjectLength:    72,
+		AdaptiveTemperature: true,
+		InferTypes:          true,
+		TrivialMaxLines:     DefaultTrivialMaxLines,
+		HistoryExamples:     DefaultHistoryExamples,
+		GitHubAPI:           DefaultGitHubAPI,
+		MaxPromptBytes:      DefaultMaxPromptBytes,
+		MaxPromptFiles:      DefaultMaxPromptFiles,
+		MaxFileBytes:        DefaultMaxFileBytes,
 	}
 }
 
 // GitCommenter handles scanning Git changes and generating commit messages
 type GitCommenter struct {
-	config *Config
-	client *http.Client
+	config  *Config
+	client  *http.Client
+	git     GitBackend
+	cache   Cache
+	trace   func(ModelCall)
+	journal *Journal
+	// anonymizer rewrites prompts before they reach the model, if set
+	anonymizer Anonymizer
+	// tokenizer counts prompt tokens; nil asks Ollama (see CountTokens)
+	tokenizer Tokenizer
+	// summaries holds the file summaries of this run by cache key
+	summaries sync.Map
+	// symbolAnalyzer lists changed symbols of non-Go files, if set
+	symbolAnalyzer SymbolAnalyzer
 }
 
 // New creates a new GitCommenter with the given configuration
@@ -53,117 +184,369 @@ func New(config *Config) *GitCommenter {
 		config = DefaultConfig()
 	}
 
-	return &GitCommenter{
+	gc := &GitCommenter{
 		config: config,
 		client: &http.Client{
 			Timeout: config.Timeout,
 		},
+		git: &ExecBackend{Dir: config.RepositoryPath, ReadOnly: config.ReadOnly},
 	}
+	if config.Anonymize {
+		gc.anonymizer = NewCodeAnonymizer()
+	}
+	return gc
+}
+
+// SetGitBackend replaces the Git backend used for scanning, committing and pushing
+func (gc *GitCommenter) SetGitBackend(backend GitBackend) {
+	gc.git = backend
+}
+
+// WithConfig returns a commenter for config that shares the Git backend,
+// cache, tracing, journal, tokenizer and analyzers of gc, e.g. to apply a
+// configuration reloaded by a ConfigWatcher
+func (gc *GitCommenter) WithConfig(config *Config) *GitCommenter {
+	next := New(config)
+	next.git = gc.git
+	next.cache = gc.cache
+	next.trace = gc.trace
+	next.journal = gc.journal
+	next.tokenizer = gc.tokenizer
+	next.symbolAnalyzer = gc.symbolAnalyzer
+	if gc.anonymizer != nil && config.Anonymize {
+		next.anonymizer = gc.anonymizer
+	}
+	return next
+}
+
+// Backend returns the Git backend in use
+func (gc *GitCommenter) Backend() GitBackend {
+	return gc.git
 }
 
 // FileChange represents a changed file with its diff
 type FileChange struct {
-	FilePath   string
-	ChangeType string // "added", "modified", "deleted", "renamed"
-	Diff       string
-	LinesAdded int
+	FilePath     string
+	OldPath      string // Path before a rename or copy
+	ChangeType   string // "added", "modified", "deleted", "renamed", "copied"
+	Diff         string
+	LinesAdded   int
 	LinesRemoved int
+	// Base is the version Diff was made from: a revision, or IndexBase for
+	// the index. Empty means HEAD, as for staged changes.
+	Base string
 }
 
 // CommitSuggestion represents a suggested commit message
 type CommitSuggestion struct {
-	Subject     string
-	Body        string
-	Confidence  float64
+	Subject       string
+	Body          string
+	Confidence    float64
 	FilesAffected []string
+	// Warnings lists style rules the message still breaks after retrying
+	Warnings []string
+	// PromptHash identifies the prompt the message was generated from
+	// (see AddProvenance)
+	PromptHash string
+	// Template names the template of a message written without the model
+	// (see TrivialMessage)
+	Template string
 }
 
-// ScanStagedChanges scans the staged changes in the Git repository
+// diffWorkers bounds how many files ScanStagedChanges diffs at once
+const diffWorkers = 8
+
+// ScanStagedChanges scans the staged changes in the Git repository,
+// leaving out the files listed in .aiignore (see ScanIgnoredChanges)
 func (gc *GitCommenter) ScanStagedChanges() ([]FileChange, error) {
+	changes, err := gc.stagedChanges()
+	if err != nil {
+		return nil, err
+	}
+	return gc.withoutIgnored(changes)
+}
+
+// stagedChanges scans every staged change
+func (gc *GitCommenter) stagedChanges() ([]FileChange, error) {
 	// Check if we're in a git repository
 	if err := gc.ensureGitRepository(); err != nil {
 		return nil, fmt.Errorf("not in a git repository: %w", err)
 	}
 
 	// Get list of staged files
-	cmd := exec.Command("git", "diff", "--cached", "--name-status")
-	cmd.Dir = gc.config.RepositoryPath
-	output, err := cmd.Output()
+	staged, err := gc.git.StagedFiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get staged files: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return []FileChange{}, nil // No staged changes
+	// Fetch every diff at once where the backend can; files it missed are
+	// diffed one by one by up to diffWorkers goroutines
+	batched := make(map[string]FileDiff)
+	if batch, ok := gc.git.(BatchDiffBackend); ok {
+		if diffs, err := batch.StagedDiffs(); err == nil {
+			for _, diff := range diffs {
+				batched[diff.Path] = diff
+			}
+		}
 	}
 
-	var changes []FileChange
-	for _, line := range lines {
-		if line == "" {
+	// Diff the remaining files concurrently, keeping the staged order
+	unbatched := make([]FileDiff, len(staged))
+	errs := make([]error, len(staged))
+	sem := make(chan struct{}, diffWorkers)
+	var wg sync.WaitGroup
+	for i, file := range staged {
+		if _, ok := batched[file.Path]; ok {
 			continue
 		}
-
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			diff := FileDiff{Path: path}
+			diff.Diff, diff.LinesAdded, diff.LinesRemoved, errs[i] = gc.getFileDiff(path)
+			unbatched[i] = diff
+		}(i, file.Path)
+	}
+	wg.Wait()
+
+	changes := []FileChange{}
+	for i, file := range staged {
+		diff, ok := batched[file.Path]
+		if !ok {
+			if errs[i] != nil {
+				// Log error but continue with other files
+				fmt.Printf("Warning: failed to get diff for %s: %v\n", file.Path, errs[i])
+				continue
+			}
+			diff = unbatched[i]
 		}
 
-		status := parts[0]
-		filepath := parts[1]
+		changes = append(changes, FileChange{
+			FilePath:     file.Path,
+			OldPath:      file.OldPath,
+			ChangeType:   gc.parseChangeType(file.Status),
+			Diff:         diff.Diff,
+			LinesAdded:   diff.LinesAdded,
+			LinesRemoved: diff.LinesRemoved,
+		})
+	}
 
-		change := FileChange{
-			FilePath:   filepath,
-			ChangeType: gc.parseChangeType(status),
-		}
+	return changes, nil
+}
 
-		// Get the diff for this file
-		diff, linesAdded, linesRemoved, err := gc.getFileDiff(filepath)
-		if err != nil {
-			// Log error but continue with other files
-			fmt.Printf("Warning: failed to get diff for %s: %v\n", filepath, err)
-			continue
-		}
+// GenerateCommitMessage generates a commit message based on the changes
+func (gc *GitCommenter) GenerateCommitMessage(changes []FileChange) (*CommitSuggestion, error) {
+	return gc.generateCandidate(changes, 0)
+}
 
-		change.Diff = diff
-		change.LinesAdded = linesAdded
-		change.LinesRemoved = linesRemoved
+// StreamCommitMessage generates a message for the changes, passing the
+// text received so far to progress as the model streams it. Cancelling ctx
+// aborts the request. Candidates above 0 use a slightly higher temperature,
+// e.g. to regenerate a message the user did not like.
+func (gc *GitCommenter) StreamCommitMessage(ctx context.Context, changes []FileChange, candidate int, progress func(partial string)) (*CommitSuggestion, error) {
+	return gc.generate(ctx, changes, candidate, progress)
+}
+
+// generateCandidate generates one suggestion. Candidates other than 0 are
+// cached separately so asking for several does not return copies.
+func (gc *GitCommenter) generateCandidate(changes []FileChange, candidate int) (*CommitSuggestion, error) {
+	return gc.generate(context.Background(), changes, candidate, nil)
+}
+
+// generationPlan is what every candidate for one changeset shares
+type generationPlan struct {
+	changes     []FileChange
+	style       StylePreset
+	scope       string
+	prompt      string
+	temperature float64
+	// types are the commit types the changed files allow (see
+	// InferTypes), and typeReason says why
+	types      []string
+	typeReason string
+	// warnings are added to every suggestion, e.g. possible secrets
+	warnings []string
+}
 
-		changes = append(changes, change)
+// generate generates one suggestion, streaming the response to progress
+// when it is not nil
+func (gc *GitCommenter) generate(ctx context.Context, changes []FileChange, candidate int, progress func(partial string)) (*CommitSuggestion, error) {
+	// Asking for another candidate of a template message asks the model
+	if suggestion := gc.TrivialMessage(changes); suggestion != nil && candidate == 0 {
+		gc.recordGenerated(changes, suggestion, nil)
+		return suggestion, nil
 	}
 
-	return changes, nil
+	plan, err := gc.plan(changes)
+	if err != nil {
+		gc.recordGenerated(changes, nil, err)
+		return nil, err
+	}
+	suggestion, err := gc.complete(ctx, plan, candidate, progress)
+	gc.recordGenerated(changes, suggestion, err)
+	return suggestion, err
 }
 
-// GenerateCommitMessage generates a commit message based on the changes
-func (gc *GitCommenter) GenerateCommitMessage(changes []FileChange) (*CommitSuggestion, error) {
+// recordGenerated adds a generated event to the journal
+func (gc *GitCommenter) recordGenerated(changes []FileChange, suggestion *CommitSuggestion, err error) {
+	if gc.journal == nil {
+		return
+	}
+	event := Event{Type: EventGenerated, Error: errorText(err)}
+	for _, change := range changes {
+		event.Files = append(event.Files, change.FilePath)
+	}
+	if suggestion != nil {
+		event.Message = suggestion.Message()
+		event.Template = suggestion.Template
+	}
+	if event.Template == "" {
+		event.Model = gc.config.Model
+	}
+	gc.RecordEvent(event)
+}
+
+// plan builds the prompt and settings for the changes
+func (gc *GitCommenter) plan(changes []FileChange) (*generationPlan, error) {
 	if len(changes) == 0 {
 		return nil, fmt.Errorf("no changes to analyze")
 	}
 
-	// Build context for the AI model
-	context := gc.buildChangeContext(changes)
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing that looks like a secret reaches the model unless allowed
+	findings, err := gc.CheckSecrets(changes)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, finding := range findings {
+		warnings = append(warnings, "possible secret sent to the model: "+finding.String())
+	}
+
+	// Leave excluded files out of the prompt, unless nothing would remain;
+	// the files listed in .aiignore never reach it
+	promptChanges, excluded, err := gc.filterExcluded(changes)
+	if err != nil {
+		return nil, err
+	}
+	if len(promptChanges) == 0 {
+		if promptChanges, err = gc.withoutIgnored(changes); err != nil {
+			return nil, err
+		}
+		// withoutIgnored keeps the order, so the rest were left out
+		excluded = nil
+		for i, kept := 0, 0; i < len(changes); i++ {
+			if kept < len(promptChanges) && changes[i].FilePath == promptChanges[kept].FilePath {
+				kept++
+			} else {
+				excluded = append(excluded, changes[i])
+			}
+		}
+	}
+	if promptChanges, err = gc.RedactChanges(promptChanges); err != nil {
+		return nil, err
+	}
+
+	scope, err := gc.inferScope(promptChanges)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create prompt for the AI model
-	prompt := gc.buildPrompt(context, changes)
+	// Build context for the AI model; generated and vendored files get a
+	// line each instead of their diffs
+	detailed, summarized, infos := gc.splitGenerated(promptChanges)
+	context := gc.buildChangeContext(detailed) + generatedSummary(summarized, infos) + gc.dependencyContext(promptChanges) +
+		gc.apiContext(detailed) + gc.historyContext(detailed)
+	if gc.config.CountExcluded {
+		context += excludedSummary(excluded)
+	}
 
-	// Call Ollama API
-	response, err := gc.callOllama(prompt)
+	// Create prompt for the AI model, fitted to the context window
+	prompt, err := gc.fitPrompt(detailed, func(budget PromptBudget) (string, error) {
+		return gc.renderPromptWithin(context, detailed, budget)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+		return nil, err
+	}
+	_, temperature := gc.AdaptiveTemperature(promptChanges)
+	types, typeReason := gc.inferTypes(detailed)
+
+	return &generationPlan{changes: changes, style: style, scope: scope, types: types, typeReason: typeReason, prompt: prompt,
+		temperature: temperature, warnings: warnings}, nil
+}
+
+// complete asks the model for one candidate of a plan, retrying answers
+// that break the style. Candidates after the first use a slightly higher
+// temperature so they differ.
+func (gc *GitCommenter) complete(ctx context.Context, plan *generationPlan, candidate int, progress func(partial string)) (*CommitSuggestion, error) {
+	prompt := plan.prompt
+	temperature := min(plan.temperature+candidateTemperatureStep*float64(candidate), 1)
+
+	for attempt := 1; ; attempt++ {
+		// Call Ollama API
+		response, err := gc.callOllamaStream(ctx, prompt, candidate, temperature, progress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate commit message: %w", err)
+		}
+
+		// Parse the suggestion and check it follows the style
+		suggestion := gc.parseCommitSuggestion(response, plan.changes)
+		if plan.style.Conventional && plan.scope != "" {
+			suggestion.Subject = forceScope(suggestion.Subject, gc.ticketPrefix(), plan.scope)
+		}
+		if plan.style.Conventional && len(plan.types) == 1 {
+			suggestion.Subject = forceType(suggestion.Subject, gc.ticketPrefix(), plan.types[0])
+		}
+		suggestion.PromptHash = PromptHash(plan.prompt)
+		suggestion.Warnings = append(suggestion.Warnings, plan.warnings...)
+		problem := gc.validateSuggestion(plan.style, suggestion)
+		if problem == nil && plan.style.Conventional && len(plan.types) > 0 {
+			problem = checkType(suggestion.Subject, gc.ticketPrefix(), plan.types, plan.typeReason)
+		}
+		if problem == nil {
+			return gc.shortenSubject(plan.style, plan.scope, suggestion)
+		}
+		if attempt == styleAttempts {
+			suggestion.Confidence = 0.5
+			suggestion.Warnings = append(suggestion.Warnings, problem.Error())
+			return gc.shortenSubject(plan.style, plan.scope, suggestion)
+		}
+
+		prompt = retryPrompt(prompt, response, problem)
 	}
+}
+
+// candidateTemperatureStep is added to the temperature of each further
+// candidate
+const candidateTemperatureStep = 0.1
 
-	// Parse and return the suggestion
-	suggestion := gc.parseCommitSuggestion(response, changes)
-	return suggestion, nil
+// styleAttempts is how many times a message breaking the style is requested
+const styleAttempts = 3
+
+// retryPrompt asks the model to fix a rejected answer
+func retryPrompt(prompt, response string, problem error) string {
+	return fmt.Sprintf("%s\n\nYour previous answer was:\n%s\n\nIt was rejected because %s. Respond again following the format exactly.",
+		prompt, response, problem)
+}
+
+// ticketPrefix returns the configured ticket prefix or, for the
+// ticket-first style, the ticket named in the current branch
+func (gc *GitCommenter) ticketPrefix() string {
+	if gc.config.TicketPrefix != "" || gc.config.Style != "ticket-first" {
+		return gc.config.TicketPrefix
+	}
+	return TicketFromBranch(gc.currentBranch())
 }
 
 // ensureGitRepository checks if the current directory is a Git repository
 func (gc *GitCommenter) ensureGitRepository() error {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = gc.config.RepositoryPath
-	_, err := cmd.Output()
-	return err
+	return gc.git.IsRepository()
 }
 
 // parseChangeType converts Git status to readable change type
@@ -186,32 +569,53 @@ func (gc *GitCommenter) parseChangeType(status string) string {
 
 // getFileDiff gets the diff for a specific file
 func (gc *GitCommenter) getFileDiff(filepath string) (string, int, int, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--", filepath)
-	cmd.Dir = gc.config.RepositoryPath
-	output, err := cmd.Output()
+	diff, err := gc.git.StagedDiff(filepath)
 	if err != nil {
 		return "", 0, 0, err
 	}
 
-	diff := string(output)
 	linesAdded, linesRemoved := gc.countDiffLines(diff)
 
 	return diff, linesAdded, linesRemoved, nil
 }
 
-// countDiffLines counts added and removed lines in a diff
+// countDiffLines counts added and removed lines in a diff, for diffs that
+// do not come with git's --numstat counts. Inside a hunk every +/- line
+// counts, even one reading "+++" or "---".
 func (gc *GitCommenter) countDiffLines(diff string) (added, removed int) {
-	lines := strings.Split(diff, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "+") && (inHunk || !strings.HasPrefix(line, "+++")):
 			added++
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
+		case strings.HasPrefix(line, "-") && (inHunk || !strings.HasPrefix(line, "---")):
 			removed++
 		}
 	}
 	return added, removed
 }
 
+// describeMove describes a rename or copy, e.g. "renamed foo.go to bar.go
+// with modifications", or returns "" for other changes
+func describeMove(change FileChange) string {
+	if change.OldPath == "" || change.OldPath == change.FilePath {
+		return ""
+	}
+	verb := "renamed"
+	if change.ChangeType == "copied" {
+		verb = "copied"
+	}
+	edits := "without changes"
+	if change.LinesAdded > 0 || change.LinesRemoved > 0 {
+		edits = "with modifications"
+	}
+	return fmt.Sprintf("%s %s to %s %s", verb, change.OldPath, change.FilePath, edits)
+}
+
 // buildChangeContext creates a summary of changes for the AI model
 func (gc *GitCommenter) buildChangeContext(changes []FileChange) string {
 	var context strings.Builder
@@ -236,6 +640,7 @@ func (gc *GitCommenter) buildChangeContext(changes []FileChange) string {
 	for changeType, count := range changeTypes {
 		typesSummary = append(typesSummary, fmt.Sprintf("%d %s", count, changeType))
 	}
+	sort.Strings(typesSummary) // Keep the prompt stable for caching and golden tests
 	context.WriteString(strings.Join(typesSummary, ", "))
 	context.WriteString("\n\n")
 
@@ -249,6 +654,14 @@ func (gc *GitCommenter) buildChangeContext(changes []FileChange) string {
 
 		context.WriteString(fmt.Sprintf("%d. %s (%s%s):\n", i+1, change.FilePath, change.ChangeType, ext))
 		context.WriteString(fmt.Sprintf("   Lines changed: +%d -%d\n", change.LinesAdded, change.LinesRemoved))
+		if move := describeMove(change); move != "" {
+			context.WriteString("   " + strings.ToUpper(move[:1]) + move[1:] + "\n")
+		}
+		if info := ClassifyChange(change); info.Vendored {
+			context.WriteString("   Vendored third-party code\n")
+		} else if info.Generated {
+			context.WriteString("   Generated file (do not describe it as hand-written)\n")
+		}
 
 		// Add file type context
 		switch ext {
@@ -281,6 +694,11 @@ func (gc *GitCommenter) buildChangeContext(changes []FileChange) string {
 
 // buildPrompt creates the prompt for the AI model
 func (gc *GitCommenter) buildPrompt(context string, changes []FileChange) string {
+	return gc.buildPromptWithin(context, changes, gc.PromptBudget())
+}
+
+// buildPromptWithin creates the prompt showing the diffs within budget
+func (gc *GitCommenter) buildPromptWithin(context string, changes []FileChange, budget PromptBudget) string {
 	var prompt strings.Builder
 
 	prompt.WriteString("You are an expert developer assistant that generates detailed, meaningful Git commit messages based on actual code changes.\n\n")
@@ -289,33 +707,35 @@ func (gc *GitCommenter) buildPrompt(context string, changes []FileChange) string
 	prompt.WriteString("\n")
 
 	// Add detailed diff context for key changes
-	for i, change := range changes {
-		if i >= 5 { // Increase limit to 5 files for better context
-			prompt.WriteString(fmt.Sprintf("... and %d more files\n\n", len(changes)-5))
-			break
-		}
-		if change.Diff != "" {
-			prompt.WriteString(fmt.Sprintf("=== DETAILED CHANGES IN %s ===\n", change.FilePath))
-			prompt.WriteString(fmt.Sprintf("Change Type: %s\n", change.ChangeType))
-			prompt.WriteString(fmt.Sprintf("Lines Added: %d, Lines Removed: %d\n\n", change.LinesAdded, change.LinesRemoved))
-
-			// Include more context but still truncate if very long
-			diff := change.Diff
-			if len(diff) > 2000 {
-				diff = diff[:2000] + "\n... (truncated - showing first 2000 characters)"
-			}
-			prompt.WriteString("DIFF CONTENT:\n")
-			prompt.WriteString(diff)
-			prompt.WriteString("\n" + strings.Repeat("=", 50) + "\n\n")
-		} else {
-			// For binary files or files without diffs
-			prompt.WriteString(fmt.Sprintf("=== %s ===\n", change.FilePath))
-			prompt.WriteString(fmt.Sprintf("Change Type: %s (binary file or no diff available)\n\n", change.ChangeType))
-		}
+	prompt.WriteString(formatDiffs(changes, budget))
+	prompt.WriteString(gc.summaryContext(changes, budget))
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		style = Styles["conventional"]
 	}
 
 	prompt.WriteString("Based on the above changes, generate a commit message that:\n")
-	prompt.WriteString("1. Uses conventional commit format (feat/fix/docs/style/refactor/test/chore)\n")
+	prompt.WriteString("1. " + style.Format + "\n")
+	if types := gc.allowedTypes(true); style.Conventional && len(types) > 0 {
+		prompt.WriteString("   Allowed types: " + strings.Join(types, ", ") + " (use no other type)\n")
+	}
+	if types, reason := gc.inferTypes(changes); style.Conventional && len(types) > 0 {
+		prompt.WriteString("   Use the type " + strings.Join(types, " or ") + ", as " + reason + "\n")
+	}
+	if scope, err := gc.inferScope(changes); err == nil && style.Conventional && scope != "" {
+		prompt.WriteString("   Use the scope (" + scope + "), derived from the changed paths\n")
+	} else if scopes := gc.allowedScopes(true); style.Conventional && len(scopes) > 0 {
+		prompt.WriteString("   Allowed scopes: " + strings.Join(scopes, ", ") + " (or omit the scope)\n")
+	}
+	if gc.config.Commitlint != nil && style.Conventional {
+		for _, line := range gc.config.Commitlint.promptLines() {
+			prompt.WriteString("   " + line + "\n")
+		}
+	}
+	if gc.gitmojiMode() {
+		prompt.WriteString("   " + strings.ReplaceAll(strings.TrimSpace(gitmojiGuide()), "\n", "\n   ") + "\n")
+	}
 	prompt.WriteString("2. Has a clear, descriptive subject line (50 characters or less)\n")
 	prompt.WriteString("3. SPECIFICALLY mentions what functionality was added/changed/fixed\n")
 	prompt.WriteString("4. Uses present tense, imperative mood (e.g., 'add', 'fix', 'update')\n")
@@ -326,13 +746,27 @@ func (gc *GitCommenter) buildPrompt(context string, changes []FileChange) string
 	prompt.WriteString("- Mention key functions, features, or components that were modified\n")
 	prompt.WriteString("- If it's a new file, mention what it contains or does\n")
 	prompt.WriteString("- If it's a modification, mention what was improved/changed\n")
+	if len(gc.config.ManualSections) > 0 {
+		prompt.WriteString("- Do not write these sections, the author fills them in: " + strings.Join(sectionHeadings(gc.config.ManualSections), ", ") + "\n")
+	}
+	if language := gc.language(); language != "en" {
+		prompt.WriteString("- Write the subject and body in " + languageName(language) + "; keep commit types, scopes, code identifiers and file names unchanged\n")
+	}
 	prompt.WriteString("- Focus on the 'what' and 'why' of the changes\n\n")
 
+	if ticket := gc.ticketPrefix(); ticket != "" && style.Name == "ticket-first" {
+		prompt.WriteString(fmt.Sprintf("The ticket reference is %s.\n\n", ticket))
+	}
+
 	prompt.WriteString("Examples of GOOD commit messages:\n")
-	prompt.WriteString("- 'feat: add interactive model selection with recommendations'\n")
-	prompt.WriteString("- 'fix: correct model validation in prerequisites check'\n")
-	prompt.WriteString("- 'refactor: enhance logging with detailed progress indicators'\n")
-	prompt.WriteString("- 'feat: implement git push with remote repository detection'\n\n")
+	for _, example := range style.Examples {
+		prompt.WriteString("- '" + example + "'\n")
+	}
+	prompt.WriteString("\n")
+
+	examples := gc.historyExamples()
+	prompt.WriteString(historyExamplesPrompt(examples))
+	prompt.WriteString(similarCommitsPrompt(gc.similarCommits(changes), examples))
 
 	prompt.WriteString("Examples of BAD commit messages (avoid these):\n")
 	prompt.WriteString("- 'add functionality'\n")
@@ -345,14 +779,26 @@ func (gc *GitCommenter) buildPrompt(context string, changes []FileChange) string
 	return prompt.String()
 }
 
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
 // OllamaRequest represents a request to the Ollama API
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model   string `json:"model"`
+	Prompt  string `json:"prompt"`
+	Stream  bool   `json:"stream"`
 	Options struct {
 		Temperature float64 `json:"temperature"`
 		NumPredict  int     `json:"num_predict"`
+		NumCtx      int     `json:"num_ctx,omitempty"`
 	} `json:"options"`
 }
 
@@ -364,46 +810,127 @@ type OllamaResponse struct {
 
 // callOllama makes a request to the Ollama API
 func (gc *GitCommenter) callOllama(prompt string) (string, error) {
+	return gc.callOllamaCandidate(prompt, 0)
+}
+
+// callOllamaCandidate makes a request for the given candidate number
+func (gc *GitCommenter) callOllamaCandidate(prompt string, candidate int) (string, error) {
+	return gc.callOllamaStream(context.Background(), prompt, candidate, gc.config.Temperature, nil)
+}
+
+// callOllamaStream makes a request for the given candidate number at the
+// given temperature. With a progress function the response is streamed and
+// the text received so far is passed to it after every chunk.
+func (gc *GitCommenter) callOllamaStream(ctx context.Context, prompt string, candidate int, temperature float64, progress func(partial string)) (string, error) {
+	start := time.Now()
+	response, cached, err := gc.requestCompletion(ctx, prompt, candidate, temperature, progress)
+	if gc.trace != nil {
+		gc.trace(ModelCall{
+			Model:       gc.config.Model,
+			Prompt:      prompt,
+			Response:    response,
+			Temperature: temperature,
+			Candidate:   candidate,
+			Duration:    time.Since(start),
+			Cached:      cached,
+			Err:         err,
+		})
+	}
+	return response, err
+}
+
+// requestCompletion answers a prompt from the cache or the Ollama API and
+// reports whether the response was cached
+func (gc *GitCommenter) requestCompletion(ctx context.Context, prompt string, candidate int, temperature float64, progress func(partial string)) (string, bool, error) {
+	prompt, err := gc.guardPrompt(prompt)
+	if err != nil {
+		return "", false, err
+	}
+	cacheKey := CacheKey("generate", gc.config.Model, prompt,
+		strconv.FormatFloat(temperature, 'g', -1, 64), strconv.Itoa(gc.config.MaxTokens), strconv.Itoa(candidate))
+	if response, ok := gc.cachedResponse(cacheKey); ok {
+		return response, true, nil
+	}
+
+	// The cache holds restored answers keyed by the original prompt
+	restore := func(text string) string { return text }
+	if gc.anonymizer != nil {
+		prompt = gc.anonymizer.Anonymize(prompt)
+		restore = gc.anonymizer.Restore
+	}
+
 	req := OllamaRequest{
 		Model:  gc.config.Model,
 		Prompt: prompt,
-		Stream: false,
+		Stream: progress != nil,
 	}
-	req.Options.Temperature = gc.config.Temperature
+	req.Options.Temperature = temperature
 	req.Options.NumPredict = gc.config.MaxTokens
+	req.Options.NumCtx = gc.numCtx()
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", false, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := gc.client.Post(gc.config.OllamaEndpoint+"/api/generate", "application/json", bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, gc.config.OllamaEndpoint+"/api/generate", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to call Ollama API: %w", err)
+		return "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := gc.client.Do(httpReq)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to call Ollama API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+		return "", false, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
+	var response string
+	if progress != nil {
+		// A streamed response is one JSON object per chunk
+		var text strings.Builder
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var chunk OllamaResponse
+			if err := decoder.Decode(&chunk); err == io.EOF {
+				break
+			} else if err != nil {
+				return "", false, fmt.Errorf("failed to read streamed response: %w", err)
+			}
+			text.WriteString(chunk.Response)
+			progress(restore(text.String()))
+			if chunk.Done {
+				break
+			}
+		}
+		response = restore(strings.TrimSpace(text.String()))
+	} else {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read response: %w", err)
+		}
 
-	var ollamaResp OllamaResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		var ollamaResp OllamaResponse
+		if err := json.Unmarshal(body, &ollamaResp); err != nil {
+			return "", false, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		response = restore(strings.TrimSpace(ollamaResp.Response))
 	}
 
-	return strings.TrimSpace(ollamaResp.Response), nil
+	gc.storeResponse(cacheKey, response)
+	return response, false, nil
 }
 
 // parseCommitSuggestion parses the AI response into a CommitSuggestion
 func (gc *GitCommenter) parseCommitSuggestion(response string, changes []FileChange) *CommitSuggestion {
-	lines := strings.Split(response, "\n")
+	// Models and terminals disagree on line endings
+	response = strings.ReplaceAll(response, "\r\n", "\n")
+	response = strings.ReplaceAll(response, "\r", "\n")
+	lines := strings.Split(strings.TrimSpace(response), "\n")
 
 	var subject, body string
 	var filesAffected []string
@@ -411,6 +938,17 @@ func (gc *GitCommenter) parseCommitSuggestion(response string, changes []FileCha
 	if len(lines) > 0 {
 		subject = strings.TrimSpace(lines[0])
 	}
+	if subject == "" {
+		subject = gc.fallbackSubject(changes)
+	}
+
+	if gc.gitmojiMode() {
+		subject = NormalizeGitmoji(subject)
+	}
+
+	if prefix := gc.ticketPrefix(); prefix != "" && !strings.HasPrefix(subject, prefix) {
+		subject = prefix + " " + subject
+	}
 
 	if len(lines) > 1 {
 		bodyLines := lines[1:]
@@ -430,12 +968,98 @@ func (gc *GitCommenter) parseCommitSuggestion(response string, changes []FileCha
 
 	return &CommitSuggestion{
 		Subject:       subject,
-		Body:         strings.TrimSpace(body),
-		Confidence:   0.8, // Default confidence
+		Body:          WrapBody(StripSections(strings.TrimSpace(body), gc.config.ManualSections), BodyWrapWidth),
+		Confidence:    0.8, // Default confidence
 		FilesAffected: filesAffected,
 	}
 }
 
+// fallbackSubject describes the changes when the model returned nothing, so
+// a suggestion never has an empty subject
+func (gc *GitCommenter) fallbackSubject(changes []FileChange) string {
+	subject := "update files"
+	switch len(changes) {
+	case 0:
+	case 1:
+		subject = "update " + changes[0].FilePath
+	default:
+		subject = fmt.Sprintf("update %d files", len(changes))
+	}
+
+	if style, err := LookupStyle(gc.config.Style); err == nil && style.Conventional {
+		return "chore: " + subject
+	}
+	return strings.ToUpper(subject[:1]) + subject[1:]
+}
+
+// Message returns the full commit message (subject, blank line, body)
+func (s *CommitSuggestion) Message() string {
+	if s.Body == "" {
+		return s.Subject
+	}
+	return s.Subject + "\n\n" + s.Body
+}
+
+// AddTrailer appends a "Key: value" trailer to the body, starting a new
+// trailer block if the body does not already end with one
+func (s *CommitSuggestion) AddTrailer(key, value string) {
+	trailer := key + ": " + value
+	if s.Body == "" {
+		s.Body = trailer
+		return
+	}
+
+	lines := strings.Split(s.Body, "\n")
+	if isTrailerLine(lines[len(lines)-1]) {
+		s.Body += "\n" + trailer
+	} else {
+		s.Body += "\n\n" + trailer
+	}
+}
+
+// isTrailerLine reports whether line looks like a "Key: value" git trailer
+func isTrailerLine(line string) bool {
+	colon := strings.Index(line, ": ")
+	if colon <= 0 {
+		return false
+	}
+	return !strings.ContainsAny(line[:colon], " \t")
+}
+
+// Commit commits the staged changes with the suggested message
+func (gc *GitCommenter) Commit(suggestion *CommitSuggestion) error {
+	if suggestion == nil || strings.TrimSpace(suggestion.Subject) == "" {
+		return fmt.Errorf("empty commit message")
+	}
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	err := gc.git.Commit(suggestion.Message())
+	if gc.journal != nil {
+		event := Event{Type: EventCommitted, Message: suggestion.Message(), Error: errorText(err)}
+		if err == nil {
+			event.Commit, _ = gc.HeadCommit()
+		}
+		gc.RecordEvent(event)
+	}
+	// Keep the commit index current; a failure only means the commit is
+	// embedded later
+	if err == nil && gc.config.EmbeddingModel != "" {
+		_, _ = gc.indexCommits(1)
+	}
+	return err
+}
+
+// Push pushes the current branch to its upstream
+func (gc *GitCommenter) Push() error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	err := gc.git.Push()
+	gc.RecordEvent(Event{Type: EventPushed, Error: errorText(err)})
+	return err
+}
+
 // GetRepository returns the current repository path
 func (gc *GitCommenter) GetRepository() string {
 	return gc.config.RepositoryPath
@@ -78,6 +78,12 @@ func TestCountDiffLines(t *testing.T) {
 	if removed != 1 {
 		t.Errorf("Expected 1 removed line, got %d", removed)
 	}
+
+	// Removed SQL comments look like file headers inside a hunk
+	added, removed = commenter.countDiffLines("diff --git a/q.sql b/q.sql\n--- a/q.sql\n+++ b/q.sql\n@@ -1,2 +1 @@\n--- old note\n-- kept\n+++ new\n")
+	if added != 1 || removed != 2 {
+		t.Errorf("Expected +1 -2 inside the hunk, got +%d -%d", added, removed)
+	}
 }
 
 func TestBuildChangeContext(t *testing.T) {
@@ -117,6 +123,29 @@ func TestBuildChangeContext(t *testing.T) {
 	}
 }
 
+func TestBuildChangeContextDescribesRenames(t *testing.T) {
+	commenter := New(nil)
+	changes := []FileChange{
+		{FilePath: "bar.go", OldPath: "foo.go", ChangeType: "renamed", LinesAdded: 2, LinesRemoved: 1},
+		{FilePath: "docs/b.md", OldPath: "docs/a.md", ChangeType: "renamed"},
+		{FilePath: "copy.go", OldPath: "src.go", ChangeType: "copied", LinesAdded: 1},
+	}
+
+	context := commenter.buildChangeContext(changes)
+	for _, want := range []string{
+		"   Renamed foo.go to bar.go with modifications\n",
+		"   Renamed docs/a.md to docs/b.md without changes\n",
+		"   Copied src.go to copy.go with modifications\n",
+	} {
+		if !contains(context, want) {
+			t.Errorf("Expected %q in the context:\n%s", want, context)
+		}
+	}
+	if move := describeMove(FileChange{FilePath: "main.go", ChangeType: "modified"}); move != "" {
+		t.Errorf("Expected no description for a modification, got %q", move)
+	}
+}
+
 func TestParseCommitSuggestion(t *testing.T) {
 	commenter := New(nil)
 
@@ -0,0 +1,100 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitConfigSection is the git config section holding per-repository
+// overrides, e.g. "git config ai-commit.model qwen2.5-coder:7b"
+const GitConfigSection = "ai-commit"
+
+// LoadGitConfig reads the ai-commit.* settings visible from repoPath
+// (system, global and local scopes, local winning)
+func LoadGitConfig(repoPath string) (*FileConfig, error) {
+	fc := &FileConfig{}
+
+	cmd := exec.Command("git", "config", "--get-regexp", `^`+GitConfigSection+`\.`)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means no matching keys; other failures mean no git
+		return fc, nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name, value, _ := strings.Cut(line, " ")
+		key := strings.TrimPrefix(name, GitConfigSection+".")
+		if key == name || key == "" || key == trustHooksName {
+			continue
+		}
+		if err := fc.Set(key, value); err != nil {
+			return nil, fmt.Errorf("invalid git config %s: %w", name, err)
+		}
+	}
+
+	return fc, nil
+}
+
+// GetGitConfig returns the value of ai-commit.<key>, or "" if unset
+func GetGitConfig(repoPath, key string) (string, error) {
+	if err := validateConfigKey(key); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "config", "--get", GitConfigSection+"."+key)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read git config: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SetGitConfig validates and stores ai-commit.<key> in the repository's
+// local git config, or the global one when global is true
+func SetGitConfig(repoPath, key, value string, global bool) error {
+	if err := (&FileConfig{}).Set(key, value); err != nil {
+		return err
+	}
+	return runGitConfig(repoPath, global, GitConfigSection+"."+strings.ToLower(key), value)
+}
+
+// UnsetGitConfig removes ai-commit.<key> from the local or global git config
+func UnsetGitConfig(repoPath, key string, global bool) error {
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+
+	err := runGitConfig(repoPath, global, "--unset", GitConfigSection+"."+strings.ToLower(key))
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 5 {
+		return nil // Key was not set
+	}
+	return err
+}
+
+// runGitConfig runs git config with the scope flag in front of args
+func runGitConfig(repoPath string, global bool, args ...string) error {
+	scope := "--local"
+	if global {
+		scope = "--global"
+	}
+
+	cmd := exec.Command("git", append([]string{"config", scope}, args...)...)
+	cmd.Dir = repoPath
+	return cmd.Run()
+}
+
+// validateConfigKey checks that key is one of ConfigKeys
+func validateConfigKey(key string) error {
+	for _, known := range ConfigKeys() {
+		if strings.EqualFold(key, known) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting %q (available: %s)", key, strings.Join(ConfigKeys(), ", "))
+}
@@ -0,0 +1,96 @@
+package gitcommenter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initTestRepo creates an empty git repository for tests that need one
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q")
+	gitRun(t, dir, "config", "user.name", "Test")
+	gitRun(t, dir, "config", "user.email", "test@example.com")
+	return dir
+}
+
+// gitRun runs git in dir for a test and returns its output, failing the
+// test when git fails
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// writeFile writes a file below dir for a test, creating its directories;
+// name is slash-separated
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitConfigRoundTrip(t *testing.T) {
+	dir := initTestRepo(t)
+
+	if err := SetGitConfig(dir, "model", "qwen2.5-coder:7b", false); err != nil {
+		t.Fatalf("SetGitConfig returned error: %v", err)
+	}
+	if err := SetGitConfig(dir, "exclude", "dist/,*.lock", false); err != nil {
+		t.Fatalf("SetGitConfig returned error: %v", err)
+	}
+
+	value, err := GetGitConfig(dir, "model")
+	if err != nil || value != "qwen2.5-coder:7b" {
+		t.Errorf("GetGitConfig = %q, %v", value, err)
+	}
+
+	fc, err := LoadGitConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadGitConfig returned error: %v", err)
+	}
+	if fc.Model != "qwen2.5-coder:7b" || len(fc.Exclude) != 2 {
+		t.Errorf("Unexpected git config: %+v", fc)
+	}
+
+	if err := UnsetGitConfig(dir, "model", false); err != nil {
+		t.Fatalf("UnsetGitConfig returned error: %v", err)
+	}
+	if value, _ := GetGitConfig(dir, "model"); value != "" {
+		t.Errorf("Expected model to be unset, got %q", value)
+	}
+}
+
+func TestSetGitConfigValidates(t *testing.T) {
+	dir := initTestRepo(t)
+
+	if err := SetGitConfig(dir, "colour", "blue", false); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+	if err := SetGitConfig(dir, "temperature", "hot", false); err == nil {
+		t.Error("Expected an error for an invalid temperature")
+	}
+	if err := SetGitConfig(dir, "push", "sometimes", false); err == nil {
+		t.Error("Expected an error for an invalid push mode")
+	}
+}
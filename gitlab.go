@@ -0,0 +1,155 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GitLabProjectFromURL returns the host and project path (group/project,
+// possibly with subgroups) of a clone URL such as
+// git@gitlab.com:group/project.git or https://gitlab.example.com/group/sub/project.
+// Both are "" when the URL has no project path.
+func GitLabProjectFromURL(remote string) (host, project string) {
+	remote = strings.TrimSpace(remote)
+	var path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if address, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(address, "/") {
+		// scp-like syntax: [user@]host:path
+		_, host, _ = strings.Cut(address, "@")
+		if host == "" {
+			host = address
+		}
+		path = rest
+	}
+	project = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(project, "/") {
+		return "", ""
+	}
+	return host, project
+}
+
+// gitlabRemote returns the host and project of the origin remote. Hosts
+// without "gitlab" in their name are only accepted with a GitLabAPI.
+func (gc *GitCommenter) gitlabRemote() (host, project string, err error) {
+	remote, err := gc.gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	host, project = GitLabProjectFromURL(remote)
+	if project == "" || (gc.config.GitLabAPI == "" && !strings.Contains(host, "gitlab")) {
+		return "", "", fmt.Errorf("origin %s is not a GitLab project; set gitlab-api for self-hosted servers", strings.TrimSpace(remote))
+	}
+	return host, project, nil
+}
+
+// GitLabProject returns the project path, e.g. group/project, of the
+// repository's origin remote
+func (gc *GitCommenter) GitLabProject() (string, error) {
+	_, project, err := gc.gitlabRemote()
+	return project, err
+}
+
+// gitlabAPI returns the configured GitLab REST API without a trailing
+// slash, or the API of the origin remote's host
+func (gc *GitCommenter) gitlabAPI() (string, error) {
+	if gc.config.GitLabAPI != "" {
+		return strings.TrimSuffix(gc.config.GitLabAPI, "/"), nil
+	}
+	host, _, err := gc.gitlabRemote()
+	if err != nil {
+		return "", err
+	}
+	return "https://" + host + "/api/v4", nil
+}
+
+// gitlabCredential returns a token for the GitLab API: GITLAB_TOKEN, or
+// else the password stored for the API's host by git's credential helper
+func (gc *GitCommenter) gitlabCredential(api string) (string, error) {
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		return token, nil
+	}
+	host := api
+	if u, err := url.Parse(api); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if password := gc.storedCredential(host); password != "" {
+		return password, nil
+	}
+	return "", fmt.Errorf("no GitLab token for %s: set GITLAB_TOKEN or store one with git's credential helper", host)
+}
+
+// gitlabRequest sends body (nil for none) as JSON to a GitLab REST API path
+// such as /projects/group%2Fproject/merge_requests and decodes the response
+// into v
+func (gc *GitCommenter) gitlabRequest(method, path string, body, v any) error {
+	api, err := gc.gitlabAPI()
+	if err != nil {
+		return err
+	}
+	token, err := gc.gitlabCredential(api)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Authorization": {"Bearer " + token}}
+	var data io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		data = bytes.NewReader(encoded)
+		header.Set("Content-Type", "application/json")
+	}
+	return gc.sendJSON(method, api+path, header, data, v)
+}
+
+// GenerateMergeRequest generates the title and a description with Summary,
+// Changes and Testing sections for a GitLab merge request of source into
+// target, from source's commits and the cumulative diff. An empty target
+// uses DefaultBranch.
+func (gc *GitCommenter) GenerateMergeRequest(target, source string) (*PullRequestDraft, error) {
+	return gc.generateBranchRequest(target, source, "GitLab", "merge request", sectionedDescription)
+}
+
+// PostMergeRequest sets draft's description on the open merge request from
+// draft.Head, keeping its title, or opens a merge request into draft.Base
+// when there is none. It sets the Number (the project's merge request IID)
+// and URL of draft. project is e.g. group/project (see GitLabProject).
+func (gc *GitCommenter) PostMergeRequest(project string, draft *PullRequestDraft) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+
+	type mergeRequest struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+	}
+	path := "/projects/" + url.PathEscape(project) + "/merge_requests"
+	var open []mergeRequest
+	query := url.Values{"state": {"opened"}, "source_branch": {draft.Head}}
+	if err := gc.gitlabRequest(http.MethodGet, path+"?"+query.Encode(), nil, &open); err != nil {
+		return fmt.Errorf("failed to look up merge requests: %w", err)
+	}
+
+	var posted mergeRequest
+	if len(open) > 0 {
+		update := map[string]string{"description": draft.Body}
+		if err := gc.gitlabRequest(http.MethodPut, fmt.Sprintf("%s/%d", path, open[0].IID), update, &posted); err != nil {
+			return fmt.Errorf("failed to update merge request !%d: %w", open[0].IID, err)
+		}
+	} else {
+		create := map[string]string{"source_branch": draft.Head, "target_branch": draft.Base, "title": draft.Title, "description": draft.Body}
+		if err := gc.gitlabRequest(http.MethodPost, path, create, &posted); err != nil {
+			return fmt.Errorf("failed to create merge request: %w", err)
+		}
+	}
+	draft.Number, draft.URL = posted.IID, posted.WebURL
+	return nil
+}
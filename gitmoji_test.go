@@ -0,0 +1,67 @@
+package gitcommenter
+
+import "testing"
+
+func TestSplitGitmoji(t *testing.T) {
+	tests := []struct {
+		subject string
+		code    string
+		rest    string
+		ok      bool
+	}{
+		{"✨ Add init wizard", ":sparkles:", "Add init wizard", true},
+		{":bug: Fix crash", ":bug:", "Fix crash", true},
+		{"♻ Simplify logging", ":recycle:", "Simplify logging", true}, // Missing variation selector
+		{"⚡️ Cache responses", ":zap:", "Cache responses", true},
+		{"🧑‍💻 Improve debug output", ":technologist:", "Improve debug output", true},
+		{"😀 Add smile", "", "😀 Add smile", false},
+		{":unknown: Do things", "", ":unknown: Do things", false},
+		{"feat: add init wizard", "", "feat: add init wizard", false},
+	}
+
+	for _, test := range tests {
+		gitmoji, rest, ok := SplitGitmoji(test.subject)
+		if ok != test.ok || gitmoji.Code != test.code || rest != test.rest {
+			t.Errorf("SplitGitmoji(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				test.subject, gitmoji.Code, rest, ok, test.code, test.rest, test.ok)
+		}
+	}
+}
+
+func TestNormalizeGitmoji(t *testing.T) {
+	if got := NormalizeGitmoji(":sparkles: feat: add x"); got != "✨ feat: add x" {
+		t.Errorf("Expected shortcode to become an emoji, got %q", got)
+	}
+	if got := NormalizeGitmoji("♻ Refactor"); got != "♻️ Refactor" {
+		t.Errorf("Expected the official emoji form, got %q", got)
+	}
+	if got := NormalizeGitmoji("feat: add x"); got != "feat: add x" {
+		t.Errorf("Expected subjects without gitmoji to be unchanged, got %q", got)
+	}
+}
+
+func TestGitmojiMode(t *testing.T) {
+	config := DefaultConfig()
+	config.Gitmoji = true
+	config.TicketPrefix = "PROJ-1"
+	gc := New(config)
+	style, _ := LookupStyle("conventional")
+
+	suggestion := gc.parseCommitSuggestion(":sparkles: feat: add init wizard", nil)
+	if suggestion.Subject != "PROJ-1 ✨ feat: add init wizard" {
+		t.Errorf("Unexpected subject %q", suggestion.Subject)
+	}
+	if err := gc.validateSuggestion(style, suggestion); err != nil {
+		t.Errorf("Expected a valid gitmoji conventional subject, got %v", err)
+	}
+
+	for _, subject := range []string{"PROJ-1 feat: add init wizard", "PROJ-1 ✨ Add init wizard"} {
+		if err := gc.validateSuggestion(style, &CommitSuggestion{Subject: subject}); err == nil {
+			t.Errorf("Expected %q to be rejected", subject)
+		}
+	}
+
+	if prompt := gc.buildPrompt("", nil); !contains(prompt, "✨ Introduce new features") {
+		t.Error("Expected the gitmoji list in the prompt")
+	}
+}
@@ -0,0 +1,428 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// APIChange is an added, removed or changed exported Go identifier
+type APIChange struct {
+	// Package is the directory of the package relative to the repository root
+	Package string
+	// Symbol is the identifier, e.g. "Config", "Config.Style" or "(*GitCommenter).Push"
+	Symbol string
+	// Kind is "added", "removed" or "changed"
+	Kind string
+	// Old and New are the declarations before and after the change
+	Old string
+	New string
+}
+
+// String formats the change for prompts and terminal output
+func (c APIChange) String() string {
+	switch c.Kind {
+	case "added":
+		return fmt.Sprintf("%s: added %s", c.Package, c.New)
+	case "removed":
+		return fmt.Sprintf("%s: removed %s", c.Package, c.Old)
+	default:
+		return fmt.Sprintf("%s: changed %s to %s", c.Package, c.Old, c.New)
+	}
+}
+
+// Breaking reports whether the change can break callers of the package
+func (c APIChange) Breaking() bool {
+	return c.Kind != "added"
+}
+
+// maxAPIPackages bounds how many packages are analyzed for one commit
+const maxAPIPackages = 10
+
+// StagedAPIChanges compares the exported API of every Go package with staged
+// changes against HEAD. Packages named main are skipped. Types from
+// dependencies that cannot be imported are compared by their source text.
+func (gc *GitCommenter) StagedAPIChanges() ([]APIChange, error) {
+	changes, err := gc.ScanStagedChanges()
+	if errors.Is(err, ErrOnlyIgnored) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return gc.apiChanges(changes)
+}
+
+// apiChanges compares the exported API of the Go packages that changes
+// touch before and after their diffs, like StagedAPIChanges: a package is
+// read at the version the diffs were made from (see FileChange.Base) and
+// the diffs are applied to it. Packages the diffs do not apply to are
+// skipped.
+func (gc *GitCommenter) apiChanges(changes []FileChange) ([]APIChange, error) {
+	byDir := make(map[string][]FileChange)
+	for _, change := range changes {
+		dirs := []string{}
+		for _, p := range []string{change.OldPath, change.FilePath} {
+			if p != "" && isGoSource(p) && !slices.Contains(dirs, path.Dir(p)) {
+				dirs = append(dirs, path.Dir(p))
+			}
+		}
+		for _, dir := range dirs {
+			byDir[dir] = append(byDir[dir], change)
+		}
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	if len(dirs) > maxAPIPackages {
+		dirs = dirs[:maxAPIPackages]
+	}
+
+	imp := &tolerantImporter{base: importer.Default(), fakes: make(map[string]*types.Package)}
+	var apiChanges []APIChange
+packages:
+	for _, dir := range dirs {
+		oldSources, err := gc.packageSources(byDir[dir][0].base(), dir)
+		if err != nil {
+			return nil, err
+		}
+		// Without commits there is no old package
+		newSources := make(map[string]string)
+		maps.Copy(newSources, oldSources)
+		for _, change := range byDir[dir] {
+			if change.OldPath != "" && change.ChangeType != "copied" {
+				delete(newSources, change.OldPath)
+			}
+			if change.ChangeType == "deleted" {
+				delete(newSources, change.FilePath)
+				continue
+			}
+			if path.Dir(change.FilePath) != dir || !isGoSource(change.FilePath) {
+				continue
+			}
+			_, after, ok := gc.changeVersions(change)
+			if !ok {
+				continue packages
+			}
+			newSources[change.FilePath] = after
+		}
+
+		apiChanges = append(apiChanges, diffAPI(dir, exportedAPI(dir, oldSources, imp), exportedAPI(dir, newSources, imp))...)
+	}
+	return apiChanges, nil
+}
+
+// apiContext lists the functions, methods and types the changes add,
+// remove or modify, so the model can name them: first the changes to the
+// exported API of the Go packages touched, then the other declarations
+// of Go files and of the files the SymbolAnalyzer supports. The analysis
+// is best effort: without results the section is left out.
+func (gc *GitCommenter) apiContext(changes []FileChange) string {
+	apiChanges, err := gc.apiChanges(changes)
+	if err != nil {
+		apiChanges = nil
+	}
+	symbolChanges := gc.symbolChangesBeyond(changes, apiChanges)
+	if len(apiChanges) == 0 && len(symbolChanges) == 0 {
+		return ""
+	}
+
+	var context strings.Builder
+	breaking := false
+	context.WriteString("CHANGED FUNCTIONS AND TYPES:\n")
+	for _, change := range apiChanges {
+		context.WriteString("- " + change.Package + " (exported API): " + strings.TrimPrefix(change.String(), change.Package+": ") + "\n")
+		breaking = breaking || change.Breaking()
+	}
+	for i, change := range symbolChanges {
+		if i == maxSymbolChanges {
+			fmt.Fprintf(&context, "- ... and %d more\n", len(symbolChanges)-i)
+			break
+		}
+		context.WriteString("- " + change.File + ": " + truncateUTF8(change.String(), 200) + "\n")
+	}
+	context.WriteString("Name the most important of these in the message.\n")
+	if breaking {
+		context.WriteString("Removed or changed exported identifiers may break callers: mention this as a BREAKING CHANGE.\n")
+	}
+	return context.String() + "\n"
+}
+
+// isGoSource reports whether p is a non-test Go file
+func isGoSource(p string) bool {
+	return strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go")
+}
+
+// packageSources returns the non-test Go files directly in dir at rev, or in
+// the index when rev is IndexBase
+func (gc *GitCommenter) packageSources(rev, dir string) (map[string]string, error) {
+	// ":(top)." matches nothing; ":(top)" is the whole tree
+	pathspec := ":(top)" + dir
+	if dir == "." {
+		pathspec = ":(top)"
+	}
+	var listing string
+	var err error
+	if rev == IndexBase {
+		listing, err = gc.gitOutput("ls-files", "--full-name", "--", pathspec)
+	} else {
+		if _, verifyErr := gc.gitOutput("rev-parse", "--verify", "-q", rev); verifyErr != nil {
+			return nil, nil // No commits yet
+		}
+		listing, err = gc.gitOutput("ls-tree", "-r", "--name-only", "--full-name", rev, "--", pathspec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string)
+	for _, file := range splitLines(listing) {
+		if path.Dir(file) != dir || !isGoSource(file) {
+			continue
+		}
+		content, err := gc.gitOutput("show", rev+":"+file)
+		if err != nil {
+			return nil, err
+		}
+		sources[file] = content
+	}
+	return sources, nil
+}
+
+// exportedAPI maps every exported identifier of a package to its declaration
+func exportedAPI(dir string, sources map[string]string, imp types.Importer) map[string]string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, sources[name], parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		// Ignore files of another package (e.g. //go:build ignore programs)
+		if len(files) > 0 && file.Name.Name != files[0].Name.Name {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 || files[0].Name.Name == "main" {
+		return nil
+	}
+
+	// Type errors are expected when dependencies are missing; the checker
+	// still records every declared object
+	config := types.Config{Importer: imp, Error: func(error) {}}
+	pkg, _ := config.Check(dir, fset, files, nil)
+
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+	fallback := sourceDeclarations(fset, files)
+	describe := func(symbol, declaration string) string {
+		if strings.Contains(declaration, "invalid type") && fallback[symbol] != "" {
+			return fallback[symbol]
+		}
+		return declaration
+	}
+
+	api := make(map[string]string)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		switch obj := obj.(type) {
+		case *types.TypeName:
+			if obj.IsAlias() {
+				api[name] = describe(name, "type "+name+" = "+types.TypeString(obj.Type(), qualifier))
+				continue
+			}
+
+			if st, ok := obj.Type().Underlying().(*types.Struct); ok {
+				api[name] = "type " + name + " struct"
+				for i := 0; i < st.NumFields(); i++ {
+					field := st.Field(i)
+					if field.Exported() {
+						symbol := name + "." + field.Name()
+						api[symbol] = describe(symbol, "field "+symbol+" "+types.TypeString(field.Type(), qualifier))
+					}
+				}
+			} else {
+				api[name] = describe(name, "type "+name+" "+types.TypeString(obj.Type().Underlying(), qualifier))
+			}
+
+			if named, ok := obj.Type().(*types.Named); ok {
+				for i := 0; i < named.NumMethods(); i++ {
+					method := named.Method(i)
+					if !method.Exported() {
+						continue
+					}
+					symbol := methodSymbol(name, method.Type().(*types.Signature).Recv().Type(), method.Name())
+					api[symbol] = describe(symbol, "func "+symbol+strings.TrimPrefix(types.TypeString(method.Type(), qualifier), "func"))
+				}
+			}
+		case *types.Func:
+			api[name] = describe(name, "func "+name+strings.TrimPrefix(types.TypeString(obj.Type(), qualifier), "func"))
+		case *types.Var:
+			api[name] = describe(name, "var "+name+" "+types.TypeString(obj.Type(), qualifier))
+		case *types.Const:
+			api[name] = describe(name, "const "+name+" "+types.TypeString(obj.Type(), qualifier))
+		}
+	}
+	return api
+}
+
+// methodSymbol formats a method as "(T).M" or "(*T).M"
+func methodSymbol(typeName string, recv types.Type, method string) string {
+	if _, ok := recv.(*types.Pointer); ok {
+		return "(*" + typeName + ")." + method
+	}
+	return "(" + typeName + ")." + method
+}
+
+// sourceDeclarations renders exported declarations from source, keyed like
+// exportedAPI, for use when type information is incomplete
+func sourceDeclarations(fset *token.FileSet, files []*ast.File) map[string]string {
+	render := func(node any) string {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, node)
+		return strings.Join(strings.Fields(buf.String()), " ")
+	}
+
+	declarations := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !decl.Name.IsExported() {
+					continue
+				}
+				symbol := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					recv := decl.Recv.List[0].Type
+					pointer := ""
+					if star, ok := recv.(*ast.StarExpr); ok {
+						pointer, recv = "*", star.X
+					}
+					if index, ok := recv.(*ast.IndexExpr); ok {
+						recv = index.X
+					}
+					ident, ok := recv.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					symbol = "(" + pointer + ident.Name + ")." + decl.Name.Name
+				}
+				declarations[symbol] = "func " + symbol + strings.TrimPrefix(render(decl.Type), "func")
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() {
+							continue
+						}
+						if st, ok := spec.Type.(*ast.StructType); ok {
+							for _, field := range st.Fields.List {
+								for _, fieldName := range field.Names {
+									symbol := spec.Name.Name + "." + fieldName.Name
+									declarations[symbol] = "field " + symbol + " " + render(field.Type)
+								}
+							}
+							continue
+						}
+						declarations[spec.Name.Name] = "type " + render(spec)
+					case *ast.ValueSpec:
+						for _, valueName := range spec.Names {
+							if valueName.IsExported() && spec.Type != nil {
+								declarations[valueName.Name] = strings.ToLower(decl.Tok.String()) + " " + valueName.Name + " " + render(spec.Type)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return declarations
+}
+
+// diffAPI compares two exported APIs of the package in dir
+func diffAPI(dir string, before, after map[string]string) []APIChange {
+	symbols := make(map[string]bool)
+	for symbol := range before {
+		symbols[symbol] = true
+	}
+	for symbol := range after {
+		symbols[symbol] = true
+	}
+
+	sorted := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	var changes []APIChange
+	for _, symbol := range sorted {
+		oldDecl, hadOld := before[symbol]
+		newDecl, hasNew := after[symbol]
+		change := APIChange{Package: dir, Symbol: symbol, Old: oldDecl, New: newDecl}
+		switch {
+		case !hadOld:
+			change.Kind = "added"
+		case !hasNew:
+			change.Kind = "removed"
+		case oldDecl != newDecl:
+			change.Kind = "changed"
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// tolerantImporter imports packages with a base importer and substitutes an
+// empty package when that fails, so one missing dependency does not stop
+// type checking
+type tolerantImporter struct {
+	base  types.Importer
+	fakes map[string]*types.Package
+}
+
+// Import implements types.Importer
+func (i *tolerantImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, err := i.base.Import(importPath); err == nil {
+		return pkg, nil
+	}
+	if fake, ok := i.fakes[importPath]; ok {
+		return fake, nil
+	}
+
+	fake := types.NewPackage(importPath, path.Base(importPath))
+	fake.MarkComplete()
+	i.fakes[importPath] = fake
+	return fake, nil
+}
@@ -0,0 +1,86 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStagedAPIChanges(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "pkg/api.go", `package pkg
+
+import "strings"
+
+// Options configures Run
+type Options struct {
+	Name    string
+	Verbose bool
+}
+
+func Run(name string) error { return nil }
+
+func Old() {}
+
+func (o *Options) Title() string { return strings.Title(o.Name) }
+`, "feat: add pkg")
+
+	newSource := `package pkg
+
+import "github.com/example/missing"
+
+type Options struct {
+	Name    string
+	Retries int
+}
+
+func Run(name string, force bool) error { return nil }
+
+func New() *Options { return &Options{} }
+
+func (o *Options) Title() string { return o.Name }
+
+func (o *Options) Client() *missing.Client { return nil }
+`
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "api.go"), []byte(newSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, dir, "add", "-A")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	changes, err := gc.StagedAPIChanges()
+	if err != nil {
+		t.Fatalf("StagedAPIChanges returned error: %v", err)
+	}
+
+	got := make(map[string]APIChange)
+	for _, change := range changes {
+		got[change.Symbol] = change
+	}
+
+	expect := map[string]string{
+		"New":               "added",
+		"Old":               "removed",
+		"Run":               "changed",
+		"Options.Retries":   "added",
+		"Options.Verbose":   "removed",
+		"(*Options).Client": "added",
+	}
+	for symbol, kind := range expect {
+		if got[symbol].Kind != kind {
+			t.Errorf("%s: got kind %q, want %q (all changes: %v)", symbol, got[symbol].Kind, kind, changes)
+		}
+	}
+	if _, ok := got["(*Options).Title"]; ok {
+		t.Errorf("Expected unchanged method signature to be omitted, got %v", got["(*Options).Title"])
+	}
+	if client := got["(*Options).Client"].New; !contains(client, "missing.Client") {
+		t.Errorf("Expected source fallback for an unresolved type, got %q", client)
+	}
+	if !got["Run"].Breaking() || got["New"].Breaking() {
+		t.Error("Expected changed symbols to be breaking and added ones not")
+	}
+}
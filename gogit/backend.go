@@ -0,0 +1,662 @@
+// Package gogit provides a pure-Go implementation of gitcommenter.GitBackend
+// built on go-git, for environments without a git binary (containers,
+// serverless, Windows without Git in PATH).
+package gogit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	diffutil "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// Backend is a gitcommenter.GitBackend backed by go-git
+type Backend struct {
+	repo *git.Repository
+	// mu serializes object reads, which go-git does not support concurrently
+	mu sync.Mutex
+	// Auth is used when pushing (optional)
+	Auth transport.AuthMethod
+	// Progress receives push progress output (optional)
+	Progress io.Writer
+}
+
+// Open opens the repository containing path, searching parent directories
+func Open(path string) (*Backend, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return &Backend{repo: repo}, nil
+}
+
+// IsRepository always succeeds once the backend has been opened
+func (b *Backend) IsRepository() error {
+	if b.repo == nil {
+		return fmt.Errorf("repository not opened")
+	}
+	return nil
+}
+
+// StagedFiles lists files whose index entry differs from HEAD
+func (b *Backend) StagedFiles() ([]gitcommenter.StagedFile, error) {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var files []gitcommenter.StagedFile
+	for path, fileStatus := range status {
+		switch fileStatus.Staging {
+		case git.Unmodified, git.Untracked:
+			continue
+		}
+		files = append(files, gitcommenter.StagedFile{
+			Status: string(fileStatus.Staging),
+			Path:   path,
+		})
+	}
+
+	// Status is a map; keep the output stable like git does
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.findRenames(files)
+}
+
+// findRenames turns a deleted and an added file with the same contents
+// into a rename, which the status of go-git reports as the two. Unlike git
+// it finds exact renames only; a renamed and edited file stays a deletion
+// and an addition.
+func (b *Backend) findRenames(files []gitcommenter.StagedFile) ([]gitcommenter.StagedFile, error) {
+	tree, err := b.headTree()
+	if err != nil || tree == nil {
+		return files, err
+	}
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[plumbing.Hash][]string)
+	for _, file := range files {
+		if file.Status != string(git.Deleted) {
+			continue
+		}
+		if entry, err := tree.FindEntry(file.Path); err == nil {
+			deleted[entry.Hash] = append(deleted[entry.Hash], file.Path)
+		}
+	}
+	if len(deleted) == 0 {
+		return files, nil
+	}
+
+	renamed := make(map[string]bool)
+	for i, file := range files {
+		if file.Status != string(git.Added) {
+			continue
+		}
+		entry, err := idx.Entry(file.Path)
+		if err != nil || len(deleted[entry.Hash]) == 0 {
+			continue
+		}
+		files[i].Status, files[i].OldPath = "R100", deleted[entry.Hash][0]
+		renamed[files[i].OldPath] = true
+		deleted[entry.Hash] = deleted[entry.Hash][1:]
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if file.Status != string(git.Deleted) || !renamed[file.Path] {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
+
+// renameSource returns the path a staged file was renamed from without
+// changes, as findRenames detects it, or "" for an added file
+func (b *Backend) renameSource(to *file) (string, error) {
+	tree, err := b.headTree()
+	if err != nil || tree == nil {
+		return "", err
+	}
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+
+	source := ""
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Hash != to.hash {
+			return nil
+		}
+		// The source is gone from the index
+		if _, err := idx.Entry(f.Name); err == index.ErrEntryNotFound {
+			source = f.Name
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return source, err
+}
+
+// StagedDiff returns a unified diff between HEAD and the index for path
+func (b *Backend) StagedDiff(path string) (string, error) {
+	b.mu.Lock()
+	from, fromContent, err := b.headFile(path)
+	if err != nil {
+		b.mu.Unlock()
+		return "", err
+	}
+
+	to, toContent, err := b.indexFile(path)
+	source := ""
+	if err == nil && from == nil && to != nil {
+		source, err = b.renameSource(to)
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	// Like git, an exact rename has no hunks
+	if source != "" {
+		return fmt.Sprintf("diff --git a/%s b/%s\nsimilarity index 100%%\nrename from %s\nrename to %s\n", source, path, source, path), nil
+	}
+
+	fp := &filePatch{}
+	// Only assign non-nil files so the encoder sees a nil interface for
+	// additions and deletions
+	if from != nil {
+		fp.from = from
+	}
+	if to != nil {
+		fp.to = to
+	}
+
+	if isBinary(fromContent) || isBinary(toContent) {
+		fp.binary = true
+	} else {
+		for _, d := range diffutil.Do(fromContent, toContent) {
+			fp.chunks = append(fp.chunks, chunk{content: d.Text, op: operation(d.Type)})
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := diff.NewUnifiedEncoder(&buf, diff.DefaultContextLines)
+	if err := encoder.Encode(patch{fp}); err != nil {
+		return "", fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// UnstagedFiles lists tracked files whose working tree content differs
+// from the index
+func (b *Backend) UnstagedFiles() ([]string, error) {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var files []string
+	for path, fileStatus := range status {
+		switch fileStatus.Worktree {
+		case git.Unmodified, git.Untracked:
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Stage adds the working tree content of the files to the index
+func (b *Backend) Stage(paths ...string) error {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Unstage resets the index entries of the files to HEAD, removing files
+// that HEAD does not have
+func (b *Backend) Unstage(paths ...string) error {
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		head, _, err := b.headFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", path, err)
+		}
+		if head == nil {
+			if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return fmt.Errorf("failed to unstage %s: %w", path, err)
+			}
+			continue
+		}
+
+		entry, err := idx.Entry(path)
+		if err == index.ErrEntryNotFound {
+			entry = idx.Add(path) // Staged deletion
+		} else if err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", path, err)
+		}
+		entry.Hash, entry.Mode = head.hash, head.mode
+	}
+	return b.repo.Storer.SetIndex(idx)
+}
+
+// Commit commits the index with the author and committer from git config
+func (b *Backend) Commit(message string) error {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if _, err := worktree.Commit(message, &git.CommitOptions{}); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// Push pushes the current branch to its upstream, like git push does by
+// default. A branch without an upstream is an error.
+func (b *Backend) Push() error {
+	_, branch, err := b.Head()
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		return fmt.Errorf("failed to push: HEAD is detached")
+	}
+	cfg, err := b.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	upstream, ok := cfg.Branches[branch]
+	if !ok || upstream.Remote == "" || upstream.Merge == "" {
+		return fmt.Errorf("failed to push: branch %s has no upstream", branch)
+	}
+
+	// Without RefSpecs go-git would push every branch
+	refSpec := config.RefSpec(plumbing.NewBranchReferenceName(branch).String() + ":" + upstream.Merge.String())
+	err = b.repo.Push(&git.PushOptions{
+		RemoteName: upstream.Remote,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       b.Auth,
+		Progress:   b.Progress,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+	return nil
+}
+
+// Log returns the last n commits on HEAD without merges, newest first
+func (b *Backend) Log(n int) ([]gitcommenter.Commit, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	head, err := b.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil // No commits yet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := b.repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []gitcommenter.Commit
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if len(commits) >= n {
+			return storer.ErrStop
+		}
+		if commit.NumParents() > 1 {
+			return nil
+		}
+		// Like git's %s and %b, the subject is the first paragraph
+		subject, body, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n\n")
+		commits = append(commits, gitcommenter.Commit{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Date:    commit.Author.When,
+			Subject: strings.Join(strings.Fields(subject), " "),
+			Body:    strings.TrimSpace(body),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	return commits, nil
+}
+
+// Head returns the checked out commit and branch
+func (b *Backend) Head() (hash, branch string, err error) {
+	ref, err := b.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+		branch = ref.Target().Short()
+	}
+
+	head, err := b.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return "", branch, nil // No commits yet
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Hash().String(), branch, nil
+}
+
+// Root returns the top-level directory of the working tree
+func (b *Backend) Root() (string, error) {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return worktree.Filesystem.Root(), nil
+}
+
+// Config returns the value of a key from the repository's config, or from
+// the global config when the repository does not set it
+func (b *Backend) Config(key string) (string, error) {
+	// The section is before the first dot, the name after the last one
+	// and the subsection, e.g. a branch name, in between
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	if first < 0 {
+		return "", fmt.Errorf("invalid config key %q", key)
+	}
+	section, name := key[:first], key[last+1:]
+	subsection := ""
+	if first < last {
+		subsection = key[first+1 : last]
+	}
+
+	local, err := b.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	global, err := config.LoadConfig(config.GlobalScope)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	for _, cfg := range []*config.Config{local, global} {
+		s := cfg.Raw.Section(section)
+		if subsection == "" && s.HasOption(name) {
+			return s.Option(name), nil
+		}
+		if subsection != "" && s.HasSubsection(subsection) && s.Subsection(subsection).HasOption(name) {
+			return s.Subsection(subsection).Option(name), nil
+		}
+	}
+	return "", nil
+}
+
+// Attr returns the gitattributes of the paths from the .gitattributes files
+// of their directories and .git/info/attributes
+func (b *Backend) Attr(paths, names []string) (map[string]map[string]string, error) {
+	worktree, err := b.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	fs := worktree.Filesystem
+
+	// Only the files in the directories of the paths apply; reading them
+	// once each avoids walking the whole tree
+	files := make(map[string][]gitattributes.MatchAttribute)
+	read := func(dir []string) ([]gitattributes.MatchAttribute, error) {
+		key := strings.Join(dir, "/")
+		if patterns, ok := files[key]; ok {
+			return patterns, nil
+		}
+		patterns, err := gitattributes.ReadAttributesFile(fs, dir, ".gitattributes", len(dir) == 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attributes: %w", err)
+		}
+		files[key] = patterns
+		return patterns, nil
+	}
+	// info/attributes is in the git directory, which .git may point to
+	var info []gitattributes.MatchAttribute
+	if storage, ok := b.repo.Storer.(*filesystem.Storage); ok {
+		f, err := storage.Filesystem().Open("info/attributes")
+		if err == nil {
+			info, err = gitattributes.ReadAttributes(f, nil, true)
+			f.Close()
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read attributes: %w", err)
+		}
+	}
+
+	attributes := make(map[string]map[string]string)
+	for _, path := range paths {
+		parts := strings.Split(path, "/")
+		// Deeper files and info/attributes come last and take precedence
+		var stack []gitattributes.MatchAttribute
+		for i := range parts {
+			// The patterns keep their directory, so it must not share
+			// the array of parts
+			patterns, err := read(slices.Clone(parts[:i]))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, patterns...)
+		}
+		stack = append(stack, info...)
+
+		// The Matcher of go-git lets earlier patterns override later ones
+		// for the attributes a later pattern does not set, so the stack is
+		// searched here, the last pattern setting an attribute winning
+		found := make(map[string]bool)
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].Pattern == nil || !stack[i].Pattern.Match(parts) {
+				continue
+			}
+			for _, attr := range stack[i].Attributes {
+				if !slices.Contains(names, attr.Name()) || found[attr.Name()] {
+					continue
+				}
+				// "!name" makes the attribute unspecified again
+				found[attr.Name()] = true
+				value := attr.Value()
+				switch {
+				case attr.IsUnspecified():
+					continue
+				case attr.IsSet():
+					value = "set"
+				case attr.IsUnset():
+					value = "unset"
+				}
+				if attributes[path] == nil {
+					attributes[path] = make(map[string]string)
+				}
+				attributes[path][attr.Name()] = value
+			}
+		}
+	}
+	return attributes, nil
+}
+
+// headTree returns the tree of HEAD, or nil before the first commit
+func (b *Backend) headTree() (*object.Tree, error) {
+	head, err := b.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil // No commits yet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := b.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// headFile returns the file at path in the HEAD tree, or nil if absent
+func (b *Backend) headFile(path string) (*file, string, error) {
+	tree, err := b.headTree()
+	if err != nil || tree == nil {
+		return nil, "", err
+	}
+
+	entry, err := tree.FindEntry(path)
+	if err == object.ErrEntryNotFound || err == object.ErrDirectoryNotFound {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := b.blobContent(entry.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	return &file{hash: entry.Hash, mode: entry.Mode, path: path}, content, nil
+}
+
+// indexFile returns the staged file at path, or nil if it was removed
+func (b *Backend) indexFile(path string) (*file, string, error) {
+	idx, err := b.repo.Storer.Index()
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return nil, "", nil // Staged deletion
+	}
+
+	content, err := b.blobContent(entry.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	return &file{hash: entry.Hash, mode: entry.Mode, path: path}, content, nil
+}
+
+// blobContent reads a blob into a string
+func (b *Backend) blobContent(hash plumbing.Hash) (string, error) {
+	blob, err := b.repo.BlobObject(hash)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// isBinary uses git's heuristic of a NUL byte in the first 8000 bytes
+func isBinary(content string) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte([]byte(content), 0) != -1
+}
+
+// operation maps a diffmatchpatch operation to a diff.Operation
+func operation(op diffmatchpatch.Operation) diff.Operation {
+	switch op {
+	case diffmatchpatch.DiffInsert:
+		return diff.Add
+	case diffmatchpatch.DiffDelete:
+		return diff.Delete
+	default:
+		return diff.Equal
+	}
+}
+
+// The types below implement the go-git diff interfaces so that the unified
+// encoder can render a patch between a HEAD blob and an index blob.
+
+type patch []diff.FilePatch
+
+func (p patch) FilePatches() []diff.FilePatch { return p }
+func (p patch) Message() string               { return "" }
+
+type filePatch struct {
+	from, to diff.File
+	chunks   []diff.Chunk
+	binary   bool
+}
+
+func (p *filePatch) IsBinary() bool              { return p.binary }
+func (p *filePatch) Files() (from, to diff.File) { return p.from, p.to }
+func (p *filePatch) Chunks() []diff.Chunk        { return p.chunks }
+
+type file struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	path string
+}
+
+func (f *file) Hash() plumbing.Hash     { return f.hash }
+func (f *file) Mode() filemode.FileMode { return f.mode }
+func (f *file) Path() string            { return f.path }
+
+type chunk struct {
+	content string
+	op      diff.Operation
+}
+
+func (c chunk) Content() string      { return c.content }
+func (c chunk) Type() diff.Operation { return c.op }
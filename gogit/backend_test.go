@@ -0,0 +1,426 @@
+package gogit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+)
+
+// newTestRepo initializes a repository with a committer identity configured
+func newTestRepo(t *testing.T) (string, *git.Repository) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("PlainInit failed: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+
+	return dir, repo
+}
+
+// appendConfig adds text to the config of the repository in dir
+func appendConfig(t *testing.T, dir, text string) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFile writes a file below dir, creating its directories; name is
+// slash-separated
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackendStageDiffCommit(t *testing.T) {
+	dir, repo := newTestRepo(t)
+
+	writeFile(t, dir, "hello.txt", "hello\n")
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("hello.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	files, err := backend.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "hello.txt" || files[0].Status != "A" {
+		t.Fatalf("Unexpected staged files: %+v", files)
+	}
+
+	diff, err := backend.StagedDiff("hello.txt")
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "+hello") {
+		t.Errorf("Expected diff to contain the added line, got:\n%s", diff)
+	}
+
+	if err := backend.Commit("feat: add hello"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	files, err = backend.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no staged files after commit, got %+v", files)
+	}
+}
+
+func TestBackendModifiedDiff(t *testing.T) {
+	dir, repo := newTestRepo(t)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, dir, "file.txt", "one\ntwo\n")
+	worktree.Add("file.txt")
+
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := backend.Commit("initial"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	writeFile(t, dir, "file.txt", "one\nthree\n")
+	worktree.Add("file.txt")
+
+	diff, err := backend.StagedDiff("file.txt")
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-two") || !strings.Contains(diff, "+three") {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+}
+
+func TestBackendUnstagedFilesAndStage(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	writeFile(t, dir, "hello.txt", "hello\n")
+
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := backend.Stage("hello.txt"); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+
+	// Edit after staging
+	writeFile(t, dir, "hello.txt", "hello again\n")
+	unstaged, err := backend.UnstagedFiles()
+	if err != nil {
+		t.Fatalf("UnstagedFiles failed: %v", err)
+	}
+	if len(unstaged) != 1 || unstaged[0] != "hello.txt" {
+		t.Fatalf("Expected hello.txt to be unstaged, got %v", unstaged)
+	}
+
+	if err := backend.Stage("hello.txt"); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if unstaged, _ := backend.UnstagedFiles(); len(unstaged) != 0 {
+		t.Errorf("Expected no unstaged files after restaging, got %v", unstaged)
+	}
+}
+
+func TestBackendUnstage(t *testing.T) {
+	dir, _ := newTestRepo(t)
+
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeFile(t, dir, "kept.txt", "kept\n")
+	writeFile(t, dir, "added.txt", "added\n")
+	if err := backend.Stage("kept.txt", "added.txt"); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+
+	// Unstaging a file HEAD does not have drops it from the index
+	if err := backend.Unstage("added.txt"); err != nil {
+		t.Fatalf("Unstage failed: %v", err)
+	}
+	files, _ := backend.StagedFiles()
+	if len(files) != 1 || files[0].Path != "kept.txt" {
+		t.Fatalf("Expected only kept.txt staged, got %+v", files)
+	}
+	if err := backend.Commit("feat: add kept"); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Unstaging a modified file restores the committed version in the index
+	writeFile(t, dir, "kept.txt", "changed\n")
+	if err := backend.Stage("kept.txt"); err != nil {
+		t.Fatalf("Stage failed: %v", err)
+	}
+	if err := backend.Unstage("kept.txt"); err != nil {
+		t.Fatalf("Unstage failed: %v", err)
+	}
+	if files, _ := backend.StagedFiles(); len(files) != 0 {
+		t.Errorf("Expected nothing staged, got %+v", files)
+	}
+	if unstaged, _ := backend.UnstagedFiles(); len(unstaged) != 1 || unstaged[0] != "kept.txt" {
+		t.Errorf("Expected the change to stay in the working tree, got %v", unstaged)
+	}
+}
+
+func TestBackendRepository(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	// Before the first commit there is a branch but no hash or history
+	if hash, branch, err := backend.Head(); err != nil || hash != "" || branch != "master" {
+		t.Fatalf("Head() = %q, %q, %v", hash, branch, err)
+	}
+	if commits, err := backend.Log(5); err != nil || len(commits) != 0 {
+		t.Fatalf("Log() = %+v, %v", commits, err)
+	}
+
+	for i, message := range []string{"feat: add one", "fix: two\n\nWith a body.\n", "docs: three"} {
+		writeFile(t, dir, "file.txt", fmt.Sprint(i))
+		if err := backend.Stage("file.txt"); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.Commit(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commits, err := backend.Log(2)
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "docs: three" || commits[1].Subject != "fix: two" || commits[1].Body != "With a body." {
+		t.Errorf("Unexpected log: %+v", commits)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash, branch, _ := backend.Head(); hash != head.Hash().String() || branch != "master" {
+		t.Errorf("Head() = %q, %q", hash, branch)
+	}
+	if root, err := backend.Root(); err != nil || root != dir {
+		t.Errorf("Root() = %q, %v, want %q", root, err, dir)
+	}
+
+	// SetConfig drops branch settings go-git does not know, as git config
+	// would write them
+	appendConfig(t, dir, "[branch \"feature/x\"]\n\tai = off\n")
+	if value, err := backend.Config("branch.feature/x.ai"); err != nil || value != "off" {
+		t.Errorf("Config() = %q, %v", value, err)
+	}
+	if value, err := backend.Config("branch.main.ai"); err != nil || value != "" {
+		t.Errorf("Config() of an unset key = %q, %v", value, err)
+	}
+
+	writeFile(t, dir, ".gitattributes", "*.pb.go linguist-generated\nvendor/** linguist-vendored\n")
+	writeFile(t, dir, "api/.gitattributes", "hand.pb.go -linguist-generated\n")
+	writeFile(t, dir, ".git/info/attributes", "main.go linguist-vendored\n")
+	names := []string{"linguist-generated", "linguist-vendored"}
+	attributes, err := backend.Attr([]string{"api/api.pb.go", "api/hand.pb.go", "vendor/lib/lib.go", "main.go"}, names)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	want := map[string]map[string]string{
+		"api/api.pb.go":     {"linguist-generated": "set"},
+		"api/hand.pb.go":    {"linguist-generated": "unset"},
+		"vendor/lib/lib.go": {"linguist-vendored": "set"},
+		"main.go":           {"linguist-vendored": "set"},
+	}
+	if !reflect.DeepEqual(attributes, want) {
+		t.Errorf("Attr() = %v, want %v", attributes, want)
+	}
+}
+
+// TestCommenterWithoutGit checks that the features reading the history,
+// branch config and attributes work through the backend without git
+func TestCommenterWithoutGit(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeFile(t, dir, ".gitattributes", "*.pb.go linguist-generated\n")
+	writeFile(t, dir, "api.pb.go", "package api\n")
+	if err := backend.Stage(".gitattributes", "api.pb.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Commit("feat: añadir la API generada para los clientes"); err != nil {
+		t.Fatal(err)
+	}
+	appendConfig(t, dir, "[branch \"master\"]\n\tai = off\n")
+
+	t.Setenv("PATH", "")
+	t.Setenv("HOME", t.TempDir())
+	config := gitcommenter.DefaultConfig()
+	config.RepositoryPath = dir
+	commenter := gitcommenter.New(config)
+	commenter.SetGitBackend(backend)
+
+	if _, err := commenter.HeadCommit(); err != nil {
+		t.Errorf("HeadCommit failed: %v", err)
+	}
+	if root, err := commenter.RepoRoot(); err != nil || root != dir {
+		t.Errorf("RepoRoot() = %q, %v", root, err)
+	}
+	if reason, err := commenter.OptOutReason(""); err != nil || !strings.Contains(reason, "branch.master.ai") {
+		t.Errorf("OptOutReason() = %q, %v", reason, err)
+	}
+	infos := commenter.ClassifyFiles([]gitcommenter.FileChange{{FilePath: "api.pb.go", ChangeType: "modified"}})
+	if !infos[0].Generated {
+		t.Errorf("Expected api.pb.go to be generated, got %+v", infos[0])
+	}
+}
+
+func TestBackendRename(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeFile(t, dir, "old.txt", "same\n")
+	if err := backend.Stage("old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Commit("feat: add old"); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Move("old.txt", "new.txt"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+
+	files, err := backend.StagedFiles()
+	if err != nil {
+		t.Fatalf("StagedFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "new.txt" || files[0].OldPath != "old.txt" || files[0].Status != "R100" {
+		t.Fatalf("Expected old.txt renamed to new.txt, got %+v", files)
+	}
+	diff, err := backend.StagedDiff("new.txt")
+	if err != nil {
+		t.Fatalf("StagedDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "rename from old.txt\nrename to new.txt\n") || strings.Contains(diff, "@@") {
+		t.Errorf("Expected a rename without hunks, got:\n%s", diff)
+	}
+}
+
+func TestBackendPushCurrentBranch(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	writeFile(t, dir, "file.txt", "one\n")
+	if err := backend.Stage("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Commit("feat: add file"); err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second branch must stay local
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/other", head.Hash())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Push(); err == nil || !strings.Contains(err.Error(), "no upstream") {
+		t.Fatalf("Expected an error without an upstream, got %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Branches["master"] = &config.Branch{Name: "master", Remote: "origin", Merge: "refs/heads/main"}
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Push(); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	refs, err := remote.References()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pushed []string
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			pushed = append(pushed, ref.Name().String())
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(pushed, []string{"refs/heads/main"}) {
+		t.Errorf("Expected only master pushed to main, got %v", pushed)
+	}
+}
@@ -0,0 +1,252 @@
+// The gRPC API of the commit message generator. It mirrors the Go library:
+// scan the staged changes, generate a message, commit and push. Generate
+// clients for other languages from this file, e.g. with
+// protoc --python_out=. --grpc_python_out=. commenter.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: commenter.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Commenter_ScanStagedChanges_FullMethodName     = "/aicommit.v1.Commenter/ScanStagedChanges"
+	Commenter_GenerateCommitMessage_FullMethodName = "/aicommit.v1.Commenter/GenerateCommitMessage"
+	Commenter_Commit_FullMethodName                = "/aicommit.v1.Commenter/Commit"
+	Commenter_Push_FullMethodName                  = "/aicommit.v1.Commenter/Push"
+)
+
+// CommenterClient is the client API for Commenter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Commenter works on the repository the server was started in
+type CommenterClient interface {
+	// ScanStagedChanges lists the files staged for the next commit
+	ScanStagedChanges(ctx context.Context, in *ScanStagedChangesRequest, opts ...grpc.CallOption) (*ScanStagedChangesResponse, error)
+	// GenerateCommitMessage generates messages for the staged changes
+	GenerateCommitMessage(ctx context.Context, in *GenerateCommitMessageRequest, opts ...grpc.CallOption) (*GenerateCommitMessageResponse, error)
+	// Commit commits the staged changes
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	// Push pushes the current branch
+	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+}
+
+type commenterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCommenterClient(cc grpc.ClientConnInterface) CommenterClient {
+	return &commenterClient{cc}
+}
+
+func (c *commenterClient) ScanStagedChanges(ctx context.Context, in *ScanStagedChangesRequest, opts ...grpc.CallOption) (*ScanStagedChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanStagedChangesResponse)
+	err := c.cc.Invoke(ctx, Commenter_ScanStagedChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commenterClient) GenerateCommitMessage(ctx context.Context, in *GenerateCommitMessageRequest, opts ...grpc.CallOption) (*GenerateCommitMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateCommitMessageResponse)
+	err := c.cc.Invoke(ctx, Commenter_GenerateCommitMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commenterClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitResponse)
+	err := c.cc.Invoke(ctx, Commenter_Commit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commenterClient) Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, Commenter_Push_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommenterServer is the server API for Commenter service.
+// All implementations must embed UnimplementedCommenterServer
+// for forward compatibility.
+//
+// Commenter works on the repository the server was started in
+type CommenterServer interface {
+	// ScanStagedChanges lists the files staged for the next commit
+	ScanStagedChanges(context.Context, *ScanStagedChangesRequest) (*ScanStagedChangesResponse, error)
+	// GenerateCommitMessage generates messages for the staged changes
+	GenerateCommitMessage(context.Context, *GenerateCommitMessageRequest) (*GenerateCommitMessageResponse, error)
+	// Commit commits the staged changes
+	Commit(context.Context, *CommitRequest) (*CommitResponse, error)
+	// Push pushes the current branch
+	Push(context.Context, *PushRequest) (*PushResponse, error)
+	mustEmbedUnimplementedCommenterServer()
+}
+
+// UnimplementedCommenterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCommenterServer struct{}
+
+func (UnimplementedCommenterServer) ScanStagedChanges(context.Context, *ScanStagedChangesRequest) (*ScanStagedChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScanStagedChanges not implemented")
+}
+func (UnimplementedCommenterServer) GenerateCommitMessage(context.Context, *GenerateCommitMessageRequest) (*GenerateCommitMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateCommitMessage not implemented")
+}
+func (UnimplementedCommenterServer) Commit(context.Context, *CommitRequest) (*CommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedCommenterServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedCommenterServer) mustEmbedUnimplementedCommenterServer() {}
+func (UnimplementedCommenterServer) testEmbeddedByValue()                   {}
+
+// UnsafeCommenterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CommenterServer will
+// result in compilation errors.
+type UnsafeCommenterServer interface {
+	mustEmbedUnimplementedCommenterServer()
+}
+
+func RegisterCommenterServer(s grpc.ServiceRegistrar, srv CommenterServer) {
+	// If the following call pancis, it indicates UnimplementedCommenterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Commenter_ServiceDesc, srv)
+}
+
+func _Commenter_ScanStagedChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanStagedChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommenterServer).ScanStagedChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Commenter_ScanStagedChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommenterServer).ScanStagedChanges(ctx, req.(*ScanStagedChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commenter_GenerateCommitMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateCommitMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommenterServer).GenerateCommitMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Commenter_GenerateCommitMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommenterServer).GenerateCommitMessage(ctx, req.(*GenerateCommitMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commenter_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommenterServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Commenter_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommenterServer).Commit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commenter_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommenterServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Commenter_Push_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommenterServer).Push(ctx, req.(*PushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Commenter_ServiceDesc is the grpc.ServiceDesc for Commenter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Commenter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aicommit.v1.Commenter",
+	HandlerType: (*CommenterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ScanStagedChanges",
+			Handler:    _Commenter_ScanStagedChanges_Handler,
+		},
+		{
+			MethodName: "GenerateCommitMessage",
+			Handler:    _Commenter_GenerateCommitMessage_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _Commenter_Commit_Handler,
+		},
+		{
+			MethodName: "Push",
+			Handler:    _Commenter_Push_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "commenter.proto",
+}
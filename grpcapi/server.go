@@ -0,0 +1,169 @@
+// Package grpcapi serves the commenter over gRPC, so services written in
+// other languages can scan, generate, commit and push. Clients are
+// generated from commenter.proto.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative commenter.proto
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the Commenter service for one repository
+type Server struct {
+	UnimplementedCommenterServer
+
+	mu sync.Mutex
+	gc *gitcommenter.GitCommenter
+}
+
+// NewServer serves the repository of gc; register it with
+// RegisterCommenterServer
+func NewServer(gc *gitcommenter.GitCommenter) *Server {
+	return &Server{gc: gc}
+}
+
+// SetCommenter replaces the commenter answering calls, e.g. with one for
+// a reloaded configuration (see GitCommenter.WithConfig). Calls already
+// running finish with the previous one.
+func (s *Server) SetCommenter(gc *gitcommenter.GitCommenter) {
+	s.mu.Lock()
+	s.gc = gc
+	s.mu.Unlock()
+}
+
+// commenter returns the commenter answering calls
+func (s *Server) commenter() *gitcommenter.GitCommenter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gc
+}
+
+// ScanStagedChanges lists the staged files
+func (s *Server) ScanStagedChanges(ctx context.Context, req *ScanStagedChangesRequest) (*ScanStagedChangesResponse, error) {
+	changes, err := s.commenter().ScanStagedChanges()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &ScanStagedChangesResponse{}
+	for _, change := range changes {
+		file := &FileChange{
+			Path:         change.FilePath,
+			ChangeType:   change.ChangeType,
+			LinesAdded:   int32(change.LinesAdded),
+			LinesRemoved: int32(change.LinesRemoved),
+		}
+		if req.GetIncludeDiffs() {
+			file.Diff = change.Diff
+		}
+		resp.Changes = append(resp.Changes, file)
+	}
+	return resp, nil
+}
+
+// GenerateCommitMessage generates one or more messages for the staged
+// changes, best first
+func (s *Server) GenerateCommitMessage(ctx context.Context, req *GenerateCommitMessageRequest) (*GenerateCommitMessageResponse, error) {
+	gc := s.commenter()
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if len(changes) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "no staged changes")
+	}
+
+	var suggestions []*gitcommenter.CommitSuggestion
+	if n := req.GetCandidates(); n > 1 {
+		suggestions, err = gc.GenerateCommitMessages(changes, int(n))
+	} else {
+		var suggestion *gitcommenter.CommitSuggestion
+		suggestion, err = gc.StreamCommitMessage(ctx, changes, 0, nil)
+		suggestions = []*gitcommenter.CommitSuggestion{suggestion}
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &GenerateCommitMessageResponse{}
+	for _, suggestion := range suggestions {
+		resp.Suggestions = append(resp.Suggestions, &CommitSuggestion{
+			Subject:       suggestion.Subject,
+			Body:          suggestion.Body,
+			Message:       suggestion.Message(),
+			Confidence:    suggestion.Confidence,
+			FilesAffected: suggestion.FilesAffected,
+			Warnings:      suggestion.Warnings,
+			Template:      suggestion.Template,
+		})
+	}
+	return resp, nil
+}
+
+// Commit commits the staged changes with the message
+func (s *Server) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
+	subject, body, _ := strings.Cut(gitcommenter.CleanCommitMessage(req.GetMessage()), "\n")
+	suggestion := &gitcommenter.CommitSuggestion{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}
+	if suggestion.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty commit message")
+	}
+	gc := s.commenter()
+	if err := gc.Commit(suggestion); err != nil {
+		return nil, statusError(err)
+	}
+
+	hash, err := gc.HeadCommit()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &CommitResponse{Commit: hash}, nil
+}
+
+// Push pushes to the upstream, or to the given remotes in parallel
+func (s *Server) Push(ctx context.Context, req *PushRequest) (*PushResponse, error) {
+	gc := s.commenter()
+	if len(req.GetRemotes()) == 0 {
+		if err := gc.Push(); err != nil {
+			return nil, statusError(err)
+		}
+		return &PushResponse{}, nil
+	}
+
+	var args []string
+	if req.GetRefspec() != "" {
+		args = append(args, req.GetRefspec())
+	}
+	resp := &PushResponse{}
+	for _, result := range gc.PushToRemotes(req.GetRemotes(), args...) {
+		pushed := &PushResult{Remote: result.Remote, Output: result.Output}
+		if result.Err != nil {
+			pushed.Error = result.Err.Error()
+		}
+		resp.Results = append(resp.Results, pushed)
+	}
+	return resp, nil
+}
+
+// statusError maps library errors to gRPC status codes
+func statusError(err error) error {
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, gitcommenter.ErrReadOnly):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, &urlErr):
+		// Ollama is unreachable
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,127 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves gc in memory and returns a client for it
+func newClient(t *testing.T, gc *gitcommenter.GitCommenter) CommenterClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterCommenterServer(server, NewServer(gc))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewCommenterClient(conn)
+}
+
+// initRepo creates a repository with a staged file
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "parser.go"), []byte("package parser\n\nfunc Parse() {}\n"), 0o644)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"add", "parser.go"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return dir
+}
+
+func TestServer(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitcommenter.OllamaResponse{Response: "feat: add parser\n\nParses the input.", Done: true})
+	}))
+	defer ollama.Close()
+
+	config := gitcommenter.DefaultConfig()
+	config.RepositoryPath = initRepo(t)
+	config.OllamaEndpoint = ollama.URL
+	client := newClient(t, gitcommenter.New(config))
+	ctx := context.Background()
+
+	scanned, err := client.ScanStagedChanges(ctx, &ScanStagedChangesRequest{IncludeDiffs: true})
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	if len(scanned.Changes) != 1 || scanned.Changes[0].Path != "parser.go" || scanned.Changes[0].LinesAdded != 3 || scanned.Changes[0].Diff == "" {
+		t.Fatalf("Unexpected changes %v", scanned.Changes)
+	}
+
+	generated, err := client.GenerateCommitMessage(ctx, &GenerateCommitMessageRequest{})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if len(generated.Suggestions) != 1 || generated.Suggestions[0].Subject != "feat: add parser" {
+		t.Fatalf("Unexpected suggestions %v", generated.Suggestions)
+	}
+
+	committed, err := client.Commit(ctx, &CommitRequest{Message: generated.Suggestions[0].Message})
+	if err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if len(committed.Commit) != 40 {
+		t.Errorf("Expected a commit hash, got %q", committed.Commit)
+	}
+
+	// Nothing is staged any more
+	if _, err := client.GenerateCommitMessage(ctx, &GenerateCommitMessageRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without staged changes, got %v", err)
+	}
+	if _, err := client.Commit(ctx, &CommitRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty message, got %v", err)
+	}
+
+	pushed, err := client.Push(ctx, &PushRequest{Remotes: []string{"missing"}, Refspec: "HEAD"})
+	if err != nil {
+		t.Fatalf("Push returned error: %v", err)
+	}
+	if len(pushed.Results) != 1 || pushed.Results[0].Remote != "missing" || pushed.Results[0].Error == "" {
+		t.Errorf("Expected a failed push to the missing remote, got %v", pushed.Results)
+	}
+}
+
+func TestServerReadOnly(t *testing.T) {
+	config := gitcommenter.DefaultConfig()
+	config.RepositoryPath = initRepo(t)
+	config.ReadOnly = true
+	client := newClient(t, gitcommenter.New(config))
+
+	_, err := client.Commit(context.Background(), &CommitRequest{Message: "feat: add parser"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package gitcommenter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Commit is a commit read from the repository history
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Subject string
+	Body    string
+}
+
+// ShortHash returns the abbreviated commit hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Message returns the full commit message
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+// logFormat separates fields with NUL and records with RS so that
+// multi-line bodies can be parsed safely
+const logFormat = "--format=%H%x00%an%x00%aI%x00%s%x00%b%x1e"
+
+// Commits lists the commits in a revision range (e.g. "main..HEAD"),
+// oldest first
+func (gc *GitCommenter) Commits(revRange string) ([]Commit, error) {
+	output, err := gc.gitOutput("log", "--reverse", logFormat, revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	return parseLog(output), nil
+}
+
+// HeadCommit returns the hash of the checked out commit
+func (gc *GitCommenter) HeadCommit() (string, error) {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		hash, _, err := repository.Head()
+		if err == nil && hash == "" {
+			err = errors.New("no commits yet")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		return hash, nil
+	}
+	output, err := gc.gitOutput("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// CommitDiff returns the patch introduced by a commit, without the files
+// listed in .aiignore
+func (gc *GitCommenter) CommitDiff(hash string) (string, error) {
+	output, err := gc.gitOutput("show", "--format=", "--patch", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for %s: %w", hash, err)
+	}
+	return gc.withoutIgnoredDiffs(output)
+}
+
+// CommitChanges returns the files changed by a commit with their diffs, as
+// ScanStagedChanges does for the index
+func (gc *GitCommenter) CommitChanges(hash string) ([]FileChange, error) {
+	output, err := gc.gitOutput("show", "--format=", "--find-renames", "--name-status", "-z", hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s: %w", hash, err)
+	}
+	counts, err := gc.numstat("show", "--format=", "--find-renames", hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lines changed in %s: %w", hash, err)
+	}
+
+	var changes []FileChange
+	for _, file := range parseNameStatus(output) {
+		diff, err := gc.gitOutput(append([]string{"show", "--format=", "--find-renames", hash, "--"}, file.paths()...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff of %s in %s: %w", file.Path, hash, err)
+		}
+		changes = append(changes, FileChange{
+			FilePath:     file.Path,
+			OldPath:      file.OldPath,
+			ChangeType:   gc.parseChangeType(file.Status),
+			Diff:         diff,
+			LinesAdded:   counts[file.Path].LinesAdded,
+			LinesRemoved: counts[file.Path].LinesRemoved,
+		})
+	}
+	// A commit of ignored files only changes nothing the model may see
+	changes, err = gc.withoutIgnored(changes)
+	if errors.Is(err, ErrOnlyIgnored) {
+		return nil, nil
+	}
+	return changes, err
+}
+
+// recentCommits returns the last n commits on HEAD without merges, newest
+// first
+func (gc *GitCommenter) recentCommits(n int) ([]Commit, error) {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		return repository.Log(n)
+	}
+	output, err := gc.gitOutput("log", "--no-merges", "-n", fmt.Sprint(n), logFormat)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(output), nil
+}
+
+// parseLog parses output produced with logFormat
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x00", 5)
+		if len(fields) < 5 {
+			continue
+		}
+
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: fields[3],
+			Body:    strings.TrimSpace(fields[4]),
+		})
+	}
+	return commits
+}
+
+// historyContextCommits is how many subjects of earlier commits touching
+// the changed files are shown with Config.IncludeHistoryContext
+const historyContextCommits = 5
+
+// historyContext lists the subjects of the latest commits touching the
+// changed files, so the model can stay consistent with ongoing work such
+// as a feature series. It is best effort: without history, or when git
+// fails, the section is left out.
+func (gc *GitCommenter) historyContext(changes []FileChange) string {
+	if !gc.config.IncludeHistoryContext || len(changes) == 0 {
+		return ""
+	}
+	args := []string{"log", "--no-merges", "-n", fmt.Sprint(historyContextCommits), "--format=%s", "--"}
+	for _, change := range changes {
+		args = append(args, ":(top)"+change.FilePath)
+	}
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		return ""
+	}
+	subjects := splitLines(output)
+	if len(subjects) == 0 {
+		return ""
+	}
+
+	var context strings.Builder
+	context.WriteString("RECENT COMMITS TOUCHING THESE FILES (newest first; stay consistent with them, e.g. when continuing a series):\n")
+	for _, subject := range subjects {
+		context.WriteString("- " + subject + "\n")
+	}
+	return context.String() + "\n"
+}
@@ -0,0 +1,101 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLog(t *testing.T) {
+	output := "abc1234def\x00Alice\x002024-05-01T10:00:00+02:00\x00feat: add x\x00Body line 1\nBody line 2\n\x1e\n" +
+		"0123456789\x00Bob\x002024-05-02T10:00:00Z\x00fix: y\x00\x1e\n"
+
+	commits := parseLog(output)
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %d", len(commits))
+	}
+
+	if commits[0].Subject != "feat: add x" || commits[0].Body != "Body line 1\nBody line 2" {
+		t.Errorf("Unexpected first commit: %+v", commits[0])
+	}
+
+	if commits[1].Author != "Bob" || commits[1].ShortHash() != "0123456" || commits[1].Message() != "fix: y" {
+		t.Errorf("Unexpected second commit: %+v", commits[1])
+	}
+
+	if commits[0].Date.IsZero() {
+		t.Error("Expected the date to be parsed")
+	}
+}
+
+func TestCommitChanges(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "a.txt", "one\n")
+	writeFile(t, dir, "b c.txt", "two\n--- three\n")
+	writeFile(t, dir, "logo.png", "\x89PNG\x00\x01\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	changes, err := New(config).CommitChanges("HEAD")
+	if err != nil {
+		t.Fatalf("CommitChanges returned error: %v", err)
+	}
+	if len(changes) != 3 || changes[0].FilePath != "a.txt" || changes[0].ChangeType != "added" || changes[0].LinesAdded != 1 {
+		t.Fatalf("Unexpected changes %+v", changes)
+	}
+	if changes[1].FilePath != "b c.txt" || changes[1].LinesAdded != 2 {
+		t.Errorf("Expected both lines of the file with a space counted, got %+v", changes[1])
+	}
+	if changes[2].FilePath != "logo.png" || changes[2].LinesAdded != 0 {
+		t.Errorf("Expected no lines counted for a binary file, got %+v", changes[2])
+	}
+
+	gitRun(t, dir, "mv", "b c.txt", "b.txt")
+	writeFile(t, dir, "b.txt", "two\n--- three\nfour\n")
+	gitRun(t, dir, "add", "-A")
+	gitRun(t, dir, "commit", "-q", "-m", "rename")
+	changes, err = New(config).CommitChanges("HEAD")
+	if err != nil {
+		t.Fatalf("CommitChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].OldPath != "b c.txt" || changes[0].ChangeType != "renamed" || changes[0].LinesAdded != 1 {
+		t.Fatalf("Expected the rename with one added line, got %+v", changes)
+	}
+	if !strings.Contains(changes[0].Diff, "rename from b c.txt") {
+		t.Errorf("Expected the diff to show the rename, got:\n%s", changes[0].Diff)
+	}
+}
+
+func TestHistoryContext(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, commit := range []struct{ file, message string }{
+		{"limiter.go", "feat(limit): add the token bucket (1/3)"},
+		{"other.go", "docs: unrelated change"},
+		{"limiter.go", "feat(limit): refill buckets over time (2/3)"},
+	} {
+		writeFile(t, dir, commit.file, strings.Repeat("// x\n", i+1))
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", commit.message)
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	changes := []FileChange{{FilePath: "limiter.go", ChangeType: "modified"}}
+	if context := gc.historyContext(changes); context != "" {
+		t.Errorf("Expected no history context unless enabled, got %q", context)
+	}
+
+	config.IncludeHistoryContext = true
+	context := gc.historyContext(changes)
+	if !strings.Contains(context, "- feat(limit): refill buckets over time (2/3)\n- feat(limit): add the token bucket (1/3)\n") {
+		t.Errorf("Expected the subjects of the commits touching limiter.go, newest first, got:\n%s", context)
+	}
+	if strings.Contains(context, "unrelated") {
+		t.Errorf("Expected only commits touching the changed files, got:\n%s", context)
+	}
+	if context := gc.historyContext([]FileChange{{FilePath: "new.go", ChangeType: "added"}}); context != "" {
+		t.Errorf("Expected no section for files without history, got %q", context)
+	}
+}
@@ -0,0 +1,56 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PrepareCommitMsgSkipReason tells why a prepare-commit-msg hook should
+// leave the message alone, or returns "" when it should generate one.
+// source is the hook's second argument: empty for a plain git commit, or
+// "message", "template", "merge", "squash" or "commit". existing is the
+// current content of the message file.
+func PrepareCommitMsgSkipReason(source, existing string) string {
+	switch source {
+	case "merge", "squash":
+		return "git wrote a " + source + " message"
+	case "message":
+		return "the message was given with -m or -F"
+	case "commit":
+		// -c, -C and --amend reuse a message
+		if hasMessageContent(existing) {
+			return "the commit reuses an existing message"
+		}
+	}
+	return ""
+}
+
+// hasMessageContent reports whether a commit message file has text besides
+// comments and blank lines
+func hasMessageContent(message string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCommitMessageFile puts message at the top of a commit message file,
+// keeping what git wrote below it (a template or the commented status)
+func WriteCommitMessageFile(path, message string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read commit message file: %w", err)
+	}
+
+	content := strings.TrimRight(message, "\n") + "\n"
+	if rest := strings.TrimLeft(string(existing), "\n"); rest != "" {
+		content += "\n" + rest
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	return nil
+}
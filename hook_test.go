@@ -0,0 +1,42 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrepareCommitMsgSkipReason(t *testing.T) {
+	for _, tc := range []struct {
+		source, existing string
+		skip             bool
+	}{
+		{"", "\n# Please enter the commit message\n", false},
+		{"template", "Summary:\n\n# Template\n", false},
+		{"message", "fix: typo\n", true},
+		{"merge", "Merge branch 'main'\n", true},
+		{"squash", "Squashed commit of the following:\n", true},
+		{"commit", "feat: add parser\n\n# Please enter\n", true},
+		{"commit", "\n# Please enter\n", false},
+	} {
+		if reason := PrepareCommitMsgSkipReason(tc.source, tc.existing); (reason != "") != tc.skip {
+			t.Errorf("PrepareCommitMsgSkipReason(%q, %q) = %q, want skip %v", tc.source, tc.existing, reason, tc.skip)
+		}
+	}
+}
+
+func TestWriteCommitMessageFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte("\n# Please enter the commit message for your changes.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteCommitMessageFile(path, "feat: add parser\n\n- parse flags\n"); err != nil {
+		t.Fatalf("WriteCommitMessageFile returned error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	want := "feat: add parser\n\n- parse flags\n\n# Please enter the commit message for your changes.\n"
+	if string(content) != want {
+		t.Errorf("Unexpected file content:\n%q", content)
+	}
+}
@@ -0,0 +1,136 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types recorded in the journal
+const (
+	EventStaged    = "staged"
+	EventGenerated = "generated"
+	EventCommitted = "committed"
+	EventPushed    = "pushed"
+)
+
+// Event is one entry of the journal, written as a line of JSON
+type Event struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	// Repo is the absolute path of the repository
+	Repo   string   `json:"repo"`
+	Branch string   `json:"branch,omitempty"`
+	Files  []string `json:"files,omitempty"`
+	// Message is the generated or committed message
+	Message  string `json:"message,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Template string `json:"template,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Remote   string `json:"remote,omitempty"`
+	// Error is set when the action failed
+	Error string `json:"error,omitempty"`
+}
+
+// Journal writes events as JSON lines to a file or socket, so dashboards
+// and editor status bars can follow what the tool does without parsing
+// its output
+type Journal struct {
+	mu  sync.Mutex
+	w   io.WriteCloser
+	err error
+}
+
+// OpenJournal opens a journal target: "unix:<path>" or "tcp:<host:port>"
+// connects to a listening socket, anything else is a file events are
+// appended to
+func OpenJournal(target string) (*Journal, error) {
+	var w io.WriteCloser
+	var err error
+	switch {
+	case strings.HasPrefix(target, "unix:"):
+		w, err = net.Dial("unix", strings.TrimPrefix(target, "unix:"))
+	case strings.HasPrefix(target, "tcp:"):
+		w, err = net.DialTimeout("tcp", strings.TrimPrefix(target, "tcp:"), 5*time.Second)
+	default:
+		w, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %w", target, err)
+	}
+	return &Journal{w: w}, nil
+}
+
+// NewJournal writes events to w
+func NewJournal(w io.WriteCloser) *Journal {
+	return &Journal{w: w}
+}
+
+// Record writes an event. A failing journal never fails the workflow; the
+// first write error is kept for Err.
+func (j *Journal) Record(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil {
+		return
+	}
+	if _, err := j.w.Write(append(line, '\n')); err != nil {
+		j.err = fmt.Errorf("failed to write journal: %w", err)
+	}
+}
+
+// Err returns the first error writing the journal
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Close closes the file or socket
+func (j *Journal) Close() error {
+	return j.w.Close()
+}
+
+// SetJournal records what the commenter does (generating, committing,
+// pushing) in a journal; nil stops recording
+func (gc *GitCommenter) SetJournal(journal *Journal) {
+	gc.journal = journal
+}
+
+// RecordEvent adds an event to the journal, if one is set, filling in the
+// time, repository and branch. Callers use it for actions done outside the
+// commenter, such as staging.
+func (gc *GitCommenter) RecordEvent(event Event) {
+	if gc.journal == nil {
+		return
+	}
+	if event.Repo == "" {
+		event.Repo, _ = filepath.Abs(gc.config.RepositoryPath)
+	}
+	if event.Branch == "" {
+		event.Branch = gc.currentBranch()
+	}
+	gc.journal.Record(event)
+}
+
+// errorText returns the message of err, or "" for nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
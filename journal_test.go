@@ -0,0 +1,70 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalRecordsCommitAndPush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned error: %v", err)
+	}
+
+	commenter := New(nil)
+	commenter.SetGitBackend(&fakeBackend{})
+	commenter.SetJournal(journal)
+
+	commenter.RecordEvent(Event{Type: EventStaged, Files: []string{"main.go"}})
+	if err := commenter.Commit(&CommitSuggestion{Subject: "Fix the parser"}); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if err := commenter.Push(); err != nil {
+		t.Fatalf("Push returned error: %v", err)
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Invalid journal line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+	for i, want := range []string{EventStaged, EventCommitted, EventPushed} {
+		if events[i].Type != want {
+			t.Errorf("Expected event %d to be %s, got %s", i, want, events[i].Type)
+		}
+		if events[i].Time.IsZero() || events[i].Repo == "" {
+			t.Errorf("Expected time and repo to be filled in, got %+v", events[i])
+		}
+	}
+	if events[0].Files[0] != "main.go" {
+		t.Errorf("Expected staged files, got %+v", events[0])
+	}
+	if events[1].Message != "Fix the parser" {
+		t.Errorf("Expected the committed message, got %q", events[1].Message)
+	}
+}
+
+func TestRecordEventWithoutJournal(t *testing.T) {
+	// Recording without a journal is a no-op
+	New(nil).RecordEvent(Event{Type: EventStaged})
+}
@@ -0,0 +1,213 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LanguageAuto makes Config.Language follow the language of the recent
+// commit messages
+const LanguageAuto = "auto"
+
+// Languages maps the codes accepted by Config.Language to their names
+var Languages = map[string]string{
+	"en": "English",
+	"de": "German",
+	"fr": "French",
+	"es": "Spanish",
+	"pt": "Portuguese",
+	"it": "Italian",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"sv": "Swedish",
+	"ru": "Russian",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+}
+
+// LanguageCodes returns the codes of Languages, sorted
+func LanguageCodes() []string {
+	codes := make([]string, 0, len(Languages))
+	for code := range Languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ValidateLanguage checks a Config.Language value
+func ValidateLanguage(language string) error {
+	if language == "" || language == LanguageAuto || Languages[language] != "" {
+		return nil
+	}
+	return fmt.Errorf("unknown language %q (use auto or one of %s)", language, strings.Join(LanguageCodes(), ", "))
+}
+
+// languageWords are frequent words of commit messages in languages written
+// in the Latin script, used to tell them apart
+var languageWords = map[string][]string{
+	"en": {"the", "a", "an", "to", "for", "of", "and", "in", "with", "from", "on", "when", "is", "not", "add", "adds", "added", "fix", "fixes", "fixed", "update", "updates", "remove", "removes", "use", "support", "new", "instead", "into", "so", "that", "this", "it"},
+	"de": {"der", "die", "das", "und", "für", "mit", "von", "nicht", "ist", "beim", "wenn", "ein", "eine", "zu", "im", "auf", "den", "dem", "des", "neue", "neuer", "hinzugefügt", "hinzufügen", "behoben", "beheben", "entfernt", "aktualisiert", "fehler", "statt", "wird", "werden"},
+	"fr": {"le", "la", "les", "des", "du", "et", "pour", "avec", "dans", "une", "un", "ajout", "ajoute", "ajouter", "correction", "corrige", "corriger", "mise", "jour", "suppression", "supprime", "lors", "sur", "pas", "est", "au", "aux"},
+	"es": {"el", "los", "las", "del", "y", "para", "con", "una", "se", "agrega", "agregar", "añade", "añadir", "corrige", "corregir", "actualiza", "actualizar", "elimina", "eliminar", "al", "cuando", "nuevo", "nueva", "por", "error"},
+	"pt": {"os", "do", "da", "dos", "das", "para", "com", "em", "um", "uma", "adiciona", "adicionar", "adicionado", "corrige", "corrigir", "corrigido", "atualiza", "atualizar", "não", "ao", "na", "no", "quando", "erro"},
+	"it": {"il", "lo", "gli", "di", "del", "della", "per", "con", "aggiunge", "aggiungi", "aggiunto", "corregge", "correggi", "corretto", "aggiorna", "aggiornato", "rimuove", "rimosso", "non", "nel", "nella", "quando", "errore"},
+	"nl": {"het", "een", "en", "van", "voor", "met", "niet", "toegevoegd", "toevoegen", "opgelost", "bijgewerkt", "verwijderd", "bij", "naar", "op", "wordt", "nieuwe"},
+	"pl": {"i", "w", "z", "na", "do", "nie", "się", "dla", "dodano", "dodaj", "poprawka", "poprawiono", "usunięto", "aktualizacja", "błąd", "przy"},
+	"sv": {"och", "för", "med", "att", "av", "på", "inte", "lägg", "till", "lagt", "fixa", "åtgärda", "uppdatera", "bort", "när", "ny", "nytt"},
+}
+
+// languageIndex maps each word to the languages using it
+var languageIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range languageWords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// messageNoise matches parts of commit messages that say nothing about
+// their language: conventional prefixes, code, URLs, ticket IDs and trailers
+var messageNoise = regexp.MustCompile("(?m)^\\s*\\w+(\\([^)]*\\))?!?:\\s|`[^`]*`|https?://\\S+|\\b[A-Z][A-Z0-9]+-\\d+\\b|^[\\w-]+: .*$")
+
+// DetectLanguage guesses the language of a commit message and returns its
+// code, or "" when the text is too short or ambiguous. Languages with
+// their own script are recognized by it; languages written in the Latin
+// script by their frequent words.
+func DetectLanguage(text string) string {
+	text = messageNoise.ReplaceAllString(text, " ")
+
+	var letters, han, kana, hangul, cyrillic int
+	ukrainian := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			ukrainian = ukrainian || strings.ContainsRune("іїєґІЇЄҐ", r)
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// Code identifiers are in Latin letters, so a fifth is enough
+	switch {
+	case (kana+han)*5 >= letters && kana > 0:
+		return "ja"
+	case han*5 >= letters:
+		return "zh"
+	case hangul*5 >= letters:
+		return "ko"
+	case cyrillic*5 >= letters && ukrainian:
+		return "uk"
+	case cyrillic*5 >= letters:
+		return "ru"
+	}
+
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, language := range languageIndex[word] {
+			scores[language]++
+		}
+	}
+	best, bestScore, tie := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = language, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// historyLanguageCommits is how many recent commits HistoryLanguage reads
+const historyLanguageCommits = 30
+
+// HistoryLanguage returns the language most recent commit messages are
+// written in, or "" when the history is too short or mixed to tell
+func (gc *GitCommenter) HistoryLanguage() (string, error) {
+	commits, err := gc.recentCommits(historyLanguageCommits)
+	if err != nil {
+		// A repository without commits has no language yet
+		if _, headErr := gc.HeadCommit(); headErr != nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read recent commits: %w", err)
+	}
+
+	counts := make(map[string]int)
+	detected := 0
+	for _, commit := range commits {
+		if language := DetectLanguage(commit.Message()); language != "" {
+			counts[language]++
+			detected++
+		}
+	}
+	if detected < 3 {
+		return "", nil
+	}
+	for language, count := range counts {
+		if count*5 >= detected*3 {
+			return language, nil
+		}
+	}
+	return "", nil
+}
+
+// language returns the code of the language messages are generated in,
+// resolving LanguageAuto from the history
+func (gc *GitCommenter) language() string {
+	switch gc.config.Language {
+	case "":
+		return "en"
+	case LanguageAuto:
+		if language, err := gc.HistoryLanguage(); err == nil && language != "" {
+			return language
+		}
+		return "en"
+	}
+	return gc.config.Language
+}
+
+// languageName returns the name of a language code, or the code itself
+// when it is unknown
+func languageName(code string) string {
+	if name := Languages[code]; name != "" {
+		return name
+	}
+	return code
+}
+
+// LanguageMismatch returns the language of the recent commit messages when
+// it differs from the one messages are generated in, and "" otherwise
+func (gc *GitCommenter) LanguageMismatch() (string, error) {
+	if gc.config.Language == LanguageAuto {
+		return "", nil
+	}
+	history, err := gc.HistoryLanguage()
+	if err != nil || history == "" || history == gc.language() {
+		return "", err
+	}
+	return history, nil
+}
@@ -0,0 +1,102 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"feat(parser): add support for streaming responses", "en"},
+		{"fix: handle empty diffs when the index is locked", "en"},
+		{"feat: Unterstützung für Streaming hinzugefügt", "de"},
+		{"fix(ui): Fehler beim Speichern der Einstellungen behoben", "de"},
+		{"feat: ajout de la mise à jour automatique des dépendances", "fr"},
+		{"fix: corrige el error al guardar la configuración", "es"},
+		{"feat: adiciona suporte para o modo escuro", "pt"},
+		{"fix: corregge il calcolo della durata nel parser", "it"},
+		{"feat: nieuwe optie voor het exporteren toegevoegd", "nl"},
+		{"feat: добавить поддержку потоковых ответов", "ru"},
+		{"feat: додати підтримку потокових відповідей", "uk"},
+		{"feat: 添加流式响应支持", "zh"},
+		{"feat: ストリーミング応答のサポートを追加", "ja"},
+		{"feat: 스트리밍 응답 지원 추가", "ko"},
+		// Nothing but code and ticket IDs
+		{"chore: bump `golang.org/x/net` PROJ-12", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.message); got != tt.expected {
+			t.Errorf("DetectLanguage(%q) = %q, expected %q", tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateLanguage(t *testing.T) {
+	for _, language := range []string{"", "auto", "en", "de", "ja"} {
+		if err := ValidateLanguage(language); err != nil {
+			t.Errorf("ValidateLanguage(%q) returned error: %v", language, err)
+		}
+	}
+	if err := ValidateLanguage("klingon"); err == nil {
+		t.Error("Expected an error for an unknown language")
+	}
+}
+
+// commitMessages makes an empty commit for each message
+func commitMessages(t *testing.T, dir string, messages ...string) {
+	t.Helper()
+	for _, message := range messages {
+		gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", message)
+	}
+}
+
+func TestHistoryLanguage(t *testing.T) {
+	dir := initTestRepo(t)
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	// No commits yet
+	if language, err := gc.HistoryLanguage(); err != nil || language != "" {
+		t.Fatalf("Expected no language without commits, got %q, %v", language, err)
+	}
+
+	commitMessages(t, dir,
+		"feat: Unterstützung für Streaming hinzugefügt",
+		"fix: Fehler beim Speichern der Einstellungen behoben",
+		"docs: Installation für macOS beschrieben, die Anleitung ist neu",
+		"fix: handle empty diffs",
+	)
+	if language, err := gc.HistoryLanguage(); err != nil || language != "de" {
+		t.Fatalf("Expected de, got %q, %v", language, err)
+	}
+
+	mismatch, err := gc.LanguageMismatch()
+	if err != nil || mismatch != "de" {
+		t.Errorf("Expected a mismatch with de, got %q, %v", mismatch, err)
+	}
+
+	config.Language = "de"
+	if mismatch, _ := gc.LanguageMismatch(); mismatch != "" {
+		t.Errorf("Expected no mismatch when generating in German, got %q", mismatch)
+	}
+
+	config.Language = LanguageAuto
+	if prompt := gc.buildPrompt("", nil); !strings.Contains(prompt, "Write the subject and body in German") {
+		t.Errorf("Expected the prompt to ask for German:\n%s", prompt)
+	}
+	if suggestion := gc.TrivialMessage([]FileChange{{FilePath: "README.md", ChangeType: "modified", LinesAdded: 1, LinesRemoved: 1, Diff: "-teh\n+the\n"}}); suggestion != nil {
+		t.Errorf("Expected no English template message, got %q", suggestion.Subject)
+	}
+
+	// A mixed history has no language
+	commitMessages(t, dir, "fix: handle the missing files", "feat: add a cache", "fix: avoid a crash on exit")
+	if language, _ := gc.HistoryLanguage(); language != "" {
+		t.Errorf("Expected no language for a mixed history, got %q", language)
+	}
+}
@@ -0,0 +1,119 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// scissorsLine marks where git commit --verbose starts the diff; everything
+// below it is removed from the message
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// CleanCommitMessage removes what git strips from a message file: comment
+// lines, the verbose diff below the scissors line and surrounding blank lines
+func CleanCommitMessage(message string) string {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	if i := strings.Index(message, scissorsLine); i >= 0 {
+		message = message[:i]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// exemptPrefixes start messages git writes itself, which are never linted
+var exemptPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// isExemptMessage reports whether message was written by git, e.g. for a
+// merge or a fixup commit
+func isExemptMessage(message string) bool {
+	for _, prefix := range exemptPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageLint is the verdict on a hand-written commit message
+type MessageLint struct {
+	// Problems are the reasons the message fails; empty when it passes
+	Problems []string
+	// Grade is the model's rating of the message against the diff
+	Grade *MessageGrade
+	// Rewrite is a suggested replacement, generated only when the message
+	// fails
+	Rewrite *CommitSuggestion
+}
+
+// Passed reports whether the message follows the conventions and is
+// informative enough
+func (l *MessageLint) Passed() bool {
+	return len(l.Problems) == 0
+}
+
+// CheckMessageConventions checks a message against the configured style,
+// allowed types and scopes, commitlint rules and subject length, without
+// asking the model. It returns nil when the message follows them. Merge,
+// revert and fixup messages are exempt.
+func (gc *GitCommenter) CheckMessageConventions(message string) error {
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return err
+	}
+
+	message = CleanCommitMessage(message)
+	if isExemptMessage(message) {
+		return nil
+	}
+	subject, body, _ := strings.Cut(message, "\n")
+	suggestion := &CommitSuggestion{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}
+	if err := gc.validateSuggestion(style, suggestion); err != nil {
+		return err
+	}
+	if limit := gc.subjectLimit(); limit > 0 && utf8.RuneCountInString(suggestion.Subject) > limit {
+		return fmt.Errorf("subject is %d characters long (limit %d)", utf8.RuneCountInString(suggestion.Subject), limit)
+	}
+	return nil
+}
+
+// LintCommitMessage checks a message against the repository's conventions
+// and grades it against the changes it commits. Messages breaking a
+// convention or scoring below minScore fail and get a suggested rewrite.
+func (gc *GitCommenter) LintCommitMessage(message string, changes []FileChange, minScore int) (*MessageLint, error) {
+	message = CleanCommitMessage(message)
+	lint := &MessageLint{}
+	if isExemptMessage(message) {
+		return lint, nil
+	}
+	if err := gc.CheckMessageConventions(message); err != nil {
+		lint.Problems = append(lint.Problems, err.Error())
+	}
+
+	var diff strings.Builder
+	for _, change := range changes {
+		diff.WriteString(change.Diff)
+		diff.WriteString("\n")
+	}
+	grade, err := gc.GradeCommitMessage(message, diff.String())
+	if err != nil {
+		return nil, err
+	}
+	lint.Grade = grade
+	if grade.Score < minScore {
+		lint.Problems = append(lint.Problems, "the message is too vague: "+grade.Reason)
+	}
+
+	if !lint.Passed() && len(changes) > 0 {
+		if lint.Rewrite, err = gc.GenerateCommitMessage(changes); err != nil {
+			return nil, err
+		}
+	}
+	return lint, nil
+}
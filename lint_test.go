@@ -0,0 +1,74 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCleanCommitMessage(t *testing.T) {
+	message := "fix: handle nil config  \n\nBody line\n# Please enter the commit message\n#\n" +
+		scissorsLine + "\ndiff --git a/x b/x\n"
+	if got := CleanCommitMessage(message); got != "fix: handle nil config\n\nBody line" {
+		t.Errorf("Unexpected cleaned message %q", got)
+	}
+}
+
+func TestCheckMessageConventions(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSubjectLength = 30
+	gc := New(config)
+
+	if err := gc.CheckMessageConventions("fix(api): handle nil config\n# comment"); err != nil {
+		t.Errorf("Expected a conventional message to pass, got %v", err)
+	}
+	if err := gc.CheckMessageConventions("Merge branch 'main' into feature"); err != nil {
+		t.Errorf("Expected merge messages to be exempt, got %v", err)
+	}
+	for _, message := range []string{"Fixed stuff", "fix(api): handle a nil config in the loader"} {
+		if err := gc.CheckMessageConventions(message); err == nil {
+			t.Errorf("Expected %q to fail", message)
+		}
+	}
+}
+
+func TestLintCommitMessage(t *testing.T) {
+	score := "5"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		response := "fix(config): handle a nil config"
+		if strings.Contains(req.Prompt, "SCORE") {
+			response = "SCORE: " + score + "\nREASON: does not say what was fixed"
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	changes := []FileChange{{FilePath: "config.go", ChangeType: "modified", Diff: "+if c == nil {", LinesAdded: 1}}
+
+	lint, err := gc.LintCommitMessage("fix(config): handle a nil config", changes, 3)
+	if err != nil {
+		t.Fatalf("LintCommitMessage returned error: %v", err)
+	}
+	if !lint.Passed() || lint.Rewrite != nil {
+		t.Errorf("Expected a pass without rewrite, got %+v", lint)
+	}
+
+	score = "2"
+	lint, err = gc.LintCommitMessage("fix: stuff", changes, 3)
+	if err != nil {
+		t.Fatalf("LintCommitMessage returned error: %v", err)
+	}
+	if lint.Passed() || len(lint.Problems) != 1 || !strings.Contains(lint.Problems[0], "does not say what was fixed") {
+		t.Errorf("Expected the message to fail as vague, got %+v", lint.Problems)
+	}
+	if lint.Rewrite == nil || lint.Rewrite.Subject != "fix(config): handle a nil config" {
+		t.Errorf("Expected a rewrite, got %+v", lint.Rewrite)
+	}
+}
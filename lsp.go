@@ -0,0 +1,304 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LSP identifiers offered to editors
+const (
+	// CodeActionGenerateCommitMessage is the code action kind offered by
+	// LSPServer
+	CodeActionGenerateCommitMessage = "source.generateCommitMessage"
+	// LSPGenerateCommand is the command the code action runs
+	LSPGenerateCommand = "aiCommit.generateCommitMessage"
+	// SuggestionURI is the virtual document holding the latest suggestion
+	SuggestionURI = "ai-commit:/COMMIT_EDITMSG"
+)
+
+// JSON-RPC and LSP error codes
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+	lspRequestFailed  = -32803
+	lspCancelled      = -32800
+)
+
+// lspError is an error answered with a specific JSON-RPC code
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *lspError) Error() string {
+	return e.Message
+}
+
+// lspMessage is any incoming message: a request, a notification (no ID) or
+// the client's response to a request of the server (no method)
+type lspMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// LSPServer is a minimal Language Server Protocol server. It offers a
+// source.generateCommitMessage code action that generates a message for the
+// staged changes. In a COMMIT_EDITMSG buffer the message is inserted at the
+// top; elsewhere the editor is asked to show the virtual document
+// SuggestionURI, which clients read with workspace/textDocumentContent.
+type LSPServer struct {
+	gc  *GitCommenter
+	in  *bufio.Reader
+	out io.Writer
+
+	mu        sync.Mutex
+	nextID    int
+	documents map[string]string
+}
+
+// NewLSPServer creates a server reading requests from r and writing to w,
+// usually stdin and stdout of a process started by the editor
+func NewLSPServer(gc *GitCommenter, r io.Reader, w io.Writer) *LSPServer {
+	return &LSPServer{
+		gc:        gc,
+		in:        bufio.NewReader(r),
+		out:       w,
+		documents: make(map[string]string),
+	}
+}
+
+// SetCommenter replaces the commenter answering requests, e.g. with one
+// for a reloaded configuration (see GitCommenter.WithConfig). Requests
+// already running finish with the previous one.
+func (s *LSPServer) SetCommenter(gc *GitCommenter) {
+	s.mu.Lock()
+	s.gc = gc
+	s.mu.Unlock()
+}
+
+// commenter returns the commenter answering requests
+func (s *LSPServer) commenter() *GitCommenter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gc
+}
+
+// Serve answers requests until the client sends exit or closes the stream
+func (s *LSPServer) Serve() error {
+	for {
+		data, err := readLSPMessage(s.in)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var msg lspMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.respond(json.RawMessage("null"), nil, &lspError{Code: lspParseError, Message: err.Error()})
+			continue
+		}
+		if msg.Method == "" {
+			// A response to showDocument or applyEdit; nothing waits for it
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(msg.Method, msg.Params)
+		if msg.ID != nil {
+			s.respond(msg.ID, result, err)
+		}
+	}
+}
+
+func (s *LSPServer) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"codeActionProvider": map[string]any{
+					"codeActionKinds": []string{CodeActionGenerateCommitMessage},
+				},
+				"executeCommandProvider": map[string]any{
+					"commands": []string{LSPGenerateCommand},
+				},
+				"workspace": map[string]any{
+					"textDocumentContent": map[string]any{"schemes": []string{"ai-commit"}},
+				},
+			},
+			"serverInfo": map[string]string{"name": "ai-git-commit"},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/codeAction":
+		return s.codeActions(params)
+	case "workspace/executeCommand":
+		return s.executeCommand(params)
+	case "workspace/textDocumentContent":
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		s.mu.Lock()
+		text, ok := s.documents[p.URI]
+		s.mu.Unlock()
+		if !ok {
+			return nil, &lspError{Code: lspInvalidParams, Message: "unknown document " + p.URI}
+		}
+		return map[string]string{"text": text}, nil
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: "method not supported: " + method}
+}
+
+// codeActions offers generation unless the client asked for other kinds
+func (s *LSPServer) codeActions(params json.RawMessage) (any, error) {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Context struct {
+			Only []string `json:"only"`
+		} `json:"context"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+	}
+
+	wanted := len(p.Context.Only) == 0
+	for _, kind := range p.Context.Only {
+		// Kinds are hierarchical, so "source" includes ours
+		if kind == CodeActionGenerateCommitMessage || strings.HasPrefix(CodeActionGenerateCommitMessage, kind+".") {
+			wanted = true
+		}
+	}
+	if !wanted {
+		return []any{}, nil
+	}
+
+	// Generation is slow, so it runs when the action is chosen
+	title := "Generate commit message"
+	return []any{map[string]any{
+		"title": title,
+		"kind":  CodeActionGenerateCommitMessage,
+		"command": map[string]any{
+			"title":     title,
+			"command":   LSPGenerateCommand,
+			"arguments": []string{p.TextDocument.URI},
+		},
+	}}, nil
+}
+
+// executeCommand generates a message for the staged changes and hands it
+// to the editor
+func (s *LSPServer) executeCommand(params json.RawMessage) (any, error) {
+	var p struct {
+		Command   string   `json:"command"`
+		Arguments []string `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.Command != LSPGenerateCommand {
+		return nil, &lspError{Code: lspInvalidParams, Message: "unknown command " + p.Command}
+	}
+
+	gc := s.commenter()
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no staged changes")
+	}
+	suggestion, err := gc.GenerateCommitMessage(changes)
+	if err != nil {
+		return nil, err
+	}
+	message := suggestion.Message()
+
+	s.mu.Lock()
+	s.documents[SuggestionURI] = message
+	s.mu.Unlock()
+
+	if len(p.Arguments) > 0 && path.Base(p.Arguments[0]) == "COMMIT_EDITMSG" {
+		uri := p.Arguments[0]
+		start := map[string]int{"line": 0, "character": 0}
+		s.request("workspace/applyEdit", map[string]any{
+			"label": "Generate commit message",
+			"edit": map[string]any{
+				"changes": map[string]any{
+					uri: []any{map[string]any{
+						"range":   map[string]any{"start": start, "end": start},
+						"newText": message + "\n",
+					}},
+				},
+			},
+		})
+	} else {
+		s.request("window/showDocument", map[string]any{"uri": SuggestionURI, "takeFocus": true})
+	}
+
+	return map[string]string{"uri": SuggestionURI, "message": message}, nil
+}
+
+// request sends a request to the client without waiting for the answer
+func (s *LSPServer) request(method string, params any) {
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.mu.Unlock()
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+}
+
+func (s *LSPServer) respond(id json.RawMessage, result any, err error) {
+	if err == nil {
+		s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+		return
+	}
+	rpcErr, ok := err.(*lspError)
+	if !ok {
+		rpcErr = &lspError{Code: lspRequestFailed, Message: err.Error()}
+	}
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "error": rpcErr})
+}
+
+func (s *LSPServer) write(msg any) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// readLSPMessage reads one message framed by LSP's Content-Length header
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLSPServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add parser", Done: true})
+	}))
+	defer server.Close()
+
+	dir := initTestRepo(t)
+	writeFile(t, dir, "parser.go", "package parser\n")
+	gitRun(t, dir, "add", "parser.go")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: dir})
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- NewLSPServer(gc, serverIn, serverOut).Serve() }()
+
+	responses := bufio.NewReader(clientIn)
+	call := func(id int, method string, params any) map[string]any {
+		t.Helper()
+		data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+		fmt.Fprintf(clientOut, "Content-Length: %d\r\n\r\n%s", len(data), data)
+		// Skip requests the server sends to the client
+		for {
+			data, err := readLSPMessage(responses)
+			if err != nil {
+				t.Fatalf("Failed to read the response to %s: %v", method, err)
+			}
+			var msg map[string]any
+			json.Unmarshal(data, &msg)
+			if msg["method"] == nil {
+				return msg
+			}
+		}
+	}
+
+	initialize := call(1, "initialize", map[string]any{})
+	if _, ok := initialize["result"].(map[string]any)["capabilities"]; !ok {
+		t.Fatalf("Expected capabilities, got %v", initialize)
+	}
+
+	actions := call(2, "textDocument/codeAction", map[string]any{
+		"textDocument": map[string]string{"uri": "file:///repo/parser.go"},
+		"context":      map[string]any{"only": []string{"source"}},
+	})["result"].([]any)
+	if len(actions) != 1 || actions[0].(map[string]any)["kind"] != CodeActionGenerateCommitMessage {
+		t.Fatalf("Expected the generate action, got %v", actions)
+	}
+	if refactors := call(3, "textDocument/codeAction", map[string]any{
+		"context": map[string]any{"only": []string{"refactor"}},
+	})["result"].([]any); len(refactors) != 0 {
+		t.Errorf("Expected no action for refactorings, got %v", refactors)
+	}
+
+	command := actions[0].(map[string]any)["command"].(map[string]any)
+	executed := call(4, "workspace/executeCommand", map[string]any{"command": command["command"], "arguments": command["arguments"]})
+	if executed["result"].(map[string]any)["message"] != "feat: add parser" {
+		t.Fatalf("Unexpected command result %v", executed)
+	}
+
+	content := call(5, "workspace/textDocumentContent", map[string]string{"uri": SuggestionURI})
+	if content["result"].(map[string]any)["text"] != "feat: add parser" {
+		t.Errorf("Unexpected virtual document %v", content)
+	}
+
+	if unknown := call(6, "textDocument/hover", map[string]any{}); unknown["error"] == nil {
+		t.Errorf("Expected an error for an unsupported method, got %v", unknown)
+	}
+
+	call(7, "shutdown", nil)
+	fmt.Fprintf(clientOut, "Content-Length: 33\r\n\r\n{\"jsonrpc\":\"2.0\",\"method\":\"exit\"}")
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MCPProtocolVersion is the newest Model Context Protocol revision spoken
+// by MCPServer
+const MCPProtocolVersion = "2025-06-18"
+
+// mcpProtocolVersions are the revisions MCPServer accepts from clients
+var mcpProtocolVersions = []string{"2024-11-05", "2025-03-26", MCPProtocolVersion}
+
+// MCPServer is a Model Context Protocol server on stdio, one JSON-RPC
+// message per line. It offers IDE assistants three tools:
+// scan_staged_changes, generate_commit_message and commit. A read-only
+// commenter (Config.ReadOnly) does not offer commit.
+type MCPServer struct {
+	gc  *GitCommenter
+	in  *bufio.Reader
+	out io.Writer
+
+	mu sync.Mutex
+}
+
+// mcpTool describes a tool in tools/list
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// mcpTools are the tools offered by MCPServer
+var mcpTools = []mcpTool{
+	{
+		Name:        "scan_staged_changes",
+		Description: "List the files staged for the next commit with their change type and line counts, optionally with their diffs.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"include_diffs": map[string]any{"type": "boolean", "description": "Include the staged diff of every file"},
+			},
+		},
+	},
+	{
+		Name:        "generate_commit_message",
+		Description: "Generate a commit message for the staged changes with the local Ollama model, following the repository's commit conventions.",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+	},
+	{
+		Name:        "commit",
+		Description: "Commit the staged changes with the given message.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"message": map[string]any{"type": "string", "description": "The full commit message: subject, blank line, body"},
+			},
+			"required": []string{"message"},
+		},
+	},
+}
+
+// NewMCPServer creates a server reading requests from r and writing to w,
+// usually stdin and stdout of a process started by the assistant
+func NewMCPServer(gc *GitCommenter, r io.Reader, w io.Writer) *MCPServer {
+	return &MCPServer{gc: gc, in: bufio.NewReader(r), out: w}
+}
+
+// SetCommenter replaces the commenter answering requests, e.g. with one
+// for a reloaded configuration (see GitCommenter.WithConfig). Requests
+// already running finish with the previous one.
+func (s *MCPServer) SetCommenter(gc *GitCommenter) {
+	s.mu.Lock()
+	s.gc = gc
+	s.mu.Unlock()
+}
+
+// commenter returns the commenter answering requests
+func (s *MCPServer) commenter() *GitCommenter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gc
+}
+
+// Serve answers requests until the client closes the stream
+func (s *MCPServer) Serve() error {
+	for {
+		line, err := s.in.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			s.dispatch(line)
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+func (s *MCPServer) dispatch(line []byte) {
+	var msg rpcMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		s.respond(json.RawMessage("null"), nil, &lspError{Code: lspParseError, Message: err.Error()})
+		return
+	}
+	if msg.Method == "" || msg.ID == nil {
+		// Notifications such as notifications/initialized need no answer
+		return
+	}
+	result, err := s.handle(msg.Method, msg.Params)
+	s.respond(msg.ID, result, err)
+}
+
+func (s *MCPServer) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(params, &p)
+		version := MCPProtocolVersion
+		if slices.Contains(mcpProtocolVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "ai-git-commit"},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools()}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		if !slices.ContainsFunc(s.tools(), func(t mcpTool) bool { return t.Name == p.Name }) {
+			return nil, &lspError{Code: lspInvalidParams, Message: "unknown tool " + p.Name}
+		}
+		// Failed tools are reported to the model, not as protocol errors
+		text, err := s.call(p.Name, p.Arguments)
+		if err != nil {
+			return mcpToolResult(err.Error(), true), nil
+		}
+		return mcpToolResult(text, false), nil
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: "method not supported: " + method}
+}
+
+// tools lists the tools offered; a read-only repository cannot be committed to
+func (s *MCPServer) tools() []mcpTool {
+	if s.commenter().config.ReadOnly {
+		return slices.DeleteFunc(slices.Clone(mcpTools), func(t mcpTool) bool { return t.Name == "commit" })
+	}
+	return mcpTools
+}
+
+// call runs a tool and returns its text output
+func (s *MCPServer) call(name string, arguments json.RawMessage) (string, error) {
+	var args struct {
+		IncludeDiffs bool   `json:"include_diffs"`
+		Message      string `json:"message"`
+	}
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	gc := s.commenter()
+	switch name {
+	case "scan_staged_changes":
+		changes, err := gc.ScanStagedChanges()
+		if err != nil {
+			return "", err
+		}
+		type stagedFile struct {
+			Path         string `json:"path"`
+			ChangeType   string `json:"change_type"`
+			LinesAdded   int    `json:"lines_added"`
+			LinesRemoved int    `json:"lines_removed"`
+			Diff         string `json:"diff,omitempty"`
+		}
+		files := []stagedFile{}
+		for _, change := range changes {
+			file := stagedFile{change.FilePath, change.ChangeType, change.LinesAdded, change.LinesRemoved, ""}
+			if args.IncludeDiffs {
+				file.Diff = change.Diff
+			}
+			files = append(files, file)
+		}
+		data, err := json.MarshalIndent(files, "", "  ")
+		return string(data), err
+
+	case "generate_commit_message":
+		changes, err := gc.ScanStagedChanges()
+		if err != nil {
+			return "", err
+		}
+		if len(changes) == 0 {
+			return "", errors.New("no staged changes; stage files with git add first")
+		}
+		suggestion, err := gc.GenerateCommitMessage(changes)
+		if err != nil {
+			return "", err
+		}
+		return suggestion.Message(), nil
+
+	case "commit":
+		subject, body, _ := strings.Cut(CleanCommitMessage(args.Message), "\n")
+		suggestion := &CommitSuggestion{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)}
+		if err := gc.Commit(suggestion); err != nil {
+			return "", err
+		}
+		hash, err := gc.HeadCommit()
+		if err != nil {
+			return "Committed: " + suggestion.Subject, nil
+		}
+		return fmt.Sprintf("Committed %s: %s", hash[:min(len(hash), 7)], suggestion.Subject), nil
+	}
+	return "", fmt.Errorf("unknown tool %s", name)
+}
+
+// mcpToolResult is the result of tools/call with text content
+func mcpToolResult(text string, isError bool) map[string]any {
+	return map[string]any{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *MCPServer) respond(id json.RawMessage, result any, err error) {
+	message := map[string]any{"jsonrpc": "2.0", "id": id, "result": result}
+	if err != nil {
+		rpcErr, ok := err.(*lspError)
+		if !ok {
+			rpcErr = &lspError{Code: lspRequestFailed, Message: err.Error()}
+		}
+		message = map[string]any{"jsonrpc": "2.0", "id": id, "error": rpcErr}
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	s.out.Write(append(data, '\n'))
+}
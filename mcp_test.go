@@ -0,0 +1,114 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMCPServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add parser\n\nParses the input.", Done: true})
+	}))
+	defer server.Close()
+
+	dir := initTestRepo(t)
+	writeFile(t, dir, "parser.go", "package parser\n\nfunc Parse() {}\n")
+	gitRun(t, dir, "add", "parser.go")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- NewMCPServer(gc, serverIn, serverOut).Serve() }()
+
+	responses := bufio.NewScanner(clientIn)
+	call := func(id int, method string, params any) map[string]any {
+		t.Helper()
+		data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+		clientOut.Write(append(data, '\n'))
+		if !responses.Scan() {
+			t.Fatalf("No response to %s: %v", method, responses.Err())
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(responses.Bytes(), &msg); err != nil {
+			t.Fatalf("Invalid response %q: %v", responses.Text(), err)
+		}
+		return msg
+	}
+	// toolText calls a tool and returns its text and whether it failed
+	toolText := func(id int, name string, arguments any) (string, bool) {
+		t.Helper()
+		result := call(id, "tools/call", map[string]any{"name": name, "arguments": arguments})["result"].(map[string]any)
+		return result["content"].([]any)[0].(map[string]any)["text"].(string), result["isError"].(bool)
+	}
+
+	initialize := call(1, "initialize", map[string]any{"protocolVersion": "2025-03-26", "capabilities": map[string]any{}})
+	if initialize["result"].(map[string]any)["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected the client's protocol version, got %v", initialize)
+	}
+	clientOut.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"))
+
+	tools := call(2, "tools/list", nil)["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 3 {
+		t.Fatalf("Expected 3 tools, got %v", tools)
+	}
+
+	scanned, failed := toolText(3, "scan_staged_changes", map[string]any{"include_diffs": true})
+	var files []map[string]any
+	if err := json.Unmarshal([]byte(scanned), &files); err != nil || failed {
+		t.Fatalf("Unexpected scan result %q: %v", scanned, err)
+	}
+	if len(files) != 1 || files[0]["path"] != "parser.go" || !strings.Contains(files[0]["diff"].(string), "+func Parse") {
+		t.Errorf("Unexpected staged files %v", files)
+	}
+
+	message, failed := toolText(4, "generate_commit_message", nil)
+	if failed || message != "feat: add parser\n\nParses the input." {
+		t.Fatalf("Unexpected generated message %q", message)
+	}
+
+	committed, failed := toolText(5, "commit", map[string]string{"message": message})
+	if failed || !strings.HasSuffix(committed, ": feat: add parser") {
+		t.Fatalf("Unexpected commit result %q", committed)
+	}
+	if subject, _ := gc.gitOutput("log", "-1", "--format=%s"); strings.TrimSpace(subject) != "feat: add parser" {
+		t.Errorf("Expected the commit to exist, got %q", subject)
+	}
+
+	// Nothing is staged any more
+	if text, failed := toolText(6, "generate_commit_message", nil); !failed {
+		t.Errorf("Expected the tool to fail without staged changes, got %q", text)
+	}
+	if unknown := call(7, "tools/call", map[string]any{"name": "push"}); unknown["error"] == nil {
+		t.Errorf("Expected an error for an unknown tool, got %v", unknown)
+	}
+
+	clientOut.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+}
+
+func TestMCPServerReadOnly(t *testing.T) {
+	config := DefaultConfig()
+	config.ReadOnly = true
+	server := NewMCPServer(New(config), nil, io.Discard)
+
+	for _, tool := range server.tools() {
+		if tool.Name == "commit" {
+			t.Error("Expected no commit tool for a read-only repository")
+		}
+	}
+	if len(mcpTools) != 3 {
+		t.Errorf("Expected the tool list to be left intact, got %d tools", len(mcpTools))
+	}
+}
@@ -0,0 +1,169 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxReadmeLength bounds how much of the README is included in the prompt
+const maxReadmeLength = 3000
+
+// DirectoryCount is a directory with the number of files (or changes) in it
+type DirectoryCount struct {
+	Directory string
+	Count     int
+}
+
+// GenerateOnboardingSummary produces a markdown overview of the repository
+// for a new contributor: structure, main components and recent activity
+func (gc *GitCommenter) GenerateOnboardingSummary() (string, error) {
+	// List the whole repository even when RepositoryPath is a subdirectory
+	tracked, err := gc.gitOutput("ls-files", "--full-name", "--", ":(top)")
+	if err != nil {
+		return "", fmt.Errorf("failed to list files: %w", err)
+	}
+	files := splitLines(tracked)
+	if len(files) == 0 {
+		return "", fmt.Errorf("repository has no tracked files")
+	}
+
+	// Activity is measured over the last 90 days of history
+	touched, err := gc.gitOutput("log", "--since=90.days", "--name-only", "--format=")
+	if err != nil {
+		touched = ""
+	}
+	commits, _ := gc.gitOutput("log", "-20", "--format=%s")
+
+	var prompt strings.Builder
+	prompt.WriteString("You are helping a new contributor get oriented in a code repository.\n\n")
+
+	prompt.WriteString("TOP-LEVEL STRUCTURE (files per directory):\n")
+	for _, dir := range countByDirectory(files, 1) {
+		prompt.WriteString(fmt.Sprintf("- %s: %d files\n", dir.Directory, dir.Count))
+	}
+
+	prompt.WriteString("\nFILE TYPES:\n")
+	for _, ext := range countByExtension(files) {
+		prompt.WriteString(fmt.Sprintf("- %s: %d\n", ext.Directory, ext.Count))
+	}
+
+	if readme := gc.readReadme(files); readme != "" {
+		prompt.WriteString("\nREADME (excerpt):\n")
+		prompt.WriteString(readme)
+		prompt.WriteString("\n")
+	}
+
+	if activity := countByDirectory(splitLines(touched), 2); len(activity) > 0 {
+		prompt.WriteString("\nMOST ACTIVE AREAS (file changes in the last 90 days):\n")
+		for i, dir := range activity {
+			if i >= 10 {
+				break
+			}
+			prompt.WriteString(fmt.Sprintf("- %s: %d changes\n", dir.Directory, dir.Count))
+		}
+	}
+
+	if commits != "" {
+		prompt.WriteString("\nRECENT COMMITS:\n")
+		prompt.WriteString(commits)
+	}
+
+	prompt.WriteString("\nWrite a concise onboarding guide in markdown with these sections:\n")
+	prompt.WriteString("## Overview, ## Structure, ## Main Components, ## Recent Activity, ## Where to Start\n")
+	prompt.WriteString("Name real directories and files from the information above; do not invent any.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to generate onboarding summary: %w", err)
+	}
+
+	// Show where recent churn concentrates and who drives it
+	if ownership, err := gc.DirectoryActivity("90.days", 2); err == nil && len(ownership) > 0 {
+		gc.SummarizeThemes(ownership)
+		response += "\n\n## Change Ownership (last 90 days)\n\n" + FormatActivityTable(ownership)
+	}
+	return response, nil
+}
+
+// readReadme returns the beginning of the repository README, if any
+func (gc *GitCommenter) readReadme(files []string) string {
+	for _, file := range files {
+		if strings.Contains(file, "/") || !strings.HasPrefix(strings.ToLower(file), "readme") {
+			continue
+		}
+
+		path, err := gc.AbsolutePath(file)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if len(data) > maxReadmeLength {
+			data = data[:maxReadmeLength]
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// countByDirectory counts paths per directory truncated to depth
+// components, most populated first. Files at the root count as ".".
+func countByDirectory(paths []string, depth int) []DirectoryCount {
+	counts := make(map[string]int)
+	for _, p := range paths {
+		dir := path.Dir(p)
+		if dir != "." {
+			parts := strings.Split(dir, "/")
+			if len(parts) > depth {
+				parts = parts[:depth]
+			}
+			dir = strings.Join(parts, "/") + "/"
+		}
+		counts[dir]++
+	}
+	return sortedCounts(counts)
+}
+
+// countByExtension counts paths per file extension, most common first
+func countByExtension(paths []string) []DirectoryCount {
+	counts := make(map[string]int)
+	for _, p := range paths {
+		ext := path.Ext(p)
+		if ext == "" {
+			ext = "(none)"
+		}
+		counts[ext]++
+	}
+	return sortedCounts(counts)
+}
+
+// sortedCounts orders counts by descending count, then name
+func sortedCounts(counts map[string]int) []DirectoryCount {
+	result := make([]DirectoryCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, DirectoryCount{Directory: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Directory < result[j].Directory
+	})
+	return result
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
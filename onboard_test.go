@@ -0,0 +1,34 @@
+package gitcommenter
+
+import "testing"
+
+func TestCountByDirectory(t *testing.T) {
+	paths := []string{"main.go", "cmd/a/main.go", "cmd/b/main.go", "cmd/b/util.go", "docs/x.md"}
+
+	top := countByDirectory(paths, 1)
+	if top[0].Directory != "cmd/" || top[0].Count != 3 {
+		t.Errorf("Expected cmd/ with 3 files first, got %+v", top)
+	}
+
+	nested := countByDirectory(paths, 2)
+	if nested[0].Directory != "cmd/b/" || nested[0].Count != 2 {
+		t.Errorf("Expected cmd/b/ with 2 files first, got %+v", nested)
+	}
+
+	found := false
+	for _, dir := range top {
+		if dir.Directory == "." && dir.Count == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected root files to be counted under '.', got %+v", top)
+	}
+}
+
+func TestCountByExtension(t *testing.T) {
+	counts := countByExtension([]string{"a.go", "b.go", "Makefile", "c.md"})
+	if counts[0].Directory != ".go" || counts[0].Count != 2 {
+		t.Errorf("Expected .go first, got %+v", counts)
+	}
+}
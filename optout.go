@@ -0,0 +1,73 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OptOutMarker turns generation off when it appears on a line of its own in
+// a commit message or a branch description
+const OptOutMarker = "ai: off"
+
+// HasOptOutMarker reports whether text has an "ai: off" line. Case, spacing
+// and a leading '#' are ignored, so commented commit templates count too.
+func HasOptOutMarker(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "ai") && strings.EqualFold(strings.TrimSpace(value), "off") {
+			return true
+		}
+	}
+	return false
+}
+
+// OptOutReason reports why generation is turned off, or "" when it is not.
+// It checks the repository config ("ai: off"), the current branch
+// ("git config branch.<name>.ai off" or the marker in the branch
+// description) and messageFile, typically .git/COMMIT_EDITMSG passed to a
+// prepare-commit-msg hook. An empty or missing messageFile is skipped.
+func (gc *GitCommenter) OptOutReason(messageFile string) (string, error) {
+	if gc.config.Disabled {
+		return "ai is off in the repository config", nil
+	}
+
+	// A detached HEAD has no branch config
+	if branch := gc.currentBranch(); branch != "" {
+		value, _ := gc.configValue("branch." + branch + ".ai")
+		if value := strings.TrimSpace(value); strings.EqualFold(value, "off") || strings.EqualFold(value, "false") {
+			return fmt.Sprintf("branch.%s.ai is %s", branch, value), nil
+		}
+		description, _ := gc.configValue("branch." + branch + ".description")
+		if HasOptOutMarker(description) {
+			return fmt.Sprintf("the description of branch %s says %q", branch, OptOutMarker), nil
+		}
+	}
+
+	if messageFile != "" {
+		message, err := os.ReadFile(messageFile)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read commit message: %w", err)
+		}
+		if HasOptOutMarker(string(message)) {
+			return fmt.Sprintf("the commit message says %q", OptOutMarker), nil
+		}
+	}
+
+	return "", nil
+}
+
+// configValue returns the value of a git config key, or "" when it is not
+// set
+func (gc *GitCommenter) configValue(key string) (string, error) {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		return repository.Config(key)
+	}
+	// git config exits with status 1 for unset keys
+	value, err := gc.gitOutput("config", "--get", key)
+	if err != nil {
+		return "", nil
+	}
+	return value, nil
+}
@@ -0,0 +1,56 @@
+package gitcommenter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHasOptOutMarker(t *testing.T) {
+	for text, want := range map[string]bool{
+		"fix: typo\n\nai: off":                  true,
+		"# AI: Off":                             true,
+		"Refactor parser\n  ai:off\n":           true,
+		"fix: turn ai: off in the config":       false,
+		"feat: add toggle\n\nai: on":            false,
+		"Tests run with the ai backend off too": false,
+	} {
+		if got := HasOptOutMarker(text); got != want {
+			t.Errorf("HasOptOutMarker(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestOptOutReason(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "checkout", "-q", "-b", "release")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	messageFile := filepath.Join(dir, ".git", "COMMIT_EDITMSG")
+	if reason, err := gc.OptOutReason(messageFile); err != nil || reason != "" {
+		t.Fatalf("Expected no opt-out, got %q (%v)", reason, err)
+	}
+
+	writeFile(t, dir, ".git/COMMIT_EDITMSG", "Bump version\n\nai: off\n")
+	if reason, _ := gc.OptOutReason(messageFile); !strings.Contains(reason, "commit message") {
+		t.Errorf("Expected the commit message to opt out, got %q", reason)
+	}
+
+	gitRun(t, dir, "config", "branch.release.description", "Release branch\nai: off")
+	if reason, _ := gc.OptOutReason(""); !strings.Contains(reason, "description of branch release") {
+		t.Errorf("Expected the branch description to opt out, got %q", reason)
+	}
+
+	gitRun(t, dir, "config", "branch.release.ai", "off")
+	if reason, _ := gc.OptOutReason(""); reason != "branch.release.ai is off" {
+		t.Errorf("Expected the branch config to opt out, got %q", reason)
+	}
+
+	config.Disabled = true
+	if reason, _ := gc.OptOutReason(""); !strings.Contains(reason, "repository config") {
+		t.Errorf("Expected the repository config to opt out, got %q", reason)
+	}
+}
@@ -0,0 +1,46 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxOutlineLines bounds the outline of one new file
+const maxOutlineLines = 20
+
+// outlineLine matches the top-level declarations of common languages and
+// Markdown headings: what a new file defines, without its bodies
+var outlineLine = regexp.MustCompile(`^(?:(?:export|pub(?:\([\w:]+\))?|public|private|protected|internal|abstract|static|final|default|async|unsafe|extern)\s+)*(?:func|function|class|interface|type|struct|enum|trait|impl|fn|def|module|object|record|protocol|extension|const|var|let|package|namespace)\b|^#{1,3} \S`)
+
+// newFileOutline returns the top-level declarations of a new file, from the
+// added lines of its diff
+func newFileOutline(diff string) []string {
+	var outline []string
+	for _, line := range strings.Split(diff, "\n") {
+		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++ ") {
+			continue
+		}
+		line = strings.TrimRight(line[1:], " \t\r")
+		if !outlineLine.MatchString(line) {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimSuffix(line, "{"))
+		if len(outline) == maxOutlineLines {
+			outline = append(outline, "...")
+			break
+		}
+		outline = append(outline, truncateUTF8(line, 120))
+	}
+	return outline
+}
+
+// formatOutline renders the outline of a new file for the prompt, or ""
+// when it declares nothing recognizable
+func formatOutline(change FileChange) string {
+	outline := newFileOutline(change.Diff)
+	if len(outline) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("OUTLINE OF NEW FILE %s:\n  %s\n", change.FilePath, strings.Join(outline, "\n  "))
+}
@@ -0,0 +1,46 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewFileOutline(t *testing.T) {
+	diff := "--- /dev/null\n+++ b/limiter.go\n@@ -0,0 +1,12 @@\n" +
+		"+package limiter\n+\n+// Limiter limits requests\n+type Limiter struct {\n+\trate int\n+}\n+\n" +
+		"+func (l *Limiter) Allow() bool {\n+\tvar ok bool\n+\treturn ok\n+}\n+export default class Widget {\n"
+
+	got := strings.Join(newFileOutline(diff), "|")
+	if want := "package limiter|type Limiter struct|func (l *Limiter) Allow() bool|export default class Widget"; got != want {
+		t.Errorf("newFileOutline = %q, want %q", got, want)
+	}
+
+	var long strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&long, "+def handler_%d():\n+    pass\n", i)
+	}
+	if outline := newFileOutline(long.String()); len(outline) != maxOutlineLines+1 || outline[maxOutlineLines] != "..." {
+		t.Errorf("Expected the outline cut at %d lines, got %d", maxOutlineLines, len(outline))
+	}
+}
+
+func TestFormatDiffsOutlinesNewFiles(t *testing.T) {
+	big := "+package limiter\n+\n+func Allow() bool {\n" + strings.Repeat("+\t// filler\n", 300) + "+}\n\n+func Reset() {}\n"
+	changes := []FileChange{{FilePath: "limiter.go", ChangeType: "added", Diff: big}}
+	for i := 0; i < 4; i++ {
+		changes = append(changes, FileChange{FilePath: fmt.Sprintf("f%d.go", i), ChangeType: "modified", Diff: "+x\n"})
+	}
+	changes = append(changes, FileChange{FilePath: "extra.py", ChangeType: "added", Diff: "+class Extra:\n+    pass\n"})
+
+	out := formatDiffs(changes, DefaultPromptBudget())
+	if !strings.Contains(out, "OUTLINE OF NEW FILE limiter.go:\n  package limiter\n  func Allow() bool\n  func Reset() {}\n") {
+		t.Errorf("Expected the outline of the truncated new file:\n%s", out)
+	}
+	if !strings.Contains(out, "... and 1 more files\n\nOUTLINE OF NEW FILE extra.py:\n  class Extra:\n") {
+		t.Errorf("Expected the outline of the new file not shown:\n%s", out)
+	}
+	if strings.Contains(out, "OUTLINE OF NEW FILE f") {
+		t.Errorf("Expected no outline for modified files:\n%s", out)
+	}
+}
@@ -0,0 +1,241 @@
+package gitcommenter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// packageManifests mark the root directory of a package in a monorepo
+var packageManifests = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "pom.xml",
+	"build.gradle", "build.gradle.kts", "composer.json", "Gemfile", "mix.exs", "pubspec.yaml",
+}
+
+// PackageGroup is the part of a changeset in one package of a monorepo,
+// committed on its own by CommitPackages
+type PackageGroup struct {
+	// Package names the group: the scope of the Config.ScopeMap entry
+	// matching its files, the package directory, or "" for files at the
+	// root of the repository
+	Package string
+	// Scope is the conventional commit scope inferred for the group
+	Scope   string
+	Changes []FileChange
+	// Suggestion is the message CommitPackages commits the group with
+	Suggestion *CommitSuggestion
+}
+
+// GroupByPackage partitions changes by package, in the order the packages
+// first appear. A file belongs to the scope of the first Config.ScopeMap
+// entry matching it, else to the nearest directory below the root with a
+// package manifest such as go.mod or package.json, else to its top-level
+// directory.
+func (gc *GitCommenter) GroupByPackage(changes []FileChange) ([]PackageGroup, error) {
+	var mappings []ScopeMapping
+	for _, entry := range gc.config.ScopeMap {
+		mapping, err := ParseScopeMapping(entry)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	top, err := gc.gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the working tree: %w", err)
+	}
+	top = strings.TrimSpace(top)
+
+	var groups []PackageGroup
+	index := make(map[string]int)
+	roots := make(map[string]string)
+	for _, change := range changes {
+		name, scope := "", ""
+		if mapped := InferScope(mappings, []FileChange{change}); mapped != "" {
+			name, scope = mapped, mapped
+		} else if name = packageRoot(top, change.FilePath, roots); name != "" {
+			scope = gc.packageScope(name)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, PackageGroup{Package: name, Scope: scope})
+		}
+		groups[i].Changes = append(groups[i].Changes, change)
+	}
+	return groups, nil
+}
+
+// packageRoot returns the package directory of a repository-relative path,
+// remembering the package of every directory looked at in roots
+func packageRoot(top, file string, roots map[string]string) string {
+	var visited []string
+	root := ""
+	for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+		if known, ok := roots[dir]; ok {
+			root = known
+			break
+		}
+		visited = append(visited, dir)
+		if hasPackageManifest(filepath.Join(top, filepath.FromSlash(dir))) {
+			root = dir
+			break
+		}
+	}
+	if root == "" && strings.Contains(file, "/") {
+		root, _, _ = strings.Cut(file, "/")
+	}
+	for _, dir := range visited {
+		roots[dir] = root
+	}
+	return root
+}
+
+// hasPackageManifest reports whether a directory holds a package manifest
+func hasPackageManifest(dir string) bool {
+	for _, name := range packageManifests {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// packageScope derives a scope from a package directory, e.g. "api" for
+// packages/api, or "" when the scope whitelist does not allow it
+func (gc *GitCommenter) packageScope(dir string) string {
+	scope := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("(),: ", r) {
+			return -1
+		}
+		return r
+	}, path.Base(dir))
+	if allowed := gc.allowedScopes(false); len(allowed) > 0 && !containsString(allowed, scope) {
+		return ""
+	}
+	return scope
+}
+
+// GeneratePackageMessage generates the message of a package group, using
+// its scope unless Config.ScopeMap gives one
+func (gc *GitCommenter) GeneratePackageMessage(group PackageGroup) (*CommitSuggestion, error) {
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion := gc.TrivialMessage(group.Changes); suggestion != nil {
+		if style.Conventional && group.Scope != "" {
+			suggestion.Subject = forceScope(suggestion.Subject, gc.ticketPrefix(), group.Scope)
+		}
+		gc.recordGenerated(group.Changes, suggestion, nil)
+		return suggestion, nil
+	}
+
+	plan, err := gc.plan(group.Changes)
+	if err != nil {
+		gc.recordGenerated(group.Changes, nil, err)
+		return nil, err
+	}
+	if plan.scope == "" {
+		plan.scope = group.Scope
+	}
+	suggestion, err := gc.complete(context.Background(), plan, 0, nil)
+	gc.recordGenerated(group.Changes, suggestion, err)
+	return suggestion, err
+}
+
+// CommitPackages commits the staged hunks of each group with its
+// Suggestion, one commit per group in order, like CommitSplit. The staged
+// files listed in .aiignore go with the group of their package. Staged
+// files that belong to no group stay staged. It returns the number of
+// commits made.
+func (gc *GitCommenter) CommitPackages(groups []PackageGroup) (int, error) {
+	if err := gc.checkWritable(); err != nil {
+		return 0, err
+	}
+	staged, err := gc.diffHunks("--cached")
+	if err != nil {
+		return 0, err
+	}
+	hunks, ignored, err := gc.splitIgnoredHunks(staged)
+	if err != nil {
+		return 0, err
+	}
+	owner := make(map[string]int)
+	for i, group := range groups {
+		for _, change := range group.Changes {
+			owner[change.FilePath] = i
+			// The removal of a renamed file goes with its new path; the
+			// source of a copy stays where it is
+			if change.ChangeType == "renamed" {
+				owner[change.OldPath] = i
+			}
+		}
+	}
+	if err := gc.ownIgnoredHunks(groups, ignored, owner); err != nil {
+		return 0, err
+	}
+	hunks = append(hunks, ignored...)
+
+	commits := make([]SplitCommit, len(groups))
+	var others []StagedHunk
+	for i, group := range groups {
+		commits[i].Suggestion = group.Suggestion
+	}
+	for _, hunk := range hunks {
+		if i, ok := owner[hunk.Path]; ok {
+			commits[i].Hunks = append(commits[i].Hunks, hunk)
+		} else {
+			others = append(others, hunk)
+		}
+	}
+
+	made, err := gc.commitHunks(commits)
+	if len(others) > 0 {
+		if restageErr := gc.applyCached(HunkPatch(others)); restageErr != nil && err == nil {
+			err = fmt.Errorf("failed to stage the files of no package again: %w", restageErr)
+		}
+	}
+	return made, err
+}
+
+// ownIgnoredHunks adds the files of the ignored hunks to owner, each with
+// the group of its package, which GroupByPackage never saw
+func (gc *GitCommenter) ownIgnoredHunks(groups []PackageGroup, ignored []StagedHunk, owner map[string]int) error {
+	var changes []FileChange
+	seen := make(map[string]bool)
+	for _, hunk := range ignored {
+		if _, ok := owner[hunk.Path]; !ok && !seen[hunk.Path] {
+			seen[hunk.Path] = true
+			changes = append(changes, FileChange{FilePath: hunk.Path})
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	packages, err := gc.GroupByPackage(changes)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]int)
+	for i, group := range groups {
+		if _, ok := index[group.Package]; !ok {
+			index[group.Package] = i
+		}
+	}
+	for _, pkg := range packages {
+		i, ok := index[pkg.Package]
+		if !ok {
+			continue
+		}
+		for _, change := range pkg.Changes {
+			owner[change.FilePath] = i
+		}
+	}
+	return nil
+}
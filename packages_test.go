@@ -0,0 +1,108 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGroupByPackage(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, path := range []string{"packages/api/package.json", "packages/web/package.json"} {
+		writeFile(t, dir, path, "{}\n")
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.ScopeMap = []string{"docs/** -> docs"}
+	gc := New(config)
+
+	changes := []FileChange{
+		{FilePath: "packages/api/src/server.ts"},
+		{FilePath: "README.md"},
+		{FilePath: "packages/web/index.ts"},
+		{FilePath: "docs/guide/setup.md"},
+		{FilePath: "packages/api/package.json"},
+		{FilePath: "tools/lint/run.sh"},
+	}
+	groups, err := gc.GroupByPackage(changes)
+	if err != nil {
+		t.Fatalf("GroupByPackage returned error: %v", err)
+	}
+	var got []string
+	for _, group := range groups {
+		got = append(got, fmt.Sprintf("%s=%s:%d", group.Package, group.Scope, len(group.Changes)))
+	}
+	want := "packages/api=api:2,=:1,packages/web=web:1,docs=docs:1,tools=tools:1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Unexpected groups %q, want %q", strings.Join(got, ","), want)
+	}
+
+	// Scopes outside the whitelist are left to the model
+	gc.config.Scopes = []string{"api"}
+	if groups, _ := gc.GroupByPackage(changes[2:3]); groups[0].Scope != "" {
+		t.Errorf("Expected no scope outside the whitelist, got %q", groups[0].Scope)
+	}
+}
+
+func TestCommitPackages(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "api/go.mod", "module api\n")
+	writeFile(t, dir, "web/main.js", "one\n")
+	writeFile(t, dir, AIIgnoreFile, "dist/\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	writeFile(t, dir, "api/server.go", "package api\n")
+	writeFile(t, dir, "api/dist/server.js", "built\n")
+	writeFile(t, dir, "web/main.js", "two\n")
+	writeFile(t, dir, "web/copy.js", "one\n")
+	writeFile(t, dir, "notes.txt", "left staged\n")
+	gitRun(t, dir, "add", ".")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add the change", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.Style = "conventional"
+	config.HistoryExamples = 0
+	gc := New(config)
+
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	groups, err := gc.GroupByPackage(changes)
+	if err != nil || len(groups) != 3 {
+		t.Fatalf("Expected three groups, got %+v: %v", groups, err)
+	}
+	// The ignored api/dist/server.js goes with api, not the last package.
+	// Only the two packages are committed, not notes.txt at the root
+	groups = []PackageGroup{groups[0], groups[2]}
+	for i := range groups {
+		if groups[i].Suggestion, err = gc.GeneratePackageMessage(groups[i]); err != nil {
+			t.Fatalf("GeneratePackageMessage returned error: %v", err)
+		}
+	}
+	if groups[0].Suggestion.Subject != "feat(api): add the change" {
+		t.Errorf("Expected the package scope, got %q", groups[0].Suggestion.Subject)
+	}
+
+	made, err := gc.CommitPackages(groups)
+	if err != nil || made != 2 {
+		t.Fatalf("CommitPackages made %d commit(s): %v", made, err)
+	}
+	if got := gitRun(t, dir, "log", "--format=%s", "--name-only", "-2"); got != "feat(web): add the change\n\nweb/copy.js\nweb/main.js\nfeat(api): add the change\n\napi/dist/server.js\napi/server.go\n" {
+		t.Errorf("Unexpected commits %q", got)
+	}
+	if got := gitRun(t, dir, "diff", "--cached", "--name-only"); got != "notes.txt\n" {
+		t.Errorf("Expected the file of no committed package staged, got %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Paths reported by git and used in FileChange, Config.Exclude and the
+// prompt are slash-separated and relative to the repository root, whatever
+// directory RepositoryPath points at. The helpers below convert between
+// those and filesystem paths.
+
+// NormalizePath cleans a repository-relative path: forward slashes, no
+// leading "./" or "/" and no trailing slash
+func NormalizePath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	p = path.Clean("/" + p)
+	return strings.TrimPrefix(p, "/")
+}
+
+// TopPathspec returns a pathspec matching exactly the repository-relative
+// path p, whichever directory git runs in
+func TopPathspec(p string) string {
+	return ":(top,literal)" + NormalizePath(p)
+}
+
+// RepoRoot returns the top-level directory of the repository containing
+// RepositoryPath
+func (gc *GitCommenter) RepoRoot() (string, error) {
+	if repository, ok := gc.git.(RepositoryBackend); ok {
+		root, err := repository.Root()
+		if err != nil {
+			return "", fmt.Errorf("failed to find repository root: %w", err)
+		}
+		return root, nil
+	}
+	root, err := gc.gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+	return strings.TrimSpace(root), nil
+}
+
+// RepoRelativePath converts a filesystem path, absolute or relative to
+// RepositoryPath, to a path relative to the repository root
+func (gc *GitCommenter) RepoRelativePath(p string) (string, error) {
+	root, err := gc.RepoRoot()
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(p) {
+		base, err := filepath.Abs(gc.config.RepositoryPath)
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(base, p)
+	}
+	// Resolve symlinks such as /tmp -> /private/tmp like git does
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		p = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository %s", p, root)
+	}
+	return NormalizePath(filepath.ToSlash(rel)), nil
+}
+
+// AbsolutePath converts a repository-relative path to a filesystem path
+func (gc *GitCommenter) AbsolutePath(p string) (string, error) {
+	root, err := gc.RepoRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filepath.FromSlash(NormalizePath(p))), nil
+}
+
+// vendorDirs are directories holding third-party code
+var vendorDirs = []string{"vendor", "node_modules", "third_party"}
+
+// IsVendored reports whether a repository-relative path is third-party code
+func IsVendored(p string) bool {
+	for _, part := range strings.Split(NormalizePath(p), "/") {
+		for _, dir := range vendorDirs {
+			if part == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generatedMarker matches the Go convention for generated files
+// (https://go.dev/s/generatedcode) and the common @generated tag
+var generatedMarker = regexp.MustCompile(`(?m)^[+ ]?\s*(//|#|/\*|--)?\s*(Code generated .* DO NOT EDIT\.|@generated\b)`)
+
+// IsGeneratedContent reports whether file content, or a diff of it,
+// carries a generated-code marker
+func IsGeneratedContent(content string) bool {
+	return generatedMarker.MatchString(content)
+}
+
+// PathInfo classifies a changed file
+type PathInfo struct {
+	// Path is the normalized repository-relative path
+	Path string
+	// Vendored is set for files under vendor/, node_modules/ or
+	// third_party/, or marked linguist-vendored (see ClassifyFiles)
+	Vendored bool
+	// Generated is set when the diff carries a generated-code marker, or
+	// the file is marked linguist-generated (see ClassifyFiles)
+	Generated bool
+}
+
+// ClassifyChange classifies the file of a change
+func ClassifyChange(change FileChange) PathInfo {
+	p := NormalizePath(change.FilePath)
+	return PathInfo{Path: p, Vendored: IsVendored(p), Generated: IsGeneratedContent(change.Diff)}
+}
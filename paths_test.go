@@ -0,0 +1,102 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"cmd/main.go":     "cmd/main.go",
+		"./cmd/main.go":   "cmd/main.go",
+		"/cmd/main.go":    "cmd/main.go",
+		"cmd\\main.go":    "cmd/main.go",
+		"cmd//x/../a.go":  "cmd/a.go",
+		"docs/":           "docs",
+		".":               "",
+		"../outside/x.go": "outside/x.go",
+	}
+	for input, want := range tests {
+		if got := NormalizePath(input); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if got := TopPathspec("./a b.go"); got != ":(top,literal)a b.go" {
+		t.Errorf("Unexpected pathspec %q", got)
+	}
+}
+
+func TestClassifyChange(t *testing.T) {
+	tests := []struct {
+		change    FileChange
+		vendored  bool
+		generated bool
+	}{
+		{FileChange{FilePath: "vendor/github.com/x/y.go"}, true, false},
+		{FileChange{FilePath: "web/node_modules/left-pad/index.js"}, true, false},
+		{FileChange{FilePath: "vendors.go"}, false, false},
+		{FileChange{FilePath: "api/api.pb.go", Diff: "+// Code generated by protoc-gen-go. DO NOT EDIT.\n+package api"}, false, true},
+		{FileChange{FilePath: "yarn.lock", Diff: "+# @generated by yarn"}, false, true},
+		{FileChange{FilePath: "main.go", Diff: "+// Code generated here is reviewed by hand\n"}, false, false},
+	}
+	for _, test := range tests {
+		info := ClassifyChange(test.change)
+		if info.Vendored != test.vendored || info.Generated != test.generated {
+			t.Errorf("ClassifyChange(%s) = %+v, want vendored=%v generated=%v", test.change.FilePath, info, test.vendored, test.generated)
+		}
+	}
+
+	gc := New(DefaultConfig())
+	context := gc.buildChangeContext([]FileChange{
+		{FilePath: "vendor/x/y.go", ChangeType: "modified"},
+		{FilePath: "api.pb.go", ChangeType: "modified", Diff: "+// Code generated by protoc-gen-go. DO NOT EDIT."},
+	})
+	if !strings.Contains(context, "Vendored third-party code") || !strings.Contains(context, "Generated file") {
+		t.Errorf("Expected vendored and generated notes in the context, got:\n%s", context)
+	}
+}
+
+func TestRepoRelativePathsFromSubdirectory(t *testing.T) {
+	dir := initTestRepo(t)
+	sub := filepath.Join(dir, "cmd", "tool")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, dir, "add", ".")
+
+	config := DefaultConfig()
+	config.RepositoryPath = sub
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: sub})
+
+	rel, err := gc.RepoRelativePath("main.go")
+	if err != nil || rel != "cmd/tool/main.go" {
+		t.Errorf("RepoRelativePath = %q, %v", rel, err)
+	}
+	if _, err := gc.RepoRelativePath(filepath.Join(dir, "..", "elsewhere")); err == nil {
+		t.Error("Expected an error for a path outside the repository")
+	}
+
+	abs, err := gc.AbsolutePath("cmd/tool/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		t.Errorf("AbsolutePath returned a missing file %s: %v", abs, err)
+	}
+
+	// Staged paths are root-relative and their diffs resolve from the subdirectory
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].FilePath != "cmd/tool/main.go" || !strings.Contains(changes[0].Diff, "+package main") {
+		t.Errorf("Unexpected changes %+v", changes)
+	}
+}
@@ -0,0 +1,124 @@
+package gitcommenter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden prompt files in testdata/prompts")
+
+// promptCase is one synthetic changeset of the prompt corpus. Cases live in
+// testdata/prompts/<name>.yaml next to the expected prompt <name>.golden.
+type promptCase struct {
+	// Description says what the case covers
+	Description string `yaml:"description"`
+	// Config is applied on top of DefaultConfig
+	Config FileConfig `yaml:"config"`
+	// Changes are the staged files
+	Changes []struct {
+		Path       string `yaml:"path"`
+		ChangeType string `yaml:"change_type"`
+		Diff       string `yaml:"diff"`
+		// Repeat repeats Diff to build huge changes without huge files
+		Repeat int `yaml:"repeat"`
+	} `yaml:"changes"`
+}
+
+// TestPromptCorpus renders the prompt of every corpus case and compares it
+// with its golden file. Add a case by dropping a YAML file into
+// testdata/prompts and running: go test -run TestPromptCorpus -update
+func TestPromptCorpus(t *testing.T) {
+	cases, err := filepath.Glob(filepath.Join("testdata", "prompts", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("No prompt corpus cases found")
+	}
+
+	for _, path := range cases {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			prompt := renderCorpusCase(t, path)
+			golden := strings.TrimSuffix(path, ".yaml") + ".golden"
+
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(prompt), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file (run with -update to create it): %v", err)
+			}
+			if prompt != string(want) {
+				t.Errorf("Prompt differs from %s (run with -update if the change is intended):\n%s",
+					golden, firstDifference(string(want), prompt))
+			}
+		})
+	}
+}
+
+// renderCorpusCase builds the prompt for one corpus file
+func renderCorpusCase(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pc promptCase
+	if err := yaml.Unmarshal(data, &pc); err != nil {
+		t.Fatalf("Invalid corpus case: %v", err)
+	}
+
+	config := DefaultConfig()
+	// The corpus is synthetic; examples from this repository's history
+	// would change with every commit
+	config.HistoryExamples = 0
+	pc.Config.Apply(config)
+	gc := New(config)
+
+	var changes []FileChange
+	for _, c := range pc.Changes {
+		diff := c.Diff
+		if c.Repeat > 1 {
+			diff = strings.Repeat(diff, c.Repeat)
+		}
+		added, removed := gc.countDiffLines(diff)
+		changes = append(changes, FileChange{
+			FilePath:     c.Path,
+			ChangeType:   c.ChangeType,
+			Diff:         diff,
+			LinesAdded:   added,
+			LinesRemoved: removed,
+		})
+	}
+
+	return gc.buildPrompt(gc.buildChangeContext(changes), changes)
+}
+
+// firstDifference shows the first differing line of two prompts
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return "line " + strconv.Itoa(i+1) + ":\n  want: " + w + "\n  got:  " + g
+		}
+	}
+	return ""
+}
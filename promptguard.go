@@ -0,0 +1,102 @@
+package gitcommenter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// promptFileLabel matches the lines that name the file of the diff below
+// them in prompts built from hunks, e.g. "HUNK 2 (api.go, modified):" or
+// "DIFF of api.go (modified):"
+var promptFileLabel = regexp.MustCompile(`^(?:HUNK \d+ \((.+), \w+\)|DIFF of (.+) \(\w+\)):$`)
+
+// diffMetaPrefixes start the lines of a git diff header
+var diffMetaPrefixes = []string{
+	"diff --git ", "index ", "--- ", "+++ ", "new file mode ", "deleted file mode ", "old mode ", "new mode ",
+	"similarity index ", "dissimilarity index ", "rename from ", "rename to ", "copy from ", "copy to ", "Binary files ",
+}
+
+// isDiffLine reports whether a prompt line belongs to a diff: a header
+// line or a hunk line
+func isDiffLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	switch line[0] {
+	case '+', '-', ' ', '@', '\\':
+		return true
+	}
+	for _, prefix := range diffMetaPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptFiles splits a prompt into the diffs it shows, each as a change
+// of the file it belongs to, and the text around them as changes without
+// a path. Joining the diffs in order gives the prompt back. A diff starts
+// at a "diff --git" line, or after a line naming its file (see
+// promptFileLabel), and ends at the first line that is not part of it.
+func promptFiles(prompt string) []FileChange {
+	var parts []FileChange
+	add := func(path, line string) {
+		if len(parts) == 0 || parts[len(parts)-1].FilePath != path || path != "" && strings.HasPrefix(line, "diff --git ") {
+			parts = append(parts, FileChange{FilePath: path})
+		}
+		parts[len(parts)-1].Diff += line
+	}
+
+	path, label, offset := "", "", 0
+	for _, line := range strings.SplitAfter(prompt, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			// The ---/+++ lines name the file, e.g. after a rename
+			header := line
+			for _, next := range strings.SplitAfter(prompt[offset+len(line):], "\n") {
+				if strings.HasPrefix(next, "@@") || strings.HasPrefix(next, "diff --git ") || !isDiffLine(strings.TrimSuffix(next, "\n")) {
+					break
+				}
+				header += next
+			}
+			path = fileDiffPath(header)
+		case path != "" && isDiffLine(text):
+		case label != "" && isDiffLine(text):
+			path = label
+		default:
+			path = ""
+		}
+		label = ""
+		if match := promptFileLabel.FindStringSubmatch(text); match != nil && path == "" {
+			label = match[1] + match[2]
+		}
+		add(path, line)
+		offset += len(line)
+	}
+	return parts
+}
+
+// guardPrompt prepares text on its way to the model, whichever feature
+// built it: every request sending repository content to Ollama passes
+// through it. Config.Redact is applied to the diffs it shows, path rules
+// to the diffs of their files, and in SecretScanBlock mode the added lines
+// left must not look like secrets (see CheckSecrets).
+func (gc *GitCommenter) guardPrompt(text string) (string, error) {
+	if len(gc.config.Redact) == 0 && gc.config.SecretScan == SecretScanOff {
+		return text, nil
+	}
+	parts, err := gc.RedactChanges(promptFiles(text))
+	if err != nil {
+		return "", err
+	}
+	if _, err := gc.CheckSecrets(parts); err != nil {
+		return "", err
+	}
+	var guarded strings.Builder
+	for _, part := range parts {
+		guarded.WriteString(part.Diff)
+	}
+	return guarded.String(), nil
+}
@@ -0,0 +1,75 @@
+package gitcommenter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Provenance trailers record that a message was generated, so AI-assisted
+// commits can be audited later
+const (
+	GeneratedByTrailer = "Generated-by"
+	PromptHashTrailer  = "Prompt-hash"
+)
+
+// PromptHash returns the identifier of a prompt used in the Prompt-hash
+// trailer
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// AddProvenance appends a "Generated-by: <tool> (model=<model>)" trailer
+// and, when known, the hash of the prompt. Template messages record the
+// template instead of the model.
+func (s *CommitSuggestion) AddProvenance(tool, model string) {
+	if s.Template != "" {
+		s.AddTrailer(GeneratedByTrailer, fmt.Sprintf("%s (template=%s)", tool, s.Template))
+		return
+	}
+	s.AddTrailer(GeneratedByTrailer, fmt.Sprintf("%s (model=%s)", tool, model))
+	if s.PromptHash != "" {
+		s.AddTrailer(PromptHashTrailer, s.PromptHash)
+	}
+}
+
+// Provenance is what the provenance trailers of a commit message record
+type Provenance struct {
+	Tool       string
+	Model      string
+	PromptHash string
+	// Template is set instead of Model for template messages
+	Template string
+}
+
+var generatedBy = regexp.MustCompile(`^(.*?)(?: \((model|template)=(.*)\))?$`)
+
+// ParseProvenance reads the provenance trailers of a commit message. It
+// returns false for messages without a Generated-by trailer.
+func ParseProvenance(message string) (Provenance, bool) {
+	var provenance Provenance
+	found := false
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case GeneratedByTrailer:
+			match := generatedBy.FindStringSubmatch(value)
+			provenance.Tool = match[1]
+			if match[2] == "template" {
+				provenance.Template = match[3]
+			} else {
+				provenance.Model = match[3]
+			}
+			found = true
+		case PromptHashTrailer:
+			provenance.PromptHash = value
+		}
+	}
+	return provenance, found
+}
@@ -0,0 +1,53 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAddProvenance(t *testing.T) {
+	suggestion := &CommitSuggestion{Subject: "feat: add x", Body: "- add x", PromptHash: PromptHash("prompt")}
+	suggestion.AddProvenance("ai-git-auto v1.0.0", "llama2")
+
+	lines := strings.Split(suggestion.Body, "\n")
+	if len(lines) != 4 || lines[2] != "Generated-by: ai-git-auto v1.0.0 (model=llama2)" || !strings.HasPrefix(lines[3], "Prompt-hash: sha256:") {
+		t.Fatalf("Unexpected body:\n%s", suggestion.Body)
+	}
+
+	provenance, ok := ParseProvenance(suggestion.Message())
+	if !ok || provenance.Tool != "ai-git-auto v1.0.0" || provenance.Model != "llama2" || provenance.PromptHash != PromptHash("prompt") {
+		t.Errorf("Unexpected provenance %+v (ok %v)", provenance, ok)
+	}
+	template := &CommitSuggestion{Subject: "docs: fix typo in README.md", Template: TemplateTypo}
+	template.AddProvenance("ai-git-auto v1.0.0", "llama2")
+	if provenance, _ := ParseProvenance(template.Message()); provenance.Template != TemplateTypo || provenance.Model != "" {
+		t.Errorf("Expected the template instead of the model, got %+v", provenance)
+	}
+	if _, ok := ParseProvenance("fix: hand-written\n\nSigned-off-by: A <a@example.com>"); ok {
+		t.Error("Expected no provenance in a hand-written message")
+	}
+}
+
+func TestGeneratedSuggestionHasPromptHash(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add init wizard", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	suggestion, err := New(config).GenerateCommitMessage([]FileChange{{FilePath: "init.go", ChangeType: "added", Diff: "+package main"}})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if suggestion.PromptHash != PromptHash(prompt) {
+		t.Errorf("Expected the hash of the prompt sent, got %q", suggestion.PromptHash)
+	}
+}
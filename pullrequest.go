@@ -0,0 +1,223 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sectionedDescription asks for a description with Summary, Changes and
+// Testing sections
+const sectionedDescription = "a markdown description with exactly these sections:\n" +
+	"   ## Summary: what the branch changes and why, in two or three sentences\n" +
+	"   ## Changes: a bullet list of the notable changes\n" +
+	"   ## Testing: the tests added or changed in the diff, or what reviewers should check by hand when there are none"
+
+// PullRequestDraft is a generated pull request for a branch
+type PullRequestDraft struct {
+	// Base is the branch merged into, Head the branch with the changes
+	Base string
+	Head string
+	// Title and Body (markdown) are generated
+	Title string
+	Body  string
+	// Commits are the commits on Head missing from Base, oldest first
+	Commits []Commit
+	// Number and URL are set by CreatePullRequest
+	Number int
+	URL    string
+}
+
+// DefaultBranch returns the branch origin/HEAD points at, or main or
+// master when the clone does not know it
+func (gc *GitCommenter) DefaultBranch() (string, error) {
+	if ref, err := gc.gitOutput("symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(ref), "origin/"), nil
+	}
+	for _, branch := range []string{"main", "master"} {
+		for _, ref := range []string{"refs/remotes/origin/" + branch, "refs/heads/" + branch} {
+			if _, err := gc.gitOutput("rev-parse", "--verify", "--quiet", ref); err == nil {
+				return branch, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to find the default branch; name the base branch")
+}
+
+// baseRef prefers origin/<branch> to the local branch, which may be behind
+// or carry unpushed commits
+func (gc *GitCommenter) baseRef(branch string) string {
+	if _, err := gc.gitOutput("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch); err == nil {
+		return "origin/" + branch
+	}
+	return branch
+}
+
+// BranchChanges returns the files changed on head since it forked from
+// base, with their diffs, as ScanStagedChanges does for the index
+func (gc *GitCommenter) BranchChanges(base, head string) ([]FileChange, error) {
+	return gc.diffChanges(base + "..." + head)
+}
+
+// ScanRefRange returns the files changed from one ref to another, e.g. two
+// deployed tags or the commits to backport, with their diffs, as
+// ScanStagedChanges does for the index
+func (gc *GitCommenter) ScanRefRange(from, to string) ([]FileChange, error) {
+	for _, ref := range []string{from, to} {
+		if ref == "" || strings.HasPrefix(ref, "-") {
+			return nil, fmt.Errorf("invalid ref %q", ref)
+		}
+		if _, err := gc.gitOutput("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("unknown ref %q", ref)
+		}
+	}
+	return gc.diffChanges(from, to)
+}
+
+// diffChanges returns the files changed between revisions given as git diff
+// arguments, e.g. "main...topic" or a tree and a commit, with their diffs
+// and the version they were made from as Base
+func (gc *GitCommenter) diffChanges(revisions ...string) ([]FileChange, error) {
+	revRange := strings.Join(revisions, " ")
+	base := IndexBase
+	if len(revisions) > 0 {
+		base = revisions[0]
+	}
+	// "a...b" diffs from where b forked from a
+	if from, to, ok := strings.Cut(base, "..."); ok {
+		forked, err := gc.gitOutput("merge-base", from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find where %s forked from %s: %w", to, from, err)
+		}
+		base = strings.TrimSpace(forked)
+	}
+	output, err := gc.gitOutput(append([]string{"diff", "--find-renames", "--name-status", "-z"}, revisions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed in %s: %w", revRange, err)
+	}
+	counts, err := gc.numstat(append([]string{"diff", "--find-renames"}, revisions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lines changed in %s: %w", revRange, err)
+	}
+
+	var changes []FileChange
+	for _, file := range parseNameStatus(output) {
+		args := append(append([]string{"diff", "--find-renames"}, revisions...), "--")
+		diff, err := gc.gitOutput(append(args, file.paths()...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff of %s in %s: %w", file.Path, revRange, err)
+		}
+		changes = append(changes, FileChange{
+			FilePath:     file.Path,
+			OldPath:      file.OldPath,
+			ChangeType:   gc.parseChangeType(file.Status),
+			Diff:         diff,
+			LinesAdded:   counts[file.Path].LinesAdded,
+			LinesRemoved: counts[file.Path].LinesRemoved,
+			Base:         base,
+		})
+	}
+	return gc.withoutIgnored(changes)
+}
+
+// GeneratePullRequest generates the title and description of a pull
+// request merging head into base from head's commits and the cumulative
+// diff. An empty base uses DefaultBranch.
+func (gc *GitCommenter) GeneratePullRequest(base, head string) (*PullRequestDraft, error) {
+	return gc.generateBranchRequest(base, head, "GitHub", "pull request",
+		"a markdown description: what the branch changes and why, followed by a bullet list of the notable changes")
+}
+
+// GeneratePRDescription generates a pull request description for merging
+// head into base, with Summary, Changes and Testing sections, from head's
+// commits and the cumulative diff. An empty base uses DefaultBranch.
+func (gc *GitCommenter) GeneratePRDescription(base, head string) (string, error) {
+	draft, err := gc.generateBranchRequest(base, head, "GitHub", "pull request", sectionedDescription)
+	if err != nil {
+		return "", err
+	}
+	return draft.Body, nil
+}
+
+// generateBranchRequest generates a pull or merge request (request) on
+// forge, asking for the description as described
+func (gc *GitCommenter) generateBranchRequest(base, head, forge, request, description string) (*PullRequestDraft, error) {
+	if base == "" {
+		var err error
+		if base, err = gc.DefaultBranch(); err != nil {
+			return nil, err
+		}
+	}
+	if head == base {
+		return nil, fmt.Errorf("%s is the base branch; open %ss from another branch", head, request)
+	}
+
+	ref := gc.baseRef(base)
+	commits, err := gc.Commits(ref + ".." + head)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("%s has no commits missing from %s", head, ref)
+	}
+	changes, err := gc.BranchChanges(ref, head)
+	if err != nil {
+		return nil, err
+	}
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing a " + forge + " " + request + " for a branch, for reviewers who have not seen it.\n\n")
+	prompt.WriteString("COMMITS ON THE BRANCH:\n")
+	for _, commit := range commits {
+		prompt.WriteString("- " + commit.Subject + "\n")
+		if commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(commit.Body, 500), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\n")
+	prompt.WriteString(gc.buildChangeContext(changes))
+	prompt.WriteString(formatDiffs(changes, gc.PromptBudget()))
+	prompt.WriteString(gc.summaryContext(changes, gc.PromptBudget()))
+
+	prompt.WriteString("Write the " + request + ":\n")
+	prompt.WriteString("1. A title on the first line summarizing the whole branch. " + style.Format + "\n")
+	prompt.WriteString("2. A blank line, then " + description + "\n")
+	prompt.WriteString("3. Do not list every commit or mention fixups such as 'address review comments'\n")
+	if language := gc.language(); language != "en" {
+		prompt.WriteString("4. Write in " + languageName(language) + "\n")
+	}
+	prompt.WriteString("\nRespond with only the title and the description, no additional text.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s: %w", request, err)
+	}
+
+	title, body := splitTitle(response)
+	title = strings.TrimSpace(strings.TrimPrefix(title, "Title:"))
+	return &PullRequestDraft{Base: base, Head: head, Title: title, Body: body, Commits: commits}, nil
+}
+
+// CreatePullRequest opens draft on GitHub and sets its Number and URL.
+// repo is "owner/repo" (see GitHubRepository); head must have been pushed.
+func (gc *GitCommenter) CreatePullRequest(repo string, draft *PullRequestDraft) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+
+	request := map[string]string{"title": draft.Title, "body": draft.Body, "base": draft.Base, "head": draft.Head}
+	var created struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := gc.githubPost("/repos/"+repo+"/pulls", request, &created); err != nil {
+		return fmt.Errorf("failed to create pull request: %w", err)
+	}
+	draft.Number, draft.URL = created.Number, created.HTMLURL
+	return nil
+}
@@ -0,0 +1,151 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAndCreatePullRequest(t *testing.T) {
+	dir := initTestRepo(t)
+	writeFile(t, dir, "README.md", "# app\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "initial commit")
+	gitRun(t, dir, "branch", "-M", "main")
+	gitRun(t, dir, "checkout", "-q", "-b", "feature/limits")
+	writeFile(t, dir, "limiter.go", "package app\n\nfunc Limit() {}\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "feat: add limiter", "-m", "Limits requests per client.")
+
+	var prompt string
+	var created map[string]string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/generate":
+			var req OllamaRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			prompt = req.Prompt
+			json.NewEncoder(w).Encode(OllamaResponse{Response: "Title: feat: add rate limiting\n\n## Summary\nLimits requests per client.\n\n- add `Limit`", Done: true})
+		case "/github/repos/acme/app/pulls":
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{"number": 7, "html_url": "https://github.com/acme/app/pull/7"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.GitHubAPI = server.URL + "/github"
+	gc := New(config)
+
+	if branch, err := gc.DefaultBranch(); err != nil || branch != "main" {
+		t.Fatalf("Expected main as the default branch, got %q, %v", branch, err)
+	}
+
+	draft, err := gc.GeneratePullRequest("", "feature/limits")
+	if err != nil {
+		t.Fatalf("GeneratePullRequest returned error: %v", err)
+	}
+	if draft.Base != "main" || draft.Title != "feat: add rate limiting" || !strings.HasPrefix(draft.Body, "## Summary") {
+		t.Errorf("Unexpected draft %+v", draft)
+	}
+	if len(draft.Commits) != 1 {
+		t.Errorf("Expected the feature commit only, got %+v", draft.Commits)
+	}
+	for _, part := range []string{"- feat: add limiter", "Limits requests per client.", "limiter.go"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected %q in the prompt:\n%s", part, prompt)
+		}
+	}
+	if strings.Contains(prompt, "README.md") {
+		t.Errorf("Expected only the branch's changes in the prompt:\n%s", prompt)
+	}
+
+	description, err := gc.GeneratePRDescription("main", "feature/limits")
+	if err != nil {
+		t.Fatalf("GeneratePRDescription returned error: %v", err)
+	}
+	if description != draft.Body {
+		t.Errorf("Expected only the description, got %q", description)
+	}
+	for _, section := range []string{"## Summary", "## Changes", "## Testing"} {
+		if !strings.Contains(prompt, section) {
+			t.Errorf("Expected the %s section in the prompt:\n%s", section, prompt)
+		}
+	}
+
+	t.Setenv("GITHUB_TOKEN", "secret")
+	if err := gc.CreatePullRequest("acme/app", draft); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if draft.Number != 7 || draft.URL != "https://github.com/acme/app/pull/7" {
+		t.Errorf("Unexpected pull request %d %s", draft.Number, draft.URL)
+	}
+	if auth != "Bearer secret" || created["head"] != "feature/limits" || created["base"] != "main" || created["title"] != draft.Title {
+		t.Errorf("Unexpected request %v with %q", created, auth)
+	}
+
+	if _, err := gc.GeneratePullRequest("main", "main"); err == nil {
+		t.Error("Expected an error for a pull request from the base branch")
+	}
+}
+
+func TestScanRefRange(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, file := range []string{"a.go", "b.go", "c.go"} {
+		writeFile(t, dir, file, "package app\n")
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", "add "+file)
+		gitRun(t, dir, "tag", fmt.Sprintf("v%d", i+1))
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	changes, err := gc.ScanRefRange("v1", "v3")
+	if err != nil {
+		t.Fatalf("ScanRefRange returned error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].FilePath != "b.go" || changes[1].FilePath != "c.go" || changes[1].LinesAdded != 1 {
+		t.Errorf("Expected b.go and c.go, got %+v", changes)
+	}
+	if changes, err := gc.ScanRefRange("v3", "v1"); err != nil || len(changes) != 2 || changes[0].ChangeType != "deleted" {
+		t.Errorf("Expected the reverse range to delete the files, got %+v, %v", changes, err)
+	}
+
+	for _, refs := range [][2]string{{"v1", "v9"}, {"--output=x", "v1"}, {"", "v1"}} {
+		if _, err := gc.ScanRefRange(refs[0], refs[1]); err == nil {
+			t.Errorf("Expected an error for %q..%q", refs[0], refs[1])
+		}
+	}
+
+	// The API and symbol sections compare the refs, not HEAD and the index
+	for i, source := range []string{"package lib\n\nfunc Old() {}\n", "package lib\n\nfunc New() {}\n"} {
+		writeFile(t, dir, "lib/lib.go", source)
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", "lib")
+		gitRun(t, dir, "tag", fmt.Sprintf("lib%d", i+1))
+	}
+	writeFile(t, dir, "lib/lib.go", "package lib\n\nfunc Staged() {}\n")
+	gitRun(t, dir, "add", ".")
+	changes, err = gc.ScanRefRange("lib1", "lib2")
+	if err != nil {
+		t.Fatalf("ScanRefRange returned error: %v", err)
+	}
+	api, err := gc.apiChanges(changes)
+	if err != nil || len(api) != 2 || api[0].String() != "lib: added func New()" || api[1].String() != "lib: removed func Old()" {
+		t.Errorf("Expected the API changes between the refs, got %v, %v", api, err)
+	}
+	if symbols := gc.StagedSymbolChanges(changes); len(symbols) != 2 || symbols[0].String() != "added func New()" {
+		t.Errorf("Expected the symbol changes between the refs, got %v", symbols)
+	}
+}
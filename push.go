@@ -0,0 +1,46 @@
+package gitcommenter
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// PushResult is the outcome of pushing to one remote
+type PushResult struct {
+	Remote string
+	// Output is what git push printed, stdout and stderr combined
+	Output string
+	Err    error
+}
+
+// PushToRemotes runs "git push <remote> <args...>" for every remote in
+// parallel, e.g. to push to origin and a mirror at once. Output is
+// collected rather than streamed so the pushes do not interleave. The
+// results are in the order of remotes.
+func (gc *GitCommenter) PushToRemotes(remotes []string, args ...string) []PushResult {
+	results := make([]PushResult, len(remotes))
+	if gc.config.ReadOnly {
+		for i, remote := range remotes {
+			results[i] = PushResult{Remote: remote, Err: ErrReadOnly}
+		}
+		return results
+	}
+	var wg sync.WaitGroup
+	for i, remote := range remotes {
+		wg.Add(1)
+		go func(i int, remote string) {
+			defer wg.Done()
+			cmd := exec.Command("git", append([]string{"push", remote}, args...)...)
+			cmd.Dir = gc.config.RepositoryPath
+			output, err := cmd.CombinedOutput()
+			results[i] = PushResult{Remote: remote, Output: strings.TrimSpace(string(output)), Err: err}
+		}(i, remote)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		gc.RecordEvent(Event{Type: EventPushed, Remote: result.Remote, Error: errorText(result.Err)})
+	}
+	return results
+}
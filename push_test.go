@@ -0,0 +1,43 @@
+package gitcommenter
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPushToRemotes(t *testing.T) {
+	dir := initTestRepo(t)
+
+	origin, mirror := t.TempDir(), t.TempDir()
+	gitRun(t, origin, "init", "-q", "--bare")
+	gitRun(t, mirror, "init", "-q", "--bare")
+	writeFile(t, dir, "a.txt", "a\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+	gitRun(t, dir, "remote", "add", "origin", origin)
+	gitRun(t, dir, "remote", "add", "mirror", mirror)
+	gitRun(t, dir, "remote", "add", "broken", filepath.Join(t.TempDir(), "missing"))
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	results := New(config).PushToRemotes([]string{"origin", "broken", "mirror"}, "HEAD:refs/heads/main")
+
+	if len(results) != 3 || results[0].Remote != "origin" || results[1].Remote != "broken" || results[2].Remote != "mirror" {
+		t.Fatalf("Expected results in remote order, got %+v", results)
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected origin and mirror to succeed, got %+v", results)
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Output, "missing") {
+		t.Errorf("Expected the broken remote to fail with git's message, got %+v", results[1])
+	}
+	for _, bare := range []string{origin, mirror} {
+		cmd := exec.Command("git", "rev-parse", "--verify", "refs/heads/main")
+		cmd.Dir = bare
+		if err := cmd.Run(); err != nil {
+			t.Errorf("Expected main in %s: %v", bare, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MessageGrade is the model's assessment of how informative a commit
+// message is relative to its diff
+type MessageGrade struct {
+	// Score ranges from 1 (vague) to 5 (specific and accurate)
+	Score int
+	// Reason is a short justification for the score
+	Reason string
+}
+
+// maxGradeDiffLength bounds how much of the diff is shown to the grader
+const maxGradeDiffLength = 4000
+
+// GradeCommitMessage asks the model to rate the informativeness of message
+// against the diff it describes
+func (gc *GitCommenter) GradeCommitMessage(message, diff string) (*MessageGrade, error) {
+	if len(diff) > maxGradeDiffLength {
+		diff = truncateUTF8(diff, maxGradeDiffLength) + "\n... (truncated)"
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are reviewing Git commit messages for informativeness.\n\n")
+	prompt.WriteString("COMMIT MESSAGE:\n")
+	prompt.WriteString(message)
+	prompt.WriteString("\n\nDIFF:\n")
+	prompt.WriteString(diff)
+	prompt.WriteString("\n\nRate how well the message describes WHAT changed and WHY on a scale of 1 to 5:\n")
+	prompt.WriteString("1 = vague or misleading (e.g. 'fix bugs', 'update files')\n")
+	prompt.WriteString("3 = understandable but generic\n")
+	prompt.WriteString("5 = specific, accurate and names the affected functionality\n\n")
+	prompt.WriteString("Respond with exactly two lines:\nSCORE: <1-5>\nREASON: <one sentence>")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to grade commit message: %w", err)
+	}
+
+	return parseMessageGrade(response)
+}
+
+var gradeScorePattern = regexp.MustCompile(`(?i)score\s*[:=]\s*([1-5])`)
+
+// parseMessageGrade extracts the score and reason from a grader response
+func parseMessageGrade(response string) (*MessageGrade, error) {
+	match := gradeScorePattern.FindStringSubmatch(response)
+	if match == nil {
+		return nil, fmt.Errorf("could not find a score in grader response: %q", response)
+	}
+
+	score, _ := strconv.Atoi(match[1])
+	grade := &MessageGrade{Score: score}
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 7 && strings.EqualFold(line[:7], "reason:") {
+			grade.Reason = strings.TrimSpace(line[7:])
+			break
+		}
+	}
+
+	return grade, nil
+}
@@ -0,0 +1,59 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseMessageGrade(t *testing.T) {
+	grade, err := parseMessageGrade("SCORE: 2\nREASON: Does not say which bug was fixed.")
+	if err != nil {
+		t.Fatalf("parseMessageGrade returned error: %v", err)
+	}
+
+	if grade.Score != 2 {
+		t.Errorf("Expected score 2, got %d", grade.Score)
+	}
+
+	if grade.Reason != "Does not say which bug was fixed." {
+		t.Errorf("Unexpected reason %q", grade.Reason)
+	}
+
+	// Models sometimes add chatter around the answer
+	grade, err = parseMessageGrade("Sure! score = 5\nreason: names the new flag")
+	if err != nil || grade.Score != 5 || grade.Reason != "names the new flag" {
+		t.Errorf("Unexpected grade %+v (err %v)", grade, err)
+	}
+
+	if _, err := parseMessageGrade("I cannot rate this"); err == nil {
+		t.Error("Expected an error when no score is present")
+	}
+}
+
+func TestGradeCommitMessageTruncatesOnRuneBoundary(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "SCORE: 4\nREASON: Specific.", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	// The limit falls inside a three-byte rune; JSON would turn a cut
+	// rune into U+FFFD
+	diff := "+" + strings.Repeat("é", maxGradeDiffLength/2-1) + "€€"
+	if _, err := gc.GradeCommitMessage("docs: translate the guide", diff); err != nil {
+		t.Fatalf("GradeCommitMessage returned error: %v", err)
+	}
+	if strings.ContainsRune(prompt, '\uFFFD') || !strings.Contains(prompt, "é\n... (truncated)") {
+		t.Errorf("Expected the diff cut before the split rune, got %q", prompt)
+	}
+}
@@ -0,0 +1,76 @@
+package gitcommenter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrReadOnly is returned for actions that would modify a repository opened
+// with Config.ReadOnly
+var ErrReadOnly = errors.New("the repository is read-only (analyze-only mode)")
+
+// readOnlyGitOptions go before the subcommand of every read-only git
+// command. They keep git from taking locks or refreshing the index, and
+// from running programs the repository configures: fsmonitor, hooks,
+// pagers and remote transports.
+var readOnlyGitOptions = []string{
+	"--no-optional-locks",
+	"-c", "core.fsmonitor=false",
+	"-c", "core.hooksPath=/dev/null",
+	"-c", "core.pager=cat",
+	"-c", "protocol.allow=never",
+}
+
+// ReadOnlyGitArgs sandboxes the arguments of a git command run in a
+// repository that must not be modified, e.g. an untrusted one analyzed by
+// a server. Commands that may write to the repository return ErrReadOnly.
+func ReadOnlyGitArgs(args []string) ([]string, error) {
+	if len(args) == 0 || !readsOnly(args) {
+		return nil, fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrReadOnly)
+	}
+
+	sandboxed := append(append([]string{}, readOnlyGitOptions...), args[0])
+	switch args[0] {
+	case "diff", "log", "show":
+		// External diff drivers and textconv filters are configured programs
+		sandboxed = append(sandboxed, "--no-ext-diff", "--no-textconv")
+	}
+	return append(sandboxed, args[1:]...), nil
+}
+
+// readsOnly reports whether a git command only reads the repository
+func readsOnly(args []string) bool {
+	switch args[0] {
+	case "cat-file", "check-attr", "describe", "diff", "diff-tree", "for-each-ref", "log", "ls-files", "ls-tree",
+		"merge-base", "rev-list", "rev-parse", "show":
+		return true
+	case "config":
+		// Lookups only: --get, --get-all, --get-regexp and --list
+		return len(args) > 1 && (strings.HasPrefix(args[1], "--get") || args[1] == "--list" || args[1] == "-l")
+	case "remote":
+		// Listing remotes, not adding them or contacting them
+		return len(args) == 1 || args[1] == "-v" || args[1] == "get-url"
+	case "symbolic-ref":
+		// Reading a ref names it; setting one names the target too
+		names := 0
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "-d" || arg == "--delete":
+				return false
+			case !strings.HasPrefix(arg, "-"):
+				names++
+			}
+		}
+		return names <= 1
+	}
+	return false
+}
+
+// checkWritable returns ErrReadOnly for a read-only repository
+func (gc *GitCommenter) checkWritable() error {
+	if gc.config.ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
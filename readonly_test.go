@@ -0,0 +1,92 @@
+package gitcommenter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyGitArgs(t *testing.T) {
+	allowed := [][]string{
+		{"diff", "--cached", "--name-status"},
+		{"log", "-5", "--format=%s"},
+		{"rev-parse", "--show-toplevel"},
+		{"config", "--get", "branch.main.ai"},
+		{"remote", "get-url", "origin"},
+		{"symbolic-ref", "--short", "-q", "HEAD"},
+	}
+	for _, args := range allowed {
+		sandboxed, err := ReadOnlyGitArgs(args)
+		if err != nil {
+			t.Errorf("Expected git %v to be allowed, got %v", args, err)
+			continue
+		}
+		if sandboxed[0] != "--no-optional-locks" || sandboxed[len(sandboxed)-1] != args[len(args)-1] {
+			t.Errorf("Unexpected sandboxed arguments %v", sandboxed)
+		}
+	}
+
+	refused := [][]string{
+		{"add", "."},
+		{"commit", "-m", "x"},
+		{"push"},
+		{"config", "user.name", "x"},
+		{"remote", "add", "evil", "https://example.com"},
+		{"symbolic-ref", "HEAD", "refs/heads/other"},
+		{"symbolic-ref", "-d", "HEAD"},
+		{},
+	}
+	for _, args := range refused {
+		if _, err := ReadOnlyGitArgs(args); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected git %v to be refused, got %v", args, err)
+		}
+	}
+
+	diff, _ := ReadOnlyGitArgs([]string{"diff", "--cached"})
+	if !strings.Contains(strings.Join(diff, " "), "diff --no-ext-diff --no-textconv --cached") {
+		t.Errorf("Expected external diff programs to be off, got %v", diff)
+	}
+}
+
+func TestReadOnlyRepository(t *testing.T) {
+	dir := initTestRepo(t)
+	// An untrusted repository may configure programs git runs for diffs
+	marker := filepath.Join(t.TempDir(), "ran")
+	gitRun(t, dir, "config", "diff.external", "touch "+marker+"; true")
+	writeFile(t, dir, "main.go", "package main\n")
+	gitRun(t, dir, "add", "main.go")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.ReadOnly = true
+	gc := New(config)
+
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || !strings.Contains(changes[0].Diff, "+package main") {
+		t.Fatalf("Expected the staged diff, got %+v", changes)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Expected the configured external diff not to run")
+	}
+
+	if err := gc.Commit(&CommitSuggestion{Subject: "feat: add main"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Commit to be refused, got %v", err)
+	}
+	if err := gc.Push(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Push to be refused, got %v", err)
+	}
+	if err := gc.Restage([]string{"main.go"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Restage to be refused, got %v", err)
+	}
+	if results := gc.PushToRemotes([]string{"origin"}); !errors.Is(results[0].Err, ErrReadOnly) {
+		t.Errorf("Expected PushToRemotes to be refused, got %+v", results)
+	}
+	if head, _ := gc.gitOutput("rev-parse", "--verify", "-q", "HEAD"); head != "" {
+		t.Errorf("Expected no commit, got HEAD %s", head)
+	}
+}
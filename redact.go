@@ -0,0 +1,88 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultRedactionPlaceholder replaces redacted text when a rule names no
+// placeholder
+const DefaultRedactionPlaceholder = "[REDACTED]"
+
+// RedactionRule masks part of the diffs before they are put into the
+// prompt; the commit keeps the real content
+type RedactionRule struct {
+	// Pattern is a regular expression whose matches are replaced, e.g. an
+	// email address or an internal host name
+	Pattern string `yaml:"pattern,omitempty"`
+	// Path limits the rule to files matching a glob as accepted by
+	// MatchesAny, e.g. "secrets/**". Without Pattern, the whole diff of
+	// those files is replaced.
+	Path string `yaml:"path,omitempty"`
+	// Placeholder replaces what the rule matches (default
+	// DefaultRedactionPlaceholder)
+	Placeholder string `yaml:"placeholder,omitempty"`
+}
+
+// redaction is a RedactionRule with its pattern compiled
+type redaction struct {
+	RedactionRule
+	pattern *regexp.Regexp
+}
+
+// compileRedactions checks and compiles the rules
+func compileRedactions(rules []RedactionRule) ([]redaction, error) {
+	redactions := make([]redaction, len(rules))
+	for i, rule := range rules {
+		if rule.Pattern == "" && rule.Path == "" {
+			return nil, fmt.Errorf("invalid redaction rule %d: it needs a pattern or a path", i+1)
+		}
+		if rule.Placeholder == "" {
+			rule.Placeholder = DefaultRedactionPlaceholder
+		}
+		redactions[i].RedactionRule = rule
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redaction pattern %q: %w", rule.Pattern, err)
+			}
+			redactions[i].pattern = pattern
+		}
+	}
+	return redactions, nil
+}
+
+// ValidateRedactionRules reports the first rule that is missing both a
+// pattern and a path or whose pattern does not compile
+func ValidateRedactionRules(rules []RedactionRule) error {
+	_, err := compileRedactions(rules)
+	return err
+}
+
+// RedactChanges returns copies of the changes with Config.Redact applied
+// to their diffs, in rule order. The changes passed in are not modified.
+func (gc *GitCommenter) RedactChanges(changes []FileChange) ([]FileChange, error) {
+	if len(gc.config.Redact) == 0 {
+		return changes, nil
+	}
+	redactions, err := compileRedactions(gc.config.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make([]FileChange, len(changes))
+	for i, change := range changes {
+		for _, rule := range redactions {
+			if rule.Path != "" && !MatchesAny(NormalizePath(change.FilePath), []string{rule.Path}) {
+				continue
+			}
+			if rule.pattern == nil {
+				change.Diff = rule.Placeholder + "\n"
+				continue
+			}
+			change.Diff = rule.pattern.ReplaceAllLiteralString(change.Diff, rule.Placeholder)
+		}
+		redacted[i] = change
+	}
+	return redacted, nil
+}
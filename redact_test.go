@@ -0,0 +1,94 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactChanges(t *testing.T) {
+	config := DefaultConfig()
+	config.Redact = []RedactionRule{
+		{Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`, Placeholder: "<email>"},
+		{Pattern: `\b[\w-]+\.corp\.example\.com\b`},
+		{Path: "secrets/**"},
+	}
+	gc := New(config)
+
+	changes := []FileChange{
+		{FilePath: "app/config.go", Diff: "@@ -1 +1,2 @@\n-const owner = \"jane@example.org\"\n+const owner = \"ops@example.org\"\n+const host = \"db-1.corp.example.com\"\n"},
+		{FilePath: "secrets/prod.env", Diff: "@@ -0,0 +1 @@\n+DB_PASSWORD=hunter2\n"},
+	}
+	redacted, err := gc.RedactChanges(changes)
+	if err != nil {
+		t.Fatalf("RedactChanges returned error: %v", err)
+	}
+
+	want := "@@ -1 +1,2 @@\n-const owner = \"<email>\"\n+const owner = \"<email>\"\n+const host = \"[REDACTED]\"\n"
+	if redacted[0].Diff != want {
+		t.Errorf("Unexpected redacted diff:\n%s", redacted[0].Diff)
+	}
+	if redacted[1].Diff != "[REDACTED]\n" {
+		t.Errorf("Expected the whole secrets/ diff to be replaced, got %q", redacted[1].Diff)
+	}
+	if !strings.Contains(changes[0].Diff, "ops@example.org") || !strings.Contains(changes[1].Diff, "hunter2") {
+		t.Error("Expected the original changes to be left intact")
+	}
+}
+
+func TestRedactionKeepsCommittedChanges(t *testing.T) {
+	config := DefaultConfig()
+	config.HistoryExamples = 0
+	config.Redact = []RedactionRule{{Pattern: `internal\.example\.net`, Placeholder: "<host>"}}
+	gc := New(config)
+
+	changes := []FileChange{{FilePath: "deploy.yaml", ChangeType: "modified", LinesAdded: 1, Diff: "@@ -1 +1 @@\n+url: https://internal.example.net/api\n"}}
+	plan, err := gc.plan(changes)
+	if err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	if strings.Contains(plan.prompt, "internal.example.net") || !strings.Contains(plan.prompt, "https://<host>/api") {
+		t.Errorf("Expected the host to be redacted in the prompt:\n%s", plan.prompt)
+	}
+	if !strings.Contains(plan.changes[0].Diff, "internal.example.net") {
+		t.Errorf("Expected the plan to keep the real diff, got %q", plan.changes[0].Diff)
+	}
+}
+
+func TestValidateRedactionRules(t *testing.T) {
+	if err := ValidateRedactionRules([]RedactionRule{{Pattern: `\d{3}-\d{4}`}, {Path: "*.pem"}}); err != nil {
+		t.Errorf("Expected valid rules, got %v", err)
+	}
+	if err := ValidateRedactionRules([]RedactionRule{{Pattern: `(unclosed`}}); err == nil {
+		t.Error("Expected an error for a pattern that does not compile")
+	}
+	if err := ValidateRedactionRules([]RedactionRule{{Placeholder: "x"}}); err == nil {
+		t.Error("Expected an error for a rule without pattern or path")
+	}
+}
+
+func TestLoadConfigFileRedact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := "redact:\n  - pattern: '[\\w.]+@example\\.com'\n    placeholder: <email>\n  - path: secrets/**\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatalf("LoadConfigFile returned error: %v", err)
+	}
+	if len(fc.Redact) != 2 || fc.Redact[0].Placeholder != "<email>" || fc.Redact[1].Path != "secrets/**" {
+		t.Fatalf("Unexpected redaction rules: %+v", fc.Redact)
+	}
+
+	// Rules from every layer apply
+	user := &FileConfig{Redact: []RedactionRule{{Pattern: `corp\.internal`}}}
+	user.Merge(fc)
+	config := DefaultConfig()
+	user.Apply(config)
+	if len(config.Redact) != 3 {
+		t.Errorf("Expected the rules of both layers, got %+v", config.Redact)
+	}
+}
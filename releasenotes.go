@@ -0,0 +1,218 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReleaseNotes are the notes for the commits between two revisions
+type ReleaseNotes struct {
+	// From and To are the revisions of the range; From is "" for the
+	// whole history
+	From string
+	To   string
+	// Date is the commit date of the newest commit
+	Date time.Time
+	// Summary is the model's overview of the release
+	Summary string
+	// Breaking lists the breaking changes, which are left out of Sections
+	Breaking []ReleaseEntry
+	// Sections group the other commits by conventional type
+	Sections []ReleaseSection
+}
+
+// ReleaseSection is a group of release note entries, e.g. Features
+type ReleaseSection struct {
+	Title   string
+	Entries []ReleaseEntry
+}
+
+// ReleaseEntry is a commit in release notes
+type ReleaseEntry struct {
+	// Type, Scopes and Description come from the conventional subject;
+	// Type is "" and Description the subject for other commits
+	Type        string
+	Scopes      []string
+	Description string
+	Breaking    bool
+	Commit      Commit
+}
+
+// releaseSections are the sections of release notes in order, with the
+// conventional types they hold; other commits go to "Other Changes"
+var releaseSections = []struct {
+	Title string
+	Types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Refactoring", []string{"refactor"}},
+	{"Documentation", []string{"docs"}},
+	{"Reverts", []string{"revert"}},
+	{"Maintenance", []string{"build", "chore", "ci", "style", "test"}},
+}
+
+// ParseReleaseEntry reads the conventional type, scopes and breaking marker
+// of a commit, ignoring a leading gitmoji. A "BREAKING CHANGE:" footer also
+// marks the commit as breaking.
+func ParseReleaseEntry(commit Commit) ReleaseEntry {
+	entry := ReleaseEntry{Description: commit.Subject, Commit: commit}
+	_, subject, _ := SplitGitmoji(commit.Subject)
+	if parsed, ok := ParseConventionalSubject(subject); ok {
+		entry.Type, entry.Scopes, entry.Description, entry.Breaking = parsed.Type, parsed.Scopes, parsed.Description, parsed.Breaking
+	}
+	for _, line := range strings.Split(commit.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			entry.Breaking = true
+		}
+	}
+	return entry
+}
+
+// previousTag returns the newest tag reachable from rev's parent, or ""
+// when there is none
+func (gc *GitCommenter) previousTag(rev string) string {
+	tag, err := gc.gitOutput("describe", "--tags", "--abbrev=0", rev+"^")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(tag)
+}
+
+// releaseCommits lists the commits from..to without merges, oldest first.
+// An empty to is HEAD and an empty from the tag before to, or the whole
+// history without one. It returns the range's resolved ends.
+func (gc *GitCommenter) releaseCommits(from, to string) ([]Commit, string, string, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	if from == "" {
+		from = gc.previousTag(to)
+	}
+	revRange := to
+	if from != "" {
+		revRange = from + ".." + to
+	}
+	output, err := gc.gitOutput("log", "--reverse", "--no-merges", logFormat, revRange)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	commits := parseLog(output)
+	if len(commits) == 0 {
+		return nil, "", "", fmt.Errorf("no commits in %s", revRange)
+	}
+	return commits, from, to, nil
+}
+
+// GenerateReleaseNotes groups the commits from..to by conventional type and
+// has the model write an overview of the release. An empty to is HEAD and
+// an empty from the tag before to. Merge commits are left out.
+func (gc *GitCommenter) GenerateReleaseNotes(from, to string) (*ReleaseNotes, error) {
+	commits, from, to, err := gc.releaseCommits(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := &ReleaseNotes{From: from, To: to, Date: commits[len(commits)-1].Date}
+	grouped := make(map[string][]ReleaseEntry)
+	for _, commit := range commits {
+		entry := ParseReleaseEntry(commit)
+		if entry.Breaking {
+			notes.Breaking = append(notes.Breaking, entry)
+			continue
+		}
+		title := "Other Changes"
+		for _, section := range releaseSections {
+			if containsString(section.Types, entry.Type) {
+				title = section.Title
+			}
+		}
+		grouped[title] = append(grouped[title], entry)
+	}
+	for _, section := range releaseSections {
+		if entries := grouped[section.Title]; len(entries) > 0 {
+			notes.Sections = append(notes.Sections, ReleaseSection{Title: section.Title, Entries: entries})
+		}
+	}
+	if entries := grouped["Other Changes"]; len(entries) > 0 {
+		notes.Sections = append(notes.Sections, ReleaseSection{Title: "Other Changes", Entries: entries})
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing the overview at the top of the release notes of " + to + ".\n\n")
+	prompt.WriteString("CHANGES IN THE RELEASE:\n")
+	if len(notes.Breaking) > 0 {
+		prompt.WriteString("Breaking changes:\n")
+		writeReleasePromptEntries(&prompt, notes.Breaking)
+	}
+	for _, section := range notes.Sections {
+		prompt.WriteString(section.Title + ":\n")
+		writeReleasePromptEntries(&prompt, section.Entries)
+	}
+	prompt.WriteString("\nWrite 2-4 sentences for the users of the project: the most important new features and fixes, and for breaking changes what users have to do. ")
+	prompt.WriteString("Do not list every change and leave out maintenance. Use no headings or bullet lists.\n")
+	if language := gc.language(); language != "en" {
+		prompt.WriteString("Write in " + languageName(language) + ".\n")
+	}
+	prompt.WriteString("\nRespond with only the overview, no additional text.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	notes.Summary = strings.TrimSpace(response)
+	return notes, nil
+}
+
+// writeReleasePromptEntries lists entries with short bodies for the prompt
+func writeReleasePromptEntries(prompt *strings.Builder, entries []ReleaseEntry) {
+	for _, entry := range entries {
+		prompt.WriteString("- " + entry.Commit.Subject + "\n")
+		if entry.Commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(entry.Commit.Body, 300), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+}
+
+// String renders the notes as markdown, with a heading for To and one
+// bullet per commit
+func (rn *ReleaseNotes) String() string {
+	var out strings.Builder
+	title := rn.To
+	if title == "HEAD" {
+		title = "Unreleased"
+	}
+	out.WriteString("## " + title)
+	if !rn.Date.IsZero() {
+		out.WriteString(" (" + rn.Date.Format("2006-01-02") + ")")
+	}
+	out.WriteString("\n\n")
+	if rn.Summary != "" {
+		out.WriteString(rn.Summary + "\n\n")
+	}
+	if len(rn.Breaking) > 0 {
+		out.WriteString("### ⚠️ Breaking Changes\n\n")
+		writeReleaseEntries(&out, rn.Breaking)
+	}
+	for _, section := range rn.Sections {
+		out.WriteString("### " + section.Title + "\n\n")
+		writeReleaseEntries(&out, section.Entries)
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// writeReleaseEntries writes entries as markdown bullets such as
+// "- **api:** add paging (abc1234)"
+func writeReleaseEntries(out *strings.Builder, entries []ReleaseEntry) {
+	for _, entry := range entries {
+		out.WriteString("- ")
+		if len(entry.Scopes) > 0 {
+			out.WriteString("**" + strings.Join(entry.Scopes, ", ") + ":** ")
+		}
+		out.WriteString(entry.Description + " (" + entry.Commit.ShortHash() + ")\n")
+	}
+	out.WriteString("\n")
+}
@@ -0,0 +1,91 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseReleaseEntry(t *testing.T) {
+	entry := ParseReleaseEntry(Commit{Subject: "✨ feat(api,cli): add paging"})
+	if entry.Type != "feat" || strings.Join(entry.Scopes, ",") != "api,cli" || entry.Description != "add paging" || entry.Breaking {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if entry := ParseReleaseEntry(Commit{Subject: "fix: drop v1", Body: "BREAKING CHANGE: v1 is gone"}); !entry.Breaking {
+		t.Error("Expected a BREAKING CHANGE footer to mark the entry as breaking")
+	}
+	if entry := ParseReleaseEntry(Commit{Subject: "Update readme"}); entry.Type != "" || entry.Description != "Update readme" {
+		t.Errorf("Unexpected entry for a plain subject %+v", entry)
+	}
+}
+
+func TestGenerateReleaseNotes(t *testing.T) {
+	dir := initTestRepo(t)
+	commit := func(message ...string) {
+		t.Helper()
+		writeFile(t, dir, "log.txt", strings.Join(message, "\n"))
+		args := []string{"commit", "-q", "-a"}
+		for _, part := range message {
+			args = append(args, "-m", part)
+		}
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, args...)
+	}
+	commit("feat: first release")
+	gitRun(t, dir, "tag", "v1.0.0")
+	commit("feat(api): add paging")
+	commit("fix: handle empty pages")
+	commit("chore: bump deps")
+	commit("Tweak wording")
+	commit("refactor(api)!: rename the client", "Callers must use NewClient.")
+	gitRun(t, dir, "tag", "v1.1.0")
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "This release adds paging.", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	// The range defaults to the commits since the previous tag
+	notes, err := gc.GenerateReleaseNotes("", "v1.1.0")
+	if err != nil {
+		t.Fatalf("GenerateReleaseNotes returned error: %v", err)
+	}
+	if notes.From != "v1.0.0" || notes.Summary != "This release adds paging." {
+		t.Errorf("Unexpected notes %+v", notes)
+	}
+	var titles []string
+	for _, section := range notes.Sections {
+		titles = append(titles, section.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Features, Bug Fixes, Maintenance, Other Changes" {
+		t.Errorf("Unexpected sections %s", got)
+	}
+	if len(notes.Breaking) != 1 || notes.Breaking[0].Description != "rename the client" {
+		t.Errorf("Expected the breaking refactor, got %+v", notes.Breaking)
+	}
+	if strings.Contains(prompt, "first release") || !strings.Contains(prompt, "Callers must use NewClient.") {
+		t.Errorf("Unexpected prompt:\n%s", prompt)
+	}
+
+	markdown := notes.String()
+	for _, part := range []string{"## v1.1.0 (", "This release adds paging.", "### ⚠️ Breaking Changes\n\n- **api:** rename the client (", "### Bug Fixes\n\n- handle empty pages ("} {
+		if !strings.Contains(markdown, part) {
+			t.Errorf("Expected %q in the notes:\n%s", part, markdown)
+		}
+	}
+
+	if _, err := gc.GenerateReleaseNotes("v1.1.0", "v1.1.0"); err == nil {
+		t.Error("Expected an error for an empty range")
+	}
+}
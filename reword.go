@@ -0,0 +1,146 @@
+package gitcommenter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateReword suggests a better message for an existing commit from its
+// diff. The model sees the current message, so the reason for the change
+// and references only the author knew are kept, and a trailer block such
+// as Signed-off-by is carried over unchanged.
+func (gc *GitCommenter) GenerateReword(commit Commit) (*CommitSuggestion, error) {
+	changes, err := gc.CommitChanges(commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("commit %s changes no files", commit.ShortHash())
+	}
+
+	plan, err := gc.plan(changes)
+	if err != nil {
+		return nil, err
+	}
+	plan.prompt += "\n\nThe commit already has this message, which is being rewritten:\n" +
+		indent(truncateUTF8(commit.Message(), 1000), "  ") +
+		"\nKeep what it says that the diff cannot show, such as why the change was made or issue references, but describe the change accurately."
+	suggestion, err := gc.complete(context.Background(), plan, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	paragraphs := strings.Split(strings.TrimSpace(commit.Body), "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; last != "" && isTrailerBlock(last) {
+		for _, line := range strings.Split(last, "\n") {
+			if !strings.Contains(suggestion.Body, line) {
+				key, value, _ := strings.Cut(line, ": ")
+				suggestion.AddTrailer(key, value)
+			}
+		}
+	}
+	return suggestion, nil
+}
+
+// RewordCommits replaces the messages of commits in revRange (e.g.
+// "HEAD~3..HEAD"), keyed by full hash, through git rebase -i. The rebase
+// picks every commit of the range and amends the reworded ones from exec
+// lines, so contents, authors and author dates are kept. The range must
+// end at HEAD without merges, and the working tree must be clean.
+func (gc *GitCommenter) RewordCommits(revRange string, messages map[string]string) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	status, err := gc.gitOutput("status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return fmt.Errorf("failed to check the working tree: %w", err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("the working tree has uncommitted changes; commit or stash them before rewording")
+	}
+
+	output, err := gc.gitOutput("rev-list", "--reverse", "--parents", revRange)
+	if err != nil {
+		return fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	head, err := gc.HeadCommit()
+	if err != nil {
+		return err
+	}
+	var hashes []string
+	base := ""
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return fmt.Errorf("%s has a merge commit, %s; reword linear history only", revRange, fields[0])
+		}
+		if i == 0 && len(fields) == 2 {
+			base = fields[1]
+		} else if i > 0 && (len(fields) < 2 || fields[1] != hashes[i-1]) {
+			return fmt.Errorf("the commits in %s are not a linear history", revRange)
+		}
+		hashes = append(hashes, fields[0])
+	}
+	if len(hashes) == 0 || hashes[len(hashes)-1] != head {
+		return fmt.Errorf("%s does not end at HEAD; check out the branch to reword", revRange)
+	}
+	for hash := range messages {
+		if !containsString(hashes, hash) {
+			return fmt.Errorf("commit %s is not in %s", hash, revRange)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "ai-git-auto-reword-")
+	if err != nil {
+		return fmt.Errorf("failed to create a directory for the rebase: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var todo strings.Builder
+	for i, hash := range hashes {
+		todo.WriteString("pick " + hash + "\n")
+		message, ok := messages[hash]
+		if !ok {
+			continue
+		}
+		file := filepath.Join(dir, fmt.Sprintf("message-%d.txt", i))
+		if err := os.WriteFile(file, []byte(strings.TrimSpace(message)+"\n"), 0o600); err != nil {
+			return fmt.Errorf("failed to write the message of %s: %w", hash, err)
+		}
+		todo.WriteString("exec git commit --amend --allow-empty --no-verify --quiet -F " + shellQuote(file) + "\n")
+	}
+	todoFile := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write the rebase todo list: %w", err)
+	}
+
+	args := []string{"rebase", "--interactive", "--quiet"}
+	if base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, base)
+	}
+	// The sequence editor replaces git's todo list with ours
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gc.config.RepositoryPath
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		abort := exec.Command("git", "rebase", "--abort")
+		abort.Dir = gc.config.RepositoryPath
+		abort.Run()
+		return fmt.Errorf("failed to reword commits: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,73 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRewordAndRewordCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	for i, name := range []string{"a.go", "b.go", "c.go"} {
+		writeFile(t, dir, name, "package app\n")
+		gitRun(t, dir, "add", name)
+		gitRun(t, dir, "commit", "-q", "-m", "wip "+string(rune('1'+i)), "-m", "Needed for #12.\n\nSigned-off-by: Test <test@example.com>")
+	}
+	tree := strings.TrimSpace(gitRun(t, dir, "rev-parse", "HEAD^{tree}"))
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat: add b.go\n\nAdds the app package file needed for #12.", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.Style = "conventional"
+	gc := New(config)
+
+	commits, err := gc.Commits("HEAD~2..HEAD")
+	if err != nil || len(commits) != 2 {
+		t.Fatalf("Expected two commits, got %v, %v", commits, err)
+	}
+	suggestion, err := gc.GenerateReword(commits[0])
+	if err != nil {
+		t.Fatalf("GenerateReword returned error: %v", err)
+	}
+	for _, part := range []string{"wip 2", "Needed for #12.", "b.go"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected %q in the prompt:\n%s", part, prompt)
+		}
+	}
+	if suggestion.Subject != "feat: add b.go" || !strings.HasSuffix(suggestion.Body, "\n\nSigned-off-by: Test <test@example.com>") {
+		t.Errorf("Expected the suggestion with the trailer kept, got %q", suggestion.Message())
+	}
+
+	messages := map[string]string{commits[0].Hash: suggestion.Message()}
+	if err := gc.RewordCommits("HEAD~2..HEAD", messages); err != nil {
+		t.Fatalf("RewordCommits returned error: %v", err)
+	}
+	if subjects := gitRun(t, dir, "log", "--format=%s"); subjects != "wip 3\nfeat: add b.go\nwip 1\n" {
+		t.Errorf("Expected only the middle commit reworded, got:\n%s", subjects)
+	}
+	if body := gitRun(t, dir, "log", "-1", "--format=%b", "HEAD~1"); !strings.Contains(body, "Signed-off-by: Test") {
+		t.Errorf("Expected the trailer in the reworded commit, got %q", body)
+	}
+	if got := strings.TrimSpace(gitRun(t, dir, "rev-parse", "HEAD^{tree}")); got != tree {
+		t.Errorf("Expected the contents unchanged, tree %s became %s", tree, got)
+	}
+
+	if err := gc.RewordCommits("HEAD~2..HEAD~1", map[string]string{"x": "y"}); err == nil || !strings.Contains(err.Error(), "HEAD") {
+		t.Errorf("Expected an error for a range not ending at HEAD, got %v", err)
+	}
+	writeFile(t, dir, "a.go", "package changed\n")
+	if err := gc.RewordCommits("HEAD~1..HEAD", map[string]string{strings.TrimSpace(gitRun(t, dir, "rev-parse", "HEAD")): "fix: x"}); err == nil {
+		t.Error("Expected an error with uncommitted changes")
+	}
+}
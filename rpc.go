@@ -0,0 +1,237 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RPCServer answers JSON-RPC 2.0 requests, one JSON object per line, for
+// editor plugins that keep the tool running as a child process:
+//
+//   - suggest generates a message for the staged changes
+//   - regenerate generates another candidate for them
+//   - listModels lists the Ollama models
+//   - cancel stops the request with the given id
+//
+// While a message is generated, progress notifications carry the request
+// id and the text streamed so far. Requests run concurrently so that a
+// cancel is answered while a suggestion is still running.
+type RPCServer struct {
+	gc  *GitCommenter
+	in  *bufio.Reader
+	out io.Writer
+
+	mu        sync.Mutex
+	candidate int
+	running   map[string]context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// rpcMessage is an incoming request, or a notification without an ID
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCSuggestion is the result of suggest and regenerate
+type RPCSuggestion struct {
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body,omitempty"`
+	Message    string   `json:"message"`
+	Confidence float64  `json:"confidence"`
+	Files      []string `json:"files,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+	Template   string   `json:"template,omitempty"`
+	Candidate  int      `json:"candidate"`
+}
+
+// NewRPCServer creates a server reading requests from r and writing
+// responses and notifications to w, usually stdin and stdout
+func NewRPCServer(gc *GitCommenter, r io.Reader, w io.Writer) *RPCServer {
+	return &RPCServer{
+		gc:      gc,
+		in:      bufio.NewReader(r),
+		out:     w,
+		running: make(map[string]context.CancelFunc),
+	}
+}
+
+// SetCommenter replaces the commenter answering requests, e.g. with one
+// for a reloaded configuration (see GitCommenter.WithConfig). Requests
+// already running finish with the previous one.
+func (s *RPCServer) SetCommenter(gc *GitCommenter) {
+	s.mu.Lock()
+	s.gc = gc
+	s.mu.Unlock()
+}
+
+// commenter returns the commenter answering requests
+func (s *RPCServer) commenter() *GitCommenter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gc
+}
+
+// Serve answers requests until the client closes the stream, then waits
+// for the requests still running
+func (s *RPCServer) Serve() error {
+	defer s.wg.Wait()
+	for {
+		line, err := s.in.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			s.dispatch(line)
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// dispatch answers cancel and listModels at once and runs generation in
+// the background
+func (s *RPCServer) dispatch(line []byte) {
+	var msg rpcMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		s.respond(json.RawMessage("null"), nil, &lspError{Code: lspParseError, Message: err.Error()})
+		return
+	}
+
+	switch msg.Method {
+	case "suggest", "regenerate":
+		ctx, cancel := context.WithCancel(context.Background())
+		key := string(msg.ID)
+		if msg.ID != nil {
+			s.mu.Lock()
+			s.running[key] = cancel
+			s.mu.Unlock()
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			result, err := s.suggest(ctx, msg.ID, msg.Method == "regenerate")
+			if ctx.Err() != nil {
+				err = &lspError{Code: lspCancelled, Message: "request cancelled"}
+			}
+			s.mu.Lock()
+			delete(s.running, key)
+			s.mu.Unlock()
+			cancel()
+			if msg.ID != nil {
+				s.respond(msg.ID, result, err)
+			}
+		}()
+		return
+	}
+
+	result, err := s.handle(msg.Method, msg.Params)
+	if msg.ID != nil {
+		s.respond(msg.ID, result, err)
+	}
+}
+
+func (s *RPCServer) handle(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "listModels":
+		gc := s.commenter()
+		models, err := gc.ListAvailableModels()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"models": models, "current": gc.config.Model}, nil
+	case "cancel":
+		var p struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || p.ID == nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: "cancel needs the id of a request"}
+		}
+		s.mu.Lock()
+		cancel, ok := s.running[string(p.ID)]
+		s.mu.Unlock()
+		if ok {
+			cancel()
+		}
+		return map[string]bool{"cancelled": ok}, nil
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: "method not supported: " + method}
+}
+
+// suggest generates a message for the staged changes. suggest starts over
+// at the first candidate; regenerate asks for the next one.
+func (s *RPCServer) suggest(ctx context.Context, id json.RawMessage, regenerate bool) (*RPCSuggestion, error) {
+	gc := s.commenter()
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("no staged changes")
+	}
+
+	s.mu.Lock()
+	if regenerate {
+		s.candidate++
+	} else {
+		s.candidate = 0
+	}
+	candidate := s.candidate
+	s.mu.Unlock()
+
+	var progress func(string)
+	if id != nil {
+		progress = func(partial string) {
+			if ctx.Err() == nil {
+				s.notify("progress", map[string]any{"id": id, "partial": partial})
+			}
+		}
+	}
+	suggestion, err := gc.StreamCommitMessage(ctx, changes, candidate, progress)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCSuggestion{
+		Subject:    suggestion.Subject,
+		Body:       suggestion.Body,
+		Message:    suggestion.Message(),
+		Confidence: suggestion.Confidence,
+		Files:      suggestion.FilesAffected,
+		Warnings:   suggestion.Warnings,
+		Template:   suggestion.Template,
+		Candidate:  candidate,
+	}, nil
+}
+
+func (s *RPCServer) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *RPCServer) respond(id json.RawMessage, result any, err error) {
+	if err == nil {
+		s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+		return
+	}
+	rpcErr, ok := err.(*lspError)
+	if !ok {
+		rpcErr = &lspError{Code: lspRequestFailed, Message: err.Error()}
+	}
+	s.write(map[string]any{"jsonrpc": "2.0", "id": id, "error": rpcErr})
+}
+
+func (s *RPCServer) write(msg any) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,120 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRPCServer(t *testing.T) {
+	block := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			fmt.Fprint(w, `{"models":[{"name":"llama2"},{"name":"mistral"}]}`)
+			return
+		}
+		if requests.Add(1) == 3 {
+			// The request to be cancelled hangs until the client goes away
+			select {
+			case <-r.Context().Done():
+			case <-block:
+			}
+			return
+		}
+		encoder := json.NewEncoder(w)
+		encoder.Encode(OllamaResponse{Response: "feat: add parser"})
+		w.(http.Flusher).Flush()
+		encoder.Encode(OllamaResponse{Response: "\n\nParses the input.", Done: true})
+	}))
+	defer server.Close()
+	defer close(block)
+
+	dir := initTestRepo(t)
+	writeFile(t, dir, "parser.go", "package parser\n\nfunc Parse() {}\n")
+	gitRun(t, dir, "add", "parser.go")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: dir})
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- NewRPCServer(gc, serverIn, serverOut).Serve() }()
+
+	responses := bufio.NewScanner(clientIn)
+	send := func(id int, method string, params any) {
+		data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+		clientOut.Write(append(data, '\n'))
+	}
+	// read returns the next response, counting the progress notifications
+	// before it
+	read := func() (map[string]any, int) {
+		t.Helper()
+		progress := 0
+		for responses.Scan() {
+			var msg map[string]any
+			if err := json.Unmarshal(responses.Bytes(), &msg); err != nil {
+				t.Fatalf("Invalid message %q: %v", responses.Text(), err)
+			}
+			if msg["method"] == "progress" {
+				progress++
+				continue
+			}
+			return msg, progress
+		}
+		t.Fatalf("The server closed the stream: %v", responses.Err())
+		return nil, 0
+	}
+
+	send(1, "suggest", nil)
+	suggested, progress := read()
+	result := suggested["result"].(map[string]any)
+	if result["message"] != "feat: add parser\n\nParses the input." || result["candidate"] != 0.0 {
+		t.Fatalf("Unexpected suggestion %v", suggested)
+	}
+	if progress == 0 {
+		t.Error("Expected progress notifications while the message streamed")
+	}
+
+	send(2, "listModels", nil)
+	models, _ := read()
+	if list := models["result"].(map[string]any)["models"].([]any); len(list) != 2 {
+		t.Errorf("Expected 2 models, got %v", models)
+	}
+
+	// The second regeneration hangs until it is cancelled
+	send(3, "regenerate", nil)
+	regenerated, _ := read()
+	if regenerated["result"].(map[string]any)["candidate"] != 1.0 {
+		t.Fatalf("Expected candidate 1, got %v", regenerated)
+	}
+	send(4, "regenerate", nil)
+	send(5, "cancel", map[string]int{"id": 4})
+	first, _ := read()
+	second, _ := read()
+	if first["id"] != 5.0 || first["result"].(map[string]any)["cancelled"] != true {
+		t.Errorf("Expected the cancel to be confirmed first, got %v", first)
+	}
+	if second["id"] != 4.0 || second["error"].(map[string]any)["code"] != float64(lspCancelled) {
+		t.Errorf("Expected the regenerate request to be cancelled, got %v", second)
+	}
+
+	send(6, "commit", nil)
+	if unknown, _ := read(); unknown["error"] == nil {
+		t.Errorf("Expected an error for an unsupported method, got %v", unknown)
+	}
+
+	clientOut.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned error: %v", err)
+	}
+}
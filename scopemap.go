@@ -0,0 +1,95 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeMapping maps changed paths to a conventional commit scope
+type ScopeMapping struct {
+	// Pattern is a glob as accepted by MatchesAny, e.g. "cmd/**"
+	Pattern string
+	// Scope is the scope used when a changed path matches
+	Scope string
+}
+
+// ParseScopeMapping parses a "pattern -> scope" entry such as "cmd/** -> cli"
+func ParseScopeMapping(entry string) (ScopeMapping, error) {
+	pattern, scope, ok := strings.Cut(entry, "->")
+	pattern, scope = strings.TrimSpace(pattern), strings.TrimSpace(scope)
+	if !ok || pattern == "" || scope == "" {
+		return ScopeMapping{}, fmt.Errorf("invalid scope mapping %q (expected 'pattern -> scope')", entry)
+	}
+	if strings.ContainsAny(scope, "(),: ") {
+		return ScopeMapping{}, fmt.Errorf("invalid scope %q in mapping %q", scope, entry)
+	}
+	return ScopeMapping{Pattern: pattern, Scope: scope}, nil
+}
+
+// InferScope returns the scope for the changed paths: the scopes of the first
+// mapping matching each path, in mapping order and comma-separated when the
+// changes span several. Paths matching no mapping are ignored.
+func InferScope(mappings []ScopeMapping, changes []FileChange) string {
+	found := make(map[string]bool)
+	for _, change := range changes {
+		for _, mapping := range mappings {
+			if MatchesAny(change.FilePath, []string{mapping.Pattern}) {
+				found[mapping.Scope] = true
+				break
+			}
+		}
+	}
+
+	var scopes []string
+	for _, mapping := range mappings {
+		if found[mapping.Scope] && !containsString(scopes, mapping.Scope) {
+			scopes = append(scopes, mapping.Scope)
+		}
+	}
+	return strings.Join(scopes, ",")
+}
+
+// inferScope applies Config.ScopeMap to the changes
+func (gc *GitCommenter) inferScope(changes []FileChange) (string, error) {
+	var mappings []ScopeMapping
+	for _, entry := range gc.config.ScopeMap {
+		mapping, err := ParseScopeMapping(entry)
+		if err != nil {
+			return "", err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return InferScope(mappings, changes), nil
+}
+
+// forceScope replaces the scope of a conventional subject, keeping a ticket
+// prefix and gitmoji in front of it. Other subjects are returned unchanged
+// and left to validation.
+func forceScope(subject, ticketPrefix, scope string) string {
+	prefix, rest := splitSubjectPrefix(subject, ticketPrefix)
+	parsed, ok := ParseConventionalSubject(rest)
+	if !ok {
+		return subject
+	}
+	breaking := ""
+	if parsed.Breaking {
+		breaking = "!"
+	}
+	rest = fmt.Sprintf("%s(%s)%s: %s", parsed.Type, scope, breaking, parsed.Description)
+	return strings.Join(append(prefix, rest), " ")
+}
+
+// splitSubjectPrefix splits a ticket prefix and gitmoji off the front of a
+// subject
+func splitSubjectPrefix(subject, ticketPrefix string) (prefix []string, rest string) {
+	rest = subject
+	if ticketPrefix != "" && strings.HasPrefix(rest, ticketPrefix) {
+		prefix = append(prefix, ticketPrefix)
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ticketPrefix))
+	}
+	if _, withoutEmoji, ok := SplitGitmoji(rest); ok {
+		prefix = append(prefix, strings.TrimSpace(strings.TrimSuffix(rest, withoutEmoji)))
+		rest = withoutEmoji
+	}
+	return prefix, rest
+}
@@ -0,0 +1,58 @@
+package gitcommenter
+
+import "testing"
+
+func TestParseScopeMapping(t *testing.T) {
+	mapping, err := ParseScopeMapping(" cmd/** -> cli ")
+	if err != nil || mapping.Pattern != "cmd/**" || mapping.Scope != "cli" {
+		t.Errorf("Unexpected mapping %+v (error %v)", mapping, err)
+	}
+
+	for _, entry := range []string{"cmd/**", "-> cli", "cmd/** ->", "cmd/** -> a b"} {
+		if _, err := ParseScopeMapping(entry); err == nil {
+			t.Errorf("Expected an error for %q", entry)
+		}
+	}
+}
+
+func TestInferScope(t *testing.T) {
+	mappings := []ScopeMapping{{"cmd/**", "cli"}, {"examples/**", "examples"}, {"*.go", "core"}}
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"cmd/ai-git-auto/main.go"}, "cli"},
+		{[]string{"cmd/ai-git-auto/main.go", "README.md"}, "cli"},
+		{[]string{"examples/basic/main.go", "cmd/git-ai-commit/main.go"}, "cli,examples"},
+		{[]string{"style.go"}, "core"},
+		{[]string{"README.md"}, ""},
+	}
+
+	for _, test := range tests {
+		var changes []FileChange
+		for _, p := range test.paths {
+			changes = append(changes, FileChange{FilePath: p})
+		}
+		if got := InferScope(mappings, changes); got != test.want {
+			t.Errorf("InferScope(%v) = %q, want %q", test.paths, got, test.want)
+		}
+	}
+}
+
+func TestForceScope(t *testing.T) {
+	tests := []struct {
+		subject string
+		ticket  string
+		want    string
+	}{
+		{"feat: add wizard", "", "feat(cli): add wizard"},
+		{"fix(api)!: drop flag", "", "fix(cli)!: drop flag"},
+		{"PROJ-1 ✨ feat(ui): add wizard", "PROJ-1", "PROJ-1 ✨ feat(cli): add wizard"},
+		{"Add wizard", "", "Add wizard"},
+	}
+	for _, test := range tests {
+		if got := forceScope(test.subject, test.ticket, "cli"); got != test.want {
+			t.Errorf("forceScope(%q) = %q, want %q", test.subject, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Modes of Config.SecretScan
+const (
+	// SecretScanBlock refuses to send changes with possible secrets to the
+	// model (the default)
+	SecretScanBlock = "block"
+	// SecretScanWarn sends them and adds a warning to the suggestion
+	SecretScanWarn = "warn"
+	// SecretScanOff skips the scan
+	SecretScanOff = "off"
+)
+
+// SecretFinding is an added line that looks like it holds a secret
+type SecretFinding struct {
+	Path string
+	// Line is the line number in the new version of the file
+	Line int
+	// Rule names what was found, e.g. "AWS access key"
+	Rule string
+	// Text is the line with the secret masked
+	Text string
+}
+
+// String formats the finding as "path:line: rule: text"
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", f.Path, f.Line, f.Rule, f.Text)
+}
+
+// SecretsError is returned when changes with possible secrets would be
+// sent to the model in SecretScanBlock mode
+type SecretsError struct {
+	Findings []SecretFinding
+}
+
+func (e *SecretsError) Error() string {
+	var places []string
+	for _, finding := range e.Findings {
+		places = append(places, fmt.Sprintf("%s:%d (%s)", finding.Path, finding.Line, finding.Rule))
+	}
+	return "possible secrets in the changes: " + strings.Join(places, ", ")
+}
+
+// secretRule matches one kind of secret. The secret is the first
+// submatch, or the whole match without one.
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// minEntropy, when set, is the Shannon entropy in bits per character
+	// the secret needs, to tell random keys from placeholders
+	minEntropy float64
+	// header marks matches that announce a secret on the following lines
+	// without holding it, so they are shown as they are
+	header bool
+}
+
+// secretRules are checked against every added line
+var secretRules = []secretRule{
+	{name: "private key", pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`), header: true},
+	{name: "AWS access key", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "GitHub token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{name: "GitLab token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}`)},
+	{name: "Slack token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`)},
+	{name: "Stripe key", pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+	{name: "Google API key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{name: "API secret key", pattern: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{32,}`)},
+	{name: "JSON web token", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+	{
+		name:       "high-entropy secret",
+		pattern:    regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|credential|auth)[a-z0-9_\-]*["']?\s*(?::=|=>|[:=])\s*["']([^"'\s]{16,})["']`),
+		minEntropy: 3.5,
+	},
+}
+
+// secretAllowMarkers on a line mark a finding as a known false positive,
+// as with detect-secrets and gitleaks
+var secretAllowMarkers = []string{"pragma: allowlist secret", "gitleaks:allow"}
+
+// ScanSecrets looks for API keys, tokens and private keys on the added
+// lines of the changes' diffs
+func ScanSecrets(changes []FileChange) []SecretFinding {
+	var findings []SecretFinding
+	for _, change := range changes {
+		line, inHunk := 0, false
+		for _, text := range strings.Split(change.Diff, "\n") {
+			if match := hunkHeader.FindStringSubmatch(text); match != nil {
+				line, _ = strconv.Atoi(match[3])
+				inHunk = true
+				continue
+			}
+			if strings.HasPrefix(text, "diff --git ") {
+				inHunk = false
+			}
+			// Outside a hunk "+++ b/path" names the file
+			if text == "" || !inHunk && strings.HasPrefix(text, "+++ ") {
+				continue
+			}
+			switch text[0] {
+			case '+':
+				if finding, ok := scanSecretLine(text[1:]); ok {
+					finding.Path, finding.Line = change.FilePath, line
+					findings = append(findings, finding)
+				}
+				line++
+			case ' ':
+				line++
+			}
+		}
+	}
+	return findings
+}
+
+// scanSecretLine checks one added line against the rules
+func scanSecretLine(text string) (SecretFinding, bool) {
+	for _, marker := range secretAllowMarkers {
+		if strings.Contains(text, marker) {
+			return SecretFinding{}, false
+		}
+	}
+	for _, rule := range secretRules {
+		match := rule.pattern.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		start, end := match[0], match[1]
+		if len(match) > 2 {
+			start, end = match[2], match[3]
+		}
+		if rule.minEntropy > 0 && shannonEntropy(text[start:end]) < rule.minEntropy {
+			continue
+		}
+		masked := text
+		if !rule.header {
+			masked = text[:start] + maskSecret(text[start:end]) + text[end:]
+		}
+		return SecretFinding{Rule: rule.name, Text: truncateUTF8(strings.TrimSpace(masked), 120)}, true
+	}
+	return SecretFinding{}, false
+}
+
+// maskSecret keeps the first four characters of a secret, which name its
+// kind, and masks the rest
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-4)
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// CheckSecrets scans the changes according to Config.SecretScan. In
+// SecretScanBlock mode findings come with a *SecretsError.
+func (gc *GitCommenter) CheckSecrets(changes []FileChange) ([]SecretFinding, error) {
+	if gc.config.SecretScan == SecretScanOff {
+		return nil, nil
+	}
+	findings := ScanSecrets(changes)
+	if len(findings) > 0 && gc.config.SecretScan != SecretScanWarn {
+		return findings, &SecretsError{Findings: findings}
+	}
+	return findings, nil
+}
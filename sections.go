@@ -0,0 +1,108 @@
+package gitcommenter
+
+import "strings"
+
+// sectionHeading normalizes a manual section name to its heading, e.g.
+// "Testing done" to "Testing done:"
+func sectionHeading(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasSuffix(name, ":") {
+		return name
+	}
+	return name + ":"
+}
+
+// sectionHeadings normalizes a list of manual section names
+func sectionHeadings(names []string) []string {
+	var headings []string
+	for _, name := range names {
+		if heading := sectionHeading(name); heading != "" {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
+
+// StripSections removes the named sections from a body, e.g. when the model
+// wrote a section reserved for the author. A section runs from the line
+// starting with its heading to the next blank line.
+func StripSections(body string, names []string) string {
+	headings := sectionHeadings(names)
+	if len(headings) == 0 {
+		return body
+	}
+
+	var kept []string
+	skipping := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			skipping = false
+		} else if hasSectionHeading(trimmed, headings) {
+			skipping = true
+		}
+		if !skipping {
+			kept = append(kept, line)
+		}
+	}
+
+	// Collapse the blank lines left around removed sections
+	var collapsed []string
+	for _, line := range kept {
+		blank := strings.TrimSpace(line) == ""
+		if blank && (len(collapsed) == 0 || strings.TrimSpace(collapsed[len(collapsed)-1]) == "") {
+			continue
+		}
+		collapsed = append(collapsed, line)
+	}
+	return strings.TrimSpace(strings.Join(collapsed, "\n"))
+}
+
+// hasSectionHeading reports whether line starts with one of the headings,
+// ignoring case and list markers
+func hasSectionHeading(line string, headings []string) bool {
+	line = strings.ToLower(strings.TrimLeft(line, "-*# "))
+	for _, heading := range headings {
+		if strings.HasPrefix(line, strings.ToLower(heading)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSection adds an author-written section to the body, before the
+// trailer block if there is one. Single-line content follows the heading
+// on the same line.
+func (s *CommitSuggestion) AddSection(name, content string) {
+	heading := sectionHeading(name)
+	content = strings.TrimSpace(content)
+	if heading == "" || content == "" {
+		return
+	}
+
+	section := heading + "\n" + content
+	if !strings.Contains(content, "\n") {
+		section = heading + " " + content
+	}
+
+	paragraphs := strings.Split(strings.TrimSpace(s.Body), "\n\n")
+	if s.Body == "" {
+		paragraphs = nil
+	}
+	at := len(paragraphs)
+	if at > 0 && isTrailerBlock(paragraphs[at-1]) {
+		at--
+	}
+	paragraphs = append(paragraphs[:at], append([]string{section}, paragraphs[at:]...)...)
+	s.Body = strings.Join(paragraphs, "\n\n")
+}
+
+// isTrailerBlock reports whether every line of a paragraph is a trailer
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !isTrailerLine(line) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package gitcommenter
+
+import "testing"
+
+func TestStripSections(t *testing.T) {
+	body := "- add the flag\n\nTesting done:\n- ran it once\n\n- document it"
+	if got := StripSections(body, []string{"Testing done"}); got != "- add the flag\n\n- document it" {
+		t.Errorf("Unexpected body %q", got)
+	}
+	if got := StripSections("**Testing done:** unit tests", []string{"testing done:"}); got != "" {
+		t.Errorf("Expected the marked-up section to be removed, got %q", got)
+	}
+	if got := StripSections(body, nil); got != body {
+		t.Errorf("Expected the body unchanged without sections, got %q", got)
+	}
+}
+
+func TestAddSection(t *testing.T) {
+	tests := []struct {
+		body    string
+		name    string
+		content string
+		want    string
+	}{
+		{"", "Testing done", "go test ./...", "Testing done: go test ./..."},
+		{"- add x", "Testing done:", "ran go test\nclicked around", "- add x\n\nTesting done:\nran go test\nclicked around"},
+		{"- add x\n\nSigned-off-by: A <a@example.com>", "Risk:", "low", "- add x\n\nRisk: low\n\nSigned-off-by: A <a@example.com>"},
+		{"- add x", "Testing done:", "  ", "- add x"},
+	}
+	for _, test := range tests {
+		suggestion := &CommitSuggestion{Subject: "feat: add x", Body: test.body}
+		suggestion.AddSection(test.name, test.content)
+		if suggestion.Body != test.want {
+			t.Errorf("AddSection(%q, %q) on %q = %q, want %q", test.name, test.content, test.body, suggestion.Body, test.want)
+		}
+	}
+}
+
+func TestManualSectionsInPrompt(t *testing.T) {
+	config := DefaultConfig()
+	config.ManualSections = []string{"Testing done"}
+	gc := New(config)
+
+	if prompt := gc.buildPrompt("", nil); !contains(prompt, "Do not write these sections, the author fills them in: Testing done:") {
+		t.Errorf("Expected the manual sections in the prompt, got:\n%s", prompt)
+	}
+	suggestion := gc.parseCommitSuggestion("feat: add x\n\n- add x\n\nTesting done: none", nil)
+	if suggestion.Body != "- add x" {
+		t.Errorf("Expected the generated section to be stripped, got %q", suggestion.Body)
+	}
+}
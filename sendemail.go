@@ -0,0 +1,70 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// format-patch leaves these placeholders in the cover letter template
+const (
+	coverSubjectPlaceholder = "*** SUBJECT HERE ***"
+	coverBlurbPlaceholder   = "*** BLURB HERE ***"
+)
+
+// FormatPatchSeries runs git format-patch --cover-letter for revRange into
+// outDir and fills the cover letter placeholders with the generated title
+// and summary. It returns the patch files in series order, cover letter first.
+func (gc *GitCommenter) FormatPatchSeries(revRange, outDir string, letter *CoverLetter, extraArgs ...string) ([]string, error) {
+	args := append([]string{"format-patch", "--cover-letter", "-o", outDir}, extraArgs...)
+	args = append(args, revRange)
+
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format patches: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(gc.config.RepositoryPath, line)
+		}
+		files = append(files, line)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no patches in %s", revRange)
+	}
+
+	if letter != nil {
+		if err := fillCoverLetter(files[0], letter); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// fillCoverLetter replaces the format-patch placeholders in the cover letter file
+func fillCoverLetter(path string, letter *CoverLetter) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read cover letter: %w", err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, coverSubjectPlaceholder) {
+		return fmt.Errorf("%s does not look like a format-patch cover letter", path)
+	}
+
+	content = strings.Replace(content, coverSubjectPlaceholder, letter.Title, 1)
+	content = strings.Replace(content, coverBlurbPlaceholder, letter.Summary, 1)
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write cover letter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// commitFile writes a file and commits it in a test repository
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+
+	writeFile(t, dir, name, content)
+	gitRun(t, dir, "add", name)
+	gitRun(t, dir, "commit", "-q", "-m", message)
+}
+
+func TestFormatPatchSeries(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "a.txt", "a\n", "initial")
+	commitFile(t, dir, "b.txt", "b\n", "add b")
+	commitFile(t, dir, "c.txt", "c\n", "add c")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	commenter := New(config)
+
+	letter := &CoverLetter{Title: "Add b and c", Summary: "Two small files."}
+	files, err := commenter.FormatPatchSeries("HEAD~2..HEAD", filepath.Join(dir, "out"), letter)
+	if err != nil {
+		t.Fatalf("FormatPatchSeries returned error: %v", err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("Expected cover letter and 2 patches, got %v", files)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(data)
+	if !contains(content, "[PATCH 0/2] Add b and c") || !contains(content, "Two small files.") {
+		t.Errorf("Cover letter placeholders were not filled:\n%s", content)
+	}
+}
@@ -0,0 +1,355 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxSplitHunkLength bounds how much of each hunk is shown when grouping
+const maxSplitHunkLength = 1500
+
+// StagedHunk is one hunk of the staged diff. New, deleted and binary files
+// and mode changes are not split: their whole diff is one StagedHunk.
+type StagedHunk struct {
+	// Path is the file the hunk changes
+	Path string
+	// ChangeType is "added", "deleted" or "modified"
+	ChangeType string
+	// Header is the diff of the file up to its first hunk
+	Header string
+	// Body is the hunk from its @@ line, or "" when the file is not split
+	Body string
+
+	// order is the position of the hunk in the diff
+	order int
+	// untracked marks an untracked file, which is staged with git add
+	untracked bool
+}
+
+// SplitCommit is one of the commits a split creates: related staged hunks
+// and the message to commit them with
+type SplitCommit struct {
+	Hunks      []StagedHunk
+	Suggestion *CommitSuggestion
+}
+
+// StagedHunks returns the hunks of the staged changes in diff order,
+// without the files listed in .aiignore. Renames are split into a deletion
+// and an addition so that every hunk applies on its own.
+func (gc *GitCommenter) StagedHunks() ([]StagedHunk, error) {
+	if err := gc.ensureGitRepository(); err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	hunks, err := gc.diffHunks("--cached")
+	if err != nil {
+		return nil, err
+	}
+	return gc.withoutIgnoredHunks(hunks)
+}
+
+// diffHunks returns the hunks of git diff with the given arguments, in a
+// form git apply accepts
+func (gc *GitCommenter) diffHunks(args ...string) ([]StagedHunk, error) {
+	args = append([]string{"diff", "--no-renames", "--binary", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/"}, args...)
+	diff, err := gc.gitOutput(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the diff: %w", err)
+	}
+	return parseStagedHunks(diff), nil
+}
+
+// parseStagedHunks splits a diff into its hunks
+func parseStagedHunks(diff string) []StagedHunk {
+	var hunks []StagedHunk
+	for _, file := range splitFileDiffs(diff) {
+		header, bodies := file, []string(nil)
+		if at := strings.Index(file, "\n@@ "); at >= 0 {
+			header = file[:at+1]
+			for _, line := range strings.SplitAfter(file[at+1:], "\n") {
+				if strings.HasPrefix(line, "@@ ") || len(bodies) == 0 {
+					bodies = append(bodies, "")
+				}
+				bodies[len(bodies)-1] += line
+			}
+		}
+
+		hunk := StagedHunk{Path: fileDiffPath(header), ChangeType: "modified", Header: header}
+		switch {
+		case strings.Contains(header, "\nnew file mode "):
+			hunk.ChangeType = "added"
+		case strings.Contains(header, "\ndeleted file mode "):
+			hunk.ChangeType = "deleted"
+		}
+		// Only the hunks of a modified file apply one at a time
+		whole := hunk.ChangeType != "modified" || strings.Contains(header, "\nold mode ") || len(bodies) < 2
+		if whole {
+			hunk.Header = file
+			hunk.order = len(hunks)
+			hunks = append(hunks, hunk)
+			continue
+		}
+		for _, body := range bodies {
+			hunk.Body = body
+			hunk.order = len(hunks)
+			hunks = append(hunks, hunk)
+		}
+	}
+	return hunks
+}
+
+// splitFileDiffs splits a diff at every "diff --git" line
+func splitFileDiffs(diff string) []string {
+	var files []string
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "diff --git ") || len(files) == 0 {
+			files = append(files, "")
+		}
+		files[len(files)-1] += line
+	}
+	return files
+}
+
+// fileDiffPath returns the path a file diff header names: the new path, or
+// the old one of a deleted file
+func fileDiffPath(header string) string {
+	var oldPath string
+	for _, line := range strings.Split(header, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- a/"):
+			oldPath = strings.TrimRight(line[6:], "\t")
+		case strings.HasPrefix(line, "+++ b/"):
+			return strings.TrimRight(line[6:], "\t")
+		case strings.HasPrefix(line, "+++ /dev/null"):
+			return oldPath
+		}
+	}
+	// Binary files and mode changes have no ---/+++ lines; without renames
+	// "diff --git a/<path> b/<path>" names the same path twice
+	first := strings.SplitN(header, "\n", 2)[0]
+	names := strings.TrimPrefix(first, "diff --git a/")
+	if len(names) > 3 {
+		return names[:(len(names)-3)/2]
+	}
+	return names
+}
+
+// HunkPatch returns a patch of the hunks that git apply accepts, with the
+// hunks of each file under a single header
+func HunkPatch(hunks []StagedHunk) string {
+	sorted := append([]StagedHunk{}, hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].order < sorted[j].order })
+
+	var patch strings.Builder
+	for i, hunk := range sorted {
+		if i == 0 || hunk.Header != sorted[i-1].Header {
+			patch.WriteString(hunk.Header)
+		}
+		patch.WriteString(hunk.Body)
+	}
+	return patch.String()
+}
+
+// HunkChanges describes the hunks as file changes for generating a message
+func (gc *GitCommenter) HunkChanges(hunks []StagedHunk) []FileChange {
+	var changes []FileChange
+	sorted := append([]StagedHunk{}, hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].order < sorted[j].order })
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && sorted[end].Header == sorted[start].Header {
+			end++
+		}
+		diff := HunkPatch(sorted[start:end])
+		added, removed := gc.countDiffLines(diff)
+		changes = append(changes, FileChange{
+			FilePath:     sorted[start].Path,
+			ChangeType:   sorted[start].ChangeType,
+			LinesAdded:   added,
+			LinesRemoved: removed,
+			Diff:         diff,
+		})
+		start = end
+	}
+	return changes
+}
+
+// GroupHunks asks the model to group hunks into logically related changes,
+// e.g. a refactoring apart from the feature built on it, in the order they
+// should be committed. Hunks the model leaves out form a last group.
+func (gc *GitCommenter) GroupHunks(hunks []StagedHunk) ([][]StagedHunk, error) {
+	if len(hunks) < 2 {
+		return [][]StagedHunk{hunks}, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are splitting staged Git changes into separate commits.\n\n")
+	for i, hunk := range hunks {
+		text := hunk.Body
+		if text == "" {
+			text = hunk.Header
+		}
+		if len(text) > maxSplitHunkLength {
+			text = truncateUTF8(text, maxSplitHunkLength) + "\n... (truncated)"
+		}
+		fmt.Fprintf(&prompt, "HUNK %d (%s, %s):\n%s\n", i+1, hunk.Path, hunk.ChangeType, strings.TrimRight(text, "\n"))
+	}
+	prompt.WriteString("\nGroup the hunks into logically related changes, each becoming one commit, ")
+	prompt.WriteString("e.g. keep a refactoring apart from a new feature. Keep hunks that depend on each other together. ")
+	prompt.WriteString("Every hunk belongs to exactly one group. Use a single group when all hunks belong together.\n\n")
+	prompt.WriteString("Respond with one line per group, in the order they should be committed:\nGROUP: <hunk numbers separated by commas>")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to group the staged hunks: %w", err)
+	}
+	groups := parseHunkGroups(response, len(hunks))
+	if groups == nil {
+		return nil, fmt.Errorf("could not find groups in the model response: %q", response)
+	}
+
+	var result [][]StagedHunk
+	for _, group := range groups {
+		var selected []StagedHunk
+		for _, n := range group {
+			selected = append(selected, hunks[n])
+		}
+		result = append(result, selected)
+	}
+	return result, nil
+}
+
+var hunkGroupPattern = regexp.MustCompile(`(?im)^\W*group\b[^:\n]*:[ \t]*([\d, \t]+)`)
+
+// parseHunkGroups returns the zero-based hunk indexes of each group in a
+// grouping response, or nil when it names no hunk. Unknown and repeated
+// numbers are ignored; hunks left out form a last group.
+func parseHunkGroups(response string, hunks int) [][]int {
+	assigned := make([]bool, hunks)
+	var groups [][]int
+	for _, match := range hunkGroupPattern.FindAllStringSubmatch(response, -1) {
+		var group []int
+		for _, field := range strings.FieldsFunc(match[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > hunks || assigned[n-1] {
+				continue
+			}
+			assigned[n-1] = true
+			group = append(group, n-1)
+		}
+		if len(group) > 0 {
+			sort.Ints(group)
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	var rest []int
+	for i, ok := range assigned {
+		if !ok {
+			rest = append(rest, i)
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, rest)
+	}
+	return groups
+}
+
+// CommitSplit commits each SplitCommit in turn: it clears the index, then
+// stages the hunks of a commit with git apply --cached and commits them.
+// The staged files listed in .aiignore, which StagedHunks leaves out, go
+// into the last commit. The working tree is not touched. When a step
+// fails, the hunks not yet committed are staged again. It returns the
+// number of commits made.
+func (gc *GitCommenter) CommitSplit(commits []SplitCommit) (int, error) {
+	if err := gc.checkWritable(); err != nil {
+		return 0, err
+	}
+	staged, err := gc.diffHunks("--cached")
+	if err != nil {
+		return 0, err
+	}
+	_, ignored, err := gc.splitIgnoredHunks(staged)
+	if err != nil {
+		return 0, err
+	}
+	if len(ignored) > 0 && len(commits) > 0 {
+		commits = append([]SplitCommit{}, commits...)
+		last := &commits[len(commits)-1]
+		last.Hunks = append(append([]StagedHunk{}, last.Hunks...), ignored...)
+	}
+	return gc.commitHunks(commits)
+}
+
+// commitHunks makes the commits of CommitSplit from exactly the hunks
+// given, leaving nothing else staged
+func (gc *GitCommenter) commitHunks(commits []SplitCommit) (int, error) {
+	reset := []string{"reset", "-q"}
+	if _, err := gc.HeadCommit(); err != nil {
+		reset = []string{"read-tree", "--empty"}
+	}
+	if err := gc.gitInput(gc.config.RepositoryPath, "", reset...); err != nil {
+		return 0, fmt.Errorf("failed to clear the index: %w", err)
+	}
+
+	for i, commit := range commits {
+		if err := gc.applyCached(HunkPatch(commit.Hunks)); err != nil {
+			return i, gc.restageSplit(commits[i:], fmt.Errorf("failed to stage commit %d: %w", i+1, err))
+		}
+		if err := gc.Commit(commit.Suggestion); err != nil {
+			// The hunks of this commit are staged already
+			return i, gc.restageSplit(commits[i+1:], fmt.Errorf("failed to create commit %d: %w", i+1, err))
+		}
+	}
+	return len(commits), nil
+}
+
+// restageSplit stages the hunks of the commits not made and returns cause,
+// noting when they could not be staged
+func (gc *GitCommenter) restageSplit(rest []SplitCommit, cause error) error {
+	var hunks []StagedHunk
+	for _, commit := range rest {
+		hunks = append(hunks, commit.Hunks...)
+	}
+	if len(hunks) == 0 {
+		return cause
+	}
+	if err := gc.applyCached(HunkPatch(hunks)); err != nil {
+		return fmt.Errorf("%w (restaging the remaining hunks failed too: %v; the working tree still has every change)", cause, err)
+	}
+	return cause
+}
+
+// applyCached stages a patch with git apply --cached. It runs at the top of
+// the working tree: in a subdirectory git skips the files outside it.
+func (gc *GitCommenter) applyCached(patch string) error {
+	top, err := gc.gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to locate the working tree: %w", err)
+	}
+	return gc.gitInput(strings.TrimSpace(top), patch, "apply", "--cached", "-")
+}
+
+// gitInput runs a git command that writes to the repository in dir, with
+// input on its stdin
+func (gc *GitCommenter) gitInput(dir, input string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(input)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("git %s: %s", args[0], text)
+		}
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHunkGroups(t *testing.T) {
+	response := "Here is the grouping:\nGROUP: 3, 1\n**Group 2**: 2, 9, 1\n"
+	if got := parseHunkGroups(response, 4); !reflect.DeepEqual(got, [][]int{{0, 2}, {1}, {3}}) {
+		t.Errorf("Unexpected groups %v", got)
+	}
+	if got := parseHunkGroups("They all belong together.", 2); got != nil {
+		t.Errorf("Expected no groups, got %v", got)
+	}
+}
+
+func TestSplitStagedHunks(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, "line "+strings.Repeat("x", i))
+	}
+	writeFile(t, dir, "main.go", strings.Join(lines, "\n")+"\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	lines[1] = "renamed line"
+	lines[27] = "feature line"
+	writeFile(t, dir, "main.go", strings.Join(lines, "\n")+"\n")
+	writeFile(t, dir, "feature.go", "package feature\n")
+	gitRun(t, dir, "add", ".")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "GROUP: 2\nGROUP: 1, 3", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	hunks, err := gc.StagedHunks()
+	if err != nil {
+		t.Fatalf("StagedHunks returned error: %v", err)
+	}
+	if len(hunks) != 3 || hunks[0].Path != "feature.go" || hunks[0].ChangeType != "added" || hunks[1].Path != "main.go" {
+		t.Fatalf("Expected the new file and two hunks of main.go, got %+v", hunks)
+	}
+
+	groups, err := gc.GroupHunks(hunks)
+	if err != nil {
+		t.Fatalf("GroupHunks returned error: %v", err)
+	}
+	if len(groups) != 2 || len(groups[0]) != 1 || groups[0][0].Path != "main.go" || len(groups[1]) != 2 {
+		t.Fatalf("Unexpected groups %+v", groups)
+	}
+	changes := gc.HunkChanges(groups[1])
+	if len(changes) != 2 || changes[1].FilePath != "main.go" || changes[1].LinesAdded != 1 || strings.Count(changes[1].Diff, "@@ -") != 1 {
+		t.Fatalf("Expected the new file and one hunk of main.go, got %+v", changes)
+	}
+
+	// Commit the refactoring of main.go first, then the feature
+	commits := []SplitCommit{
+		{Hunks: groups[0], Suggestion: &CommitSuggestion{Subject: "refactor: rename the line"}},
+		{Hunks: groups[1], Suggestion: &CommitSuggestion{Subject: "feat: add the feature"}},
+	}
+	made, err := gc.CommitSplit(commits)
+	if err != nil || made != 2 {
+		t.Fatalf("CommitSplit made %d commit(s): %v", made, err)
+	}
+	if got := gitRun(t, dir, "log", "--format=%s", "-3"); got != "feat: add the feature\nrefactor: rename the line\nfirst\n" {
+		t.Errorf("Unexpected history %q", got)
+	}
+	if got := gitRun(t, dir, "show", "--format=", "--stat", "HEAD~1"); !strings.Contains(got, "main.go | 2 +-") {
+		t.Errorf("Expected only the first hunk in the first commit, got %q", got)
+	}
+	if got := gitRun(t, dir, "status", "--porcelain"); got != "" {
+		t.Errorf("Expected a clean tree after the split, got %q", got)
+	}
+
+	// A commit that fails leaves the rest of the hunks staged
+	writeFile(t, dir, "main.go", "changed\n")
+	gitRun(t, dir, "add", ".")
+	hunks, _ = gc.StagedHunks()
+	made, err = gc.CommitSplit([]SplitCommit{{Hunks: hunks, Suggestion: &CommitSuggestion{}}})
+	if err == nil || made != 0 {
+		t.Fatalf("Expected the empty message to fail, made %d: %v", made, err)
+	}
+	if got := gitRun(t, dir, "diff", "--cached", "--name-only"); got != "main.go\n" {
+		t.Errorf("Expected main.go staged again, got %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PullRequest is a pull request with the commits and changes it would
+// squash into one commit
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	// Commits are the pull request's commits, oldest first
+	Commits []Commit
+	// Changes are the files the pull request changes, with their patches
+	Changes []FileChange
+	// CoAuthors are "Name <email>" of commit authors other than the author
+	// of the first commit, for Co-authored-by trailers
+	CoAuthors []string
+}
+
+// githubPullFileStatus maps the file status of the GitHub API to the change
+// types of FileChange
+var githubPullFileStatus = map[string]string{
+	"added":   "added",
+	"removed": "deleted",
+	"renamed": "renamed",
+	"copied":  "copied",
+}
+
+// FetchPullRequest fetches a pull request, its commits and its changed
+// files from the GitHub API. repo is "owner/repo".
+func (gc *GitCommenter) FetchPullRequest(repo string, number int) (*PullRequest, error) {
+	path := fmt.Sprintf("/repos/%s/pulls/%d", repo, number)
+
+	var pull struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := gc.githubGet(path, &pull); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request #%d: %w", number, err)
+	}
+
+	var commits []struct {
+		SHA    string `json:"sha"`
+		Commit struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name  string    `json:"name"`
+				Email string    `json:"email"`
+				Date  time.Time `json:"date"`
+			} `json:"author"`
+		} `json:"commit"`
+	}
+	if err := gc.githubGet(path+"/commits?per_page=100", &commits); err != nil {
+		return nil, fmt.Errorf("failed to fetch commits of pull request #%d: %w", number, err)
+	}
+
+	var files []struct {
+		Filename  string `json:"filename"`
+		Previous  string `json:"previous_filename"`
+		Status    string `json:"status"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+		Patch     string `json:"patch"`
+	}
+	if err := gc.githubGet(path+"/files?per_page=100", &files); err != nil {
+		return nil, fmt.Errorf("failed to fetch files of pull request #%d: %w", number, err)
+	}
+
+	pr := &PullRequest{Number: number, Title: pull.Title, Body: strings.TrimSpace(pull.Body)}
+	seen := make(map[string]bool)
+	for i, c := range commits {
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Commit.Message), "\n")
+		pr.Commits = append(pr.Commits, Commit{
+			Hash:    c.SHA,
+			Author:  c.Commit.Author.Name,
+			Date:    c.Commit.Author.Date,
+			Subject: subject,
+			Body:    strings.TrimSpace(body),
+		})
+
+		email := strings.ToLower(c.Commit.Author.Email)
+		if i > 0 && !seen[email] {
+			pr.CoAuthors = append(pr.CoAuthors, fmt.Sprintf("%s <%s>", c.Commit.Author.Name, c.Commit.Author.Email))
+		}
+		seen[email] = true
+	}
+	for _, file := range files {
+		changeType, ok := githubPullFileStatus[file.Status]
+		if !ok {
+			changeType = "modified"
+		}
+		pr.Changes = append(pr.Changes, FileChange{
+			FilePath:     file.Filename,
+			OldPath:      file.Previous,
+			ChangeType:   changeType,
+			Diff:         file.Patch,
+			LinesAdded:   file.Additions,
+			LinesRemoved: file.Deletions,
+		})
+	}
+	return pr, nil
+}
+
+// GenerateSquashMergeMessage generates the message of the commit that
+// squash-merges pr. Like GitHub's default, the subject ends with the pull
+// request number, and other commit authors are credited with
+// Co-authored-by trailers.
+func (gc *GitCommenter) GenerateSquashMergeMessage(pr *PullRequest) (*CommitSuggestion, error) {
+	if len(pr.Changes) == 0 {
+		return nil, fmt.Errorf("pull request #%d changes no files", pr.Number)
+	}
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing the single commit message used to squash-merge a pull request.\n")
+	prompt.WriteString("Its individual commits are discarded, so the message must describe the combined change.\n\n")
+	prompt.WriteString("PULL REQUEST TITLE: " + pr.Title + "\n")
+	if pr.Body != "" {
+		prompt.WriteString("PULL REQUEST DESCRIPTION:\n")
+		prompt.WriteString(truncateUTF8(pr.Body, 2000))
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("\nCOMMITS BEING SQUASHED:\n")
+	for _, commit := range pr.Commits {
+		prompt.WriteString("- " + commit.Subject + "\n")
+	}
+	prompt.WriteString("\n")
+	prompt.WriteString(gc.buildChangeContext(pr.Changes))
+	prompt.WriteString(formatDiffs(pr.Changes, gc.PromptBudget()))
+	prompt.WriteString(gc.summaryContext(pr.Changes, gc.PromptBudget()))
+
+	prompt.WriteString("Write a commit message that:\n")
+	prompt.WriteString("1. " + style.Format + "\n")
+	prompt.WriteString("2. Has a subject line of 50 characters or less summarizing the whole pull request\n")
+	prompt.WriteString("3. Explains in the body what changed and why, drawing on the description\n")
+	prompt.WriteString("4. Does not list the commits or mention fixups such as 'address review comments' or 'fix typo'\n\n")
+	prompt.WriteString("Respond with only the commit message (subject, blank line, body), no additional text or formatting.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate squash-merge message: %w", err)
+	}
+
+	suggestion := gc.parseCommitSuggestion(response, pr.Changes)
+	suggestion.PromptHash = PromptHash(prompt.String())
+	suggestion.Subject = fmt.Sprintf("%s (#%d)", strings.TrimSuffix(suggestion.Subject, "."), pr.Number)
+	for _, coAuthor := range pr.CoAuthors {
+		suggestion.AddTrailer("Co-authored-by", coAuthor)
+	}
+	return suggestion, nil
+}
+
+// emptyTree is git's empty tree, the base for diffs from the root commit
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// GenerateSquashMessage generates one message for squashing the commits in
+// revRange (e.g. "HEAD~3..HEAD"), e.g. for a squash in git rebase -i, from
+// their messages and combined diff. Authors other than the first commit's
+// are credited with Co-authored-by trailers.
+func (gc *GitCommenter) GenerateSquashMessage(revRange string) (*CommitSuggestion, error) {
+	commits, err := gc.Commits(revRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in %s", revRange)
+	}
+
+	first, last := commits[0].Hash, commits[len(commits)-1].Hash
+	base := first + "^"
+	if _, err := gc.gitOutput("rev-parse", "--verify", "--quiet", base); err != nil {
+		base = emptyTree
+	}
+	changes, err := gc.diffChanges(base, last)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("the commits in %s change no files together", revRange)
+	}
+
+	authors, err := gc.gitOutput("log", "--reverse", "--format=%an%x00%ae", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list authors in %s: %w", revRange, err)
+	}
+	var coAuthors []string
+	seen := make(map[string]bool)
+	for i, author := range strings.Split(strings.TrimSpace(authors), "\n") {
+		name, email, _ := strings.Cut(author, "\x00")
+		if i > 0 && !seen[strings.ToLower(email)] {
+			coAuthors = append(coAuthors, fmt.Sprintf("%s <%s>", name, email))
+		}
+		seen[strings.ToLower(email)] = true
+	}
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are writing the single commit message for squashing several commits into one.\n")
+	prompt.WriteString("The individual commits are discarded, so the message must describe the combined change.\n\n")
+	prompt.WriteString("COMMITS BEING SQUASHED:\n")
+	for _, commit := range commits {
+		prompt.WriteString("- " + commit.Subject + "\n")
+		if commit.Body != "" {
+			prompt.WriteString(indent(truncateUTF8(commit.Body, 500), "  "))
+			prompt.WriteString("\n")
+		}
+	}
+	prompt.WriteString("\n")
+	prompt.WriteString(gc.buildChangeContext(changes))
+	prompt.WriteString(formatDiffs(changes, gc.PromptBudget()))
+	prompt.WriteString(gc.summaryContext(changes, gc.PromptBudget()))
+
+	prompt.WriteString("Write a commit message that:\n")
+	prompt.WriteString("1. " + style.Format + "\n")
+	prompt.WriteString("2. Has a subject line of 50 characters or less summarizing the combined change\n")
+	prompt.WriteString("3. Explains in the body what changed and why, drawing on the commit messages\n")
+	prompt.WriteString("4. Does not list the commits or mention fixups such as 'address review comments' or 'fix typo'\n")
+	if language := gc.language(); language != "en" {
+		prompt.WriteString("5. Is written in " + languageName(language) + "\n")
+	}
+	prompt.WriteString("\nRespond with only the commit message (subject, blank line, body), no additional text or formatting.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate squash message: %w", err)
+	}
+
+	suggestion := gc.parseCommitSuggestion(response, changes)
+	suggestion.PromptHash = PromptHash(prompt.String())
+	for _, coAuthor := range coAuthors {
+		suggestion.AddTrailer("Co-authored-by", coAuthor)
+	}
+	return suggestion, nil
+}
@@ -0,0 +1,127 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSquashMergeMessage(t *testing.T) {
+	commit := func(message, name, email string) map[string]any {
+		return map[string]any{"sha": "abc123", "commit": map[string]any{
+			"message": message,
+			"author":  map[string]string{"name": name, "email": email, "date": "2024-05-01T10:00:00Z"},
+		}}
+	}
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github/repos/acme/app/pulls/42":
+			json.NewEncoder(w).Encode(map[string]string{"title": "Add rate limiting", "body": "Protects the API from bursts."})
+		case "/github/repos/acme/app/pulls/42/commits":
+			json.NewEncoder(w).Encode([]map[string]any{
+				commit("Add limiter middleware", "Ada", "ada@example.com"),
+				commit("address review comments", "Bob", "bob@example.com"),
+				commit("fix typo", "Ada", "ADA@example.com"),
+			})
+		case "/github/repos/acme/app/pulls/42/files":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"filename": "limiter.go", "status": "added", "additions": 3, "deletions": 0, "patch": "+package api"},
+				{"filename": "old.go", "status": "removed", "additions": 0, "deletions": 1, "patch": "-package api"},
+			})
+		case "/api/generate":
+			var req OllamaRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			prompt = req.Prompt
+			json.NewEncoder(w).Encode(OllamaResponse{Response: "feat(api): add rate limiting.\n\nLimit requests per client.", Done: true})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.GitHubAPI = server.URL + "/github"
+	gc := New(config)
+
+	pr, err := gc.FetchPullRequest("acme/app", 42)
+	if err != nil {
+		t.Fatalf("FetchPullRequest returned error: %v", err)
+	}
+	if len(pr.Commits) != 3 || pr.Commits[0].Subject != "Add limiter middleware" {
+		t.Errorf("Unexpected commits %+v", pr.Commits)
+	}
+	if len(pr.Changes) != 2 || pr.Changes[1].ChangeType != "deleted" || pr.Changes[0].LinesAdded != 3 {
+		t.Errorf("Unexpected changes %+v", pr.Changes)
+	}
+	if len(pr.CoAuthors) != 1 || pr.CoAuthors[0] != "Bob <bob@example.com>" {
+		t.Errorf("Expected Bob as the only co-author, got %v", pr.CoAuthors)
+	}
+
+	suggestion, err := gc.GenerateSquashMergeMessage(pr)
+	if err != nil {
+		t.Fatalf("GenerateSquashMergeMessage returned error: %v", err)
+	}
+	want := "feat(api): add rate limiting (#42)\n\nLimit requests per client.\n\nCo-authored-by: Bob <bob@example.com>"
+	if suggestion.Message() != want {
+		t.Errorf("Unexpected message:\n%s\nwant:\n%s", suggestion.Message(), want)
+	}
+	for _, part := range []string{"Add rate limiting", "Protects the API from bursts.", "- address review comments", "limiter.go"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected %q in the prompt:\n%s", part, prompt)
+		}
+	}
+}
+
+func TestGenerateSquashMessage(t *testing.T) {
+	dir := initTestRepo(t)
+	commit := func(file, content, message, author string) {
+		t.Helper()
+		writeFile(t, dir, file, content)
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "--author", author, "-m", message)
+	}
+	commit("limiter.go", "package api\n", "Add limiter", "Ada <ada@example.com>")
+	commit("limiter.go", "package api\n\nfunc Limit() {}\n", "wip", "Bob <bob@example.com>")
+	commit("README.md", "# api\n", "fix typo", "Ada <ADA@example.com>")
+
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "feat(api): add rate limiting\n\nLimit requests per client.", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	// The range includes the root commit, which has no parent to diff against
+	suggestion, err := gc.GenerateSquashMessage("HEAD")
+	if err != nil {
+		t.Fatalf("GenerateSquashMessage returned error: %v", err)
+	}
+	want := "feat(api): add rate limiting\n\nLimit requests per client.\n\nCo-authored-by: Bob <bob@example.com>"
+	if suggestion.Message() != want {
+		t.Errorf("Unexpected message:\n%s\nwant:\n%s", suggestion.Message(), want)
+	}
+	for _, part := range []string{"- Add limiter", "- wip", "+func Limit() {}", "README.md"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected %q in the prompt:\n%s", part, prompt)
+		}
+	}
+
+	if _, err := gc.GenerateSquashMessage("HEAD~1..HEAD"); err != nil {
+		t.Fatalf("GenerateSquashMessage returned error: %v", err)
+	}
+	if strings.Contains(prompt, "limiter.go") {
+		t.Errorf("Expected only the last commit's changes:\n%s", prompt)
+	}
+}
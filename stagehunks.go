@@ -0,0 +1,104 @@
+package gitcommenter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxDescribeHunkLength bounds how much of a hunk DescribeHunk shows
+const maxDescribeHunkLength = 3000
+
+// UnstagedHunks returns the hunks of the working tree changes that are not
+// staged, followed by the untracked files, each as one hunk, without the
+// files listed in .aiignore
+func (gc *GitCommenter) UnstagedHunks() ([]StagedHunk, error) {
+	if err := gc.ensureGitRepository(); err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	changed, err := gc.diffHunks()
+	if err != nil {
+		return nil, err
+	}
+	hunks, err := gc.withoutIgnoredHunks(changed)
+	ignored := errors.Is(err, ErrOnlyIgnored)
+	if err != nil && !ignored {
+		return nil, err
+	}
+	untracked, err := gc.untrackedChanges()
+	if errors.Is(err, ErrOnlyIgnored) {
+		ignored = true
+	} else if err != nil {
+		return nil, err
+	}
+	for i, change := range untracked {
+		hunks = append(hunks, StagedHunk{
+			Path:       change.FilePath,
+			ChangeType: change.ChangeType,
+			Header:     change.Diff,
+			order:      len(changed) + i,
+			untracked:  true,
+		})
+	}
+	if len(hunks) == 0 && ignored {
+		return nil, ErrOnlyIgnored
+	}
+	return hunks, nil
+}
+
+// DescribeHunk asks the model what a hunk changes, in one line
+func (gc *GitCommenter) DescribeHunk(hunk StagedHunk) (string, error) {
+	diff := HunkPatch([]StagedHunk{hunk})
+	if len(diff) > maxDescribeHunkLength {
+		diff = truncateUTF8(diff, maxDescribeHunkLength) + "\n... (truncated)"
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "DIFF of %s (%s):\n%s\n\n", hunk.Path, hunk.ChangeType, diff)
+	prompt.WriteString("Describe what this change does in one line of at most 72 characters, ")
+	prompt.WriteString("naming the affected functionality. Respond with the line only, without a prefix or quotes.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to describe the hunk: %w", err)
+	}
+	return parseHunkDescription(response), nil
+}
+
+// parseHunkDescription returns the first line of a description response
+// without list markers and quotes
+func parseHunkDescription(response string) string {
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "-*•\"'` ")
+		if line != "" {
+			return truncateUTF8(line, 100)
+		}
+	}
+	return ""
+}
+
+// StageHunks stages hunks, e.g. the ones of UnstagedHunks a user picked:
+// untracked files with git add, the other hunks with git apply --cached
+func (gc *GitCommenter) StageHunks(hunks []StagedHunk) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	var tracked []StagedHunk
+	var untracked []string
+	for _, hunk := range hunks {
+		if hunk.untracked {
+			untracked = append(untracked, hunk.Path)
+		} else {
+			tracked = append(tracked, hunk)
+		}
+	}
+	if len(tracked) > 0 {
+		if err := gc.applyCached(HunkPatch(tracked)); err != nil {
+			return fmt.Errorf("failed to stage hunks: %w", err)
+		}
+	}
+	if len(untracked) > 0 {
+		return gc.Restage(untracked)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHunkDescription(t *testing.T) {
+	for response, want := range map[string]string{
+		"Renames the config loader":               "Renames the config loader",
+		"\n- \"Adds retry to the HTTP client\"\n": "Adds retry to the HTTP client",
+		"* `Fixes the typo`":                      "Fixes the typo",
+	} {
+		if got := parseHunkDescription(response); got != want {
+			t.Errorf("parseHunkDescription(%q) = %q, want %q", response, got, want)
+		}
+	}
+}
+
+func TestStageUnstagedHunks(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, "line "+strings.Repeat("x", i))
+	}
+	writeFile(t, dir, "main.go", strings.Join(lines, "\n")+"\n")
+	writeFile(t, dir, "sub/keep.txt", "keep\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	lines[1] = "first change"
+	lines[27] = "second change"
+	writeFile(t, dir, "main.go", strings.Join(lines, "\n")+"\n")
+	writeFile(t, dir, "new.txt", "new\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "- Changes a line\n", Done: true})
+	}))
+	defer server.Close()
+
+	// Paths are repository-relative from a subdirectory too
+	config := DefaultConfig()
+	config.RepositoryPath = filepath.Join(dir, "sub")
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+
+	hunks, err := gc.UnstagedHunks()
+	if err != nil {
+		t.Fatalf("UnstagedHunks returned error: %v", err)
+	}
+	if len(hunks) != 3 || hunks[0].Path != "main.go" || hunks[2].Path != "new.txt" || !hunks[2].untracked {
+		t.Fatalf("Expected two hunks of main.go and the untracked file, got %+v", hunks)
+	}
+	if description, err := gc.DescribeHunk(hunks[0]); err != nil || description != "Changes a line" {
+		t.Errorf("Unexpected description %q: %v", description, err)
+	}
+
+	if err := gc.StageHunks([]StagedHunk{hunks[1], hunks[2]}); err != nil {
+		t.Fatalf("StageHunks returned error: %v", err)
+	}
+	staged := gitRun(t, dir, "diff", "--cached")
+	if !strings.Contains(staged, "+second change") || strings.Contains(staged, "+first change") || !strings.Contains(staged, "+++ b/new.txt") {
+		t.Errorf("Expected the second hunk and the new file staged, got:\n%s", staged)
+	}
+	if unstaged := gitRun(t, dir, "diff"); !strings.Contains(unstaged, "+first change") {
+		t.Errorf("Expected the first hunk left unstaged, got:\n%s", unstaged)
+	}
+}
@@ -0,0 +1,79 @@
+package gitcommenter
+
+import "fmt"
+
+// WorktreeBackend is implemented by backends that can compare the index
+// with the working tree and stage files
+type WorktreeBackend interface {
+	// UnstagedFiles lists tracked files whose working tree content differs
+	// from the index
+	UnstagedFiles() ([]string, error)
+	// Stage adds the current content of the files to the index
+	Stage(paths ...string) error
+	// Unstage resets the index entries of the files to HEAD, keeping the
+	// working tree
+	Unstage(paths ...string) error
+}
+
+// StaleStagedFiles lists staged files that were edited again after staging,
+// so the commit would not contain what is in the working tree. Backends
+// without WorktreeBackend report none.
+func (gc *GitCommenter) StaleStagedFiles() ([]string, error) {
+	worktree, ok := gc.git.(WorktreeBackend)
+	if !ok {
+		return nil, nil
+	}
+
+	staged, err := gc.git.StagedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged files: %w", err)
+	}
+	unstaged, err := worktree.UnstagedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unstaged files: %w", err)
+	}
+
+	edited := make(map[string]bool)
+	for _, path := range unstaged {
+		edited[path] = true
+	}
+
+	var stale []string
+	for _, file := range staged {
+		if edited[file.Path] {
+			stale = append(stale, file.Path)
+		}
+	}
+	return stale, nil
+}
+
+// Restage stages the current working tree content of the files
+func (gc *GitCommenter) Restage(paths []string) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	worktree, ok := gc.git.(WorktreeBackend)
+	if !ok {
+		return fmt.Errorf("git backend cannot stage files")
+	}
+	if err := worktree.Stage(paths...); err != nil {
+		return fmt.Errorf("failed to stage files: %w", err)
+	}
+	return nil
+}
+
+// Unstage removes the files from the pending commit, keeping their changes
+// in the working tree
+func (gc *GitCommenter) Unstage(paths []string) error {
+	if err := gc.checkWritable(); err != nil {
+		return err
+	}
+	worktree, ok := gc.git.(WorktreeBackend)
+	if !ok {
+		return fmt.Errorf("git backend cannot unstage files")
+	}
+	if err := worktree.Unstage(paths...); err != nil {
+		return fmt.Errorf("failed to unstage files: %w", err)
+	}
+	return nil
+}
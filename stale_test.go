@@ -0,0 +1,60 @@
+package gitcommenter
+
+import (
+	"testing"
+)
+
+func TestStaleStagedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+
+	writeFile(t, dir, "a.go", "package a\n")
+	writeFile(t, dir, "b.go", "package a\n")
+	gitRun(t, dir, "add", "a.go", "b.go")
+	writeFile(t, dir, "a.go", "package a // edited after staging\n")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: dir})
+
+	stale, err := gc.StaleStagedFiles()
+	if err != nil {
+		t.Fatalf("StaleStagedFiles returned error: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != "a.go" {
+		t.Fatalf("Expected a.go to be stale, got %v", stale)
+	}
+
+	if err := gc.Restage(stale); err != nil {
+		t.Fatalf("Restage returned error: %v", err)
+	}
+	if stale, _ := gc.StaleStagedFiles(); len(stale) != 0 {
+		t.Errorf("Expected no stale files after restaging, got %v", stale)
+	}
+}
+
+func TestUnstage(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, name := range []string{"a.go", "b.go"} {
+		writeFile(t, dir, name, "package a\n")
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: dir})
+
+	if err := gc.Restage([]string{"a.go", "b.go"}); err != nil {
+		t.Fatalf("Restage returned error: %v", err)
+	}
+	if err := gc.Unstage([]string{"b.go"}); err != nil {
+		t.Fatalf("Unstage returned error: %v", err)
+	}
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].FilePath != "a.go" {
+		t.Errorf("Expected only a.go staged, got %+v", changes)
+	}
+}
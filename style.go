@@ -0,0 +1,278 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StylePreset describes how messages of one commit style are requested from
+// the model and how the result is checked
+type StylePreset struct {
+	// Name is the identifier used with --style and Config.Style
+	Name string
+	// Description is a one-line summary shown in help and the init wizard
+	Description string
+	// Format is the prompt instruction describing the subject format
+	Format string
+	// Examples are good subjects shown to the model
+	Examples []string
+	// RequireBody rejects messages without a body
+	RequireBody bool
+	// Conventional styles use "type(scope): description" subjects, so the
+	// type and scope whitelists apply
+	Conventional bool
+	// validateSubject checks the subject (without the ticket prefix)
+	validateSubject func(subject string) error
+}
+
+var (
+	conventionalSubject = regexp.MustCompile(`^([a-z]+)(\(([^()]+)\))?(!)?: (\S.*)$`)
+	ticketReference     = regexp.MustCompile(`(?i)\b([a-z][a-z0-9]+-[0-9]+)\b`)
+	ticketFirstSubject  = regexp.MustCompile(`^[A-Z][A-Z0-9]+-[0-9]+:? \S`)
+)
+
+// Styles are the built-in commit message styles keyed by name
+var Styles = map[string]StylePreset{
+	"conventional": {
+		Name:         "conventional",
+		Description:  "type(scope): subject, e.g. 'feat(cli): add init wizard'",
+		Format:       "Uses conventional commit format (feat/fix/docs/style/refactor/test/chore)",
+		Conventional: true,
+		Examples: []string{
+			"feat: add interactive model selection with recommendations",
+			"fix: correct model validation in prerequisites check",
+			"refactor: enhance logging with detailed progress indicators",
+			"feat: implement git push with remote repository detection",
+		},
+		validateSubject: func(subject string) error {
+			if !conventionalSubject.MatchString(subject) {
+				return fmt.Errorf("subject %q is not in 'type(scope): description' form", subject)
+			}
+			return nil
+		},
+	},
+	"gitmoji": {
+		Name:        "gitmoji",
+		Description: "emoji followed by the subject, e.g. '✨ Add init wizard'",
+		Format:      "Starts with a single gitmoji matching the change followed by a capitalized imperative description",
+		Examples: []string{
+			"✨ Add interactive model selection with recommendations",
+			"🐛 Fix model validation in prerequisites check",
+			"♻️ Simplify progress logging",
+			"📝 Document the push workflow",
+		},
+		validateSubject: func(subject string) error {
+			if _, rest, ok := SplitGitmoji(subject); !ok || rest == "" {
+				return fmt.Errorf("subject %q does not start with a gitmoji from the official list", subject)
+			}
+			return nil
+		},
+	},
+	"plain": {
+		Name:        "plain",
+		Description: "capitalized imperative subject without a type prefix",
+		Format:      "Uses a plain imperative subject without a type prefix (e.g. 'Add retry to HTTP client')",
+		Examples: []string{
+			"Add interactive model selection with recommendations",
+			"Fix model validation in prerequisites check",
+			"Implement git push with remote repository detection",
+		},
+		validateSubject: func(subject string) error {
+			if conventionalSubject.MatchString(subject) {
+				return fmt.Errorf("subject %q should not have a type prefix", subject)
+			}
+			if first, _ := utf8.DecodeRuneInString(subject); !unicode.IsUpper(first) {
+				return fmt.Errorf("subject %q should start with a capital letter", subject)
+			}
+			return nil
+		},
+	},
+	"detailed": {
+		Name:        "detailed",
+		Description: "conventional subject plus a body explaining what and why",
+		Format:      "Uses conventional commit format (feat/fix/docs/style/refactor/test/chore) and ALWAYS includes a body of bullet points explaining what changed and why",
+		Examples: []string{
+			"feat(cli): add interactive model selection",
+			"fix(config): respect explicit flags over config files",
+		},
+		RequireBody:  true,
+		Conventional: true,
+		validateSubject: func(subject string) error {
+			if !conventionalSubject.MatchString(subject) {
+				return fmt.Errorf("subject %q is not in 'type(scope): description' form", subject)
+			}
+			return nil
+		},
+	},
+	"ticket-first": {
+		Name:        "ticket-first",
+		Description: "ticket reference first, e.g. 'PROJ-123 Add init wizard'",
+		Format:      "Starts with the ticket reference followed by a plain imperative description (e.g. 'PROJ-123 Add retry to HTTP client')",
+		Examples: []string{
+			"PROJ-123 Add interactive model selection",
+			"OPS-42 Fix model validation in prerequisites check",
+		},
+		validateSubject: func(subject string) error {
+			if !ticketFirstSubject.MatchString(subject) {
+				return fmt.Errorf("subject %q does not start with a ticket reference", subject)
+			}
+			return nil
+		},
+	},
+}
+
+// StyleNames returns the names of the built-in styles in sorted order
+func StyleNames() []string {
+	names := make([]string, 0, len(Styles))
+	for name := range Styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupStyle returns the style with the given name; an empty name is the
+// conventional style
+func LookupStyle(name string) (StylePreset, error) {
+	if name == "" {
+		name = "conventional"
+	}
+	style, ok := Styles[name]
+	if !ok {
+		return StylePreset{}, fmt.Errorf("unknown style %q (available: %s)", name, strings.Join(StyleNames(), ", "))
+	}
+	return style, nil
+}
+
+// Validate checks that a suggestion follows the style. A configured ticket
+// prefix is ignored, except by ticket-first which is about the ticket.
+func (s StylePreset) Validate(suggestion *CommitSuggestion, ticketPrefix string) error {
+	subject := suggestion.Subject
+	if subject == "" {
+		return fmt.Errorf("empty subject")
+	}
+	if ticketPrefix != "" && s.Name != "ticket-first" {
+		subject = strings.TrimSpace(strings.TrimPrefix(subject, ticketPrefix))
+	}
+
+	if s.validateSubject != nil {
+		if err := s.validateSubject(subject); err != nil {
+			return err
+		}
+	}
+	if s.RequireBody && strings.TrimSpace(suggestion.Body) == "" {
+		return fmt.Errorf("the %s style requires a message body", s.Name)
+	}
+	return nil
+}
+
+// ConventionalSubject is a parsed "type(scope)!: description" subject
+type ConventionalSubject struct {
+	Type        string
+	Scopes      []string
+	Breaking    bool
+	Description string
+}
+
+// ParseConventionalSubject parses a conventional commit subject. Multiple
+// scopes may be separated by commas, e.g. "fix(api,cli): ...".
+func ParseConventionalSubject(subject string) (ConventionalSubject, bool) {
+	match := conventionalSubject.FindStringSubmatch(subject)
+	if match == nil {
+		return ConventionalSubject{}, false
+	}
+
+	parsed := ConventionalSubject{Type: match[1], Breaking: match[4] == "!", Description: match[5]}
+	if match[3] != "" {
+		parsed.Scopes = splitCommaList(match[3])
+	}
+	return parsed, true
+}
+
+// validateSuggestion checks a suggestion against the style, the type and
+// scope whitelists and, in gitmoji mode, requires an official gitmoji in
+// front of the styled subject
+func (gc *GitCommenter) validateSuggestion(style StylePreset, suggestion *CommitSuggestion) error {
+	ticket := gc.ticketPrefix()
+	styled := *suggestion
+
+	if gc.config.Gitmoji && style.Name != "gitmoji" {
+		// The gitmoji follows the ticket prefix, if any
+		subject := strings.TrimSpace(strings.TrimPrefix(suggestion.Subject, ticket))
+		_, rest, ok := SplitGitmoji(subject)
+		if !ok {
+			return fmt.Errorf("subject %q does not start with a gitmoji from the official list", suggestion.Subject)
+		}
+		styled.Subject = strings.TrimSpace(ticket + " " + rest)
+	}
+
+	if err := style.Validate(&styled, ticket); err != nil {
+		return err
+	}
+	if !style.Conventional {
+		return nil
+	}
+
+	subject := strings.TrimSpace(strings.TrimPrefix(styled.Subject, ticket))
+	parsed, _ := ParseConventionalSubject(subject)
+	if types := gc.allowedTypes(false); len(types) > 0 && !containsString(types, parsed.Type) {
+		return fmt.Errorf("type %q is not allowed (use one of %s)", parsed.Type, strings.Join(types, ", "))
+	}
+	if scopes := gc.allowedScopes(false); len(scopes) > 0 {
+		for _, scope := range parsed.Scopes {
+			if !containsString(scopes, scope) {
+				return fmt.Errorf("scope %q is not allowed (use one of %s or none)", scope, strings.Join(scopes, ", "))
+			}
+		}
+	}
+	if gc.config.Commitlint != nil {
+		return gc.config.Commitlint.validate(suggestion.Subject, subject)
+	}
+	return nil
+}
+
+// allowedTypes returns the type whitelist: Config.Types, or else the
+// commitlint type-enum. When not prompting, only error-level rules count.
+func (gc *GitCommenter) allowedTypes(prompting bool) []string {
+	if len(gc.config.Types) > 0 || gc.config.Commitlint == nil {
+		return gc.config.Types
+	}
+	return gc.config.Commitlint.enforcedList("type-enum", gc.config.Commitlint.Types, prompting)
+}
+
+// allowedScopes returns the scope whitelist like allowedTypes
+func (gc *GitCommenter) allowedScopes(prompting bool) []string {
+	if len(gc.config.Scopes) > 0 || gc.config.Commitlint == nil {
+		return gc.config.Scopes
+	}
+	return gc.config.Commitlint.enforcedList("scope-enum", gc.config.Commitlint.Scopes, prompting)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// gitmojiMode reports whether subjects carry a gitmoji
+func (gc *GitCommenter) gitmojiMode() bool {
+	return gc.config.Gitmoji || gc.config.Style == "gitmoji"
+}
+
+// TicketFromBranch extracts a ticket reference such as "PROJ-123" from a
+// branch name like "feature/proj-123-login", or returns ""
+func TicketFromBranch(branch string) string {
+	match := ticketReference.FindStringSubmatch(branch)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(match[1])
+}
@@ -0,0 +1,139 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStyleValidate(t *testing.T) {
+	tests := []struct {
+		style   string
+		subject string
+		body    string
+		valid   bool
+	}{
+		{"conventional", "feat(cli): add init wizard", "", true},
+		{"conventional", "Add init wizard", "", false},
+		{"gitmoji", "✨ Add init wizard", "", true},
+		{"gitmoji", ":bug: Fix crash on empty diff", "", true},
+		{"gitmoji", "feat: add init wizard", "", false},
+		{"plain", "Add init wizard", "", true},
+		{"plain", "fix: crash on empty diff", "", false},
+		{"plain", "add init wizard", "", false},
+		{"detailed", "feat: add init wizard", "- asks for a model", true},
+		{"detailed", "feat: add init wizard", "", false},
+		{"ticket-first", "PROJ-123 Add init wizard", "", true},
+		{"ticket-first", "Add init wizard", "", false},
+	}
+
+	for _, test := range tests {
+		style, err := LookupStyle(test.style)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = style.Validate(&CommitSuggestion{Subject: test.subject, Body: test.body}, "")
+		if (err == nil) != test.valid {
+			t.Errorf("%s %q: got error %v, want valid=%v", test.style, test.subject, err, test.valid)
+		}
+	}
+
+	// A configured ticket prefix does not break the conventional format
+	style, _ := LookupStyle("")
+	if err := style.Validate(&CommitSuggestion{Subject: "PROJ-1 feat: add x"}, "PROJ-1"); err != nil {
+		t.Errorf("Expected ticket prefix to be ignored, got %v", err)
+	}
+
+	if _, err := LookupStyle("haiku"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
+
+func TestTicketFromBranch(t *testing.T) {
+	tests := map[string]string{
+		"feature/proj-123-login": "PROJ-123",
+		"OPS-42":                 "OPS-42",
+		"main":                   "",
+	}
+	for branch, want := range tests {
+		if got := TicketFromBranch(branch); got != want {
+			t.Errorf("TicketFromBranch(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestGenerateCommitMessageRetriesStyle(t *testing.T) {
+	responses := []string{"Add init wizard", "feat: add init wizard"}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := responses[len(responses)-1]
+		if calls < len(responses) {
+			response = responses[calls]
+		}
+		calls++
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	changes := []FileChange{{FilePath: "init.go", ChangeType: "added", Diff: "+package main"}}
+
+	suggestion, err := gc.GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if calls != 2 || suggestion.Subject != "feat: add init wizard" || len(suggestion.Warnings) != 0 {
+		t.Errorf("Expected a valid message on the second call, got %+v after %d calls", suggestion, calls)
+	}
+
+	// A model that keeps breaking the style produces a warning
+	responses, calls = []string{"Add init wizard"}, 0
+	suggestion, err = gc.GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if calls != styleAttempts || len(suggestion.Warnings) != 1 {
+		t.Errorf("Expected %d calls and a warning, got %d calls and %v", styleAttempts, calls, suggestion.Warnings)
+	}
+}
+
+func TestValidateTypeAndScopeWhitelist(t *testing.T) {
+	config := DefaultConfig()
+	config.Types = []string{"feat", "fix", "chore"}
+	config.Scopes = []string{"api", "cli"}
+	gc := New(config)
+	style, _ := LookupStyle("conventional")
+
+	tests := map[string]bool{
+		"feat(cli): add init wizard":     true,
+		"fix: handle empty diff":         true,
+		"fix(api,cli)!: rename flags":    true,
+		"docs: describe profiles":        false,
+		"feat(web): add login page":      false,
+		"feat(api,web): share the token": false,
+	}
+	for subject, valid := range tests {
+		err := gc.validateSuggestion(style, &CommitSuggestion{Subject: subject})
+		if (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid=%v", subject, err, valid)
+		}
+	}
+
+	prompt := gc.buildPrompt("", nil)
+	if !contains(prompt, "Allowed types: feat, fix, chore") || !contains(prompt, "Allowed scopes: api, cli") {
+		t.Errorf("Expected the whitelists in the prompt, got:\n%s", prompt)
+	}
+}
+
+func TestParseConventionalSubject(t *testing.T) {
+	parsed, ok := ParseConventionalSubject("feat(api,cli)!: rename flags")
+	if !ok || parsed.Type != "feat" || len(parsed.Scopes) != 2 || !parsed.Breaking || parsed.Description != "rename flags" {
+		t.Errorf("Unexpected parse result %+v (ok %v)", parsed, ok)
+	}
+	if _, ok := ParseConventionalSubject("Add init wizard"); ok {
+		t.Error("Expected a plain subject not to parse")
+	}
+}
@@ -0,0 +1,78 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// shortenAttempts is how many times an overlong subject is sent back to the
+// model before it is truncated
+const shortenAttempts = 2
+
+// subjectLimit returns the maximum subject length: Config.MaxSubjectLength,
+// lowered by a commitlint header-max-length. Zero means unlimited.
+func (gc *GitCommenter) subjectLimit() int {
+	limit := gc.config.MaxSubjectLength
+	if rules := gc.config.Commitlint; rules != nil && rules.HeaderMaxLength > 0 && (limit <= 0 || rules.HeaderMaxLength < limit) {
+		limit = rules.HeaderMaxLength
+	}
+	return limit
+}
+
+// shortenSubject asks the model to shorten a subject over the limit and,
+// if it still does not fit, truncates it at a word boundary. A shortened
+// subject is only accepted if it still follows the style.
+func (gc *GitCommenter) shortenSubject(style StylePreset, scope string, suggestion *CommitSuggestion) (*CommitSuggestion, error) {
+	limit := gc.subjectLimit()
+	if limit <= 0 {
+		return suggestion, nil
+	}
+
+	for attempt := 0; attempt < shortenAttempts && utf8.RuneCountInString(suggestion.Subject) > limit; attempt++ {
+		// The attempt selects the cache slot, so a rejected answer is not
+		// returned again
+		response, err := gc.callOllamaCandidate(shortenPrompt(suggestion.Subject, limit), attempt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to shorten subject: %w", err)
+		}
+
+		shortened := *suggestion
+		shortened.Subject = gc.parseCommitSuggestion(response, nil).Subject
+		if style.Conventional && scope != "" {
+			shortened.Subject = forceScope(shortened.Subject, gc.ticketPrefix(), scope)
+		}
+		if utf8.RuneCountInString(shortened.Subject) < utf8.RuneCountInString(suggestion.Subject) &&
+			gc.validateSuggestion(style, &shortened) == nil {
+			suggestion = &shortened
+		}
+	}
+
+	if utf8.RuneCountInString(suggestion.Subject) > limit {
+		suggestion.Subject = TruncateSubject(suggestion.Subject, limit)
+		suggestion.Warnings = append(suggestion.Warnings, fmt.Sprintf("subject truncated to %d characters", limit))
+	}
+	return suggestion, nil
+}
+
+// shortenPrompt asks the model for a shorter version of a subject
+func shortenPrompt(subject string, limit int) string {
+	return fmt.Sprintf("This commit message subject is %d characters long:\n%s\n\n"+
+		"Shorten it to at most %d characters. Keep the same format, prefix and meaning. "+
+		"Respond with only the shortened subject.", utf8.RuneCountInString(subject), subject, limit)
+}
+
+// TruncateSubject cuts a subject to at most limit characters, at the last
+// word boundary unless that would drop more than half of it
+func TruncateSubject(subject string, limit int) string {
+	runes := []rune(subject)
+	if len(runes) <= limit {
+		return subject
+	}
+
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i >= 0 && utf8.RuneCountInString(cut[:i]) > limit/2 && runes[limit] != ' ' {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-")
+}
@@ -0,0 +1,69 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTruncateSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		limit   int
+		want    string
+	}{
+		{"feat: add wizard", 50, "feat: add wizard"},
+		{"feat: add interactive setup wizard", 24, "feat: add interactive"},
+		{"feat: add interactive setup wizard", 21, "feat: add interactive"},
+		{"feat: supercalifragilistic", 12, "feat: superc"},
+		{"fix: ünïcödé everywhere", 12, "fix: ünïcödé"},
+	}
+	for _, test := range tests {
+		if got := TruncateSubject(test.subject, test.limit); got != test.want {
+			t.Errorf("TruncateSubject(%q, %d) = %q, want %q", test.subject, test.limit, got, test.want)
+		}
+	}
+}
+
+func TestGenerateCommitMessageShortensSubject(t *testing.T) {
+	long := "feat: add an interactive setup wizard that asks for the model, style and push behavior"
+	var shortenResponses []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		response := long
+		if strings.HasPrefix(req.Prompt, "This commit message subject") {
+			response, shortenResponses = shortenResponses[0], shortenResponses[1:]
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.MaxSubjectLength = 50
+	changes := []FileChange{{FilePath: "init.go", ChangeType: "added", Diff: "+package main"}}
+
+	// The second answer fits and follows the style
+	shortenResponses = []string{"Add setup wizard", "feat: add interactive setup wizard"}
+	suggestion, err := New(config).GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if suggestion.Subject != "feat: add interactive setup wizard" || len(suggestion.Warnings) != 0 {
+		t.Errorf("Expected the shortened subject, got %+v", suggestion)
+	}
+
+	// A model that cannot shorten it gets truncated
+	shortenResponses = []string{long, long}
+	suggestion, err = New(config).GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if len(suggestion.Subject) > 50 || len(suggestion.Warnings) != 1 {
+		t.Errorf("Expected a truncated subject with a warning, got %+v", suggestion)
+	}
+}
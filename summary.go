@@ -0,0 +1,75 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxSummaryDiffLength bounds how much of a diff SummarizeFile shows
+const maxSummaryDiffLength = 6000
+
+// blobIndexPattern matches the "index <old>..<new>" line of a git diff
+var blobIndexPattern = regexp.MustCompile(`(?m)^index ([0-9a-f]+)\.\.([0-9a-f]+)`)
+
+// summaryCacheKey identifies a file's change by the blobs before and after
+// it, so the summary is reused whichever command diffed the file. Diffs
+// without an index line, e.g. of hunks, are keyed by their content.
+func (gc *GitCommenter) summaryCacheKey(change FileChange) string {
+	if match := blobIndexPattern.FindStringSubmatch(change.Diff); match != nil {
+		return CacheKey("summary", gc.config.Model, change.FilePath, match[1], match[2])
+	}
+	return CacheKey("summary", gc.config.Model, change.FilePath, change.Diff)
+}
+
+// SummarizeFile asks the model what a file's change does, in one line.
+// Summaries are cached by the file's blobs (see SetCache), so regenerating
+// a message or describing a branch does not summarize the file again.
+func (gc *GitCommenter) SummarizeFile(change FileChange) (string, error) {
+	key := gc.summaryCacheKey(change)
+	if summary, ok := gc.summaries.Load(key); ok {
+		return summary.(string), nil
+	}
+	if summary, ok := gc.cachedResponse(key); ok {
+		return summary, nil
+	}
+
+	diff := change.Diff
+	if len(diff) > maxSummaryDiffLength {
+		diff = truncateUTF8(diff, maxSummaryDiffLength) + "\n... (truncated)"
+	}
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "DIFF of %s (%s):\n%s\n\n", change.FilePath, change.ChangeType, diff)
+	prompt.WriteString("Summarize what this change does to the file in one line of at most 100 characters, ")
+	prompt.WriteString("naming the affected functions or features. Respond with the line only, without a prefix or quotes.")
+
+	response, err := gc.callOllama(prompt.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize %s: %w", change.FilePath, err)
+	}
+	summary := parseHunkDescription(response)
+	if summary != "" {
+		gc.summaries.Store(key, summary)
+		gc.storeResponse(key, summary)
+	}
+	return summary, nil
+}
+
+// summaryContext lists a summary of each file whose diff the budget cuts
+// short or leaves out, when Config.SummarizeFiles is set. Files the model
+// fails to summarize are left out.
+func (gc *GitCommenter) summaryContext(changes []FileChange, budget PromptBudget) string {
+	if !gc.config.SummarizeFiles {
+		return ""
+	}
+	var lines []string
+	for _, change := range layoutDiffs(changes, budget).cut() {
+		if summary, err := gc.SummarizeFile(change); err == nil && summary != "" {
+			lines = append(lines, "- "+change.FilePath+": "+summary)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "SUMMARIES OF FILES NOT SHOWN IN FULL:\n" + strings.Join(lines, "\n") + "\n\n"
+}
@@ -0,0 +1,78 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeFileCachedByBlobs(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "\"Adds retries to the client\"\n", Done: true})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newGC := func() *GitCommenter {
+		config := DefaultConfig()
+		config.OllamaEndpoint = server.URL
+		gc := New(config)
+		cache, err := NewFileCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gc.SetCache(cache)
+		return gc
+	}
+
+	change := FileChange{FilePath: "client.go", ChangeType: "modified",
+		Diff: "diff --git a/client.go b/client.go\nindex 1a2b3c4..5d6e7f8 100644\n@@ -1 +1,2 @@\n+retry()\n"}
+	summary, err := newGC().SummarizeFile(change)
+	if err != nil || summary != "Adds retries to the client" {
+		t.Fatalf("SummarizeFile = %q, %v", summary, err)
+	}
+
+	// The same blobs diffed with other context, in a later run
+	change.Diff = strings.Replace(change.Diff, "@@ -1 +1,2 @@", "@@ -1,3 +1,4 @@\n context", 1)
+	if summary, err := newGC().SummarizeFile(change); err != nil || summary != "Adds retries to the client" || calls != 1 {
+		t.Errorf("Expected the cached summary, got %q, %v after %d calls", summary, err, calls)
+	}
+
+	change.Diff = strings.Replace(change.Diff, "5d6e7f8", "9a8b7c6", 1)
+	if _, err := newGC().SummarizeFile(change); err != nil || calls != 2 {
+		t.Errorf("Expected a changed blob to be summarized again, %v after %d calls", err, calls)
+	}
+}
+
+func TestSummaryContextListsCutFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		path := strings.Fields(req.Prompt)[2]
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "Changes " + path, Done: true})
+	}))
+	defer server.Close()
+
+	changes := []FileChange{
+		{FilePath: "big.go", ChangeType: "modified", Diff: strings.Repeat("+big\n", 400), LinesAdded: 400},
+		{FilePath: "small.go", ChangeType: "modified", Diff: "+small\n", LinesAdded: 1},
+		{FilePath: "rest.go", ChangeType: "modified", Diff: "+rest\n", LinesAdded: 1},
+	}
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	budget := PromptBudget{MaxBytes: 10000, MaxFiles: 2, MaxFileBytes: 300}
+	if context := gc.summaryContext(changes, budget); context != "" {
+		t.Errorf("Expected no summaries unless enabled, got %q", context)
+	}
+
+	config.SummarizeFiles = true
+	want := "SUMMARIES OF FILES NOT SHOWN IN FULL:\n- big.go: Changes big.go\n- rest.go: Changes rest.go\n\n"
+	if context := gc.summaryContext(changes, budget); context != want {
+		t.Errorf("summaryContext = %q, want %q", context, want)
+	}
+}
@@ -0,0 +1,250 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Limits of the symbol changes shown in a prompt
+const (
+	// maxSymbolFiles bounds how many files are analyzed for one commit
+	maxSymbolFiles = 10
+	// maxSymbolChanges bounds how many symbol changes are listed
+	maxSymbolChanges = 30
+)
+
+// SymbolChange is a function, method or type that a change added, removed
+// or modified in one file
+type SymbolChange struct {
+	// File is the path of the file, relative to the repository root
+	File string
+	// Symbol identifies the declaration, e.g. "New", "(*Client).Retry" or
+	// "type Option"
+	Symbol string
+	// Kind is "added", "removed", "signature" (a changed signature) or
+	// "modified" (a changed body)
+	Kind string
+	// Old and New are the declarations before and after the change,
+	// without bodies, e.g. "func (c *Client) Retry(n int) error"
+	Old string
+	New string
+}
+
+// String formats the change for prompts, e.g. "added func (c *Client)
+// Retry(n int) error" or "changed signature of New"
+func (c SymbolChange) String() string {
+	switch c.Kind {
+	case "added":
+		return "added " + c.New
+	case "removed":
+		return "removed " + c.Old
+	case "signature":
+		return "changed signature of " + c.Symbol + ": " + c.Old + " -> " + c.New
+	default:
+		return "modified " + c.New
+	}
+}
+
+// SymbolAnalyzer finds the changed symbols of files in languages other
+// than Go, e.g. the tree-sitter analyzer of the treesitter package
+type SymbolAnalyzer interface {
+	// Supports reports whether the analyzer understands the file at path
+	Supports(path string) bool
+	// SymbolChanges compares two versions of a file like GoSymbolChanges
+	SymbolChanges(file, before, after string) []SymbolChange
+}
+
+// SetSymbolAnalyzer sets the analyzer of non-Go files listed in the prompt
+// (nil analyzes Go files only)
+func (gc *GitCommenter) SetSymbolAnalyzer(analyzer SymbolAnalyzer) {
+	gc.symbolAnalyzer = analyzer
+}
+
+// goSymbol is a top-level declaration of a Go file
+type goSymbol struct {
+	// declaration is the declaration without the body
+	declaration string
+	// body is the rest of the declaration, compared to find modifications
+	body string
+}
+
+// GoSymbolChanges compares the functions, methods and types of two
+// versions of a Go file; before is empty for a new file and after for a
+// deleted one. Changes to comments and formatting are ignored. A version
+// that does not parse yields no changes.
+func GoSymbolChanges(file, before, after string) []SymbolChange {
+	oldSymbols, oldOrder, ok := goSymbols(before)
+	if !ok {
+		return nil
+	}
+	newSymbols, newOrder, ok := goSymbols(after)
+	if !ok {
+		return nil
+	}
+
+	var changes []SymbolChange
+	for _, symbol := range newOrder {
+		current := newSymbols[symbol]
+		change := SymbolChange{File: file, Symbol: symbol, New: current.declaration}
+		previous, existed := oldSymbols[symbol]
+		switch {
+		case !existed:
+			change.Kind = "added"
+		case previous.declaration != current.declaration:
+			change.Kind, change.Old = "signature", previous.declaration
+		case previous.body != current.body:
+			change.Kind, change.Old = "modified", previous.declaration
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, symbol := range oldOrder {
+		if _, exists := newSymbols[symbol]; !exists {
+			changes = append(changes, SymbolChange{File: file, Symbol: symbol, Kind: "removed", Old: oldSymbols[symbol].declaration})
+		}
+	}
+	return changes
+}
+
+// goSymbols returns the functions, methods and types declared in src by
+// symbol, and the symbols in source order. Empty source declares nothing.
+func goSymbols(src string) (map[string]goSymbol, []string, bool) {
+	symbols := make(map[string]goSymbol)
+	if strings.TrimSpace(src) == "" {
+		return symbols, nil, true
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	render := func(node any) string {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, node)
+		return strings.Join(strings.Fields(buf.String()), " ")
+	}
+	var order []string
+	add := func(symbol string, declaration goSymbol) {
+		if _, seen := symbols[symbol]; !seen {
+			order = append(order, symbol)
+		}
+		symbols[symbol] = declaration
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			symbol := decl.Name.Name
+			if recv := receiverType(decl); recv != "" {
+				symbol = "(" + recv + ")." + decl.Name.Name
+			} else if decl.Recv != nil {
+				continue
+			}
+			// init functions may be declared many times
+			if symbol == "init" {
+				continue
+			}
+			body := decl.Body
+			decl.Body, decl.Doc = nil, nil
+			declaration := render(decl)
+			add(symbol, goSymbol{declaration: declaration, body: render(body)})
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				spec.Doc, spec.Comment = nil, nil
+				declaration := "type " + render(spec)
+				switch spec.Type.(type) {
+				case *ast.StructType:
+					declaration = "type " + spec.Name.Name + " struct"
+				case *ast.InterfaceType:
+					declaration = "type " + spec.Name.Name + " interface"
+				}
+				add("type "+spec.Name.Name, goSymbol{declaration: declaration, body: render(spec)})
+			}
+		}
+	}
+	return symbols, order, true
+}
+
+// receiverType returns the receiver of a method as "T" or "*T" without
+// type parameters, or "" for a function
+func receiverType(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	recv := decl.Recv.List[0].Type
+	pointer := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer, recv = "*", star.X
+	}
+	switch index := recv.(type) {
+	case *ast.IndexExpr:
+		recv = index.X
+	case *ast.IndexListExpr:
+		recv = index.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return pointer + ident.Name
+}
+
+// StagedSymbolChanges compares the functions, methods and types of the
+// files among changes before and after their diffs (see FileChange.Base):
+// Go files, and the files the analyzer set with SetSymbolAnalyzer
+// supports. Files that cannot be read or parsed are skipped.
+func (gc *GitCommenter) StagedSymbolChanges(changes []FileChange) []SymbolChange {
+	var result []SymbolChange
+	files := 0
+	for _, change := range changes {
+		analyze := GoSymbolChanges
+		if !strings.HasSuffix(change.FilePath, ".go") {
+			if gc.symbolAnalyzer == nil || !gc.symbolAnalyzer.Supports(change.FilePath) {
+				continue
+			}
+			analyze = gc.symbolAnalyzer.SymbolChanges
+		}
+		if files == maxSymbolFiles {
+			break
+		}
+		files++
+		if before, after, ok := gc.changeVersions(change); ok {
+			result = append(result, analyze(change.FilePath, before, after)...)
+		}
+	}
+	return result
+}
+
+// symbolChangesBeyond returns the symbol changes of changes that apiChanges
+// do not already list, signature changes and additions first since they
+// say the most about a change
+func (gc *GitCommenter) symbolChangesBeyond(changes []FileChange, apiChanges []APIChange) []SymbolChange {
+	listed := make(map[string]bool)
+	for _, change := range apiChanges {
+		listed[change.Package+" "+change.Symbol] = true
+	}
+	var symbolChanges []SymbolChange
+	for _, change := range gc.StagedSymbolChanges(changes) {
+		if strings.HasSuffix(change.File, ".go") && listed[path.Dir(change.File)+" "+strings.TrimPrefix(change.Symbol, "type ")] {
+			continue
+		}
+		symbolChanges = append(symbolChanges, change)
+	}
+	rank := map[string]int{"signature": 0, "added": 1, "removed": 2, "modified": 3}
+	sort.SliceStable(symbolChanges, func(i, j int) bool {
+		return rank[symbolChanges[i].Kind] < rank[symbolChanges[j].Kind]
+	})
+	return symbolChanges
+}
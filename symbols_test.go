@@ -0,0 +1,121 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoSymbolChanges(t *testing.T) {
+	before := `package client
+
+// Client calls the API
+type Client struct{ url string }
+
+// New creates a client
+func New(url string) *Client { return &Client{url: url} }
+
+func (c *Client) Do() error { return nil }
+
+func helper() {}
+`
+	after := `package client
+
+// Client calls the API with retries
+type Client struct{ url string }
+
+// Option configures a Client
+type Option func(*Client)
+
+// New creates a client with options
+func New(url string, opts ...Option) *Client { return &Client{url: url} }
+
+func (c *Client) Do() error {
+	return c.Retry(3)
+}
+
+func (c *Client) Retry(n int) error { return nil }
+`
+	var got []string
+	for _, change := range GoSymbolChanges("client.go", before, after) {
+		got = append(got, change.String())
+	}
+	want := []string{
+		"added type Option func(*Client)",
+		"changed signature of New: func New(url string) *Client -> func New(url string, opts ...Option) *Client",
+		"modified func (c *Client) Do() error",
+		"added func (c *Client) Retry(n int) error",
+		"removed func helper()",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GoSymbolChanges =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := GoSymbolChanges("client.go", before, "package client\nfunc broken("); changes != nil {
+		t.Errorf("Expected no changes for a file that does not parse, got %v", changes)
+	}
+	if changes := GoSymbolChanges("client.go", "", before); len(changes) != 4 || changes[0].Kind != "added" {
+		t.Errorf("Expected every declaration of a new file to be added, got %v", changes)
+	}
+}
+
+func TestAPIContextListsSymbols(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "lib.go", "package lib\n\nfunc Old() {}\n", "feat: add lib")
+	if err := os.WriteFile(filepath.Join(dir, "lib.go"), []byte("package lib\n\nfunc Old() { println() }\n\nfunc New(n int) int { return n }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, dir, "add", "-A")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// New is listed once, as part of the exported API
+	context := gc.apiContext(changes)
+	want := "CHANGED FUNCTIONS AND TYPES:\n- . (exported API): added func New(n int) int\n- lib.go: modified func Old()\nName the most important of these in the message.\n\n"
+	if context != want {
+		t.Errorf("apiContext =\n%s\nwant\n%s", context, want)
+	}
+	if context := gc.apiContext([]FileChange{{FilePath: "README.md", ChangeType: "modified"}}); context != "" {
+		t.Errorf("Expected no section without Go files, got %q", context)
+	}
+}
+
+// fakeAnalyzer reports every supported file as modified
+type fakeAnalyzer struct{}
+
+func (fakeAnalyzer) Supports(path string) bool { return strings.HasSuffix(path, ".py") }
+
+func (fakeAnalyzer) SymbolChanges(file, before, after string) []SymbolChange {
+	return []SymbolChange{{File: file, Symbol: "main", Kind: "modified", Old: before, New: strings.TrimSpace(after)}}
+}
+
+func TestStagedSymbolChangesUsesAnalyzer(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "app.py", "def main(): pass\n", "feat: add app")
+	if err := os.WriteFile(filepath.Join(dir, "app.py"), []byte("def main(): print()\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, dir, "add", "-A")
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	changes, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if symbols := gc.StagedSymbolChanges(changes); len(symbols) != 0 {
+		t.Errorf("Expected no analysis of Python without an analyzer, got %v", symbols)
+	}
+	gc.SetSymbolAnalyzer(fakeAnalyzer{})
+	symbols := gc.StagedSymbolChanges(changes)
+	if len(symbols) != 1 || symbols[0].New != "def main(): print()" || symbols[0].Old != "def main(): pass\n" {
+		t.Errorf("Expected the analyzer to compare both versions of app.py, got %v", symbols)
+	}
+}
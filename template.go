@@ -0,0 +1,127 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PromptData is the data available to user prompt templates
+type PromptData struct {
+	// Context is the change summary also used by the built-in prompt
+	Context string
+	// Diffs are the formatted diffs of the most relevant files
+	Diffs string
+	// FileSummaries summarize the files Diffs cuts short or leaves out
+	// (see Config.SummarizeFiles)
+	FileSummaries string
+	// Branch is the current branch name (empty on a detached HEAD)
+	Branch string
+	// RecentCommits are the subjects of the latest commits, newest first
+	RecentCommits []string
+	// Changes are the staged changes included in the prompt
+	Changes []FileChange
+	// Style and TicketPrefix mirror the configuration
+	Style        string
+	TicketPrefix string
+	// Scope is the scope derived from Config.ScopeMap, if any
+	Scope string
+	// Types are the commit types the changed files allow, e.g. "test" when
+	// every file is a test (see Config.InferTypes)
+	Types []string
+	// Language is the name of the language to write in, e.g. "German"
+	Language string
+	// HistoryExamples are representative messages from the history (see
+	// Config.HistoryExamples), newest first
+	HistoryExamples []string
+	// SimilarCommits are the messages of past commits with similar diffs
+	// (see Config.EmbeddingModel), most similar first
+	SimilarCommits []string
+}
+
+// recentCommitCount is how many subjects are passed as RecentCommits
+const recentCommitCount = 10
+
+// templateFuncs are the helpers available to prompt templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// ParsePromptTemplate parses a prompt template, e.g. to validate a file
+// before using it
+func ParsePromptTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderPrompt returns the prompt for the given changes, using the
+// configured template file if there is one and buildPrompt otherwise
+func (gc *GitCommenter) renderPrompt(context string, changes []FileChange) (string, error) {
+	return gc.renderPromptWithin(context, changes, gc.PromptBudget())
+}
+
+// renderPromptWithin renders the prompt showing the diffs within budget
+func (gc *GitCommenter) renderPromptWithin(context string, changes []FileChange, budget PromptBudget) (string, error) {
+	if gc.config.PromptTemplate == "" {
+		return gc.buildPromptWithin(context, changes, budget), nil
+	}
+
+	path := gc.config.PromptTemplate
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(gc.config.RepositoryPath, path)
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	tmpl, err := ParsePromptTemplate(filepath.Base(path), string(text))
+	if err != nil {
+		return "", err
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, gc.promptData(context, changes, budget)); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return prompt.String(), nil
+}
+
+// promptData collects the template variables. Git lookups that fail (e.g.
+// in a repository without commits) leave the fields empty.
+func (gc *GitCommenter) promptData(context string, changes []FileChange, budget PromptBudget) PromptData {
+	data := PromptData{
+		Context:       context,
+		Branch:        gc.currentBranch(),
+		Diffs:         formatDiffs(changes, budget),
+		FileSummaries: gc.summaryContext(changes, budget),
+		Changes:       changes,
+		Style:         gc.config.Style,
+		TicketPrefix:  gc.ticketPrefix(),
+		Language:      languageName(gc.language()),
+	}
+
+	data.Scope, _ = gc.inferScope(changes)
+	data.Types, _ = gc.inferTypes(changes)
+	for _, commit := range gc.historyExamples() {
+		data.HistoryExamples = append(data.HistoryExamples, commit.Message())
+	}
+	for _, commit := range gc.similarCommits(changes) {
+		data.SimilarCommits = append(data.SimilarCommits, commit.Message())
+	}
+
+	if commits, err := gc.recentCommits(recentCommitCount); err == nil {
+		for _, commit := range commits {
+			data.RecentCommits = append(data.RecentCommits, commit.Subject)
+		}
+	}
+	return data
+}
@@ -0,0 +1,70 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderPromptTemplate(t *testing.T) {
+	dir := initTestRepo(t)
+	commitFile(t, dir, "a.txt", "a\n", "feat: add a")
+	commitFile(t, dir, "b.txt", "b\n", "fix: handle b")
+
+	text := "Branch={{.Branch}}\nRecent={{join .RecentCommits \"|\"}}\nFiles={{range .Changes}}{{.FilePath}} {{end}}\n{{.Diffs}}"
+	if err := os.WriteFile(filepath.Join(dir, "prompt.tmpl"), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.PromptTemplate = "prompt.tmpl"
+	gc := New(config)
+
+	changes := []FileChange{{FilePath: "main.go", ChangeType: "modified", Diff: "+func main() {}"}}
+	prompt, err := gc.renderPrompt("context", changes)
+	if err != nil {
+		t.Fatalf("renderPrompt returned error: %v", err)
+	}
+
+	for _, want := range []string{"Branch=", "Recent=fix: handle b|feat: add a", "Files=main.go", "DETAILED CHANGES IN main.go"} {
+		if !contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if contains(prompt, "Branch=\n") {
+		t.Errorf("Expected the branch name to be filled in, got:\n%s", prompt)
+	}
+}
+
+func TestRenderPromptTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.PromptTemplate = "missing.tmpl"
+	gc := New(config)
+
+	if _, err := gc.renderPrompt("", nil); err == nil {
+		t.Error("Expected an error for a missing template file")
+	}
+
+	if _, err := ParsePromptTemplate("bad", "{{.Context"); err == nil {
+		t.Error("Expected a parse error for an unterminated action")
+	}
+
+	writeFile(t, dir, "unknown.tmpl", "{{.Unknown}}")
+	config.PromptTemplate = "unknown.tmpl"
+	if _, err := gc.renderPrompt("", nil); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestRenderPromptDefault(t *testing.T) {
+	gc := New(DefaultConfig())
+	changes := []FileChange{{FilePath: "main.go", ChangeType: "added", Diff: "+package main"}}
+
+	prompt, err := gc.renderPrompt("context", changes)
+	if err != nil || prompt != gc.buildPrompt("context", changes) {
+		t.Errorf("Expected the built-in prompt without a template (err %v)", err)
+	}
+}
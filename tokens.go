@@ -0,0 +1,231 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContextWindowAuto is the Config.ContextWindow reading the window from
+// the model (see ContextWindow)
+const ContextWindowAuto = -1
+
+// DefaultContextWindow is the window Ollama runs models with unless their
+// Modelfile sets num_ctx
+const DefaultContextWindow = 4096
+
+// bytesPerToken estimates the tokens of a text when the model's tokenizer
+// is not available. Code averages three to four bytes per token; the
+// lower figure errs towards prompts that fit.
+const bytesPerToken = 3
+
+// fitAttempts is how many times a prompt is rendered to fit the window
+const fitAttempts = 4
+
+// Tokenizer counts the tokens of a text for the configured model
+type Tokenizer interface {
+	CountTokens(text string) (int, error)
+}
+
+// SetTokenizer replaces the tokenizer used to fit prompts to the context
+// window; the default asks Ollama's /api/tokenize endpoint
+func (gc *GitCommenter) SetTokenizer(tokenizer Tokenizer) {
+	gc.tokenizer = tokenizer
+}
+
+// OllamaTokenizeRequest represents a request to the Ollama tokenize API
+type OllamaTokenizeRequest struct {
+	Model   string `json:"model"`
+	Content string `json:"content"`
+}
+
+// OllamaTokenizeResponse represents a response from the Ollama tokenize API
+type OllamaTokenizeResponse struct {
+	Tokens []int `json:"tokens"`
+}
+
+// ollamaTokenizer counts tokens with the model's own tokenizer
+type ollamaTokenizer struct {
+	gc *GitCommenter
+}
+
+// CountTokens tokenizes text with Config.Model
+func (t ollamaTokenizer) CountTokens(text string) (int, error) {
+	text, err := t.gc.guardPrompt(text)
+	if err != nil {
+		return 0, err
+	}
+	var resp OllamaTokenizeResponse
+	err = t.gc.postOllama("/api/tokenize", OllamaTokenizeRequest{Model: t.gc.config.Model, Content: text}, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return len(resp.Tokens), nil
+}
+
+// EstimateTokens guesses the tokens of a text from its length, for
+// servers without a tokenize endpoint
+func EstimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// CountTokens counts the tokens of text with the tokenizer, falling back
+// to EstimateTokens when it fails, e.g. on an Ollama version without
+// /api/tokenize
+func (gc *GitCommenter) CountTokens(text string) int {
+	tokenizer := gc.tokenizer
+	if tokenizer == nil {
+		tokenizer = ollamaTokenizer{gc}
+	}
+	if tokens, err := tokenizer.CountTokens(text); err == nil {
+		return tokens
+	}
+	return EstimateTokens(text)
+}
+
+// OllamaShowRequest represents a request to the Ollama show API
+type OllamaShowRequest struct {
+	Model string `json:"model"`
+}
+
+// OllamaShowResponse represents the parts of a response from the Ollama
+// show API that describe the context window
+type OllamaShowResponse struct {
+	// Parameters are the Modelfile parameters, one "name value" per line
+	Parameters string `json:"parameters"`
+	// ModelInfo holds e.g. "llama.context_length"
+	ModelInfo map[string]any `json:"model_info"`
+}
+
+// contextWindowCache remembers the window read from each model
+var contextWindowCache sync.Map
+
+// ContextWindow returns the context window prompts are fitted to, in
+// tokens, or 0 when Config.ContextWindow is unset. With ContextWindowAuto
+// it is the num_ctx of the model's Modelfile, else DefaultContextWindow,
+// but never more than the model was trained for.
+func (gc *GitCommenter) ContextWindow() int {
+	if gc.config.ContextWindow >= 0 {
+		return gc.config.ContextWindow
+	}
+	key := gc.config.OllamaEndpoint + " " + gc.config.Model
+	if window, ok := contextWindowCache.Load(key); ok {
+		return window.(int)
+	}
+
+	window := DefaultContextWindow
+	var resp OllamaShowResponse
+	if err := gc.postOllama("/api/show", OllamaShowRequest{Model: gc.config.Model}, &resp); err != nil {
+		return window
+	}
+	for _, line := range strings.Split(resp.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 {
+				window = n
+			}
+		}
+	}
+	for key, value := range resp.ModelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") && n > 0 {
+			window = min(window, int(n))
+		}
+	}
+	contextWindowCache.Store(key, window)
+	return window
+}
+
+// numCtx is the num_ctx option sent with requests: the configured window,
+// so Ollama does not cut prompts at its default, or 0 to leave it unset
+func (gc *GitCommenter) numCtx() int {
+	return max(gc.config.ContextWindow, 0)
+}
+
+// fitPrompt renders the prompt with the diff budget filling the context
+// window: the byte limits shrink when the prompt does not fit and grow
+// when diffs are cut while tokens are left over. Without a window the
+// prompt is rendered with PromptBudget.
+func (gc *GitCommenter) fitPrompt(changes []FileChange, render func(PromptBudget) (string, error)) (string, error) {
+	budget := gc.PromptBudget()
+	prompt, err := render(budget)
+	window := gc.ContextWindow()
+	if err != nil || window <= 0 {
+		return prompt, err
+	}
+	// Leave room for the answer
+	limit := window - gc.config.MaxTokens
+
+	for attempt := 1; ; attempt++ {
+		tokens := gc.CountTokens(prompt)
+		shown, full := diffBytes(changes, budget)
+		if tokens <= limit && (attempt > 1 || shown == full) {
+			return prompt, nil
+		}
+		if attempt == fitAttempts {
+			if tokens <= limit {
+				return prompt, nil
+			}
+			return "", fmt.Errorf("prompt of %d tokens does not fit the context window of %d tokens", tokens, window)
+		}
+
+		// Move the diff budget by the tokens to spare or to cut, converted
+		// at the prompt's own bytes per token and keeping a tenth in reserve
+		spare := float64(limit-tokens) * float64(len(prompt)) / float64(max(tokens, 1))
+		scaled := int(float64(shown)+spare) * 9 / 10
+		if tokens <= limit && scaled <= shown {
+			return prompt, nil
+		}
+		if scaled <= 0 || shown == 0 {
+			return "", fmt.Errorf("prompt of %d tokens does not fit the context window of %d tokens", tokens, window)
+		}
+		budget.MaxFileBytes = max(budget.MaxFileBytes*scaled/shown, 1)
+		budget.MaxBytes = scaled
+		if prompt, err = render(budget); err != nil {
+			return "", err
+		}
+	}
+}
+
+// diffBytes returns how much diff content the budget shows, and how much
+// the files it may show have in full
+func diffBytes(changes []FileChange, budget PromptBudget) (shown, full int) {
+	layout := layoutDiffs(changes, budget)
+	for _, limit := range layout.limits {
+		shown += limit
+	}
+	for _, change := range layout.ordered[:min(budget.MaxFiles, len(layout.ordered))] {
+		full += len(change.Diff)
+	}
+	return shown, full
+}
+
+// postOllama posts a JSON request to an Ollama API path and decodes the
+// JSON response into out
+func (gc *GitCommenter) postOllama(path string, request, out any) error {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := gc.client.Post(gc.config.OllamaEndpoint+path, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to call Ollama API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
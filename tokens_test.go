@@ -0,0 +1,100 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// byteTokenizer counts one token per byte
+type byteTokenizer struct{}
+
+func (byteTokenizer) CountTokens(text string) (int, error) {
+	return len(text), nil
+}
+
+func TestCountTokensFromOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tokenize":
+			var req OllamaTokenizeRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(OllamaTokenizeResponse{Tokens: make([]int, len(strings.Fields(req.Content)))})
+		case "/api/show":
+			json.NewEncoder(w).Encode(map[string]any{
+				"parameters": "stop \"<|eot|>\"\nnum_ctx 32768",
+				"model_info": map[string]any{"llama.context_length": 8192},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	config.ContextWindow = ContextWindowAuto
+	gc := New(config)
+	if tokens := gc.CountTokens("add a retry loop"); tokens != 4 {
+		t.Errorf("Expected 4 tokens from the tokenizer, got %d", tokens)
+	}
+	if window := gc.ContextWindow(); window != 8192 {
+		t.Errorf("Expected num_ctx capped at the trained context length, got %d", window)
+	}
+
+	// Servers without the endpoints fall back to estimates and the default
+	config.OllamaEndpoint = server.URL + "/missing"
+	if tokens := gc.CountTokens("123456"); tokens != EstimateTokens("123456") {
+		t.Errorf("Expected an estimate without a tokenizer, got %d", tokens)
+	}
+	if window := gc.ContextWindow(); window != DefaultContextWindow {
+		t.Errorf("Expected the default window, got %d", window)
+	}
+}
+
+func TestFitPrompt(t *testing.T) {
+	var changes []FileChange
+	for i := 0; i < 3; i++ {
+		diff := strings.Repeat(fmt.Sprintf("+line of file %d\n", i), 200)
+		changes = append(changes, FileChange{FilePath: fmt.Sprintf("f%d.go", i), ChangeType: "modified", Diff: diff, LinesAdded: 200})
+	}
+	render := func(budget PromptBudget) (string, error) {
+		return "header\n" + formatDiffs(changes, budget), nil
+	}
+
+	// Without a window the byte budget applies as is
+	gc := New(&Config{MaxTokens: 100})
+	gc.SetTokenizer(byteTokenizer{})
+	prompt, err := gc.fitPrompt(changes, render)
+	if err != nil || prompt != "header\n"+formatDiffs(changes, gc.PromptBudget()) {
+		t.Fatalf("Expected the default budget without a window, got %v", err)
+	}
+
+	// A small window cuts the diffs further
+	gc.config.ContextWindow = 2000
+	prompt, err = gc.fitPrompt(changes, render)
+	if err != nil {
+		t.Fatalf("fitPrompt returned error: %v", err)
+	}
+	if len(prompt) > 2000-100 || !strings.Contains(prompt, "DETAILED CHANGES IN") {
+		t.Errorf("Expected a prompt of diffs within 1900 tokens, got %d:\n%s", len(prompt), prompt)
+	}
+
+	// A large window shows the diffs the byte budget cut
+	gc.config.ContextWindow = 50000
+	if prompt, err = gc.fitPrompt(changes, render); err != nil {
+		t.Fatalf("fitPrompt returned error: %v", err)
+	}
+	if strings.Contains(prompt, "truncated") || strings.Count(prompt, "DETAILED CHANGES IN") != 3 {
+		t.Errorf("Expected every diff in full:\n%s", prompt)
+	}
+
+	// A window too small for the rest of the prompt is an error
+	gc.config.ContextWindow = 110
+	if _, err := gc.fitPrompt(changes, render); err == nil {
+		t.Error("Expected an error when the prompt cannot fit")
+	}
+}
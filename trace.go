@@ -0,0 +1,24 @@
+package gitcommenter
+
+import "time"
+
+// ModelCall describes one request to the model, as passed to the function
+// set with SetTrace
+type ModelCall struct {
+	Model       string
+	Prompt      string
+	Response    string
+	Temperature float64
+	Candidate   int
+	Duration    time.Duration
+	// Cached is set when the response came from the cache without a request
+	Cached bool
+	Err    error
+}
+
+// SetTrace sets a function called after every model request, e.g. to show
+// the prompts while tuning them. It may be called from several goroutines
+// when candidates are generated in parallel.
+func (gc *GitCommenter) SetTrace(trace func(ModelCall)) {
+	gc.trace = trace
+}
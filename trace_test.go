@@ -0,0 +1,45 @@
+package gitcommenter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaResponse{Response: "fix: handle nil config", Done: true})
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.OllamaEndpoint = server.URL
+	gc := New(config)
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc.SetCache(cache)
+	var calls []ModelCall
+	gc.SetTrace(func(call ModelCall) { calls = append(calls, call) })
+
+	changes := []FileChange{{FilePath: "config.go", ChangeType: "modified", Diff: "+if config == nil {"}}
+	suggestion, err := gc.GenerateCommitMessage(changes)
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Cached || calls[0].Response != "fix: handle nil config" || calls[0].Model != config.Model {
+		t.Fatalf("Unexpected calls %+v", calls)
+	}
+	if PromptHash(calls[0].Prompt) != suggestion.PromptHash {
+		t.Error("Expected the traced prompt to be the one sent")
+	}
+
+	if _, err := gc.GenerateCommitMessage(changes); err != nil {
+		t.Fatalf("GenerateCommitMessage returned error: %v", err)
+	}
+	if len(calls) != 2 || !calls[1].Cached {
+		t.Errorf("Expected the second call to be cached, got %+v", calls)
+	}
+}
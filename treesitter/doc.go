@@ -0,0 +1,5 @@
+// Package treesitter provides a gitcommenter.SymbolAnalyzer finding the
+// functions, classes and methods that changes add, remove or modify in
+// JavaScript, TypeScript, Python, Rust and Java files, parsed with
+// tree-sitter grammars. It requires cgo; without it the package is empty.
+package treesitter
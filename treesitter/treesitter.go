@@ -0,0 +1,285 @@
+//go:build cgo
+
+package treesitter
+
+import (
+	"path"
+	"strings"
+	"unsafe"
+
+	gitcommenter "github.com/TheRealMasterK/Ai-Git-Comments-Auto"
+	sitter "github.com/tree-sitter/go-tree-sitter"
+	java "github.com/tree-sitter/tree-sitter-java/bindings/go"
+	javascript "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	python "github.com/tree-sitter/tree-sitter-python/bindings/go"
+	rust "github.com/tree-sitter/tree-sitter-rust/bindings/go"
+	typescript "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+)
+
+// grammar describes the declarations of one language
+type grammar struct {
+	language func() unsafe.Pointer
+	// definitions are the node kinds declaring a symbol
+	definitions map[string]bool
+	// containers are the node kinds whose members are qualified by their
+	// name, e.g. methods by their class
+	containers map[string]bool
+}
+
+var (
+	javascriptGrammar = grammar{
+		language: javascript.Language,
+		definitions: set("function_declaration", "generator_function_declaration", "class_declaration",
+			"method_definition", "variable_declarator"),
+		containers: set("class_declaration"),
+	}
+	typescriptDefinitions = set("function_declaration", "generator_function_declaration", "class_declaration",
+		"abstract_class_declaration", "method_definition", "abstract_method_signature", "variable_declarator",
+		"interface_declaration", "type_alias_declaration", "enum_declaration")
+	typescriptGrammar = grammar{
+		language:    typescript.LanguageTypescript,
+		definitions: typescriptDefinitions,
+		containers:  set("class_declaration", "abstract_class_declaration"),
+	}
+	tsxGrammar = grammar{
+		language:    typescript.LanguageTSX,
+		definitions: typescriptDefinitions,
+		containers:  set("class_declaration", "abstract_class_declaration"),
+	}
+	pythonGrammar = grammar{
+		language:    python.Language,
+		definitions: set("function_definition", "class_definition"),
+		containers:  set("class_definition"),
+	}
+	rustGrammar = grammar{
+		language: rust.Language,
+		definitions: set("function_item", "function_signature_item", "struct_item", "enum_item", "trait_item",
+			"type_item", "macro_definition"),
+		containers: set("impl_item", "trait_item", "mod_item"),
+	}
+	javaGrammar = grammar{
+		language: java.Language,
+		definitions: set("class_declaration", "interface_declaration", "enum_declaration", "record_declaration",
+			"method_declaration", "constructor_declaration"),
+		containers: set("class_declaration", "interface_declaration", "enum_declaration", "record_declaration"),
+	}
+)
+
+// grammars maps file extensions to their grammar
+var grammars = map[string]grammar{
+	".js": javascriptGrammar, ".jsx": javascriptGrammar, ".mjs": javascriptGrammar, ".cjs": javascriptGrammar,
+	".ts": typescriptGrammar, ".mts": typescriptGrammar, ".cts": typescriptGrammar,
+	".tsx":  tsxGrammar,
+	".py":   pythonGrammar,
+	".rs":   rustGrammar,
+	".java": javaGrammar,
+}
+
+// set returns a set of node kinds
+func set(kinds ...string) map[string]bool {
+	result := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		result[kind] = true
+	}
+	return result
+}
+
+// Analyzer finds changed symbols with tree-sitter; the zero value is ready
+// to use
+type Analyzer struct{}
+
+// New returns an Analyzer
+func New() *Analyzer {
+	return &Analyzer{}
+}
+
+// Supports reports whether the file is JavaScript, TypeScript, Python,
+// Rust or Java
+func (a *Analyzer) Supports(file string) bool {
+	_, ok := grammars[strings.ToLower(path.Ext(file))]
+	return ok
+}
+
+// SymbolChanges compares the functions, classes and methods of two versions
+// of a file; before is empty for a new file and after for a deleted one.
+// Changes to whitespace are ignored. A version with syntax errors yields
+// no changes.
+func (a *Analyzer) SymbolChanges(file, before, after string) []gitcommenter.SymbolChange {
+	lang, ok := grammars[strings.ToLower(path.Ext(file))]
+	if !ok {
+		return nil
+	}
+	oldSymbols, oldOrder, ok := lang.symbols(before)
+	if !ok {
+		return nil
+	}
+	newSymbols, newOrder, ok := lang.symbols(after)
+	if !ok {
+		return nil
+	}
+
+	var changes []gitcommenter.SymbolChange
+	for _, name := range newOrder {
+		current := newSymbols[name]
+		change := gitcommenter.SymbolChange{File: file, Symbol: name, New: current.declaration}
+		previous, existed := oldSymbols[name]
+		switch {
+		case !existed:
+			change.Kind = "added"
+		case previous.declaration != current.declaration:
+			change.Kind, change.Old = "signature", previous.declaration
+		case previous.body != current.body:
+			change.Kind, change.Old = "modified", previous.declaration
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, name := range oldOrder {
+		if _, exists := newSymbols[name]; !exists {
+			changes = append(changes, gitcommenter.SymbolChange{File: file, Symbol: name, Kind: "removed", Old: oldSymbols[name].declaration})
+		}
+	}
+
+	// A class is modified by its changed members, which are listed
+	var listed []gitcommenter.SymbolChange
+	for _, change := range changes {
+		if change.Kind != "modified" || !hasMemberChange(changes, change.Symbol) {
+			listed = append(listed, change)
+		}
+	}
+	return listed
+}
+
+// hasMemberChange reports whether changes change a member of container
+func hasMemberChange(changes []gitcommenter.SymbolChange, container string) bool {
+	for _, change := range changes {
+		if strings.HasPrefix(change.Symbol, container+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// symbol is a declaration found in a file
+type symbol struct {
+	// declaration is the declaration up to its body, e.g. "def retry(self,
+	// n) in Client"
+	declaration string
+	// body is the whole declaration, compared to find modifications
+	body string
+}
+
+// symbols returns the declarations of src by qualified name, e.g.
+// "Client.retry", and the names in source order
+func (g grammar) symbols(src string) (map[string]symbol, []string, bool) {
+	symbols := make(map[string]symbol)
+	if strings.TrimSpace(src) == "" {
+		return symbols, nil, true
+	}
+
+	parser := sitter.NewParser()
+	defer parser.Close()
+	if err := parser.SetLanguage(sitter.NewLanguage(g.language())); err != nil {
+		return nil, nil, false
+	}
+	source := []byte(src)
+	tree := parser.Parse(source, nil)
+	if tree == nil {
+		return nil, nil, false
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+	if root.HasError() {
+		return nil, nil, false
+	}
+
+	var order []string
+	var walk func(node *sitter.Node, container string)
+	walk = func(node *sitter.Node, container string) {
+		kind := node.Kind()
+		name := g.name(node, source)
+		if g.definitions[kind] && name != "" {
+			qualified := name
+			if container != "" {
+				qualified = container + "." + name
+			}
+			text := node.Utf8Text(source)
+			declaration := text
+			if body := g.body(node); body != nil {
+				declaration = string(source[node.StartByte():body.StartByte()])
+			}
+			declaration = strings.TrimSuffix(strings.Join(strings.Fields(declaration), " "), " =>")
+			declaration = strings.TrimRight(declaration, " :=")
+			if container != "" {
+				declaration += " in " + container
+			}
+			// Overloads and redefinitions get a name each
+			if _, seen := symbols[qualified]; seen {
+				qualified += " " + declaration
+			}
+			symbols[qualified] = symbol{declaration: declaration, body: strings.Join(strings.Fields(text), " ")}
+			order = append(order, qualified)
+			// Functions declared inside functions are part of their body
+			if !g.containers[kind] {
+				return
+			}
+		}
+		if g.containers[kind] {
+			if name := g.containerName(node, source); name != "" {
+				if container != "" {
+					name = container + "." + name
+				}
+				container = name
+			}
+		}
+		for i := uint(0); i < node.NamedChildCount(); i++ {
+			walk(node.NamedChild(i), container)
+		}
+	}
+	walk(root, "")
+	return symbols, order, true
+}
+
+// name returns the name a definition node declares, or "" for nodes that
+// declare nothing worth listing, such as variables holding no function
+func (g grammar) name(node *sitter.Node, source []byte) string {
+	if node.Kind() == "variable_declarator" {
+		value := node.ChildByFieldName("value")
+		if value == nil {
+			return ""
+		}
+		switch value.Kind() {
+		case "arrow_function", "function_expression", "function", "generator_function", "class":
+		default:
+			return ""
+		}
+	}
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Utf8Text(source)
+	}
+	return ""
+}
+
+// body returns the body of a definition node, or nil when it has none,
+// e.g. a TypeScript type alias
+func (g grammar) body(node *sitter.Node) *sitter.Node {
+	if node.Kind() == "variable_declarator" {
+		if value := node.ChildByFieldName("value"); value != nil {
+			return value.ChildByFieldName("body")
+		}
+	}
+	return node.ChildByFieldName("body")
+}
+
+// containerName returns the name qualifying the members of a container,
+// e.g. the type of a Rust impl block
+func (g grammar) containerName(node *sitter.Node, source []byte) string {
+	if node.Kind() == "impl_item" {
+		if typ := node.ChildByFieldName("type"); typ != nil {
+			return typ.Utf8Text(source)
+		}
+		return ""
+	}
+	return g.name(node, source)
+}
@@ -0,0 +1,89 @@
+//go:build cgo
+
+package treesitter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSymbolChanges(t *testing.T) {
+	for _, test := range []struct {
+		file          string
+		before, after string
+		want          []string
+	}{
+		{
+			file: "client.py",
+			before: `class Client:
+    def get(self, url):
+        return url
+
+def helper():
+    pass
+`,
+			after: `class Client:
+    def get(self, url, timeout=10):
+        return url
+
+    @retry
+    def retry(self, n):
+        def inner():
+            pass
+        return n
+`,
+			want: []string{
+				"changed signature of Client.get: def get(self, url) in Client -> def get(self, url, timeout=10) in Client",
+				"added def retry(self, n) in Client",
+				"removed def helper()",
+			},
+		},
+		{
+			file:   "client.ts",
+			before: "export class Client {\n  get(url: string): string { return url; }\n}\nconst parse = (s: string) => s;\nconst limit = 3;\n",
+			after:  "export class Client {\n  get(url: string): string { return url.trim(); }\n}\nconst parse = (s: string) => s;\ninterface Options { retries: number }\n",
+			want: []string{
+				"modified get(url: string): string in Client",
+				"added interface Options",
+			},
+		},
+		{
+			file:   "lib.rs",
+			before: "struct Client;\n\nimpl Client {\n    pub fn get(&self) -> Vec<u8> { vec![] }\n}\n",
+			after:  "struct Client;\n\nimpl Client {\n    pub fn get(&self) -> Vec<u8> { vec![1] }\n    pub fn retry(&self, n: u32) {}\n}\n",
+			want: []string{
+				"modified pub fn get(&self) -> Vec<u8> in Client",
+				"added pub fn retry(&self, n: u32) in Client",
+			},
+		},
+		{
+			file:   "Client.java",
+			before: "class Client {\n  String get(String url) { return url; }\n}\n",
+			after:  "class Client {\n  String get(String url) { return url; }\n  String get(String url, int timeout) { return url; }\n}\n",
+			want: []string{
+				"added String get(String url, int timeout) in Client",
+			},
+		},
+	} {
+		var got []string
+		for _, change := range New().SymbolChanges(test.file, test.before, test.after) {
+			got = append(got, change.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("SymbolChanges(%s) =\n%s\nwant\n%s", test.file, strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+}
+
+func TestSymbolChangesSkipsInvalidSources(t *testing.T) {
+	analyzer := New()
+	if !analyzer.Supports("src/app.JSX") || analyzer.Supports("main.go") || analyzer.Supports("README.md") {
+		t.Error("Expected JavaScript and no Go or Markdown support")
+	}
+	if changes := analyzer.SymbolChanges("app.py", "def f():\n    pass\n", "def f(:\n"); changes != nil {
+		t.Errorf("Expected no changes for a file with syntax errors, got %v", changes)
+	}
+	if changes := analyzer.SymbolChanges("app.py", "", "def f():\n    pass\n"); len(changes) != 1 || changes[0].Kind != "added" {
+		t.Errorf("Expected the function of a new file to be added, got %v", changes)
+	}
+}
@@ -0,0 +1,189 @@
+package gitcommenter
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTrivialMaxLines is the default Config.TrivialMaxLines
+const DefaultTrivialMaxLines = 10
+
+// Templates of trivial changes, recorded in CommitSuggestion.Template
+const (
+	TemplateFormatting = "formatting"
+	TemplateTypo       = "typo"
+	TemplateVersion    = "version"
+)
+
+// versionLine matches a line setting a project version, e.g.
+// `"version": "1.2.3",`, `version = "1.2.3"` or `const Version = "v1.2.3"`.
+// Dependency versions such as `"lodash": "4.17.21"` do not match.
+var versionLine = regexp.MustCompile(`(?i)^(\s*(?:(?:export|const|var|let)\s+)?["']?[\w.]*version["']?\s*(?::=|[:=])\s*["']?)v?(\d+(?:\.\d+){1,3}(?:[-+][\w.]+)?)(["']?[,;]?\s*)$`)
+
+// trivialChange is what a template says about a change
+type trivialChange struct {
+	template    string
+	kind        string // conventional commit type
+	emoji       string
+	description string
+}
+
+// TrivialMessage returns a template message for a trivial single-file
+// change — a reformat, a typo fix in documentation or a version bump — so
+// the model is not needed. It returns nil when the change needs the model:
+// several files, more than Config.TrivialMaxLines changed lines, no
+// matching template, a language other than English (see Config.Language)
+// or a template message breaking the conventions.
+func (gc *GitCommenter) TrivialMessage(changes []FileChange) *CommitSuggestion {
+	// The templates are written in English
+	if gc.config.TrivialMaxLines <= 0 || len(changes) != 1 || gc.language() != "en" {
+		return nil
+	}
+	change := changes[0]
+	if change.LinesAdded+change.LinesRemoved > gc.config.TrivialMaxLines {
+		return nil
+	}
+	trivial, ok := classifyTrivial(change)
+	if !ok {
+		return nil
+	}
+
+	style, err := LookupStyle(gc.config.Style)
+	if err != nil {
+		return nil
+	}
+	scope, err := gc.inferScope(changes)
+	if err != nil {
+		return nil
+	}
+
+	subject := strings.ToUpper(trivial.description[:1]) + trivial.description[1:]
+	if style.Conventional {
+		if scope != "" {
+			trivial.kind += "(" + scope + ")"
+		}
+		subject = trivial.kind + ": " + trivial.description
+	}
+	if gc.gitmojiMode() {
+		subject = trivial.emoji + " " + subject
+	}
+	if prefix := gc.ticketPrefix(); prefix != "" {
+		subject = prefix + " " + subject
+	}
+
+	suggestion := &CommitSuggestion{
+		Subject:       subject,
+		Confidence:    1,
+		FilesAffected: []string{change.FilePath},
+		Template:      trivial.template,
+	}
+	if gc.validateSuggestion(style, suggestion) != nil {
+		return nil
+	}
+	if limit := gc.subjectLimit(); limit > 0 && utf8.RuneCountInString(subject) > limit {
+		return nil
+	}
+	return suggestion
+}
+
+// classifyTrivial finds the template matching a change
+func classifyTrivial(change FileChange) (trivialChange, bool) {
+	name := path.Base(change.FilePath)
+	if isFormattingChange(change) {
+		return trivialChange{TemplateFormatting, "style", "🎨", "format " + name}, true
+	}
+	if change.ChangeType != "modified" {
+		return trivialChange{}, false
+	}
+
+	removed, added := changedLines(change.Diff)
+	if len(removed) != len(added) || len(removed) == 0 {
+		return trivialChange{}, false
+	}
+
+	if len(removed) == 1 {
+		before, after := versionLine.FindStringSubmatch(removed[0]), versionLine.FindStringSubmatch(added[0])
+		if before != nil && after != nil && before[1] == after[1] && before[2] != after[2] {
+			return trivialChange{TemplateVersion, "chore", "🔖", "bump version to " + after[2]}, true
+		}
+	}
+
+	if isDocFile(change.FilePath) {
+		typos := 0
+		for i := range removed {
+			n, ok := typoCount(removed[i], added[i])
+			if !ok {
+				return trivialChange{}, false
+			}
+			typos += n
+		}
+		switch {
+		case typos == 1:
+			return trivialChange{TemplateTypo, "docs", "✏️", "fix typo in " + name}, true
+		case typos > 1:
+			return trivialChange{TemplateTypo, "docs", "✏️", "fix typos in " + name}, true
+		}
+	}
+	return trivialChange{}, false
+}
+
+// changedLines returns the removed and added lines of a diff
+func changedLines(diff string) (removed, added []string) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, line[1:])
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		}
+	}
+	return removed, added
+}
+
+// typoCount compares a removed and an added line word by word. It reports
+// how many words changed, and false unless every change is a small edit of
+// one word, as a typo fix is.
+func typoCount(before, after string) (int, bool) {
+	old, fixed := strings.Fields(before), strings.Fields(after)
+	if len(old) != len(fixed) {
+		return 0, false
+	}
+	count := 0
+	for i := range old {
+		if old[i] == fixed[i] {
+			continue
+		}
+		// Changed numbers are corrections of content, not spelling
+		if strings.ContainsAny(old[i]+fixed[i], "0123456789") || editDistance(old[i], fixed[i]) > 2 {
+			return 0, false
+		}
+		count++
+	}
+	// Rewording most of a line is not a typo fix
+	return count, count <= 2
+}
+
+// editDistance returns the Levenshtein distance between two words
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
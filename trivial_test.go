@@ -0,0 +1,85 @@
+package gitcommenter
+
+import "testing"
+
+func TestTrivialMessage(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		style  string
+		change FileChange
+		want   string
+	}{
+		{
+			name:   "typo",
+			change: FileChange{FilePath: "docs/README.md", ChangeType: "modified", Diff: "-Teh parser is fast.\n+The parser is fast.\n", LinesAdded: 1, LinesRemoved: 1},
+			want:   "docs: fix typo in README.md",
+		},
+		{
+			name:   "typos in plain style",
+			style:  "plain",
+			change: FileChange{FilePath: "guide.md", ChangeType: "modified", Diff: "-Recieve the mesage\n+Receive the message\n", LinesAdded: 1, LinesRemoved: 1},
+			want:   "Fix typos in guide.md",
+		},
+		{
+			name:   "version bump",
+			change: FileChange{FilePath: "package.json", ChangeType: "modified", Diff: "-  \"version\": \"1.2.3\",\n+  \"version\": \"1.2.4\",\n", LinesAdded: 1, LinesRemoved: 1},
+			want:   "chore: bump version to 1.2.4",
+		},
+		{
+			name:   "formatting with gitmoji",
+			style:  "gitmoji",
+			change: FileChange{FilePath: "main.go", ChangeType: "modified", Diff: "-func main(){\n+func main() {\n", LinesAdded: 1, LinesRemoved: 1},
+			want:   "🎨 Format main.go",
+		},
+		{
+			name:   "dependency version",
+			change: FileChange{FilePath: "package.json", ChangeType: "modified", Diff: "-    \"lodash\": \"4.17.20\",\n+    \"lodash\": \"4.17.21\",\n", LinesAdded: 1, LinesRemoved: 1},
+		},
+		{
+			name:   "changed number in docs",
+			change: FileChange{FilePath: "README.md", ChangeType: "modified", Diff: "-Wait 30 seconds\n+Wait 60 seconds\n", LinesAdded: 1, LinesRemoved: 1},
+		},
+		{
+			name:   "reworded docs",
+			change: FileChange{FilePath: "README.md", ChangeType: "modified", Diff: "-Run the tool\n+Start the program\n", LinesAdded: 1, LinesRemoved: 1},
+		},
+		{
+			name:   "code change",
+			change: FileChange{FilePath: "main.go", ChangeType: "modified", Diff: "-return nil\n+return err\n", LinesAdded: 1, LinesRemoved: 1},
+		},
+		{
+			name:   "ticket-first without a ticket",
+			style:  "ticket-first",
+			change: FileChange{FilePath: "README.md", ChangeType: "modified", Diff: "-Teh parser\n+The parser\n", LinesAdded: 1, LinesRemoved: 1},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig()
+			if test.style != "" {
+				config.Style = test.style
+			}
+			suggestion := New(config).TrivialMessage([]FileChange{test.change})
+			switch {
+			case test.want == "" && suggestion != nil:
+				t.Errorf("Expected the model to be needed, got %q", suggestion.Subject)
+			case test.want != "" && (suggestion == nil || suggestion.Subject != test.want):
+				t.Errorf("Expected %q, got %+v", test.want, suggestion)
+			}
+		})
+	}
+}
+
+func TestTrivialMessageThreshold(t *testing.T) {
+	change := FileChange{FilePath: "README.md", ChangeType: "modified", Diff: "-Teh parser\n+The parser\n", LinesAdded: 1, LinesRemoved: 1}
+
+	config := DefaultConfig()
+	config.TrivialMaxLines = 0
+	if suggestion := New(config).TrivialMessage([]FileChange{change}); suggestion != nil {
+		t.Errorf("Expected no template message with the threshold at 0, got %q", suggestion.Subject)
+	}
+
+	config.TrivialMaxLines = 1
+	if suggestion := New(config).TrivialMessage([]FileChange{change}); suggestion != nil {
+		t.Errorf("Expected no template message above the threshold, got %q", suggestion.Subject)
+	}
+}
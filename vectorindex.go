@@ -0,0 +1,276 @@
+package gitcommenter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SimilarCommit is an indexed commit and the similarity of its diff to
+// another diff, from -1 to 1
+type SimilarCommit struct {
+	Commit     Commit
+	Similarity float64
+}
+
+// indexEntry is one line of an index file
+type indexEntry struct {
+	Hash   string    `json:"hash"`
+	Vector []float64 `json:"vector"`
+}
+
+// indexBatchSize is how many commits are embedded per request while
+// indexing; each batch is saved before the next one starts
+const indexBatchSize = 32
+
+// findSimilarLimit is how many commits FindSimilar returns
+const findSimilarLimit = 10
+
+// unsafeIndexName matches the characters of a model name not used in the
+// index file name
+var unsafeIndexName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// indexPath returns the index file of the embedding model:
+// .git/ai-index/<model>.jsonl, shared by all worktrees
+func (gc *GitCommenter) indexPath() (string, error) {
+	dir, err := gc.gitOutput("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the git directory: %w", err)
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gc.config.RepositoryPath, dir)
+	}
+	return filepath.Join(dir, "ai-index", unsafeIndexName.ReplaceAllString(gc.config.EmbeddingModel, "_")+".jsonl"), nil
+}
+
+// loadIndex reads the index of the embedding model; a missing index is
+// empty and unreadable lines, e.g. from an interrupted write, are skipped
+func (gc *GitCommenter) loadIndex() (map[string][]float64, error) {
+	path, err := gc.indexPath()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string][]float64)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the commit index: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry indexEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry.Hash != "" {
+			index[entry.Hash] = entry.Vector
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the commit index: %w", err)
+	}
+	return index, nil
+}
+
+// appendIndex adds entries to the index of the embedding model
+func (gc *GitCommenter) appendIndex(entries []indexEntry) error {
+	path, err := gc.indexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create the commit index: %w", err)
+	}
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode the commit index: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the commit index: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write the commit index: %w", err)
+	}
+	return nil
+}
+
+// IndexHistory embeds the diffs of the commits on HEAD (without merges)
+// that are not indexed yet with Config.EmbeddingModel and stores them in
+// .git/ai-index/. It returns how many commits were added. Indexing is
+// incremental: progress is saved after every batch, and later calls only
+// embed new commits.
+func (gc *GitCommenter) IndexHistory() (int, error) {
+	return gc.indexCommits(0)
+}
+
+// indexCommits indexes the unindexed commits among the last limit commits
+// on HEAD, or all of them when limit is 0
+func (gc *GitCommenter) indexCommits(limit int) (int, error) {
+	if gc.config.EmbeddingModel == "" {
+		return 0, fmt.Errorf("no embedding model configured")
+	}
+	if err := gc.checkWritable(); err != nil {
+		return 0, err
+	}
+	index, err := gc.loadIndex()
+	if err != nil {
+		return 0, err
+	}
+	args := []string{"rev-list", "--no-merges", "HEAD"}
+	if limit > 0 {
+		args = append(args, "-n", fmt.Sprint(limit))
+	}
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		// A repository without commits has nothing to index
+		if _, headErr := gc.HeadCommit(); headErr != nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to list commits: %w", err)
+	}
+	var pending []string
+	for _, hash := range splitLines(output) {
+		if _, ok := index[hash]; !ok {
+			pending = append(pending, hash)
+		}
+	}
+
+	indexed := 0
+	for start := 0; start < len(pending); start += indexBatchSize {
+		batch := pending[start:min(start+indexBatchSize, len(pending))]
+		diffs, err := gc.commitDiffs(batch)
+		if err != nil {
+			return indexed, err
+		}
+		// Commits without a diff are indexed without a vector so they are
+		// not looked at again
+		entries := make([]indexEntry, len(batch))
+		var texts []string
+		var embedded []int
+		for i, hash := range batch {
+			entries[i].Hash = hash
+			if text := embeddingText(diffs[hash]); text != "" {
+				texts = append(texts, text)
+				embedded = append(embedded, i)
+			}
+		}
+		if len(texts) > 0 {
+			vectors, err := gc.Embed(texts)
+			if err != nil {
+				return indexed, err
+			}
+			for j, i := range embedded {
+				entries[i].Vector = vectors[j]
+			}
+		}
+		if err := gc.appendIndex(entries); err != nil {
+			return indexed, err
+		}
+		indexed += len(batch)
+	}
+	return indexed, nil
+}
+
+// commitDiffs returns the patches of commits keyed by hash, with one line
+// of context to keep them short and without the files listed in .aiignore
+func (gc *GitCommenter) commitDiffs(hashes []string) (map[string]string, error) {
+	args := append([]string{"show", "--patch", "--unified=1", "--format=%x1e%H"}, hashes...)
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diffs of commits: %w", err)
+	}
+	diffs := make(map[string]string)
+	for _, record := range strings.Split(output, "\x1e") {
+		if hash, diff, ok := strings.Cut(record, "\n"); ok {
+			if diffs[hash], err = gc.withoutIgnoredDiffs(diff); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return diffs, nil
+}
+
+// FindSimilar returns the indexed commits on HEAD with the diffs most
+// similar to diff, most similar first (see IndexHistory). Commits that
+// are no longer on HEAD, e.g. after a rebase, are skipped.
+func (gc *GitCommenter) FindSimilar(diff string) ([]SimilarCommit, error) {
+	text := embeddingText(diff)
+	if text == "" {
+		return nil, fmt.Errorf("no diff to compare")
+	}
+	index, err := gc.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	if len(index) == 0 {
+		return nil, nil
+	}
+	vectors, err := gc.Embed([]string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		hash       string
+		similarity float64
+	}
+	var matches []scored
+	for hash, vector := range index {
+		if len(vector) > 0 {
+			matches = append(matches, scored{hash, cosineSimilarity(vectors[0], vector)})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].similarity != matches[j].similarity {
+			return matches[i].similarity > matches[j].similarity
+		}
+		return matches[i].hash < matches[j].hash
+	})
+
+	// Commits rewritten or dropped since they were indexed are not on HEAD
+	var hashes []string
+	similarity := make(map[string]float64)
+	for _, match := range matches {
+		if len(hashes) == findSimilarLimit {
+			break
+		}
+		if _, err := gc.gitOutput("merge-base", "--is-ancestor", match.hash, "HEAD"); err == nil {
+			hashes = append(hashes, match.hash)
+			similarity[match.hash] = match.similarity
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"log", "--no-walk=unsorted", logFormat}, hashes...)
+	output, err := gc.gitOutput(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read similar commits: %w", err)
+	}
+	commits := make(map[string]Commit)
+	for _, commit := range parseLog(output) {
+		commits[commit.Hash] = commit
+	}
+	var similar []SimilarCommit
+	for _, hash := range hashes {
+		if commit, ok := commits[hash]; ok {
+			similar = append(similar, SimilarCommit{Commit: commit, Similarity: similarity[hash]})
+		}
+	}
+	return similar, nil
+}
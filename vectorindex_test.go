@@ -0,0 +1,79 @@
+package gitcommenter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexHistoryAndFindSimilar(t *testing.T) {
+	dir := initTestRepo(t)
+	commit := func(file, content, message string) {
+		t.Helper()
+		writeFile(t, dir, file, content)
+		gitRun(t, dir, "add", ".")
+		gitRun(t, dir, "commit", "-q", "-m", message)
+	}
+	commit("limiter.go", "package app\n\nfunc limit() {}\n", "feat(limit): add the token bucket")
+	commit("README.md", "# app\n", "docs: describe the app")
+
+	var embedded int
+	server := embedServer(t, &embedded)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	config.OllamaEndpoint = server.URL
+	config.EmbeddingModel = "nomic-embed-text"
+	gc := New(config)
+
+	if n, err := gc.IndexHistory(); err != nil || n != 2 {
+		t.Fatalf("Expected two commits indexed, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", "ai-index", "nomic-embed-text.jsonl")); err != nil {
+		t.Errorf("Expected the index in .git/ai-index: %v", err)
+	}
+	if n, err := gc.IndexHistory(); err != nil || n != 0 || embedded != 2 {
+		t.Errorf("Expected nothing new to index, got %d, %v after %d embeddings", n, err, embedded)
+	}
+
+	// Committing through the library indexes the commit right away
+	writeFile(t, dir, "limiter.go", "package app\n\nfunc limit() {}\n\nfunc limitBurst() {}\n")
+	gitRun(t, dir, "add", ".")
+	if err := gc.Commit(&CommitSuggestion{Subject: "feat(limit): allow bursts"}); err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+	if n, err := gc.IndexHistory(); err != nil || n != 0 {
+		t.Errorf("Expected the new commit indexed on commit, got %d more, %v", n, err)
+	}
+
+	similar, err := gc.FindSimilar("+func limitRefill() {}\n")
+	if err != nil {
+		t.Fatalf("FindSimilar returned error: %v", err)
+	}
+	if len(similar) != 3 || !strings.HasPrefix(similar[0].Commit.Subject, "feat(limit)") || similar[0].Similarity != 1 {
+		t.Fatalf("Expected the limiter commits first, got %+v", similar)
+	}
+	if last := similar[2]; last.Commit.Subject != "docs: describe the app" || last.Similarity != 0 {
+		t.Errorf("Expected the docs commit last, got %+v", last)
+	}
+
+	// Rewritten commits drop out of the results
+	gitRun(t, dir, "commit", "-q", "--amend", "-m", "feat(limit): allow request bursts")
+	similar, err = gc.FindSimilar("+func limitRefill() {}\n")
+	if err != nil {
+		t.Fatalf("FindSimilar returned error: %v", err)
+	}
+	for _, match := range similar {
+		if match.Commit.Subject == "feat(limit): allow bursts" {
+			t.Errorf("Expected the amended commit skipped, got %+v", similar)
+		}
+	}
+
+	config.ReadOnly = true
+	if _, err := gc.IndexHistory(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly in a read-only repository, got %v", err)
+	}
+}
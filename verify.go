@@ -0,0 +1,60 @@
+package gitcommenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommitVerification compares the last commit with the changes that were
+// scanned before committing
+type CommitVerification struct {
+	// Missing are scanned files the commit does not contain
+	Missing []string
+	// Unexpected are committed files that were not scanned, e.g. staged by
+	// a hook or by a broad "git add ."
+	Unexpected []string
+	// Diffstat is the summary of HEAD from git show --stat --summary
+	Diffstat string
+}
+
+// OK reports whether the commit contains exactly the scanned files
+func (v *CommitVerification) OK() bool {
+	return len(v.Missing) == 0 && len(v.Unexpected) == 0
+}
+
+// VerifyCommit checks that HEAD contains exactly the expected changes,
+// typically the results of ScanStagedChanges and ScanIgnoredChanges before
+// committing
+func (gc *GitCommenter) VerifyCommit(expected []FileChange) (*CommitVerification, error) {
+	output, err := gc.gitOutput("diff-tree", "--root", "--no-commit-id", "-r", "-M", "--name-status", "-z", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list committed files: %w", err)
+	}
+	committed := make(map[string]bool)
+	for _, file := range parseNameStatus(output) {
+		committed[NormalizePath(file.Path)] = true
+	}
+
+	verification := &CommitVerification{}
+	for _, change := range expected {
+		path := NormalizePath(change.FilePath)
+		if committed[path] {
+			delete(committed, path)
+		} else {
+			verification.Missing = append(verification.Missing, path)
+		}
+	}
+	for path := range committed {
+		verification.Unexpected = append(verification.Unexpected, path)
+	}
+	sort.Strings(verification.Missing)
+	sort.Strings(verification.Unexpected)
+
+	stat, err := gc.gitOutput("show", "--stat", "--summary", "--format=", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize the commit: %w", err)
+	}
+	verification.Diffstat = strings.Trim(stat, "\n")
+	return verification, nil
+}
@@ -0,0 +1,53 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCommit(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		writeFile(t, dir, name, "package a\n")
+	}
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+	gc.SetGitBackend(&ExecBackend{Dir: dir})
+
+	gitRun(t, dir, "add", "a.go", "b.go")
+	scanned, err := gc.ScanStagedChanges()
+	if err != nil {
+		t.Fatalf("ScanStagedChanges returned error: %v", err)
+	}
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	verification, err := gc.VerifyCommit(scanned)
+	if err != nil {
+		t.Fatalf("VerifyCommit returned error: %v", err)
+	}
+	if !verification.OK() || !strings.Contains(verification.Diffstat, "2 files changed") {
+		t.Errorf("Expected the root commit to match the scan, got %+v", verification)
+	}
+
+	// The index changes between scanning and committing
+	writeFile(t, dir, "a.go", "package a // edited\n")
+	writeFile(t, dir, "b.go", "package a // edited\n")
+	gitRun(t, dir, "add", "a.go", "b.go")
+	scanned, _ = gc.ScanStagedChanges()
+	gitRun(t, dir, "reset", "-q", "b.go")
+	gitRun(t, dir, "add", "c.go")
+	gitRun(t, dir, "commit", "-q", "-m", "second")
+
+	verification, err = gc.VerifyCommit(scanned)
+	if err != nil {
+		t.Fatalf("VerifyCommit returned error: %v", err)
+	}
+	if verification.OK() || strings.Join(verification.Missing, ",") != "b.go" || strings.Join(verification.Unexpected, ",") != "c.go" {
+		t.Errorf("Expected b.go missing and c.go unexpected, got %+v", verification)
+	}
+	if !strings.Contains(verification.Diffstat, "create mode 100644 c.go") {
+		t.Errorf("Expected the new file in the summary, got:\n%s", verification.Diffstat)
+	}
+}
@@ -0,0 +1,93 @@
+package gitcommenter
+
+import "strings"
+
+// IndexBase is the FileChange.Base of a diff made from the index, e.g. of
+// the unstaged changes. It is stage 0 in git's :<stage>:<path> syntax.
+const IndexBase = ":0"
+
+// base returns the version the diff of the change was made from
+func (c FileChange) base() string {
+	if c.Base == "" {
+		return "HEAD"
+	}
+	return c.Base
+}
+
+// changeVersions returns the content of a changed file before and after
+// the change: before is read from the version the diff was made from, and
+// after is before with the diff applied, so both match the diff whatever
+// its source (the index, the working tree, a ref range or a part of a
+// split). A version that does not exist is empty; ok is false when the
+// file cannot be read or the diff does not apply.
+func (gc *GitCommenter) changeVersions(change FileChange) (before, after string, ok bool) {
+	oldPath := change.FilePath
+	if change.OldPath != "" {
+		oldPath = change.OldPath
+	}
+	if change.ChangeType != "added" {
+		content, err := gc.gitOutput("show", change.base()+":"+oldPath)
+		if err != nil {
+			return "", "", false
+		}
+		before = content
+	}
+	if change.ChangeType == "deleted" {
+		return before, "", true
+	}
+	after, ok = applyDiff(before, change.Diff)
+	return before, after, ok
+}
+
+// applyDiff applies the hunks of a file diff to before, the version it was
+// made from. It reports false when the hunks do not match before.
+func applyDiff(before, diff string) (string, bool) {
+	old := strings.SplitAfter(before, "\n")
+	if old[len(old)-1] == "" {
+		old = old[:len(old)-1]
+	}
+
+	var result []string
+	next := 0 // the index in old of the next line not yet copied
+	inHunk := false
+	var last byte
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if match := hunkHeader.FindStringSubmatch(strings.TrimSuffix(line, "\n")); match != nil {
+			start, lines := hunkRange(match[1], match[2])
+			// An insertion names the line it follows, other hunks their first line
+			if lines > 0 {
+				start--
+			}
+			if start < next || start > len(old) {
+				return "", false
+			}
+			result = append(result, old[next:start]...)
+			next, inHunk = start, true
+			continue
+		}
+		if !inHunk || line == "" {
+			continue
+		}
+		switch line[0] {
+		case ' ', '-':
+			if next == len(old) || strings.TrimSuffix(old[next], "\n") != strings.TrimSuffix(line[1:], "\n") {
+				return "", false
+			}
+			if line[0] == ' ' {
+				result = append(result, old[next])
+			}
+			next++
+		case '+':
+			result = append(result, line[1:])
+		case '\\':
+			// "\ No newline at end of file" is about the line before
+			if last != '-' && len(result) > 0 {
+				result[len(result)-1] = strings.TrimSuffix(result[len(result)-1], "\n")
+			}
+		default:
+			inHunk = false
+		}
+		last = line[0]
+	}
+	return strings.Join(append(result, old[next:]...), ""), true
+}
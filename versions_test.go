@@ -0,0 +1,29 @@
+package gitcommenter
+
+import "testing"
+
+func TestApplyDiff(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive"
+	tests := []struct {
+		name  string
+		diff  string
+		after string
+		ok    bool
+	}{
+		{"change", "@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n", "one\nTWO\nthree\nfour\nfive", true},
+		{"insertion", "@@ -2,0 +3,2 @@\n+a\n+b\n", "one\ntwo\na\nb\nthree\nfour\nfive", true},
+		{"two hunks", "@@ -1 +0,0 @@\n-one\n@@ -4,2 +3,2 @@\n four\n-five\n\\ No newline at end of file\n+FIVE\n", "two\nthree\nfour\nFIVE\n", true},
+		{"no newline added", "@@ -5 +5 @@\n-five\n\\ No newline at end of file\n+5\n\\ No newline at end of file\n", "one\ntwo\nthree\nfour\n5", true},
+		{"header only", "diff --git a/f b/f\nold mode 100644\nnew mode 100755\n", before, true},
+		{"mismatch", "@@ -1,2 +1,2 @@\n one\n-zwei\n+2\n", "", false},
+	}
+	for _, test := range tests {
+		after, ok := applyDiff(before, test.diff)
+		if after != test.after || ok != test.ok {
+			t.Errorf("%s: applyDiff = %q, %v, want %q, %v", test.name, after, ok, test.after, test.ok)
+		}
+	}
+	if after, ok := applyDiff("", "@@ -0,0 +1,2 @@\n+new\n+file\n"); after != "new\nfile\n" || !ok {
+		t.Errorf("Expected a new file from its diff, got %q, %v", after, ok)
+	}
+}
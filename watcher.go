@@ -0,0 +1,143 @@
+package gitcommenter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ConfigChange describes a setting whose effective value changed
+type ConfigChange struct {
+	Key    string
+	Old    string
+	New    string
+	Source string
+}
+
+// String formats the change for logging
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q (%s)", c.Key, c.Old, c.New, c.Source)
+}
+
+// ConfigWatcher polls the config layers of a repository (files, git config
+// and environment) so long-running modes can apply changes without a restart
+type ConfigWatcher struct {
+	repoPath string
+	profile  string
+	interval time.Duration
+	current  []EffectiveSetting
+	// load is LoadConfigLayers or LoadUntrustedConfigLayers
+	load func(repoPath, profile string) ([]ConfigLayer, error)
+}
+
+// DefaultWatchInterval is used when NewConfigWatcher is given no interval
+const DefaultWatchInterval = 2 * time.Second
+
+// NewConfigWatcher loads the current configuration of repoPath (with the
+// given profile, if any) and returns a watcher that polls it every interval
+func NewConfigWatcher(repoPath, profile string, interval time.Duration) (*ConfigWatcher, error) {
+	return newConfigWatcher(repoPath, profile, interval, LoadConfigLayers)
+}
+
+// NewUntrustedConfigWatcher is NewConfigWatcher for repositories the user
+// does not trust, whose config file it ignores (see
+// LoadUntrustedConfigLayers)
+func NewUntrustedConfigWatcher(repoPath, profile string, interval time.Duration) (*ConfigWatcher, error) {
+	return newConfigWatcher(repoPath, profile, interval, LoadUntrustedConfigLayers)
+}
+
+// newConfigWatcher returns a watcher reading the layers with load
+func newConfigWatcher(repoPath, profile string, interval time.Duration, load func(repoPath, profile string) ([]ConfigLayer, error)) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	layers, err := load(repoPath, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigWatcher{
+		repoPath: repoPath,
+		profile:  profile,
+		interval: interval,
+		current:  ResolveSettings(layers),
+		load:     load,
+	}, nil
+}
+
+// Check reloads the configuration and returns the merged settings and the
+// settings that changed since the previous check
+func (w *ConfigWatcher) Check() (*FileConfig, []ConfigChange, error) {
+	layers, err := w.load(w.repoPath, w.profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := &FileConfig{}
+	for _, layer := range layers {
+		merged.Merge(layer.Config)
+	}
+
+	next := ResolveSettings(layers)
+	changes := diffSettings(w.current, next)
+	w.current = next
+
+	return merged, changes, nil
+}
+
+// Watch calls onChange whenever the effective configuration changes, until
+// ctx is cancelled. Reload errors (e.g. a half-written YAML file) are passed
+// to onError and the previous configuration stays in effect.
+func (w *ConfigWatcher) Watch(ctx context.Context, onChange func(*FileConfig, []ConfigChange), onError func(error)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			merged, changes, err := w.Check()
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			if len(changes) > 0 {
+				onChange(merged, changes)
+			}
+		}
+	}
+}
+
+// diffSettings compares two resolved setting lists keyed by setting name.
+// A setting only one of them has changed too: from or to "".
+func diffSettings(old, next []EffectiveSetting) []ConfigChange {
+	previous := make(map[string]EffectiveSetting)
+	for _, setting := range old {
+		previous[setting.Key] = setting
+	}
+
+	var changes []ConfigChange
+	for _, setting := range next {
+		before, ok := previous[setting.Key]
+		delete(previous, setting.Key)
+		if !ok || before.Value != setting.Value {
+			changes = append(changes, ConfigChange{
+				Key:    setting.Key,
+				Old:    before.Value,
+				New:    setting.Value,
+				Source: setting.Source,
+			})
+		}
+	}
+	// The settings left were removed
+	for _, setting := range old {
+		if _, ok := previous[setting.Key]; ok {
+			changes = append(changes, ConfigChange{Key: setting.Key, Old: setting.Value, Source: "removed"})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,63 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigWatcherCheck(t *testing.T) {
+	dir := initTestRepo(t)
+	// Isolate from the developer's own config
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	watcher, err := NewConfigWatcher(dir, "", 0)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher returned error: %v", err)
+	}
+
+	if _, changes, err := watcher.Check(); err != nil || len(changes) != 0 {
+		t.Fatalf("Expected no changes, got %v (err %v)", changes, err)
+	}
+
+	path := filepath.Join(dir, ConfigFileName)
+	if err := os.WriteFile(path, []byte("model: mistral\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, changes, err := watcher.Check()
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if merged.Model != "mistral" {
+		t.Errorf("Expected merged model mistral, got %q", merged.Model)
+	}
+	if len(changes) != 1 || changes[0].Key != "model" || changes[0].Old != "llama2" || changes[0].New != "mistral" {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+
+	// A broken file is reported and does not replace the last good state
+	if err := os.WriteFile(path, []byte("model: [unterminated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := watcher.Check(); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
+
+func TestDiffSettings(t *testing.T) {
+	old := []EffectiveSetting{{Key: "model", Value: "llama2"}, {Key: "style", Value: "conventional", Source: "repository"}}
+	next := []EffectiveSetting{{Key: "model", Value: "mistral", Source: "user"}, {Key: "language", Value: "de", Source: "env"}}
+
+	changes := diffSettings(old, next)
+	want := []ConfigChange{
+		{Key: "model", Old: "llama2", New: "mistral", Source: "user"},
+		{Key: "language", New: "de", Source: "env"},
+		{Key: "style", Old: "conventional", Source: "removed"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("diffSettings = %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,121 @@
+package gitcommenter
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Workflow is a preset bundling push, branching and message defaults for a
+// team's way of working
+type Workflow struct {
+	// Name is the identifier used with --workflow and in config files
+	Name string
+	// Description is a one-line summary shown in help and the init wizard
+	Description string
+	// Style is the default commit message style
+	Style string
+	// Push is the default push behavior: "always", "ask" or "never"
+	Push string
+	// CreateBranch creates a topic branch named after the subject when
+	// committing on the default branch
+	CreateBranch bool
+	// PushRefspec is the destination ref pattern; "{branch}" is replaced by
+	// the current branch. Empty pushes to the upstream.
+	PushRefspec string
+	// SetUpstream pushes with -u so new branches track the remote
+	SetUpstream bool
+	// ChangeID appends a Gerrit Change-Id trailer to every message
+	ChangeID bool
+}
+
+// Workflows are the built-in presets keyed by name
+var Workflows = map[string]Workflow{
+	"trunk": {
+		Name:        "trunk",
+		Description: "commit straight to the main branch and push immediately",
+		Style:       "conventional",
+		Push:        "always",
+	},
+	"pr-flow": {
+		Name:         "pr-flow",
+		Description:  "commit on a topic branch and push it for a pull request",
+		Style:        "conventional",
+		Push:         "ask",
+		CreateBranch: true,
+		SetUpstream:  true,
+	},
+	"gerrit": {
+		Name:        "gerrit",
+		Description: "push to refs/for/<branch> for review with a Change-Id trailer",
+		Style:       "plain",
+		Push:        "ask",
+		PushRefspec: "HEAD:refs/for/{branch}",
+		ChangeID:    true,
+	},
+}
+
+// WorkflowNames returns the names of the built-in presets in sorted order
+func WorkflowNames() []string {
+	names := make([]string, 0, len(Workflows))
+	for name := range Workflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupWorkflow returns the preset with the given name
+func LookupWorkflow(name string) (Workflow, error) {
+	workflow, ok := Workflows[name]
+	if !ok {
+		return Workflow{}, fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(WorkflowNames(), ", "))
+	}
+	return workflow, nil
+}
+
+// Refspec returns the push refspec for the given branch, or "" to push to the upstream
+func (w Workflow) Refspec(branch string) string {
+	return strings.ReplaceAll(w.PushRefspec, "{branch}", branch)
+}
+
+var branchNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// BranchName derives a topic branch name from a commit subject, e.g.
+// "feat(cli): add init wizard" becomes "feat/add-init-wizard"
+func BranchName(subject string) string {
+	prefix := "change"
+	description := subject
+
+	// Use the conventional commit type as the branch prefix
+	if colon := strings.Index(subject, ":"); colon != -1 {
+		commitType := subject[:colon]
+		if paren := strings.Index(commitType, "("); paren != -1 {
+			commitType = commitType[:paren]
+		}
+		commitType = strings.TrimSuffix(strings.TrimSpace(commitType), "!")
+		if commitType != "" && !strings.ContainsAny(commitType, " \t") {
+			prefix = strings.ToLower(commitType)
+			description = subject[colon+1:]
+		}
+	}
+
+	slug := strings.Trim(branchNameInvalid.ReplaceAllString(strings.ToLower(description), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = time.Now().Format("20060102-150405")
+	}
+
+	return prefix + "/" + slug
+}
+
+// ChangeID returns a Gerrit style Change-Id for the given message
+func ChangeID(message string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s\n%d", message, time.Now().UnixNano())))
+	return fmt.Sprintf("I%x", sum)
+}
@@ -0,0 +1,66 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupWorkflow(t *testing.T) {
+	for _, name := range []string{"trunk", "pr-flow", "gerrit"} {
+		if _, err := LookupWorkflow(name); err != nil {
+			t.Errorf("LookupWorkflow(%s) returned error: %v", name, err)
+		}
+	}
+
+	if _, err := LookupWorkflow("waterfall"); err == nil {
+		t.Error("Expected an error for an unknown workflow")
+	}
+}
+
+func TestWorkflowRefspec(t *testing.T) {
+	gerrit := Workflows["gerrit"]
+	if got := gerrit.Refspec("main"); got != "HEAD:refs/for/main" {
+		t.Errorf("Expected HEAD:refs/for/main, got %s", got)
+	}
+
+	if got := Workflows["trunk"].Refspec("main"); got != "" {
+		t.Errorf("Expected empty refspec for trunk, got %s", got)
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		subject  string
+		expected string
+	}{
+		{"feat(cli): add init wizard", "feat/add-init-wizard"},
+		{"fix!: Handle EMPTY diffs", "fix/handle-empty-diffs"},
+		{"Update README", "change/update-readme"},
+	}
+
+	for _, test := range tests {
+		if got := BranchName(test.subject); got != test.expected {
+			t.Errorf("BranchName(%q) = %s, want %s", test.subject, got, test.expected)
+		}
+	}
+
+	long := BranchName("feat: " + strings.Repeat("word ", 20))
+	if len(long) > len("feat/")+40 || strings.HasSuffix(long, "-") {
+		t.Errorf("Expected a trimmed branch name, got %s", long)
+	}
+}
+
+func TestAddTrailer(t *testing.T) {
+	suggestion := &CommitSuggestion{Subject: "feat: x", Body: "Explain the change."}
+	suggestion.AddTrailer("Change-Id", "I123")
+	suggestion.AddTrailer("Signed-off-by", "Dev <dev@example.com>")
+
+	expected := "Explain the change.\n\nChange-Id: I123\nSigned-off-by: Dev <dev@example.com>"
+	if suggestion.Body != expected {
+		t.Errorf("Unexpected body:\n%s", suggestion.Body)
+	}
+
+	if id := ChangeID("msg"); len(id) != 41 || id[0] != 'I' {
+		t.Errorf("Unexpected Change-Id %s", id)
+	}
+}
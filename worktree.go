@@ -0,0 +1,162 @@
+package gitcommenter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ScanUnstagedChanges scans the working tree changes that are not staged:
+// tracked files edited or deleted since staging (git diff) and untracked
+// files. Stage them with StageChanges before committing.
+func (gc *GitCommenter) ScanUnstagedChanges() ([]FileChange, error) {
+	return gc.scanWorkingTree()
+}
+
+// ScanAllChanges scans every change in the working tree, staged or not,
+// against HEAD, and untracked files. Stage them with StageChanges before
+// committing.
+func (gc *GitCommenter) ScanAllChanges() ([]FileChange, error) {
+	base := "HEAD"
+	if _, err := gc.HeadCommit(); err != nil {
+		base = emptyTree
+	}
+	return gc.scanWorkingTree(base)
+}
+
+// scanWorkingTree returns the changes of the working tree against the
+// index, or against a revision, followed by the untracked files
+func (gc *GitCommenter) scanWorkingTree(revisions ...string) ([]FileChange, error) {
+	if err := gc.ensureGitRepository(); err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+	changes, err := gc.diffChanges(revisions...)
+	ignored := errors.Is(err, ErrOnlyIgnored)
+	if err != nil && !ignored {
+		return nil, err
+	}
+	untracked, err := gc.untrackedChanges()
+	if errors.Is(err, ErrOnlyIgnored) {
+		ignored = true
+	} else if err != nil {
+		return nil, err
+	}
+	if changes = append(changes, untracked...); len(changes) == 0 && ignored {
+		return nil, ErrOnlyIgnored
+	}
+	// The diffs were made from the revision, or from the index
+	base := IndexBase
+	if len(revisions) > 0 {
+		base = revisions[0]
+	}
+	for i := range changes {
+		changes[i].Base = base
+	}
+	return changes, nil
+}
+
+// untrackedChanges describes the untracked files that are not ignored, by
+// git or .aiignore, as additions
+func (gc *GitCommenter) untrackedChanges() ([]FileChange, error) {
+	top, err := gc.gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the working tree: %w", err)
+	}
+	output, err := gc.gitOutput("ls-files", "--others", "--exclude-standard", "--full-name", "-z", "--", ":/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var changes []FileChange
+	for _, path := range strings.Split(output, "\x00") {
+		if path == "" {
+			continue
+		}
+		change, err := untrackedChange(strings.TrimSpace(top), path)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return gc.withoutIgnored(changes)
+}
+
+// untrackedChange describes an untracked file as an addition, with the
+// diff git would show once it is staged
+func untrackedChange(top, path string) (FileChange, error) {
+	full := filepath.Join(top, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if err != nil {
+		return FileChange{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Symbolic links are stored as their target, and never followed
+	mode := "100644"
+	var content []byte
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		mode = "120000"
+		target, err := os.Readlink(full)
+		if err != nil {
+			return FileChange{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		content = []byte(target)
+	case info.Mode().IsRegular():
+		if info.Mode()&0o111 != 0 {
+			mode = "100755"
+		}
+		if content, err = os.ReadFile(full); err != nil {
+			return FileChange{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	change := FileChange{FilePath: path, ChangeType: "added"}
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "diff --git a/%s b/%s\nnew file mode %s\n", path, path, mode)
+	switch {
+	case len(content) == 0:
+	case bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0:
+		fmt.Fprintf(&diff, "Binary files /dev/null and b/%s differ\n", path)
+	default:
+		text := string(content)
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+		change.LinesAdded = len(lines)
+		// git ends names containing a space with a tab
+		name := "b/" + path
+		if strings.Contains(path, " ") {
+			name += "\t"
+		}
+		fmt.Fprintf(&diff, "--- /dev/null\n+++ %s\n@@ -0,0 +1", name)
+		if len(lines) > 1 {
+			fmt.Fprintf(&diff, ",%d", len(lines))
+		}
+		diff.WriteString(" @@\n")
+		for _, line := range lines {
+			diff.WriteString("+" + line + "\n")
+		}
+		if !strings.HasSuffix(text, "\n") {
+			diff.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	change.Diff = diff.String()
+	return change, nil
+}
+
+// StageChanges stages the files of changes, e.g. from ScanUnstagedChanges,
+// so the commit contains exactly what was analyzed. The old path of a
+// renamed file is staged too, recording its removal.
+func (gc *GitCommenter) StageChanges(changes []FileChange) error {
+	var paths []string
+	for _, change := range changes {
+		if change.OldPath != "" && change.OldPath != change.FilePath {
+			paths = append(paths, change.OldPath)
+		}
+		paths = append(paths, change.FilePath)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return gc.Restage(paths)
+}
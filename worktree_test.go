@@ -0,0 +1,73 @@
+package gitcommenter
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestScanWorkingTree(t *testing.T) {
+	dir := initTestRepo(t)
+
+	config := DefaultConfig()
+	config.RepositoryPath = dir
+	gc := New(config)
+
+	// Without commits everything is compared with the empty tree
+	writeFile(t, dir, "main.go", "package main\n")
+	if changes, err := gc.ScanAllChanges(); err != nil || len(changes) != 1 || changes[0].ChangeType != "added" {
+		t.Fatalf("Expected the untracked file before the first commit, got %+v, %v", changes, err)
+	}
+	writeFile(t, dir, "old.go", "package main\n\nfunc old() {}\n")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "first")
+
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, dir, "staged.go", "package staged\n")
+	gitRun(t, dir, "add", "staged.go")
+	writeFile(t, dir, "docs/new file.md", "# Title\nno newline")
+	os.Remove(filepath.Join(dir, "old.go"))
+
+	changes, err := gc.ScanUnstagedChanges()
+	if err != nil {
+		t.Fatalf("ScanUnstagedChanges returned error: %v", err)
+	}
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.FilePath+":"+change.ChangeType)
+	}
+	if got := strings.Join(paths, ","); got != "main.go:modified,old.go:deleted,docs/new file.md:added" {
+		t.Fatalf("Unexpected unstaged changes %q", got)
+	}
+	if changes[0].LinesAdded != 2 || changes[2].LinesAdded != 2 {
+		t.Errorf("Unexpected line counts %+v", changes)
+	}
+	// The symbols are those of the working tree, not of the index
+	if symbols := gc.StagedSymbolChanges(changes); len(symbols) != 2 || symbols[0].String() != "added func main()" || symbols[1].String() != "removed func old()" {
+		t.Errorf("Expected the symbols of the unstaged changes, got %v", symbols)
+	}
+
+	all, err := gc.ScanAllChanges()
+	if err != nil {
+		t.Fatalf("ScanAllChanges returned error: %v", err)
+	}
+	if len(all) != 4 || all[2].FilePath != "staged.go" {
+		t.Errorf("Expected the staged file among all changes, got %+v", all)
+	}
+
+	if err := gc.StageChanges(changes); err != nil {
+		t.Fatalf("StageChanges returned error: %v", err)
+	}
+	if got := gitRun(t, dir, "diff", "--cached", "--name-only"); got != "docs/new file.md\nmain.go\nold.go\nstaged.go\n" {
+		t.Errorf("Expected the analyzed files staged, got %q", got)
+	}
+
+	// The diff of an untracked file is the one git shows once it is staged
+	staged := gitRun(t, dir, "diff", "--cached", "--", "docs/new file.md")
+	staged = regexp.MustCompile(`(?m)^index .*\n`).ReplaceAllString(staged, "")
+	if changes[2].Diff != staged {
+		t.Errorf("Untracked diff differs:\n%s\nwant:\n%s", changes[2].Diff, staged)
+	}
+}
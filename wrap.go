@@ -0,0 +1,94 @@
+package gitcommenter
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// BodyWrapWidth is the column at which message bodies are wrapped, so that
+// git log renders them cleanly in an 80 column terminal
+const BodyWrapWidth = 72
+
+var (
+	listMarker  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+`)
+	trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z-]*( [A-Z]+)*: \S`)
+)
+
+// WrapBody hard-wraps a message body at width columns. Paragraphs are
+// refilled, list items get a hanging indent, and fenced or indented code
+// blocks and trailer lines such as "Signed-off-by:" are left untouched.
+// Words longer than the width are not split.
+func WrapBody(body string, width int) string {
+	var out []string
+	var item []string // words of the paragraph or list item being filled
+	indent, hanging := "", ""
+
+	flush := func() {
+		if len(item) > 0 {
+			out = append(out, fillWords(item, indent, hanging, width)...)
+		}
+		item, indent, hanging = nil, "", ""
+	}
+
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			inFence = !inFence
+			out = append(out, line)
+		case inFence:
+			out = append(out, line)
+		case trimmed == "":
+			flush()
+			out = append(out, "")
+		case listMarker.MatchString(line):
+			flush()
+			marker := listMarker.FindString(line)
+			indent, hanging = marker, strings.Repeat(" ", utf8.RuneCountInString(marker))
+			item = strings.Fields(line[len(marker):])
+		case len(item) > 0 && hanging != "" && strings.HasPrefix(line, " "):
+			// Continuation of a list item
+			item = append(item, strings.Fields(line)...)
+		case strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t"):
+			flush()
+			out = append(out, line)
+		case trailerLine.MatchString(line):
+			flush()
+			out = append(out, line)
+		default:
+			if hanging != "" {
+				flush()
+			}
+			item = append(item, strings.Fields(line)...)
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// fillWords lays words out in lines of at most width columns, starting with
+// first and continuing with rest as the line prefix
+func fillWords(words []string, first, rest string, width int) []string {
+	var lines []string
+	line := first
+	length := utf8.RuneCountInString(first)
+	empty := true
+	for _, word := range words {
+		wordLength := utf8.RuneCountInString(word)
+		if !empty && length+1+wordLength > width {
+			lines = append(lines, line)
+			line, length, empty = rest, utf8.RuneCountInString(rest), true
+		}
+		if !empty {
+			line += " "
+			length++
+		}
+		line += word
+		length += wordLength
+		empty = false
+	}
+	return append(lines, line)
+}
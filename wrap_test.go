@@ -0,0 +1,53 @@
+package gitcommenter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapBody(t *testing.T) {
+	body := strings.Join([]string{
+		"This change adds a wizard that asks for the model, the commit style and the push behavior before writing the config file.",
+		"",
+		"- Detects installed Ollama models and recommends one that fits the available memory",
+		"- Keeps short items",
+		"  with continuation lines",
+		"",
+		"```",
+		"ai-git-auto init --model llama3 --style conventional --push ask --workflow trunk",
+		"```",
+		"",
+		"    indented code stays exactly as it is, no matter how long the line gets",
+		"",
+		"See https://example.com/a/very/long/url/that/cannot/be/split/anywhere/at/all/really",
+		"Signed-off-by: Somebody With A Very Long Name <somebody.with.a.very.long.name@example.com>",
+	}, "\n")
+
+	want := strings.Join([]string{
+		"This change adds a wizard that asks for the model, the commit style and",
+		"the push behavior before writing the config file.",
+		"",
+		"- Detects installed Ollama models and recommends one that fits the",
+		"  available memory",
+		"- Keeps short items with continuation lines",
+		"",
+		"```",
+		"ai-git-auto init --model llama3 --style conventional --push ask --workflow trunk",
+		"```",
+		"",
+		"    indented code stays exactly as it is, no matter how long the line gets",
+		"",
+		"See",
+		"https://example.com/a/very/long/url/that/cannot/be/split/anywhere/at/all/really",
+		"Signed-off-by: Somebody With A Very Long Name <somebody.with.a.very.long.name@example.com>",
+	}, "\n")
+
+	if got := WrapBody(body, BodyWrapWidth); got != want {
+		t.Errorf("WrapBody mismatch:\n%s", firstDifference(want, got))
+	}
+
+	// Wrapping is idempotent
+	if again := WrapBody(want, BodyWrapWidth); again != want {
+		t.Errorf("Rewrapping changed the body:\n%s", firstDifference(want, again))
+	}
+}